@@ -8,14 +8,54 @@ import (
 type Eviction struct {
 	PodName      string      `json:"podName,omitempty"`
 	EvictionTime metav1.Time `json:"evictionTime,omitempty"`
+	// Inferred is set when the eviction was synthesized by the polling detector
+	// from a PDB and pod deletions on cordoned nodes, rather than observed directly.
+	// +optional
+	Inferred bool `json:"inferred,omitempty"`
 }
 
 // EvictionAutoScalerSpec defines the desired state of EvictionAutoScaler
 type EvictionAutoScalerSpec struct {
-	//todo make this mirror horizontalpodautoscaler's target reference
-	TargetName   string   `json:"targetName"`
-	TargetKind   string   `json:"targetKind"` //deployment or statefulset (anything with an update statedgy)
-	LastEviction Eviction `json:"lastEviction,omitempty"`
+	// TargetName is the name of the workload to surge. It is always looked up in the
+	// EvictionAutoScaler's namespace; cross-namespace targets are not supported.
+	// +kubebuilder:validation:XValidation:rule="!self.contains('/')",message="targetName must name a workload in the EvictionAutoScaler's namespace; cross-namespace targets are not supported"
+	TargetName string `json:"targetName"`
+	// TargetKind is the kind of the target: deployment, statefulset or rollout. Any
+	// casing, the plural and the kubectl short name are accepted and normalized.
+	TargetKind   TargetKind `json:"targetKind"`
+	LastEviction Eviction   `json:"lastEviction,omitempty"`
+	// SurgeMode selects how replicas are applied to the target. auto (default) picks
+	// KEDA, HPA or a /scale write based on what targets the workload; direct updates
+	// spec.replicas on the whole object; scale uses the /scale subresource; hpa raises
+	// the HPA's minReplicas; annotation only records the surge for GitOps tooling to apply.
+	// +kubebuilder:validation:Enum=auto;direct;scale;hpa;annotation
+	// +optional
+	SurgeMode string `json:"surgeMode,omitempty"`
+	// SurgePriorityClassName is a PriorityClass given to pods created while a surge is
+	// active, so replacement pods can preempt lower-priority workloads when the
+	// cluster is short on capacity. Only deployments are supported, and a pod already
+	// at a higher priority keeps it.
+	// +optional
+	SurgePriorityClassName string `json:"surgePriorityClassName,omitempty"`
+	// SurgeStep limits how many replicas a single blocked eviction adds on top of the
+	// current surge, so a large drain ramps up over several evictions. Unset surges
+	// straight to minReplicas plus the displaced pod count.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	SurgeStep *int32 `json:"surgeStep,omitempty"`
+	// MinReplicas is a floor surges never revert below. The target is otherwise
+	// scaled back to the replica count recorded before the surge, even when that
+	// was lower, for example after someone scaled it down by hand.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+	// ChangeDetection selects how an owner's change to the target is noticed, which
+	// resets status.minReplicas. generation (default) compares metadata.generation;
+	// podTemplate compares a hash of the pod template and replica count, for targets
+	// whose spec is rewritten by rollout controllers like Flagger, or that are recreated.
+	// +kubebuilder:validation:Enum=generation;podTemplate
+	// +optional
+	ChangeDetection string `json:"changeDetection,omitempty"`
 }
 
 // EvictionAutoScalerStatus defines the observed state of EvictionAutoScaler
@@ -24,10 +64,29 @@ type EvictionAutoScalerStatus struct {
 	MinReplicas      int32              `json:"minReplicas"`            // Minimum number of replicas to maintain
 	TargetGeneration int64              `json:"deploymentGeneration"`   // generation (spec hash) of deployment or statefulse
 	Conditions       []metav1.Condition `json:"conditions,omitempty"`
+	SurgeActive      bool               `json:"surgeActive,omitempty"`     // true while the controller holds the target above MinReplicas
+	SurgeReplicas    int32              `json:"surgeReplicas,omitempty"`   // replica count the target was surged to, 0 when not surged
+	SurgeStartTime   *metav1.Time       `json:"surgeStartTime,omitempty"`  // when the current surge began
+	CooldownUntil    *metav1.Time       `json:"cooldownUntil,omitempty"`   // scale-down is held until then; persisted so failover keeps the deadline
+	AbortedDrains    int32              `json:"abortedDrains,omitempty"`   // consecutive surges reverted after their drain was abandoned
+	SuppressedUntil  *metav1.Time       `json:"suppressedUntil,omitempty"` // new surges are suppressed until then after repeated aborted drains
+	TargetSpecHash   string             `json:"targetSpecHash,omitempty"`  // hash of the target's spec without replicas, recorded with TargetGeneration
+	PodTemplateHash  string             `json:"podTemplateHash,omitempty"` // hash of the target's pod template and replicas, recorded with TargetGeneration
+	CoalescingSince  *metav1.Time       `json:"coalescingSince,omitempty"` // a new surge waits for more evictions until the coalescing window after this
+	SurgeCycles      []metav1.Time      `json:"surgeCycles,omitempty"`     // when recent surges were reverted, within the hysteresis window; each lengthens the cooldown
+	Recommendation   string             `json:"recommendation,omitempty"`  // how to change the PDB or target so evictions can proceed, empty when nothing stands out
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=eas
+// +kubebuilder:printcolumn:name="Target",type=string,JSONPath=`.spec.targetName`
+// +kubebuilder:printcolumn:name="MinReplicas",type=integer,JSONPath=`.status.minReplicas`
+// +kubebuilder:printcolumn:name="SurgeActive",type=boolean,JSONPath=`.status.surgeActive`
+// +kubebuilder:printcolumn:name="LastEviction",type=date,JSONPath=`.spec.lastEviction.evictionTime`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:printcolumn:name="Recommendation",type=string,JSONPath=`.status.recommendation`,priority=1
 
 // EvictionAutoScaler is the Schema for the EvictionAutoScalers API
 type EvictionAutoScaler struct {
@@ -46,7 +105,3 @@ type EvictionAutoScalerList struct {
 	metav1.ListMeta `json:"metadata,omitempty"`
 	Items           []EvictionAutoScaler `json:"items"`
 }
-
-func init() {
-	SchemeBuilder.Register(&EvictionAutoScaler{}, &EvictionAutoScalerList{})
-}
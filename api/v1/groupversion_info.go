@@ -20,17 +20,28 @@ limitations under the License.
 package v1
 
 import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"sigs.k8s.io/controller-runtime/pkg/scheme"
 )
 
+// The types live in their own module so clients can depend on them without pulling
+// in controller-runtime; register them with apimachinery's scheme builder only.
 var (
 	// GroupVersion is group version used to register these objects
 	GroupVersion = schema.GroupVersion{Group: "eviction-autoscaler.azure.com", Version: "v1"}
 
 	// SchemeBuilder is used to add go types to the GroupVersionKind scheme
-	SchemeBuilder = &scheme.Builder{GroupVersion: GroupVersion}
+	SchemeBuilder = runtime.NewSchemeBuilder(addKnownTypes)
 
 	// AddToScheme adds the types in this group-version to the given scheme.
 	AddToScheme = SchemeBuilder.AddToScheme
 )
+
+func addKnownTypes(scheme *runtime.Scheme) error {
+	scheme.AddKnownTypes(GroupVersion, &EvictionAutoScaler{}, &EvictionAutoScalerList{},
+		&EvictionAutoScalerNamespaceStatus{}, &EvictionAutoScalerNamespaceStatusList{},
+		&SurgePlan{}, &SurgePlanList{})
+	metav1.AddToGroupVersion(scheme, GroupVersion)
+	return nil
+}
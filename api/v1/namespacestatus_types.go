@@ -0,0 +1,62 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NamespaceStatusName is the name of the single EvictionAutoScalerNamespaceStatus
+// the controller maintains in each enrolled namespace.
+const NamespaceStatusName = "eviction-autoscaler"
+
+// SkippedWorkload is a workload in the namespace that eviction-autoscaler does not
+// protect, and why.
+type SkippedWorkload struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// NamespaceSummary is the controller's view of one namespace.
+type NamespaceSummary struct {
+	// Enrolled is true while eviction-autoscaler manages the namespace, through the
+	// enable annotation, the controller's defaults or as an AKS-owned namespace.
+	Enrolled bool `json:"enrolled"`
+	// ManagedPDBs counts the PodDisruptionBudgets created and owned by the controller.
+	ManagedPDBs int32 `json:"managedPDBs"`
+	// EvictionAutoScalers counts the EvictionAutoScalers in the namespace.
+	EvictionAutoScalers int32 `json:"evictionAutoScalers"`
+	// ActiveSurges counts the EvictionAutoScalers currently holding their target
+	// above minReplicas.
+	ActiveSurges int32 `json:"activeSurges"`
+	// SkippedWorkloads lists deployments that get no PDB, excluded statefulsets and
+	// targets whose EvictionAutoScaler is Degraded, sorted by kind and name.
+	// +optional
+	SkippedWorkloads []SkippedWorkload `json:"skippedWorkloads,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=easns
+// +kubebuilder:printcolumn:name="Enrolled",type=boolean,JSONPath=`.status.enrolled`
+// +kubebuilder:printcolumn:name="ManagedPDBs",type=integer,JSONPath=`.status.managedPDBs`
+// +kubebuilder:printcolumn:name="EvictionAutoScalers",type=integer,JSONPath=`.status.evictionAutoScalers`
+// +kubebuilder:printcolumn:name="ActiveSurges",type=integer,JSONPath=`.status.activeSurges`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// EvictionAutoScalerNamespaceStatus summarizes eviction-autoscaler's state in its
+// namespace. It is maintained by the controller and has no spec.
+type EvictionAutoScalerNamespaceStatus struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status NamespaceSummary `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// EvictionAutoScalerNamespaceStatusList contains a list of EvictionAutoScalerNamespaceStatus
+type EvictionAutoScalerNamespaceStatusList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []EvictionAutoScalerNamespaceStatus `json:"items"`
+}
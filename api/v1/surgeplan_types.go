@@ -0,0 +1,77 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SurgePlan phases.
+const (
+	// SurgePlanPending plans wait for approval; the eviction stays unhandled.
+	SurgePlanPending = "Pending"
+	// SurgePlanApplied plans were approved and the surge was made.
+	SurgePlanApplied = "Applied"
+	// SurgePlanRejected plans were turned down; evictions are recorded without
+	// surging until the burst of evictions that prompted the plan is over.
+	SurgePlanRejected = "Rejected"
+)
+
+// SurgePlanSpec is the surge the controller proposes to make. It is written by the
+// controller; approvers act on it through the approve annotation.
+type SurgePlanSpec struct {
+	// TargetKind and TargetName identify the workload to scale.
+	TargetKind TargetKind `json:"targetKind"`
+	TargetName string     `json:"targetName"`
+	// CurrentReplicas is the target's replica count when the plan was proposed.
+	CurrentReplicas int32 `json:"currentReplicas"`
+	// SurgeReplicas is the replica count the target is scaled to once approved.
+	SurgeReplicas int32 `json:"surgeReplicas"`
+	// Reason explains why the surge is needed.
+	Reason string `json:"reason"`
+	// Eviction is the eviction that prompted the plan.
+	Eviction Eviction `json:"eviction"`
+	// AutoApproveAt, when set, is when the plan is approved without an annotation.
+	// +optional
+	AutoApproveAt *metav1.Time `json:"autoApproveAt,omitempty"`
+}
+
+// SurgePlanStatus is the outcome of a SurgePlan.
+type SurgePlanStatus struct {
+	// Phase is Pending, Applied or Rejected.
+	// +optional
+	Phase string `json:"phase,omitempty"`
+	// DecidedBy says how the plan was approved or rejected: annotation or timeout.
+	// +optional
+	DecidedBy string `json:"decidedBy,omitempty"`
+	// DecidedAt is when the plan was approved or rejected.
+	// +optional
+	DecidedAt *metav1.Time `json:"decidedAt,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=easplan
+// +kubebuilder:printcolumn:name="Target",type=string,JSONPath=`.spec.targetName`
+// +kubebuilder:printcolumn:name="Current",type=integer,JSONPath=`.spec.currentReplicas`
+// +kubebuilder:printcolumn:name="Surge",type=integer,JSONPath=`.spec.surgeReplicas`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="AutoApprove",type=date,JSONPath=`.spec.autoApproveAt`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// SurgePlan is a surge the controller wants to make, published before acting when
+// surge approval is on. It is named after and owned by its EvictionAutoScaler.
+type SurgePlan struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SurgePlanSpec   `json:"spec,omitempty"`
+	Status SurgePlanStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// SurgePlanList contains a list of SurgePlan
+type SurgePlanList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SurgePlan `json:"items"`
+}
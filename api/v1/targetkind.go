@@ -0,0 +1,62 @@
+package v1
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TargetKind is the kind of workload an EvictionAutoScaler surges. The API server
+// accepts any casing of the kind, its plural or its kubectl short name; the
+// defaulting webhook rewrites those to the canonical lowercase kind.
+// +kubebuilder:validation:XValidation:rule="self.lowerAscii() in ['deployment', 'deployments', 'deploy', 'statefulset', 'statefulsets', 'sts', 'rollout', 'rollouts', 'ro']",message="targetKind must be deployment, statefulset or rollout"
+type TargetKind string
+
+// Canonical spec.targetKind values. The controller compares against these, so the
+// admission webhook rewrites any accepted alias to one of them.
+const (
+	TargetKindDeployment  TargetKind = "deployment"
+	TargetKindStatefulSet TargetKind = "statefulset"
+	TargetKindRollout     TargetKind = "rollout"
+)
+
+// TargetKinds lists the canonical targetKind values.
+var TargetKinds = []TargetKind{TargetKindDeployment, TargetKindStatefulSet, TargetKindRollout}
+
+// targetKindAliases maps the lowercased kind, plural and kubectl short name of each
+// supported workload to its canonical targetKind.
+var targetKindAliases = map[string]TargetKind{
+	"deployment":   TargetKindDeployment,
+	"deployments":  TargetKindDeployment,
+	"deploy":       TargetKindDeployment,
+	"statefulset":  TargetKindStatefulSet,
+	"statefulsets": TargetKindStatefulSet,
+	"sts":          TargetKindStatefulSet,
+	"rollout":      TargetKindRollout,
+	"rollouts":     TargetKindRollout,
+	"ro":           TargetKindRollout,
+}
+
+// SplitTargetName splits a targetName written as namespace/name. ok is false for a
+// plain name.
+func SplitTargetName(targetName string) (namespace, name string, ok bool) {
+	return strings.Cut(targetName, "/")
+}
+
+// NormalizeTargetKind returns the canonical targetKind for kind, accepting any
+// casing as well as plural and short-name aliases ("Deployment", "deployments",
+// "sts"). It returns an error naming the supported kinds when kind is unknown.
+func NormalizeTargetKind(kind TargetKind) (TargetKind, error) {
+	if canonical, ok := targetKindAliases[strings.ToLower(strings.TrimSpace(string(kind)))]; ok {
+		return canonical, nil
+	}
+	return "", fmt.Errorf("unsupported targetKind %q: must be one of %s, %s or %s",
+		kind, TargetKindDeployment, TargetKindStatefulSet, TargetKindRollout)
+}
+
+// Normalized returns the canonical form of k, or k unchanged when it is unknown.
+func (k TargetKind) Normalized() TargetKind {
+	if canonical, err := NormalizeTargetKind(k); err == nil {
+		return canonical
+	}
+	return k
+}
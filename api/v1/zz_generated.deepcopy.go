@@ -96,10 +96,78 @@ func (in *EvictionAutoScalerList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvictionAutoScalerNamespaceStatus) DeepCopyInto(out *EvictionAutoScalerNamespaceStatus) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvictionAutoScalerNamespaceStatus.
+func (in *EvictionAutoScalerNamespaceStatus) DeepCopy() *EvictionAutoScalerNamespaceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EvictionAutoScalerNamespaceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EvictionAutoScalerNamespaceStatus) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvictionAutoScalerNamespaceStatusList) DeepCopyInto(out *EvictionAutoScalerNamespaceStatusList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]EvictionAutoScalerNamespaceStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvictionAutoScalerNamespaceStatusList.
+func (in *EvictionAutoScalerNamespaceStatusList) DeepCopy() *EvictionAutoScalerNamespaceStatusList {
+	if in == nil {
+		return nil
+	}
+	out := new(EvictionAutoScalerNamespaceStatusList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EvictionAutoScalerNamespaceStatusList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EvictionAutoScalerSpec) DeepCopyInto(out *EvictionAutoScalerSpec) {
 	*out = *in
 	in.LastEviction.DeepCopyInto(&out.LastEviction)
+	if in.SurgeStep != nil {
+		in, out := &in.SurgeStep, &out.SurgeStep
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvictionAutoScalerSpec.
@@ -123,6 +191,29 @@ func (in *EvictionAutoScalerStatus) DeepCopyInto(out *EvictionAutoScalerStatus)
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.SurgeStartTime != nil {
+		in, out := &in.SurgeStartTime, &out.SurgeStartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CooldownUntil != nil {
+		in, out := &in.CooldownUntil, &out.CooldownUntil
+		*out = (*in).DeepCopy()
+	}
+	if in.SuppressedUntil != nil {
+		in, out := &in.SuppressedUntil, &out.SuppressedUntil
+		*out = (*in).DeepCopy()
+	}
+	if in.CoalescingSince != nil {
+		in, out := &in.CoalescingSince, &out.CoalescingSince
+		*out = (*in).DeepCopy()
+	}
+	if in.SurgeCycles != nil {
+		in, out := &in.SurgeCycles, &out.SurgeCycles
+		*out = make([]metav1.Time, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvictionAutoScalerStatus.
@@ -134,3 +225,136 @@ func (in *EvictionAutoScalerStatus) DeepCopy() *EvictionAutoScalerStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceSummary) DeepCopyInto(out *NamespaceSummary) {
+	*out = *in
+	if in.SkippedWorkloads != nil {
+		in, out := &in.SkippedWorkloads, &out.SkippedWorkloads
+		*out = make([]SkippedWorkload, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceSummary.
+func (in *NamespaceSummary) DeepCopy() *NamespaceSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SkippedWorkload) DeepCopyInto(out *SkippedWorkload) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SkippedWorkload.
+func (in *SkippedWorkload) DeepCopy() *SkippedWorkload {
+	if in == nil {
+		return nil
+	}
+	out := new(SkippedWorkload)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SurgePlan) DeepCopyInto(out *SurgePlan) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SurgePlan.
+func (in *SurgePlan) DeepCopy() *SurgePlan {
+	if in == nil {
+		return nil
+	}
+	out := new(SurgePlan)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SurgePlan) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SurgePlanList) DeepCopyInto(out *SurgePlanList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SurgePlan, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SurgePlanList.
+func (in *SurgePlanList) DeepCopy() *SurgePlanList {
+	if in == nil {
+		return nil
+	}
+	out := new(SurgePlanList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SurgePlanList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SurgePlanSpec) DeepCopyInto(out *SurgePlanSpec) {
+	*out = *in
+	in.Eviction.DeepCopyInto(&out.Eviction)
+	if in.AutoApproveAt != nil {
+		in, out := &in.AutoApproveAt, &out.AutoApproveAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SurgePlanSpec.
+func (in *SurgePlanSpec) DeepCopy() *SurgePlanSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SurgePlanSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SurgePlanStatus) DeepCopyInto(out *SurgePlanStatus) {
+	*out = *in
+	if in.DecidedAt != nil {
+		in, out := &in.DecidedAt, &out.DecidedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SurgePlanStatus.
+func (in *SurgePlanStatus) DeepCopy() *SurgePlanStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SurgePlanStatus)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,169 @@
+// Command kubectl-eviction_autoscaler is a kubectl plugin for inspecting
+// EvictionAutoScalers and turning eviction-autoscaler on or off per namespace.
+// Installed on the PATH it runs as "kubectl eviction-autoscaler".
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/duration"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/annotations"
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(myappsv1.AddToScheme(scheme))
+}
+
+const usage = `Usage: kubectl eviction-autoscaler COMMAND [flags]
+
+Commands:
+  status [-n NAMESPACE]   List EvictionAutoScalers with their target, surge state, PDB and last eviction
+  enable NAMESPACE...     Annotate namespaces so eviction-autoscaler acts in them
+  disable NAMESPACE...    Annotate namespaces so eviction-autoscaler leaves them alone
+`
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "error:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+func run(args []string, out io.Writer) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		fmt.Fprint(os.Stderr, usage)
+		return flag.ErrHelp
+	}
+	fs := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	kubeconfig := fs.String("kubeconfig", "", "Path to a kubeconfig. Defaults to $KUBECONFIG, in-cluster config, then ~/.kube/config.")
+	namespace := fs.String("n", "", "Only list EvictionAutoScalers in this namespace (status only). Defaults to all namespaces.")
+	fs.Usage = func() { fmt.Fprint(fs.Output(), usage, "\nFlags:\n"); fs.PrintDefaults() }
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	var command func(context.Context, client.Client) error
+	switch args[0] {
+	case "status":
+		command = func(ctx context.Context, c client.Client) error { return status(ctx, c, *namespace, out) }
+	case "enable", "disable":
+		if fs.NArg() == 0 {
+			return fmt.Errorf("%s needs at least one namespace", args[0])
+		}
+		command = func(ctx context.Context, c client.Client) error {
+			return setEnabled(ctx, c, fs.Args(), args[0] == "enable", out)
+		}
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+
+	cfg, err := ctrl.GetConfig()
+	if *kubeconfig != "" {
+		cfg, err = clientcmd.BuildConfigFromFlags("", *kubeconfig)
+	}
+	if err != nil {
+		return err
+	}
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return err
+	}
+	return command(context.Background(), c)
+}
+
+// status prints one row per EvictionAutoScaler in namespace, or in every namespace
+// if it is empty.
+func status(ctx context.Context, c client.Client, namespace string, out io.Writer) error {
+	var list myappsv1.EvictionAutoScalerList
+	if err := c.List(ctx, &list, client.InNamespace(namespace)); err != nil {
+		return err
+	}
+	var pdbList policyv1.PodDisruptionBudgetList
+	if err := c.List(ctx, &pdbList, client.InNamespace(namespace)); err != nil {
+		return err
+	}
+	pdbs := map[types.NamespacedName]*policyv1.PodDisruptionBudget{}
+	for i := range pdbList.Items {
+		pdbs[client.ObjectKeyFromObject(&pdbList.Items[i])] = &pdbList.Items[i]
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		if list.Items[i].Namespace != list.Items[j].Namespace {
+			return list.Items[i].Namespace < list.Items[j].Namespace
+		}
+		return list.Items[i].Name < list.Items[j].Name
+	})
+
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tNAME\tTARGET\tMIN REPLICAS\tSURGE\tDISRUPTIONS ALLOWED\tLAST EVICTION")
+	for i := range list.Items {
+		eas := &list.Items[i]
+		disruptions := "<no pdb>"
+		// EvictionAutoScalers share their PDB's name.
+		if pdb, ok := pdbs[client.ObjectKeyFromObject(eas)]; ok {
+			disruptions = fmt.Sprint(pdb.Status.DisruptionsAllowed)
+		}
+		surge := "-"
+		if eas.Status.SurgeActive {
+			surge = fmt.Sprintf("active, %d", eas.Status.SurgeReplicas)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s/%s\t%d\t%s\t%s\t%s\n", eas.Namespace, eas.Name, eas.Spec.TargetKind, eas.Spec.TargetName,
+			eas.Status.MinReplicas, surge, disruptions, lastEviction(eas.Spec.LastEviction, time.Now()))
+	}
+	return w.Flush()
+}
+
+// lastEviction renders eviction as the pod and how long ago it was evicted.
+func lastEviction(eviction myappsv1.Eviction, now time.Time) string {
+	if eviction.EvictionTime.IsZero() {
+		return "<none>"
+	}
+	return fmt.Sprintf("%s (%s ago)", eviction.PodName, duration.HumanDuration(now.Sub(eviction.EvictionTime.Time)))
+}
+
+// setEnabled sets the enable annotation on each namespace. The annotation overrides
+// the controller's ENABLED_BY_DEFAULT setting in both directions, except in the
+// always-enabled namespaces, which ignore it.
+func setEnabled(ctx context.Context, c client.Client, namespaces []string, enabled bool, out io.Writer) error {
+	value := fmt.Sprint(enabled)
+	for _, name := range namespaces {
+		var ns corev1.Namespace
+		if err := c.Get(ctx, types.NamespacedName{Name: name}, &ns); err != nil {
+			return err
+		}
+		patch := client.MergeFrom(ns.DeepCopy())
+		if ns.Annotations == nil {
+			ns.Annotations = map[string]string{}
+		}
+		ns.Annotations[annotations.Enable] = value
+		if err := c.Patch(ctx, &ns, patch); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "namespace/%s annotated %s=%s\n", name, annotations.Enable, value)
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/annotations"
+)
+
+func TestStatus(t *testing.T) {
+	now := time.Now()
+	eas := func(namespace, name string) *myappsv1.EvictionAutoScaler {
+		return &myappsv1.EvictionAutoScaler{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       myappsv1.EvictionAutoScalerSpec{TargetKind: myappsv1.TargetKindDeployment, TargetName: name},
+			Status:     myappsv1.EvictionAutoScalerStatus{MinReplicas: 2},
+		}
+	}
+	surged := eas("shop", "web")
+	surged.Status.SurgeActive, surged.Status.SurgeReplicas = true, 3
+	surged.Spec.LastEviction = myappsv1.Eviction{PodName: "web-1", EvictionTime: metav1.NewTime(now.Add(-5 * time.Minute))}
+	pdb := &policyv1.PodDisruptionBudget{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"}}
+	pdb.Status.DisruptionsAllowed = 1
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(surged, eas("shop", "api"), eas("batch", "worker"), pdb).
+		WithStatusSubresource(&myappsv1.EvictionAutoScaler{}, &policyv1.PodDisruptionBudget{}).
+		Build()
+	// Status is dropped on create with the status subresource; write it back.
+	for _, obj := range []client.Object{surged, pdb} {
+		if err := c.Status().Update(context.Background(), obj); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	header := "NAMESPACE NAME TARGET MIN REPLICAS SURGE DISRUPTIONS ALLOWED LAST EVICTION"
+	for _, tc := range []struct {
+		name      string
+		namespace string
+		want      []string
+	}{
+		{
+			name: "all namespaces, sorted",
+			want: []string{
+				header,
+				"batch worker deployment/worker 2 - <no pdb> <none>",
+				"shop api deployment/api 2 - <no pdb> <none>",
+				"shop web deployment/web 2 active, 3 1 web-1 (5m ago)",
+			},
+		},
+		{
+			name:      "one namespace",
+			namespace: "batch",
+			want: []string{
+				header,
+				"batch worker deployment/worker 2 - <no pdb> <none>",
+			},
+		},
+		{
+			name:      "empty namespace",
+			namespace: "empty",
+			want:      []string{header},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := status(context.Background(), c, tc.namespace, &out); err != nil {
+				t.Fatal(err)
+			}
+			got := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+			for i := range got {
+				// Compare cells, not the padding tabwriter aligns them with.
+				got[i] = strings.Join(strings.Fields(got[i]), " ")
+			}
+			if strings.Join(got, "\n") != strings.Join(tc.want, "\n") {
+				t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(tc.want, "\n"), strings.Join(got, "\n"))
+			}
+		})
+	}
+}
+
+func TestSetEnabled(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		namespaces []string
+		enabled    bool
+		want       string
+		wantErr    bool
+	}{
+		{
+			name:       "enable",
+			namespaces: []string{"shop", "batch"},
+			enabled:    true,
+			want: "namespace/shop annotated " + annotations.Enable + "=true\n" +
+				"namespace/batch annotated " + annotations.Enable + "=true\n",
+		},
+		{
+			name:       "disable",
+			namespaces: []string{"shop"},
+			want:       "namespace/shop annotated " + annotations.Enable + "=false\n",
+		},
+		{
+			name:       "missing namespace",
+			namespaces: []string{"missing"},
+			enabled:    true,
+			wantErr:    true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop"}},
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "batch",
+					Annotations: map[string]string{annotations.Enable: "false"}}},
+			).Build()
+			var out bytes.Buffer
+			err := setEnabled(ctx, c, tc.namespaces, tc.enabled, &out)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("expected error %v, got %v", tc.wantErr, err)
+			}
+			if out.String() != tc.want {
+				t.Errorf("expected output %q, got %q", tc.want, out.String())
+			}
+			if tc.wantErr {
+				return
+			}
+			for _, name := range tc.namespaces {
+				var ns corev1.Namespace
+				if err := c.Get(ctx, types.NamespacedName{Name: name}, &ns); err != nil {
+					t.Fatal(err)
+				}
+				if got, want := ns.Annotations[annotations.Enable], strconv.FormatBool(tc.enabled); got != want {
+					t.Errorf("namespace %s: expected %s=%s, got %q", name, annotations.Enable, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestLastEviction(t *testing.T) {
+	now := time.Now()
+	for _, tc := range []struct {
+		eviction myappsv1.Eviction
+		want     string
+	}{
+		{myappsv1.Eviction{}, "<none>"},
+		{myappsv1.Eviction{PodName: "web-1", EvictionTime: metav1.NewTime(now.Add(-90 * time.Second))}, "web-1 (90s ago)"},
+		{myappsv1.Eviction{PodName: "web-2", EvictionTime: metav1.NewTime(now.Add(-3 * time.Hour))}, "web-2 (3h ago)"},
+	} {
+		if got := lastEviction(tc.eviction, now); got != tc.want {
+			t.Errorf("lastEviction(%v): expected %q, got %q", tc.eviction, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/clientcmd"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	controllers "github.com/azure/eviction-autoscaler/internal/controller"
+)
+
+// runGenerate implements "manager generate": it prints the PDB and EvictionAutoScaler
+// the controllers would create for a deployment, as user-owned YAML for GitOps repos.
+func runGenerate(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("generate", flag.ContinueOnError)
+	namespace := fs.String("namespace", "default", "Namespace of the deployment.")
+	deployment := fs.String("deployment", "", "Name of the deployment to generate a PDB and EvictionAutoScaler for.")
+	kubeconfig := fs.String("kubeconfig", "", "Path to a kubeconfig. Defaults to $KUBECONFIG, in-cluster config, then ~/.kube/config.")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s generate --deployment NAME [--namespace NAMESPACE]\n\n", os.Args[0])
+		fmt.Fprintln(fs.Output(), "Prints ready-to-apply YAML for the PDB and EvictionAutoScaler the controllers would create for the deployment.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *deployment == "" {
+		fs.Usage()
+		return errors.New("--deployment is required")
+	}
+
+	c, err := newClient(*kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	pdb, eas, err := controllers.GenerateForDeployment(context.Background(), c, *namespace, *deployment)
+	if err != nil {
+		return err
+	}
+	for i, obj := range []runtime.Object{pdb, eas} {
+		manifest, err := toManifest(obj)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			fmt.Fprintln(out, "---")
+		}
+		if _, err := out.Write(manifest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newClient returns an uncached client for the subcommands, using kubeconfig if set.
+func newClient(kubeconfig string) (client.Client, error) {
+	cfg, err := ctrl.GetConfig()
+	if kubeconfig != "" {
+		cfg, err = clientcmd.BuildConfigFromFlags("", kubeconfig)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return client.New(cfg, client.Options{Scheme: scheme})
+}
+
+// toManifest renders obj as YAML without the empty status and creation timestamp
+// that only the API server fills in.
+func toManifest(obj runtime.Object) ([]byte, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	delete(content, "status")
+	unstructured.RemoveNestedField(content, "metadata", "creationTimestamp")
+	return yaml.Marshal(content)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	promapi "github.com/prometheus/client_golang/api"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	controllers "github.com/azure/eviction-autoscaler/internal/controller"
+)
+
+// newHeadroomGuard builds the guard for --headroom-source, or returns nil when no
+// source is set. reader must be uncached.
+func newHeadroomGuard(source, prometheusURL, query string, threshold float64, interval time.Duration, reader client.Reader) (*controllers.HeadroomGuard, error) {
+	if threshold < 0 || threshold > 1 {
+		return nil, fmt.Errorf("headroom-threshold must be between 0 and 1, got %v", threshold)
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("headroom-check-interval must be positive, got %s", interval)
+	}
+	guard := &controllers.HeadroomGuard{Threshold: threshold, Interval: interval}
+	switch source {
+	case "":
+		return nil, nil
+	case controllers.MetricsServerHeadroomSource:
+		guard.Source = controllers.MetricsServerHeadroom{Reader: reader}
+	case controllers.PrometheusHeadroomSource:
+		if prometheusURL == "" || query == "" {
+			return nil, fmt.Errorf("headroom-source=%s requires headroom-prometheus-url and headroom-prometheus-query", source)
+		}
+		promClient, err := promapi.NewClient(promapi.Config{Address: prometheusURL})
+		if err != nil {
+			return nil, err
+		}
+		guard.Source = controllers.PrometheusHeadroom{API: promv1.NewAPI(promClient), Query: query}
+	default:
+		return nil, fmt.Errorf("unknown headroom-source %q, want %s or %s", source,
+			controllers.MetricsServerHeadroomSource, controllers.PrometheusHeadroomSource)
+	}
+	return guard, nil
+}
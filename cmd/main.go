@@ -17,29 +17,52 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"crypto/tls"
+	"errors"
 	"flag"
+	"fmt"
+	"net/http"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/config"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	appsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/annotations"
+	"github.com/azure/eviction-autoscaler/internal/circuitbreaker"
 	controllers "github.com/azure/eviction-autoscaler/internal/controller"
-	_ "github.com/azure/eviction-autoscaler/internal/metrics"
+	"github.com/azure/eviction-autoscaler/internal/decisions"
+	"github.com/azure/eviction-autoscaler/internal/diagnostics"
+	"github.com/azure/eviction-autoscaler/internal/features"
+	"github.com/azure/eviction-autoscaler/internal/metrics"
 	"github.com/azure/eviction-autoscaler/internal/namespacefilter"
+	"github.com/azure/eviction-autoscaler/internal/pause"
+	"github.com/azure/eviction-autoscaler/internal/tracing"
+	webhookv1 "github.com/azure/eviction-autoscaler/internal/webhook/v1"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -57,22 +80,294 @@ func init() {
 }
 
 func main() {
+	if len(os.Args) > 1 && (os.Args[1] == "generate" || os.Args[1] == "simulate-drain") {
+		run := runGenerate
+		if os.Args[1] == "simulate-drain" {
+			run = runSimulateDrain
+		}
+		if err := run(os.Args[2:], os.Stdout); err != nil {
+			if !errors.Is(err, flag.ErrHelp) {
+				fmt.Fprintln(os.Stderr, err)
+			}
+			os.Exit(1)
+		}
+		return
+	}
+
 	var metricsAddr string
 	var enableLeaderElection bool
+	var highAvailability bool
+	var protectSelf bool
+	var gracefulShutdownTimeout time.Duration
 	var probeAddr string
+	var pprofAddr string
+	var diagnosticsDir string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var shardCount uint
+	var shardIndex uint
+	var namespaceSelector string
+	var alwaysEnabledNamespaces string
+	var evictionRetention time.Duration
+	var evictionFreshness time.Duration
+	var evictionCoalesceWindow time.Duration
+	var preemptionNodeConditions string
+	var preemptionTaintKeys string
+	var impersonateTenants bool
+	var enableWebhooks bool
+	var enableControllers bool
+	var placementHints bool
+	var autoscalerHints bool
+	var drainTaintKeys string
+	var karpenter bool
+	var decisionTraceSize int
+	var cooldown time.Duration
+	var hysteresisWindow time.Duration
+	var hysteresisMaxCooldown time.Duration
+	var surgeMarkerAnnotation bool
+	var skipSingleReplica bool
+	var minAvailableDebounce time.Duration
+	var minAvailableFactor float64
+	var nodeWarmupTimeout time.Duration
+	var nodeDrainReady bool
+	var drainFailureThreshold int
+	var drainFailureSuppression time.Duration
+	var surgePendingDeadline time.Duration
+	var rollbackStuckSurges bool
+	var topologyLimitedSurges bool
+	var statefulSetSurge bool
+	var headroomSource string
+	var headroomThreshold float64
+	var headroomCheckInterval time.Duration
+	var headroomPrometheusURL string
+	var headroomPrometheusQuery string
+	var surgeApproval bool
+	var surgeAutoApproveAfter time.Duration
+	var namespaceStatus bool
+	var metricsMode string
+	var metricsDetailLevel string
+	var metricsSyncInterval time.Duration
+	var evictionPollInterval time.Duration
+	var orphanSweepInterval time.Duration
+	var easRepairInterval time.Duration
+	var resyncPeriod time.Duration
+	var surgeBatchWindow time.Duration
+	var surgeSchedulingEvents bool
+	var pdbValidation string
+	var maxConcurrentReconciles int
+	var controllerConcurrency string
+	var kubeAPIQPS float64
+	var kubeAPIBurst int
+	var circuitBreakerThreshold int
+	var circuitBreakerBackoff time.Duration
+	var pauseConfigMap string
+	var pausePollInterval time.Duration
+	var featureFlagsConfigMap string
+	var featureFlagsPollInterval time.Duration
+	var ownerReferences bool
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metric endpoint binds to. "+
 		"Use the port :8080. If not set, it will be 0 in order to disable the metrics server")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
+	flag.StringVar(&pprofAddr, "pprof-bind-address", "",
+		"If set, e.g. to :8082, serve net/http/pprof profiles at /debug/pprof/ on this address, and write "+
+			"goroutine and heap profiles to --diagnostics-snapshot-dir on SIGUSR1. The profiles are not "+
+			"authenticated; keep the port off Services.")
+	flag.StringVar(&diagnosticsDir, "diagnostics-snapshot-dir", os.TempDir(),
+		"Directory SIGUSR1 snapshots are written to, with --pprof-bind-address.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.BoolVar(&highAvailability, "high-availability", false,
+		"Run as one of several active-passive replicas, with --leader-elect: the leader releases its lease "+
+			"as soon as it stops, finishing the surges and scale-downs in flight first, so a standby takes over at once.")
+	flag.BoolVar(&protectSelf, "protect-self", false,
+		"Create a PDB and an EvictionAutoScaler for the controller's own deployment, found from the pod "+
+			"named by the POD_NAME and POD_NAMESPACE environment variables, so draining its node surges it "+
+			"first. Only that deployment, its PDB and its EvictionAutoScaler are managed in a disabled namespace.")
+	flag.DurationVar(&gracefulShutdownTimeout, "graceful-shutdown-timeout", 30*time.Second,
+		"How long the manager waits for in-flight reconciles and other runnables to stop before exiting.")
 	flag.BoolVar(&secureMetrics, "metrics-secure", false,
 		"If set the metrics endpoint is served securely")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics servers")
+	flag.UintVar(&shardCount, "shard-count", 1,
+		"Number of shards namespaces are hashed across. Each shard runs its own replica set "+
+			"with its own leader election. 1 disables sharding.")
+	flag.UintVar(&shardIndex, "shard-index", 0,
+		"Which shard (0 to shard-count-1) this replica reconciles.")
+	flag.StringVar(&namespaceSelector, "namespace-selector", "",
+		"Label selector, e.g. tier=critical, whose matching namespaces are enabled like those in "+
+			"ACTIONED_NAMESPACES. The enable annotation still takes precedence.")
+	flag.StringVar(&alwaysEnabledNamespaces, "always-enabled-namespaces", strings.Join(namespacefilter.AKSOwnedNamespaces(), ","),
+		"Comma-separated namespaces that are always enabled, regardless of ENABLED_BY_DEFAULT, ACTIONED_NAMESPACES "+
+			"and the enable annotation. Defaults to the AKS-owned namespaces, kube-system among them; set it to "+
+			"\"\" to treat them like any other namespace.")
+	flag.DurationVar(&evictionRetention, "eviction-retention", 0,
+		"How long a handled lastEviction is kept on an EvictionAutoScaler before it is cleared. 0 keeps it forever.")
+	flag.DurationVar(&evictionFreshness, "eviction-freshness", 0,
+		"If set, how old a newly seen eviction may be and still trigger a surge, e.g. 5m. Older "+
+			"evictions are recorded without surging. 0 acts on evictions of any age.")
+	flag.DurationVar(&evictionCoalesceWindow, "eviction-coalesce-window", 0,
+		"If set, wait this long after a new eviction before surging, so evictions of the same target "+
+			"arriving close together, as in a node drain, share one surge. 0 surges on the first eviction.")
+	flag.BoolVar(&impersonateTenants, "impersonate-tenant-service-accounts", false,
+		"If set, surge writes in namespaces annotated with "+annotations.ImpersonateServiceAccount+
+			" impersonate the named service account.")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"If set, serve the EvictionAutoScaler defaulting and validating admission webhooks on :9443. "+
+			"Requires a serving certificate in /tmp/k8s-webhook-server/serving-certs.")
+	flag.BoolVar(&enableControllers, "enable-controllers", true,
+		"If set, run the reconcilers. Disable it together with --enable-webhooks to run the webhook "+
+			"in its own deployment, isolated from the reconcilers.")
+	flag.BoolVar(&placementHints, "surge-placement-hints", false,
+		"If set, record the draining nodes on a surged deployment so the pod placement webhook keeps "+
+			"surge pods off them. Requires the webhook to be deployed.")
+	flag.BoolVar(&autoscalerHints, "cluster-autoscaler-hints", false,
+		"If set, annotate a surged deployment's pods as not safe to evict while the surge is active, so the "+
+			"Cluster Autoscaler and Karpenter add capacity for surge pods without removing nodes mid-drain. "+
+			"Surge pods are annotated by the pod placement webhook, which must be deployed.")
+	flag.StringVar(&drainTaintKeys, "drain-taint-keys", strings.Join(controllers.DefaultDrainTaintKeys, ","),
+		"Comma-separated node taint keys that mark a node as about to be drained, in addition to a cordon.")
+	flag.BoolVar(&karpenter, "karpenter", false,
+		"If set, treat Karpenter's "+controllers.KarpenterDisruptionTaintKey+" taint as a drain taint and a "+
+			"preemption notice, so workloads surge before Karpenter drains the node, and default --cooldown to "+
+			controllers.KarpenterCooldown.String()+".")
+	flag.DurationVar(&cooldown, "cooldown", 0,
+		"How long a surge is held after the last eviction before it is reverted. 0 uses 1m, or "+
+			controllers.KarpenterCooldown.String()+" with --karpenter.")
+	flag.DurationVar(&hysteresisWindow, "surge-hysteresis-window", 0,
+		"If set, double the cooldown for each surge of the same target reverted within this window, so "+
+			"rolling drains hold one surge instead of surging and reverting node after node. 0 disables it.")
+	flag.DurationVar(&hysteresisMaxCooldown, "surge-hysteresis-max-cooldown", controllers.DefaultHysteresisMaxCooldown,
+		"Longest cooldown --surge-hysteresis-window grows to.")
+	flag.BoolVar(&skipSingleReplica, "skip-single-replica-deployments", false,
+		"If set, create no PDB for deployments running a single replica without maxSurge, which can't be surged.")
+	flag.DurationVar(&minAvailableDebounce, "pdb-min-available-debounce", 0,
+		"If set, only update the minAvailable of a created PDB to follow its deployment's replicas once they "+
+			"have stayed the same this long, so frequent scaling doesn't rewrite the PDB each time. 0 updates at once.")
+	flag.Float64Var(&minAvailableFactor, "pdb-min-available-factor", 1,
+		"Fraction, above 0 and at most 1, of a deployment's replicas a created PDB's minAvailable follows, "+
+			"rounded up. 1 keeps minAvailable equal to the replicas.")
+	flag.BoolVar(&surgeMarkerAnnotation, "surge-marker-annotation", true,
+		"If set, also mark a surged deployment, statefulset or Rollout with the "+controllers.EvictionSurgeReplicasAnnotationKey+
+			" annotation. Surges are recorded in the EvictionAutoScaler's status either way; unset it so surging "+
+			"only writes the target's replicas.")
+	flag.IntVar(&decisionTraceSize, "decision-trace-size", 10,
+		"Number of recent reconcile decisions kept in memory per EvictionAutoScaler and served, to "+
+			"authenticated callers, from /debug/decisions on the metrics endpoint. 0 disables the trace.")
+	flag.StringVar(&preemptionNodeConditions, "preemption-node-conditions", "",
+		"Comma-separated node condition types that, while True, give notice that the node is about to be "+
+			"preempted or taken down for maintenance, e.g. VMEventScheduled. Such nodes are cordoned so their "+
+			"workloads surge at once, and uncordoned if the notice clears.")
+	flag.StringVar(&preemptionTaintKeys, "preemption-taint-keys", "",
+		"Comma-separated node taint keys that give notice that the node is about to be preempted, like "+
+			"--preemption-node-conditions.")
+	flag.DurationVar(&nodeWarmupTimeout, "node-warmup-timeout", 0,
+		"If set, hold scale-down after a surge while a node that joined less than this long ago is not "+
+			"Ready or still has DaemonSet pods starting. 0 disables the gate.")
+	flag.BoolVar(&nodeDrainReady, "node-drain-ready-annotation", false,
+		"If set, mark cordoned nodes with "+controllers.DrainReadyAnnotationKey+"=true once no pod left on them "+
+			"is blocked by a PDB, so external drain orchestrators can wait for surges to land.")
+	flag.IntVar(&drainFailureThreshold, "drain-failure-threshold", 0,
+		"Number of consecutive surges whose drain was aborted before new surges for that PDB are "+
+			"suppressed. 0 disables suppression.")
+	flag.DurationVar(&drainFailureSuppression, "drain-failure-suppression", time.Hour,
+		"How long new surges stay suppressed once --drain-failure-threshold is reached.")
+	flag.DurationVar(&surgePendingDeadline, "surge-pending-deadline", 0,
+		"If set, report a surge as stuck once one of its pods has been Pending this long, with a warning "+
+			"event on the EvictionAutoScaler and the target and the SurgeStuckPending condition. 0 disables the watchdog.")
+	flag.BoolVar(&rollbackStuckSurges, "rollback-stuck-surges", false,
+		"If set, also revert a surge reported as stuck by --surge-pending-deadline.")
+	flag.BoolVar(&topologyLimitedSurges, "topology-limited-surges", false,
+		"If set, limit a surge to the pods its target's topology spread constraints and required pod "+
+			"anti-affinity leave room for, and hold it while they leave room for none. Otherwise a shortfall "+
+			"is only reported.")
+	flag.BoolVar(&statefulSetSurge, "statefulset-surge", false,
+		"If set, surge StatefulSet targets by up to their "+annotations.MaxSurge+" annotation, 1 pod by "+
+			"default. Each surge pod is a new ordinal with its own PVCs. Otherwise StatefulSet targets are skipped.")
+	flag.StringVar(&headroomSource, "headroom-source", "",
+		"If set, defer new surges while cluster headroom is below --headroom-threshold, read from "+
+			controllers.MetricsServerHeadroomSource+" node usage or a "+controllers.PrometheusHeadroomSource+" query.")
+	flag.Float64Var(&headroomThreshold, "headroom-threshold", 0.1,
+		"Fraction of cluster capacity, from 0 to 1, that must be free for new surges to go ahead.")
+	flag.DurationVar(&headroomCheckInterval, "headroom-check-interval", 30*time.Second,
+		"How often cluster headroom is read, and how often a deferred surge is retried.")
+	flag.StringVar(&headroomPrometheusURL, "headroom-prometheus-url", "",
+		"Address of the Prometheus server queried with --headroom-source=prometheus.")
+	flag.StringVar(&headroomPrometheusQuery, "headroom-prometheus-query", "",
+		"PromQL query returning the free fraction of cluster capacity, for --headroom-source=prometheus.")
+	flag.BoolVar(&surgeApproval, "surge-approval", false,
+		"If set, publish each surge as a SurgePlan and only make it once the plan is approved with the "+
+			controllers.ApproveSurgeAnnotationKey+" annotation.")
+	flag.DurationVar(&surgeAutoApproveAfter, "surge-auto-approve-after", 0,
+		"With --surge-approval, approve a SurgePlan nobody has decided on after this long. 0 waits indefinitely.")
+	flag.BoolVar(&namespaceStatus, "namespace-status", false,
+		"If set, maintain an EvictionAutoScalerNamespaceStatus summarizing each enrolled namespace.")
+	flag.StringVar(&metricsMode, "metrics-mode", metrics.FullMode,
+		"How metrics are labelled: full, or namespace to drop object-name labels and "+
+			"aggregate per namespace on large clusters. low-cardinality is the older name of namespace.")
+	flag.StringVar(&metricsDetailLevel, "metrics-detail-level", "",
+		"Alias of --metrics-mode taking the same values: full or namespace. Takes precedence over "+
+			"--metrics-mode when set.")
+	flag.DurationVar(&metricsSyncInterval, "metrics-sync-interval", time.Minute,
+		"How often the deployment and PDB count gauges are recomputed from the cache. 0 disables the gauges.")
+	flag.DurationVar(&evictionPollInterval, "eviction-poll-interval", 0,
+		"If set, poll PDBs at this interval and infer evictions from pods being deleted off cordoned "+
+			"nodes while the PDB blocks disruptions. A fallback for clusters that don't allow admission "+
+			"webhooks. 0 disables polling.")
+	flag.DurationVar(&orphanSweepInterval, "orphan-sweep-interval", 0,
+		"If set, how often deployments carrying a surge annotation are checked for a surge that is no "+
+			"longer active. Orphans found on two consecutive checks are reverted. 0 disables the sweeper.")
+	flag.DurationVar(&easRepairInterval, "evictionautoscaler-repair-interval", 0,
+		"If set, deployments with a PDB the controller created are rechecked at this interval, and an "+
+			"EvictionAutoScaler missing from such a PDB for as long is recreated. 0 disables the repair.")
+	flag.DurationVar(&resyncPeriod, "resync-period", 0,
+		"If set, every PDB and deployment is reconciled again at this interval, so drift from out-of-band "+
+			"edits is repaired even when no watch event triggers a reconcile. 0 disables the resync.")
+	flag.DurationVar(&surgeBatchWindow, "surge-batch-window", 0,
+		"If set, the pod placement webhook admits the pods of each surge wave behind a scheduling gate, "+
+			"lifted for the whole wave once its ReplicaSet has created it or after this long, so node "+
+			"provisioners scale up for the wave at once. Requires the webhook to be deployed. 0 disables batching.")
+	flag.BoolVar(&surgeSchedulingEvents, "surge-scheduling-events", false,
+		"If set, watch FailedScheduling events across the cluster and count those reported for surge pods "+
+			"in eviction_autoscaler_surge_failed_scheduling_events_total.")
+	flag.StringVar(&pdbValidation, "pdb-validation", "",
+		"If set, serve a PodDisruptionBudget webhook that flags PDBs blocking every eviction of their workload in "+
+			"namespaces eviction-autoscaler won't surge: "+webhookv1.PDBValidationWarn+" admits them with a warning, "+
+			webhookv1.PDBValidationDeny+" rejects them. Requires --enable-webhooks.")
+
+	flag.IntVar(&maxConcurrentReconciles, "max-concurrent-reconciles", 1,
+		"How many objects each controller reconciles in parallel, unless overridden by --controller-concurrency.")
+	flag.StringVar(&controllerConcurrency, "controller-concurrency", "",
+		"Comma-separated name=workers overrides of --max-concurrent-reconciles for single controllers, e.g. "+
+			"evictionautoscaler=8,node=2. Controllers: "+strings.Join(controllers.ControllerNames, ", ")+".")
+	flag.Float64Var(&kubeAPIQPS, "kube-api-qps", 20,
+		"Sustained queries per second the controller's Kubernetes client may send to the API server.")
+	flag.IntVar(&kubeAPIBurst, "kube-api-burst", 30,
+		"Queries the controller's Kubernetes client may send in a burst above --kube-api-qps.")
+	flag.IntVar(&circuitBreakerThreshold, "circuit-breaker-threshold", 10,
+		"Consecutive conflict, throttling, timeout or server errors from writes to the API server after which "+
+			"all writes are paused for --circuit-breaker-backoff. 0 disables the circuit breaker.")
+	flag.DurationVar(&circuitBreakerBackoff, "circuit-breaker-backoff", 30*time.Second,
+		"How long writes stay paused once the circuit breaker opens.")
+	flag.StringVar(&pauseConfigMap, "pause-configmap", "",
+		"Name of a ConfigMap in the controller's namespace that pauses the controller while its "+pause.Key+
+			" key is set to true: all writes are refused, while reads, events and metrics continue. "+
+			"Empty disables the pause switch.")
+	flag.DurationVar(&pausePollInterval, "pause-poll-interval", 10*time.Second,
+		"How often the pause ConfigMap is read.")
+	flag.StringVar(&featureFlagsConfigMap, "feature-flags-configmap", "",
+		"Name of a ConfigMap in the controller's namespace whose keys narrow the "+string(features.SurgeBatches)+", "+
+			string(features.CapacityPrecheck)+" and "+string(features.WebhookGating)+" runtime feature flags to the namespaces "+
+			"they are on in: \"true\", \"false\" or a comma-separated list of namespaces. Empty leaves them all on.")
+	flag.DurationVar(&featureFlagsPollInterval, "feature-flags-poll-interval", 10*time.Second,
+		"How often the feature flag ConfigMap is read.")
+	flag.BoolVar(&ownerReferences, "owner-references", true,
+		"If set, PDBs and EvictionAutoScalers the controllers create carry owner references and are garbage "+
+			"collected with their owner. If unset, for PDBs synced by GitOps tools that report owner references "+
+			"as drift, the owner is recorded in the eviction-autoscaler.azure.com/owner annotation and the "+
+			"controllers delete the objects themselves.")
 
 	opts := zap.Options{
 		Development: true,
@@ -100,32 +395,142 @@ func main() {
 		c.MinVersion = tls.VersionTLS12
 	}
 
+	if shardCount == 0 || shardIndex >= shardCount {
+		setupLog.Error(os.ErrInvalid, "shard-index must be less than shard-count and shard-count must be at least 1",
+			"shardIndex", shardIndex, "shardCount", shardCount)
+		os.Exit(1)
+	}
+	if !enableControllers && !enableWebhooks {
+		setupLog.Error(os.ErrInvalid, "at least one of enable-controllers and enable-webhooks must be set")
+		os.Exit(1)
+	}
+	if highAvailability && (!enableLeaderElection || !enableControllers) {
+		setupLog.Error(os.ErrInvalid, "high-availability requires leader-elect and enable-controllers")
+		os.Exit(1)
+	}
+	podName, podNamespace := os.Getenv("POD_NAME"), os.Getenv("POD_NAMESPACE")
+	if protectSelf && (!enableControllers || podName == "" || podNamespace == "") {
+		setupLog.Error(os.ErrInvalid, "protect-self requires enable-controllers and the POD_NAME and POD_NAMESPACE "+
+			"environment variables")
+		os.Exit(1)
+	}
+	metricsModeFlag := "metrics-mode"
+	if metricsDetailLevel != "" {
+		metricsMode, metricsModeFlag = metricsDetailLevel, "metrics-detail-level"
+	}
+	if err := metrics.SetMode(metricsMode); err != nil {
+		setupLog.Error(err, "invalid "+metricsModeFlag)
+		os.Exit(1)
+	}
+	if minAvailableFactor <= 0 || minAvailableFactor > 1 {
+		setupLog.Error(os.ErrInvalid, "pdb-min-available-factor must be above 0 and at most 1", "factor", minAvailableFactor)
+		os.Exit(1)
+	}
+	if rollbackStuckSurges && surgePendingDeadline <= 0 {
+		setupLog.Error(os.ErrInvalid, "rollback-stuck-surges requires surge-pending-deadline")
+		os.Exit(1)
+	}
+	if pauseConfigMap != "" && (podNamespace == "" || pausePollInterval <= 0) {
+		setupLog.Error(os.ErrInvalid, "pause-configmap requires the POD_NAMESPACE environment variable and a positive pause-poll-interval")
+		os.Exit(1)
+	}
+	if featureFlagsConfigMap != "" && (podNamespace == "" || featureFlagsPollInterval <= 0) {
+		setupLog.Error(os.ErrInvalid, "feature-flags-configmap requires the POD_NAMESPACE environment variable and a positive feature-flags-poll-interval")
+		os.Exit(1)
+	}
+	if maxConcurrentReconciles < 1 || kubeAPIQPS <= 0 || kubeAPIBurst < 1 {
+		setupLog.Error(os.ErrInvalid, "max-concurrent-reconciles, kube-api-qps and kube-api-burst must be positive")
+		os.Exit(1)
+	}
+	if err := controllers.SetConcurrency(controllerConcurrency); err != nil {
+		setupLog.Error(err, "invalid controller-concurrency")
+		os.Exit(1)
+	}
+	controllers.SetOwnerReferences(ownerReferences)
+	drainTaints := splitList(drainTaintKeys)
+	preemptionTaints := splitList(preemptionTaintKeys)
+	if karpenter {
+		drainTaints = controllers.WithKarpenterTaint(drainTaints)
+		preemptionTaints = controllers.WithKarpenterTaint(preemptionTaints)
+		if cooldown == 0 {
+			cooldown = controllers.KarpenterCooldown
+		}
+	}
+	// Replicas of the same shard elect a leader among themselves; different shards
+	// must not contend for the same lease.
+	leaderElectionID := "d482b936.azure.com"
+	if shardCount > 1 {
+		leaderElectionID = fmt.Sprintf("shard-%d-of-%d.%s", shardIndex, shardCount, leaderElectionID)
+	}
+
 	tlsOpts := []func(*tls.Config){enforceFIPS}
 	if !enableHTTP2 {
 		tlsOpts = append(tlsOpts, disableHTTP2)
 	}
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
-		Scheme: scheme,
+	restConfig := ctrl.GetConfigOrDie()
+	restConfig.QPS = float32(kubeAPIQPS)
+	restConfig.Burst = kubeAPIBurst
+
+	// Serve the annotation registry next to the metrics so users can check what the
+	// running version recognizes.
+	extraHandlers := map[string]http.Handler{
+		"/annotations": annotations.Handler(),
+	}
+	// Decision traces name workloads, so only callers the API server authenticates
+	// and authorizes for the path get them.
+	decisionLog := decisions.NewLog(decisionTraceSize)
+	if decisionLog != nil && enableControllers {
+		reviewer, err := client.New(restConfig, client.Options{Scheme: scheme})
+		if err != nil {
+			setupLog.Error(err, "unable to create client for decision trace authentication")
+			os.Exit(1)
+		}
+		extraHandlers["/debug/decisions"] = decisions.Authorized(reviewer, decisionLog.Handler())
+	}
+
+	// Every write the manager's client makes goes through the breaker, so a storm of
+	// API errors pauses them all instead of each controller retrying on its own.
+	breaker := circuitbreaker.New(circuitBreakerThreshold, circuitBreakerBackoff)
+	// The pause switch wraps the breaker, so writes refused while paused neither
+	// count towards opening it nor close it.
+	var pauseSwitch *pause.Switch
+	if enableControllers {
+		pauseSwitch = pause.New(podNamespace, pauseConfigMap, pausePollInterval)
+	}
+	featureFlags := features.New(podNamespace, featureFlagsConfigMap, featureFlagsPollInterval)
+	mgr, err := ctrl.NewManager(restConfig, ctrl.Options{
+		Scheme:     scheme,
+		Controller: config.Controller{MaxConcurrentReconciles: maxConcurrentReconciles},
+		NewClient: func(config *rest.Config, options client.Options) (client.Client, error) {
+			c, err := client.New(config, options)
+			if err != nil {
+				return nil, err
+			}
+			return pauseSwitch.Client(breaker.Client(c)), nil
+		},
+		// Only FailedScheduling events are read; caching every event in the cluster
+		// would cost far more than the rest of the cache.
+		Cache: cache.Options{ByObject: map[client.Object]cache.ByObject{
+			&corev1.Event{}: {Field: fields.OneTermEqualSelector("reason", controllers.FailedSchedulingReason)},
+		}},
 		Metrics: metricsserver.Options{
 			BindAddress:   metricsAddr,
 			SecureServing: secureMetrics,
 			TLSOpts:       tlsOpts,
+			ExtraHandlers: extraHandlers,
 		},
+		WebhookServer:          webhook.NewServer(webhook.Options{TLSOpts: tlsOpts}),
 		HealthProbeBindAddress: probeAddr,
-		LeaderElection:         enableLeaderElection,
-		LeaderElectionID:       "d482b936.azure.com",
-		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
-		// when the Manager ends. This requires the binary to immediately end when the
-		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
-		// speeds up voluntary leader transitions as the new leader don't have to wait
-		// LeaseDuration time first.
-		//
-		// In the default scaffold provided, the program ends immediately after
-		// the manager stops, so would be fine to enable this option. However,
-		// if you are doing or is intended to do any operation such as perform cleanups
-		// after the manager stops then its usage might be unsafe.
-		// LeaderElectionReleaseOnCancel: true,
+		PprofBindAddress:       pprofAddr,
+		LeaderElection:         enableLeaderElection && enableControllers, // webhook-only replicas all serve
+		LeaderElectionID:       leaderElectionID,
+		// Releasing the lease when the manager stops lets a standby take over at once
+		// instead of after the lease duration. It is safe because the program ends
+		// as soon as the manager has stopped, and the manager releases the lease only
+		// after the reconcilers have.
+		LeaderElectionReleaseOnCancel: highAvailability,
+		GracefulShutdownTimeout:       &gracefulShutdownTimeout,
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
@@ -151,36 +556,70 @@ func main() {
 	// When ENABLED_BY_DEFAULT=true, disabledByDefault=false (enabled by default)
 	disabledByDefault := !enabledByDefault
 
-	// Parse ACTIONED_NAMESPACES environment variable (comma-separated list)
+	// Parse ACTIONED_NAMESPACES environment variable (comma-separated list of names and patterns)
 	// These namespaces will be enabled when disabledByDefault=true and will be ignored when disabledByDefault=false
 	actionedNamespacesStr := os.Getenv("ACTIONED_NAMESPACES")
-	// Split on commas, trim whitespace, and drop empty entries. An unset or empty
-	// ACTIONED_NAMESPACES yields no entries (strings.Split("", ",") would otherwise
-	// produce a single empty string).
-	var actionedNamespacesList []string
-	for _, ns := range strings.Split(actionedNamespacesStr, ",") {
-		if trimmed := strings.TrimSpace(ns); trimmed != "" {
-			actionedNamespacesList = append(actionedNamespacesList, trimmed)
-		}
+	actionedNamespacesList := splitList(actionedNamespacesStr)
+
+	// Entries may be globs or /regexes/; reject any that don't compile.
+	if err := namespacefilter.ValidatePatterns(actionedNamespacesList); err != nil {
+		setupLog.Error(err, "invalid ACTIONED_NAMESPACES entry")
+		os.Exit(1)
 	}
 
-	// Customers may not action AKS-owned namespaces; fail the install if they try.
+	// Customers may not action always-enabled namespaces, by default the AKS-owned
+	// ones; fail the install if they try.
+	alwaysEnabledList := splitList(alwaysEnabledNamespaces)
 	for _, ns := range actionedNamespacesList {
-		if namespacefilter.IsAKSOwnedNamespace(ns) {
+		if slices.Contains(alwaysEnabledList, ns) {
 			setupLog.Error(os.ErrInvalid,
-				"ACTIONED_NAMESPACES may not contain an AKS-owned namespace; eviction-autoscaler manages these automatically",
+				"ACTIONED_NAMESPACES may not contain an always-enabled namespace; eviction-autoscaler manages these automatically",
 				"namespace", ns)
 			os.Exit(1)
 		}
 	}
 
+	// The controller's own deployment is managed even if its namespace is disabled;
+	// the rest of the namespace is left alone. The manager's cache isn't started yet,
+	// so the deployment is read from the API server.
+	var exempt []types.NamespacedName
+	if protectSelf {
+		deployment, err := controllers.OwnDeployment(context.Background(), mgr.GetAPIReader(), podNamespace, podName)
+		if err != nil {
+			setupLog.Error(err, "unable to find the controller's own deployment for protect-self")
+			os.Exit(1)
+		}
+		exempt = append(exempt, client.ObjectKeyFromObject(deployment))
+	}
+
+	nsSelector, err := labels.Parse(namespaceSelector)
+	if err != nil {
+		setupLog.Error(err, "unable to parse --namespace-selector", "namespaceSelector", namespaceSelector)
+		os.Exit(1)
+	}
+
 	// Create namespace filter
-	nsfilter := namespacefilter.New(actionedNamespacesList, disabledByDefault)
+	nsfilter := namespacefilter.New(actionedNamespacesList, disabledByDefault).
+		WithAlwaysEnabled(alwaysEnabledList).
+		WithSelector(nsSelector).
+		WithShard(uint32(shardIndex), uint32(shardCount)).
+		WithExempt(exempt...)
 
 	setupLog.Info("Eviction autoscaler configuration",
 		"disabledByDefault", disabledByDefault,
 		"enabledByDefault", enabledByDefault,
-		"actionedNamespaces", actionedNamespacesList)
+		"actionedNamespaces", actionedNamespacesList,
+		"alwaysEnabledNamespaces", alwaysEnabledList,
+		"namespaceSelector", nsSelector.String(),
+		"shardIndex", shardIndex,
+		"shardCount", shardCount,
+		"exemptDeployments", exempt)
+
+	// Serve repeated namespace decisions from memory, dropped as namespaces change.
+	if err := mgr.Add(nsfilter.CacheDecisions(mgr.GetCache())); err != nil {
+		setupLog.Error(err, "unable to set up namespace decision cache")
+		os.Exit(1)
+	}
 
 	// Parse PDB_CREATE environment variable (defaults to false if not set)
 	pdbCreateStr := os.Getenv("PDB_CREATE")
@@ -195,59 +634,304 @@ func main() {
 	}
 	setupLog.Info("PDB creation configuration", "pdbCreate", pdbCreate)
 
-	if err = (&controllers.EvictionAutoScalerReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-		Filter: nsfilter,
-	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "EvictionAutoScaler")
-		os.Exit(1)
-	}
-	setupLog.Info("EvictionAutoScalerReconciler setup completed")
+	if enableControllers {
+		headroom, err := newHeadroomGuard(headroomSource, headroomPrometheusURL, headroomPrometheusQuery,
+			headroomThreshold, headroomCheckInterval, mgr.GetAPIReader())
+		if err != nil {
+			setupLog.Error(err, "invalid headroom configuration")
+			os.Exit(1)
+		}
+		if err = controllers.SetupLabelIndexes(context.TODO(), mgr.GetFieldIndexer()); err != nil {
+			setupLog.Error(err, "unable to set up label indexes")
+			os.Exit(1)
+		}
+		var impersonator *controllers.Impersonator
+		if impersonateTenants {
+			impersonator = &controllers.Impersonator{
+				Config: mgr.GetConfig(),
+				Scheme: mgr.GetScheme(),
+				Mapper: mgr.GetRESTMapper(),
+				Cache:  mgr.GetCache(),
+				Pause:  pauseSwitch,
+			}
+		}
+		if err = (&controllers.EvictionAutoScalerReconciler{
+			Client:                  mgr.GetClient(),
+			Scheme:                  mgr.GetScheme(),
+			Recorder:                mgr.GetEventRecorderFor("eviction-autoscaler"),
+			Filter:                  nsfilter,
+			EvictionFreshness:       evictionFreshness,
+			EvictionRetention:       evictionRetention,
+			EvictionCoalesceWindow:  evictionCoalesceWindow,
+			APIReader:               mgr.GetAPIReader(),
+			Impersonator:            impersonator,
+			PlacementHints:          placementHints,
+			SurgeBatches:            surgeBatchWindow > 0,
+			AutoscalerHints:         autoscalerHints,
+			DrainTaintKeys:          drainTaints,
+			NodeWarmupTimeout:       nodeWarmupTimeout,
+			DrainFailureThreshold:   int32(drainFailureThreshold),
+			DrainFailureSuppression: drainFailureSuppression,
+			Headroom:                headroom,
+			SurgeApproval:           surgeApproval,
+			SurgeAutoApproveAfter:   surgeAutoApproveAfter,
+			Cooldown:                cooldown,
+			HysteresisWindow:        hysteresisWindow,
+			HysteresisMaxCooldown:   hysteresisMaxCooldown,
+			UnmarkedSurges:          !surgeMarkerAnnotation,
+			Decisions:               decisionLog,
+			FinishOnShutdown:        highAvailability,
+			SurgePendingDeadline:    surgePendingDeadline,
+			RollbackStuckSurges:     rollbackStuckSurges,
+			TopologyLimitedSurges:   topologyLimitedSurges,
+			StatefulSetSurge:        statefulSetSurge,
+			Features:                featureFlags,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "EvictionAutoScaler")
+			os.Exit(1)
+		}
+		setupLog.Info("EvictionAutoScalerReconciler setup completed")
+
+		if pdbCreate {
+			if err = (&controllers.DeploymentToPDBReconciler{
+				Client:               mgr.GetClient(),
+				Scheme:               mgr.GetScheme(),
+				Recorder:             mgr.GetEventRecorderFor("eviction-autoscaler"),
+				Filter:               nsfilter,
+				SkipSingleReplica:    skipSingleReplica,
+				MinAvailableDebounce: minAvailableDebounce,
+				MinAvailableFactor:   minAvailableFactor,
+				RepairInterval:       easRepairInterval,
+				ResyncPeriod:         resyncPeriod,
+			}).SetupWithManager(mgr); err != nil {
+				setupLog.Error(err, "unable to create controller", "controller", "DeploymentToPDBReconciler")
+				os.Exit(1)
+			}
+			setupLog.Info("DeploymentToPDBReconciler setup completed")
+
+			if err = (&controllers.JobToPDBReconciler{
+				Client:   mgr.GetClient(),
+				Scheme:   mgr.GetScheme(),
+				Recorder: mgr.GetEventRecorderFor("eviction-autoscaler"),
+				Filter:   nsfilter,
+			}).SetupWithManager(mgr); err != nil {
+				setupLog.Error(err, "unable to create controller", "controller", "JobToPDBReconciler")
+				os.Exit(1)
+			}
+			setupLog.Info("JobToPDBReconciler setup completed")
+
+			// Watches both HPA and KEDA ScaledObject changes to keep PDB minAvailable
+			// in sync with the autoscaler's min replicas floor.
+			if err = (&controllers.AutoscalerToPDBReconciler{
+				Client: mgr.GetClient(),
+				Scheme: mgr.GetScheme(),
+				Filter: nsfilter,
+			}).SetupWithManager(mgr); err != nil {
+				setupLog.Error(err, "unable to create controller", "controller", "AutoscalerToPDBReconciler")
+				os.Exit(1)
+			}
+			setupLog.Info("AutoscalerToPDBReconciler setup completed")
+		}
+
+		if err = (&controllers.PDBToEvictionAutoScalerReconciler{
+			Client:       mgr.GetClient(),
+			Scheme:       mgr.GetScheme(),
+			Recorder:     mgr.GetEventRecorderFor("eviction-autoscaler"),
+			Filter:       nsfilter,
+			ResyncPeriod: resyncPeriod,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "PDBToEvictionAutoScalerReconciler")
+			os.Exit(1)
+		}
+		setupLog.Info("PDBToEvictionAutoScalerReconciler  setup completed")
+
+		if err = (&controllers.NodeReconciler{
+			Client:          mgr.GetClient(),
+			Scheme:          mgr.GetScheme(),
+			Filter:          nsfilter,
+			TrackWarmup:     nodeWarmupTimeout > 0,
+			TrackDrainReady: nodeDrainReady,
+			Cooldown:        cooldown,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "EvictionAutoScaler")
+			os.Exit(1)
+		}
+
+		if preemptionNodeConditions != "" || len(preemptionTaints) > 0 {
+			if err = (&controllers.PreemptionNoticeReconciler{
+				Client:     mgr.GetClient(),
+				Scheme:     mgr.GetScheme(),
+				Recorder:   mgr.GetEventRecorderFor("eviction-autoscaler"),
+				Conditions: splitList(preemptionNodeConditions),
+				TaintKeys:  preemptionTaints,
+			}).SetupWithManager(mgr); err != nil {
+				setupLog.Error(err, "unable to create controller", "controller", "PreemptionNoticeReconciler")
+				os.Exit(1)
+			}
+		}
 
-	if pdbCreate {
-		if err = (&controllers.DeploymentToPDBReconciler{
+		if err = (&controllers.PDBDisruptionsReconciler{
 			Client: mgr.GetClient(),
 			Scheme: mgr.GetScheme(),
 			Filter: nsfilter,
 		}).SetupWithManager(mgr); err != nil {
-			setupLog.Error(err, "unable to create controller", "controller", "DeploymentToPDBReconciler")
+			setupLog.Error(err, "unable to create controller", "controller", "PDBDisruptionsReconciler")
 			os.Exit(1)
 		}
-		setupLog.Info("DeploymentToPDBReconciler setup completed")
 
-		// Watches both HPA and KEDA ScaledObject changes to keep PDB minAvailable
-		// in sync with the autoscaler's min replicas floor.
-		if err = (&controllers.AutoscalerToPDBReconciler{
+		if evictionPollInterval > 0 {
+			if err = (&controllers.EvictionPollReconciler{
+				Client:   mgr.GetClient(),
+				Scheme:   mgr.GetScheme(),
+				Filter:   nsfilter,
+				Interval: evictionPollInterval,
+			}).SetupWithManager(mgr); err != nil {
+				setupLog.Error(err, "unable to create controller", "controller", "EvictionPollReconciler")
+				os.Exit(1)
+			}
+			setupLog.Info("EvictionPollReconciler setup completed")
+		}
+
+		if orphanSweepInterval > 0 {
+			if err = (&controllers.SurgeOrphanReconciler{
+				Client:   mgr.GetClient(),
+				Scheme:   mgr.GetScheme(),
+				Recorder: mgr.GetEventRecorderFor("eviction-autoscaler"),
+				Filter:   nsfilter,
+				Interval: orphanSweepInterval,
+			}).SetupWithManager(mgr); err != nil {
+				setupLog.Error(err, "unable to create controller", "controller", "SurgeOrphanReconciler")
+				os.Exit(1)
+			}
+			setupLog.Info("SurgeOrphanReconciler setup completed")
+		}
+
+		if surgeBatchWindow > 0 {
+			if err = (&controllers.SurgeBatchReconciler{
+				Client: mgr.GetClient(),
+				Scheme: mgr.GetScheme(),
+				Filter: nsfilter,
+				Window: surgeBatchWindow,
+			}).SetupWithManager(mgr); err != nil {
+				setupLog.Error(err, "unable to create controller", "controller", "SurgeBatchReconciler")
+				os.Exit(1)
+			}
+			setupLog.Info("SurgeBatchReconciler setup completed")
+		}
+
+		if surgeSchedulingEvents {
+			if err = (&controllers.SurgeSchedulingReconciler{
+				Client: mgr.GetClient(),
+				Scheme: mgr.GetScheme(),
+				Filter: nsfilter,
+			}).SetupWithManager(mgr); err != nil {
+				setupLog.Error(err, "unable to create controller", "controller", "SurgeSchedulingReconciler")
+				os.Exit(1)
+			}
+			setupLog.Info("SurgeSchedulingReconciler setup completed")
+		}
+
+		if pauseSwitch != nil {
+			if err = mgr.Add(pauseSwitch.Watcher(mgr.GetAPIReader(), mgr.GetEventRecorderFor("eviction-autoscaler"))); err != nil {
+				setupLog.Error(err, "unable to set up pause switch")
+				os.Exit(1)
+			}
+		}
+
+		if protectSelf {
+			if err = mgr.Add(&controllers.SelfProtection{
+				Client:    mgr.GetClient(),
+				APIReader: mgr.GetAPIReader(),
+				Recorder:  mgr.GetEventRecorderFor("eviction-autoscaler"),
+				Namespace: podNamespace,
+				PodName:   podName,
+			}); err != nil {
+				setupLog.Error(err, "unable to set up self-protection")
+				os.Exit(1)
+			}
+		}
+
+		if metricsSyncInterval > 0 {
+			if err = mgr.Add(&controllers.GaugeSynchronizer{
+				Reader:   mgr.GetClient(),
+				Filter:   nsfilter,
+				Interval: metricsSyncInterval,
+			}); err != nil {
+				setupLog.Error(err, "unable to set up gauge synchronizer")
+				os.Exit(1)
+			}
+		}
+
+		if err = (&controllers.NamespaceReconciler{
 			Client: mgr.GetClient(),
 			Scheme: mgr.GetScheme(),
 			Filter: nsfilter,
 		}).SetupWithManager(mgr); err != nil {
-			setupLog.Error(err, "unable to create controller", "controller", "AutoscalerToPDBReconciler")
+			setupLog.Error(err, "unable to create controller", "controller", "NamespaceReconciler")
 			os.Exit(1)
 		}
-		setupLog.Info("AutoscalerToPDBReconciler setup completed")
-	}
 
-	if err = (&controllers.PDBToEvictionAutoScalerReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-		Filter: nsfilter,
-	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "PDBToEvictionAutoScalerReconciler")
-		os.Exit(1)
+		if namespaceStatus {
+			if err = (&controllers.NamespaceStatusReconciler{
+				Client:   mgr.GetClient(),
+				Scheme:   mgr.GetScheme(),
+				Recorder: mgr.GetEventRecorderFor("eviction-autoscaler"),
+				Filter:   nsfilter,
+			}).SetupWithManager(mgr); err != nil {
+				setupLog.Error(err, "unable to create controller", "controller", "NamespaceStatusReconciler")
+				os.Exit(1)
+			}
+			setupLog.Info("NamespaceStatusReconciler setup completed")
+		}
 	}
-	setupLog.Info("PDBToEvictionAutoScalerReconciler  setup completed")
 
-	if err = (&controllers.NodeReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "EvictionAutoScaler")
-		os.Exit(1)
+	if enableWebhooks {
+		if err = webhookv1.SetupEvictionAutoScalerWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "EvictionAutoScaler")
+			os.Exit(1)
+		}
+		if err = webhookv1.SetupPodPlacementWebhookWithManager(mgr, featureFlags); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Pod")
+			os.Exit(1)
+		}
+		if pdbValidation != "" {
+			if err = webhookv1.SetupPDBWebhookWithManager(mgr, nsfilter, pdbValidation); err != nil {
+				setupLog.Error(err, "unable to create webhook", "webhook", "PodDisruptionBudget")
+				os.Exit(1)
+			}
+		}
+		setupLog.Info("EvictionAutoScaler webhook setup completed")
+	}
+	if featureFlags != nil {
+		if err = mgr.Add(featureFlags.Watcher(mgr.GetAPIReader())); err != nil {
+			setupLog.Error(err, "unable to set up feature flags")
+			os.Exit(1)
+		}
 	}
 	// +kubebuilder:scaffold:builder
 
+	if pprofAddr != "" {
+		if err := mgr.Add(&diagnostics.SnapshotOnSignal{Dir: diagnosticsDir}); err != nil {
+			setupLog.Error(err, "unable to set up diagnostics snapshots")
+			os.Exit(1)
+		}
+	}
+
+	// Tracing is configured by the standard OTEL_* environment variables, and only
+	// turned on by an OTLP endpoint among them.
+	if tracing.Enabled() {
+		provider, err := tracing.NewProvider(context.Background())
+		if err != nil {
+			setupLog.Error(err, "unable to set up OpenTelemetry tracing")
+			os.Exit(1)
+		}
+		if err := mgr.Add(provider); err != nil {
+			setupLog.Error(err, "unable to add OpenTelemetry tracing")
+			os.Exit(1)
+		}
+		setupLog.Info("Exporting reconcile spans over OTLP")
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -256,6 +940,21 @@ func main() {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
+	// Each component gets its own readiness check, served at /readyz/<name>, so probes
+	// can follow one component. Liveness stays a ping: restarting the process because
+	// one component is unready would take the other down with it.
+	if enableControllers {
+		if err := mgr.AddReadyzCheck("controllers", cacheSyncedChecker(mgr)); err != nil {
+			setupLog.Error(err, "unable to set up controllers ready check")
+			os.Exit(1)
+		}
+	}
+	if enableWebhooks {
+		if err := mgr.AddReadyzCheck("webhook", mgr.GetWebhookServer().StartedChecker()); err != nil {
+			setupLog.Error(err, "unable to set up webhook ready check")
+			os.Exit(1)
+		}
+	}
 
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
@@ -263,3 +962,28 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// cacheSyncedChecker reports ready once the informer caches the reconcilers read from
+// have synced.
+func cacheSyncedChecker(mgr ctrl.Manager) healthz.Checker {
+	return func(req *http.Request) error {
+		ctx, cancel := context.WithTimeout(req.Context(), time.Second)
+		defer cancel()
+		if !mgr.GetCache().WaitForCacheSync(ctx) {
+			return errors.New("informer caches not synced")
+		}
+		return nil
+	}
+}
+
+// splitList splits a comma-separated value, trimming whitespace and dropping empty
+// entries, so an unset value yields no entries.
+func splitList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if trimmed := strings.TrimSpace(item); trimmed != "" {
+			list = append(list, trimmed)
+		}
+	}
+	return list
+}
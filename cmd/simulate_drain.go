@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	controllers "github.com/azure/eviction-autoscaler/internal/controller"
+)
+
+// runSimulateDrain implements "manager simulate-drain": it reports which evictions a
+// drain of the node would block, which workloads would surge, and the extra replicas
+// and resource requests the surges need, without touching the cluster.
+func runSimulateDrain(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("simulate-drain", flag.ContinueOnError)
+	node := fs.String("node", "", "Name of the node to simulate draining.")
+	output := fs.String("output", "text", "Output format, text or json.")
+	kubeconfig := fs.String("kubeconfig", "", "Path to a kubeconfig. Defaults to $KUBECONFIG, in-cluster config, then ~/.kube/config.")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s simulate-drain --node NAME [--output text|json]\n\n", os.Args[0])
+		fmt.Fprintln(fs.Output(), "Previews a drain of the node: blocked evictions, surges, and the extra capacity they need.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *node == "" {
+		fs.Usage()
+		return errors.New("--node is required")
+	}
+	if *output != "text" && *output != "json" {
+		return fmt.Errorf("unknown --output %q, want text or json", *output)
+	}
+
+	c, err := newClient(*kubeconfig)
+	if err != nil {
+		return err
+	}
+	sim, err := controllers.SimulateDrain(context.Background(), c, *node)
+	if err != nil {
+		return err
+	}
+	if *output == "json" {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(sim)
+	}
+	_, err = io.WriteString(out, sim.String())
+	return err
+}
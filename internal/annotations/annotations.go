@@ -0,0 +1,303 @@
+// Package annotations is the single registry of every annotation eviction-autoscaler
+// reads or writes. Controllers take their keys from here and parse user input through
+// it, and the same table is served from the manager so documentation can't drift from
+// the code.
+package annotations
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	Enable                    = "eviction-autoscaler.azure.com/enable"
+	PDBCreate                 = "eviction-autoscaler.azure.com/pdb-create"
+	JobPDB                    = "eviction-autoscaler.azure.com/job-pdb"
+	Surge                     = "eviction-autoscaler.azure.com/surge"
+	Exclude                   = "eviction-autoscaler.azure.com/exclude"
+	EmergencySurgeUntil       = "eviction-autoscaler.azure.com/emergency-surge-until"
+	ImpersonateServiceAccount = "eviction-autoscaler.azure.com/impersonate-service-account"
+	OriginalMinReplicas       = "eviction-autoscaler.azure.com/original-min-replicas"
+	AvoidNodes                = "eviction-autoscaler.azure.com/avoid-nodes"
+	SurgePriorityClass        = "eviction-autoscaler.azure.com/surge-priority-class"
+	WarmupComplete            = "eviction-autoscaler.azure.com/warmup-complete"
+	DrainReady                = "eviction-autoscaler.azure.com/drain-ready"
+	SurgeBatch                = "eviction-autoscaler.azure.com/surge-batch"
+	ApproveSurge              = "eviction-autoscaler.azure.com/approve"
+	MinAvailableFloor         = "eviction-autoscaler.azure.com/min-available-floor"
+	MaxSurge                  = "eviction-autoscaler.azure.com/max-surge"
+	CordonedForPreemption     = "eviction-autoscaler.azure.com/cordoned-for-preemption"
+	AutoscalerHints           = "eviction-autoscaler.azure.com/autoscaler-hints"
+	SafeToEvict               = "cluster-autoscaler.kubernetes.io/safe-to-evict"
+	DoNotDisrupt              = "karpenter.sh/do-not-disrupt"
+	SurgeReplicas             = "evictionSurgeReplicas"
+	OwnedBy                   = "ownedBy"
+	Target                    = "target"
+	Owner                     = "eviction-autoscaler.azure.com/owner"
+)
+
+// OwnedByController is the OwnedBy value on objects the controller created.
+const OwnedByController = "EvictionAutoScaler"
+
+// Type is the expected format of an annotation value.
+type Type string
+
+const (
+	TypeBool      Type = "bool"
+	TypeInt       Type = "int"
+	TypeTimestamp Type = "RFC3339 timestamp"
+	TypeString    Type = "string"
+)
+
+// Annotation describes one recognized annotation.
+type Annotation struct {
+	Key         string `json:"key"`
+	Scope       string `json:"scope"`             // kinds the annotation is read from or written to
+	Type        Type   `json:"type"`              // expected value format
+	Default     string `json:"default,omitempty"` // behaviour when the annotation is absent
+	Managed     bool   `json:"managed"`           // set by the controller; users should not edit it
+	Description string `json:"description"`
+}
+
+var registry = []Annotation{
+	{
+		Key:         Enable,
+		Scope:       "Namespace",
+		Type:        TypeBool,
+		Default:     "ENABLED_BY_DEFAULT setting",
+		Description: "Enables or disables eviction-autoscaler for the namespace; takes precedence over the controller default.",
+	},
+	{
+		Key:         PDBCreate,
+		Scope:       "Deployment",
+		Type:        TypeBool,
+		Default:     "true",
+		Description: "Set to false to stop the controller creating a PDB for the deployment.",
+	},
+	{
+		Key:         JobPDB,
+		Scope:       "Job, CronJob",
+		Type:        TypeBool,
+		Default:     "false",
+		Description: "Set to true to have the controller create a PDB blocking evictions of the Job's running pods until it completes, with PDB_CREATE. On a CronJob it covers every Job the CronJob creates. The Job's value takes precedence. No EvictionAutoScaler is created and the Job is never surged.",
+	},
+	{
+		Key:         Surge,
+		Scope:       "Deployment, StatefulSet, Rollout, Namespace",
+		Type:        TypeBool,
+		Default:     "true",
+		Description: "Set to false to keep PDB management but never change replicas, including for emergency overrides; evictions are only recorded. The workload's value takes precedence over the namespace's.",
+	},
+	{
+		Key:         Exclude,
+		Scope:       "Deployment, StatefulSet, PodDisruptionBudget",
+		Type:        TypeBool,
+		Default:     "false",
+		Description: "Set to true to have eviction-autoscaler leave the workload or PDB alone as if its namespace were disabled, overriding the namespace's enablement. PDBs and EvictionAutoScalers it created are removed, reverting any surge in flight.",
+	},
+	{
+		Key:         EmergencySurgeUntil,
+		Scope:       "EvictionAutoScaler",
+		Type:        TypeTimestamp,
+		Description: "Surges the target one above the PDB floor until the given time, at most one hour ahead.",
+	},
+	{
+		Key:         ApproveSurge,
+		Scope:       "SurgePlan",
+		Type:        TypeBool,
+		Default:     "plan waits, or is approved at spec.autoApproveAt",
+		Description: "Approves (true) or rejects (false) a pending surge plan, with --surge-approval.",
+	},
+	{
+		Key:         ImpersonateServiceAccount,
+		Scope:       "Namespace",
+		Type:        TypeString,
+		Description: "Service account in the namespace that surge writes impersonate when --impersonate-tenant-service-accounts is set.",
+	},
+	{
+		Key:         SurgeReplicas,
+		Scope:       "Deployment, HorizontalPodAutoscaler, ScaledObject",
+		Type:        TypeInt,
+		Managed:     true,
+		Description: "Replica count of the active surge; present only while a surge is in flight.",
+	},
+	{
+		Key:         OriginalMinReplicas,
+		Scope:       "HorizontalPodAutoscaler, ScaledObject",
+		Type:        TypeInt,
+		Managed:     true,
+		Description: "Pre-surge minReplicas/minReplicaCount restored on revert.",
+	},
+	{
+		Key:         AvoidNodes,
+		Scope:       "Deployment",
+		Type:        TypeString,
+		Managed:     true,
+		Description: "Comma-separated draining nodes that new pods of the deployment are kept off while a surge is active, with --surge-placement-hints.",
+	},
+	{
+		Key:         SurgePriorityClass,
+		Scope:       "Deployment",
+		Type:        TypeString,
+		Managed:     true,
+		Description: "PriorityClass given to new pods of the deployment while a surge is active, from the EvictionAutoScaler's spec.surgePriorityClassName.",
+	},
+	{
+		Key:         SurgeBatch,
+		Scope:       "Deployment, Pod",
+		Type:        TypeString,
+		Managed:     true,
+		Description: "Identifies the wave of pods created by one surge, with --surge-batch-window. New pods of the deployment are admitted with it and with a scheduling gate of the same name, which is lifted for the whole wave at once.",
+	},
+	{
+		Key:         WarmupComplete,
+		Scope:       "Node",
+		Type:        TypeBool,
+		Managed:     true,
+		Description: "Set once the node is Ready and runs a Ready pod of every DaemonSet expected on it, with --node-warmup-timeout. Scale-down after a surge waits for new nodes to carry it.",
+	},
+	{
+		Key:         DrainReady,
+		Scope:       "Node",
+		Type:        TypeBool,
+		Managed:     true,
+		Description: "Set on a cordoned node with --node-drain-ready-annotation: true once no pod left to evict from it is covered by a PDB allowing no disruptions, e.g. after the blocking workloads surged, false until then. Drain orchestrators can wait for it before evicting. Removed when the node is uncordoned.",
+	},
+	{
+		Key:         CordonedForPreemption,
+		Scope:       "Node",
+		Type:        TypeBool,
+		Managed:     true,
+		Description: "Set on a node the controller cordoned because of a preemption or maintenance notice, with --preemption-node-conditions or --preemption-taint-keys. The node is uncordoned if the notice clears.",
+	},
+	{
+		Key:         AutoscalerHints,
+		Scope:       "Deployment, Pod",
+		Type:        TypeString,
+		Managed:     true,
+		Description: "With --cluster-autoscaler-hints, set on a deployment while a surge is active so its new pods are kept from node scale-down too. On a pod, lists the node autoscaler annotations the controller added, which are removed when the surge is reverted.",
+	},
+	{
+		Key:         SafeToEvict,
+		Scope:       "Pod",
+		Type:        TypeBool,
+		Managed:     true,
+		Description: "Cluster Autoscaler annotation set to false on a surged deployment's pods with --cluster-autoscaler-hints, so their nodes aren't scaled down during the drain. A value the pod already carries is kept.",
+	},
+	{
+		Key:         DoNotDisrupt,
+		Scope:       "Pod",
+		Type:        TypeBool,
+		Managed:     true,
+		Description: "Karpenter annotation set to true on a surged deployment's pods with --cluster-autoscaler-hints, so their nodes aren't consolidated during the drain. A value the pod already carries is kept.",
+	},
+	{
+		Key:         OwnedBy,
+		Scope:       "PodDisruptionBudget, EvictionAutoScaler",
+		Type:        TypeString,
+		Managed:     true,
+		Description: "Marks objects created by the controller; remove it from a PDB to take ownership of it.",
+	},
+	{
+		Key:         MinAvailableFloor,
+		Scope:       "PodDisruptionBudget",
+		Type:        TypeInt,
+		Default:     "the PDB's integer minAvailable when ownedBy is added to a user-created PDB",
+		Description: "Lowest minAvailable the controller sets on a PDB it manages; it tracks replicas above it. Recorded when the controller adopts a PDB; edit or remove it to change the floor.",
+	},
+	{
+		Key:         MaxSurge,
+		Scope:       "StatefulSet",
+		Type:        TypeString,
+		Default:     "1",
+		Description: "How many pods a surge may add to the StatefulSet, as a count or a percentage of its replicas. StatefulSets have no maxSurge of their own; 0 stops them being surged.",
+	},
+	{
+		Key:         Target,
+		Scope:       "PodDisruptionBudget, EvictionAutoScaler",
+		Type:        TypeString,
+		Managed:     true,
+		Description: "Name of the workload the object was created for.",
+	},
+	{
+		Key:         Owner,
+		Scope:       "PodDisruptionBudget, EvictionAutoScaler",
+		Type:        TypeString,
+		Managed:     true,
+		Description: "Kind/name of the object's owner with --owner-references=false, set instead of an owner reference. The controller deletes the object once its owner is gone.",
+	},
+}
+
+// All returns a copy of every registered annotation.
+func All() []Annotation {
+	return append([]Annotation(nil), registry...)
+}
+
+// Lookup returns the registered annotation for key.
+func Lookup(key string) (Annotation, bool) {
+	for _, a := range registry {
+		if a.Key == key {
+			return a, true
+		}
+	}
+	return Annotation{}, false
+}
+
+// Validate checks val against the registered type of key.
+func Validate(key, val string) error {
+	a, ok := Lookup(key)
+	if !ok {
+		return fmt.Errorf("unknown annotation %s", key)
+	}
+	var err error
+	switch a.Type {
+	case TypeBool:
+		_, err = strconv.ParseBool(val)
+	case TypeInt:
+		_, err = strconv.ParseInt(val, 10, 32)
+	case TypeTimestamp:
+		_, err = time.Parse(time.RFC3339, val)
+	}
+	if err != nil {
+		return fmt.Errorf("annotation %s value %q is not a valid %s: %w", key, val, a.Type, err)
+	}
+	return nil
+}
+
+// Bool parses a bool annotation value, validating it against the registry.
+func Bool(key, val string) (bool, error) {
+	if err := Validate(key, val); err != nil {
+		return false, err
+	}
+	return strconv.ParseBool(val)
+}
+
+// Int32 parses an int annotation value, validating it against the registry.
+func Int32(key, val string) (int32, error) {
+	if err := Validate(key, val); err != nil {
+		return 0, err
+	}
+	n, err := strconv.ParseInt(val, 10, 32)
+	return int32(n), err
+}
+
+// Timestamp parses an RFC3339 annotation value, validating it against the registry.
+func Timestamp(key, val string) (time.Time, error) {
+	if err := Validate(key, val); err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339, val)
+}
+
+// Handler serves the registry as JSON.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(registry); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
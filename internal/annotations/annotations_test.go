@@ -0,0 +1,58 @@
+package annotations
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRegistryKeysUnique(t *testing.T) {
+	seen := map[string]bool{}
+	for _, a := range All() {
+		if seen[a.Key] {
+			t.Errorf("annotation %s registered twice", a.Key)
+		}
+		seen[a.Key] = true
+		if a.Scope == "" || a.Type == "" || a.Description == "" {
+			t.Errorf("annotation %s is missing scope, type or description", a.Key)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		key, val string
+		wantErr  bool
+	}{
+		{Enable, "true", false},
+		{Enable, "yes", true},
+		{PDBCreate, "false", false},
+		{EmergencySurgeUntil, "2026-01-01T12:00:00Z", false},
+		{EmergencySurgeUntil, "tomorrow", true},
+		{SurgeReplicas, "3", false},
+		{SurgeReplicas, "three", true},
+		{MinAvailableFloor, "2", false},
+		{MinAvailableFloor, "50%", true},
+		{OwnedBy, "anything", false},
+		{"example.com/unknown", "true", true},
+	}
+	for _, tt := range tests {
+		if err := Validate(tt.key, tt.val); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%q, %q) error = %v, wantErr %v", tt.key, tt.val, err, tt.wantErr)
+		}
+	}
+}
+
+func TestHandlerServesRegistry(t *testing.T) {
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/annotations", nil))
+
+	var got []Annotation
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if len(got) != len(All()) {
+		t.Errorf("got %d annotations, want %d", len(got), len(All()))
+	}
+}
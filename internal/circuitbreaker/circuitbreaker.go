@@ -0,0 +1,174 @@
+// Package circuitbreaker pauses the controller's writes to the API server after a
+// run of errors that signal API server pressure. During a mass drain every
+// EvictionAutoScaler retries its conflicting or timed-out updates at once, and those
+// retries add to the load that made them fail; backing off for a while lets the API
+// server recover.
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/azure/eviction-autoscaler/internal/metrics"
+)
+
+var log = logf.Log.WithName("circuit-breaker")
+
+// ErrOpen is returned, wrapped, for writes refused while the breaker is open.
+var ErrOpen = errors.New("circuit breaker open")
+
+// Breaker counts consecutive pressure errors from writes. Once its threshold is
+// reached it opens and refuses writes for its backoff. Writes then go through again: the next
+// pressure error reopens it at once, and any other outcome closes it.
+type Breaker struct {
+	threshold int
+	backoff   time.Duration
+	now       func() time.Time
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+// New returns a Breaker opening after threshold consecutive pressure errors, or nil
+// if threshold is not positive. A nil Breaker lets every write through.
+func New(threshold int, backoff time.Duration) *Breaker {
+	if threshold <= 0 {
+		return nil
+	}
+	return &Breaker{threshold: threshold, backoff: backoff, now: time.Now}
+}
+
+// Do runs write unless the breaker is open, and records its outcome.
+func (b *Breaker) Do(write func() error) error {
+	if b == nil {
+		return write()
+	}
+	if until, open := b.open(); open {
+		return fmt.Errorf("%w: writes paused until %s", ErrOpen, until.UTC().Format(time.RFC3339))
+	}
+	err := write()
+	b.record(err)
+	return err
+}
+
+func (b *Breaker) open() (time.Time, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.openUntil, b.now().Before(b.openUntil)
+}
+
+func (b *Breaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	if !pressure(err) {
+		// Any other answer shows the API server is keeping up.
+		if b.failures >= b.threshold {
+			log.Info("Closing circuit breaker, writes resumed")
+			metrics.CircuitOpenGauge.Set(0)
+		}
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openUntil = b.now().Add(b.backoff)
+		log.Info("Opening circuit breaker after consecutive API errors, pausing writes",
+			"failures", b.failures, "until", b.openUntil, "error", err.Error())
+		metrics.CircuitOpenGauge.Set(1)
+	}
+}
+
+// pressure reports whether err, which may be nil, suggests the API server is
+// overloaded: conflicts from writers racing each other, throttling, timeouts and
+// server errors.
+func pressure(err error) bool {
+	return apierrors.IsConflict(err) ||
+		apierrors.IsTooManyRequests(err) ||
+		apierrors.IsServerTimeout(err) ||
+		apierrors.IsTimeout(err) ||
+		apierrors.IsInternalError(err) ||
+		apierrors.IsServiceUnavailable(err) ||
+		apierrors.IsUnexpectedServerError(err) ||
+		errors.Is(err, context.DeadlineExceeded)
+}
+
+// Client wraps c so every write, including status and other subresource writes,
+// goes through b. Reads are left alone.
+func (b *Breaker) Client(c client.Client) client.Client {
+	if b == nil {
+		return c
+	}
+	return &breakerClient{Client: c, breaker: b}
+}
+
+type breakerClient struct {
+	client.Client
+	breaker *Breaker
+}
+
+func (c *breakerClient) Apply(ctx context.Context, obj runtime.ApplyConfiguration, opts ...client.ApplyOption) error {
+	return c.breaker.Do(func() error { return c.Client.Apply(ctx, obj, opts...) })
+}
+
+func (c *breakerClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	return c.breaker.Do(func() error { return c.Client.Create(ctx, obj, opts...) })
+}
+
+func (c *breakerClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	return c.breaker.Do(func() error { return c.Client.Delete(ctx, obj, opts...) })
+}
+
+func (c *breakerClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	return c.breaker.Do(func() error { return c.Client.Update(ctx, obj, opts...) })
+}
+
+func (c *breakerClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return c.breaker.Do(func() error { return c.Client.Patch(ctx, obj, patch, opts...) })
+}
+
+func (c *breakerClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	return c.breaker.Do(func() error { return c.Client.DeleteAllOf(ctx, obj, opts...) })
+}
+
+func (c *breakerClient) Status() client.SubResourceWriter {
+	return &subResourceWriter{SubResourceWriter: c.Client.Status(), breaker: c.breaker}
+}
+
+func (c *breakerClient) SubResource(subResource string) client.SubResourceClient {
+	sub := c.Client.SubResource(subResource)
+	return &subResourceClient{SubResourceReader: sub, subResourceWriter: subResourceWriter{SubResourceWriter: sub, breaker: c.breaker}}
+}
+
+type subResourceWriter struct {
+	client.SubResourceWriter
+	breaker *Breaker
+}
+
+func (w *subResourceWriter) Create(ctx context.Context, obj client.Object, subResource client.Object, opts ...client.SubResourceCreateOption) error {
+	return w.breaker.Do(func() error { return w.SubResourceWriter.Create(ctx, obj, subResource, opts...) })
+}
+
+func (w *subResourceWriter) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	return w.breaker.Do(func() error { return w.SubResourceWriter.Update(ctx, obj, opts...) })
+}
+
+func (w *subResourceWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+	return w.breaker.Do(func() error { return w.SubResourceWriter.Patch(ctx, obj, patch, opts...) })
+}
+
+type subResourceClient struct {
+	client.SubResourceReader
+	subResourceWriter
+}
@@ -0,0 +1,99 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestBreakerOpensAfterConsecutivePressureErrors(t *testing.T) {
+	now := time.Now()
+	b := New(2, time.Minute)
+	b.now = func() time.Time { return now }
+
+	var writeErr error
+	writes := 0
+	c := b.Client(fake.NewClientBuilder().WithObjects(
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default"}},
+	).WithInterceptorFuncs(interceptor.Funcs{
+		Update: func(context.Context, client.WithWatch, client.Object, ...client.UpdateOption) error {
+			writes++
+			return writeErr
+		},
+		SubResourceUpdate: func(context.Context, client.Client, string, client.Object, ...client.SubResourceUpdateOption) error {
+			writes++
+			return writeErr
+		},
+	}).Build())
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default"}}
+	ctx := context.Background()
+
+	writeErr = apierrors.NewConflict(schema.GroupResource{Resource: "configmaps"}, "cm", errors.New("stale"))
+	for range 2 {
+		if err := c.Update(ctx, cm); !apierrors.IsConflict(err) {
+			t.Fatalf("expected the conflict to be returned, got %v", err)
+		}
+	}
+	if err := c.Status().Update(ctx, cm); !errors.Is(err, ErrOpen) {
+		t.Fatalf("expected writes to be refused while open, got %v", err)
+	}
+	if writes != 2 {
+		t.Errorf("expected refused writes not to reach the API server, got %d writes", writes)
+	}
+
+	// Once the backoff has passed a single pressure error reopens the breaker.
+	now = now.Add(time.Minute)
+	if err := c.Update(ctx, cm); !apierrors.IsConflict(err) {
+		t.Fatalf("expected the write to go through after the backoff, got %v", err)
+	}
+	if err := c.Update(ctx, cm); !errors.Is(err, ErrOpen) {
+		t.Fatalf("expected the breaker to reopen, got %v", err)
+	}
+
+	// Any other outcome closes it.
+	now = now.Add(time.Minute)
+	writeErr = nil
+	if err := c.Update(ctx, cm); err != nil {
+		t.Fatalf("expected the write to succeed, got %v", err)
+	}
+	writeErr = apierrors.NewTooManyRequests("slow down", 1)
+	if err := c.Update(ctx, cm); !apierrors.IsTooManyRequests(err) {
+		t.Fatalf("expected a single error not to open the breaker, got %v", err)
+	}
+}
+
+func TestBreakerIgnoresOtherErrors(t *testing.T) {
+	b := New(1, time.Minute)
+	for _, err := range []error{
+		apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, "web"),
+		apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "web", errors.New("denied")),
+		context.Canceled,
+	} {
+		if got := b.Do(func() error { return err }); got != err {
+			t.Fatalf("expected %v to be returned, got %v", err, got)
+		}
+	}
+	if err := b.Do(func() error { return nil }); err != nil {
+		t.Errorf("expected the breaker to stay closed, got %v", err)
+	}
+}
+
+func TestNilBreaker(t *testing.T) {
+	b := New(0, time.Minute)
+	if b != nil {
+		t.Fatal("expected a nil breaker for threshold 0")
+	}
+	c := fake.NewClientBuilder().Build()
+	if b.Client(c) != c {
+		t.Error("expected a nil breaker to return the client unwrapped")
+	}
+}
@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ambiguousPDBs remembers, per deployment, the PDBs last reported as selecting its
+// pods together, so the AmbiguousPDB warning is recorded when the overlap starts or
+// changes rather than on every reconcile. Observations are kept in memory, like
+// replicaSettle's, so a restart reports an overlap still in place once more.
+type ambiguousPDBs struct {
+	mu   sync.Mutex
+	seen map[types.NamespacedName]string
+}
+
+// changed records the PDBs selecting the pods of the deployment named key and
+// reports whether they differ from the last ones recorded. Fewer than two PDBs
+// clear the observation.
+func (a *ambiguousPDBs) changed(key types.NamespacedName, names []string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(names) < 2 {
+		delete(a.seen, key)
+		return false
+	}
+	joined := strings.Join(names, ", ")
+	if a.seen[key] == joined {
+		return false
+	}
+	if a.seen == nil {
+		a.seen = map[types.NamespacedName]string{}
+	}
+	a.seen[key] = joined
+	return true
+}
+
+// forget drops the observation of a deleted deployment.
+func (a *ambiguousPDBs) forget(key types.NamespacedName) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.seen, key)
+}
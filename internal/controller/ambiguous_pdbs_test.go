@@ -0,0 +1,26 @@
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("Ambiguous PDB tracking", func() {
+	key := types.NamespacedName{Namespace: "default", Name: "web"}
+
+	It("should report an overlap when it starts or changes", func() {
+		var ambiguous ambiguousPDBs
+		Expect(ambiguous.changed(key, []string{"web"})).To(BeFalse())
+		Expect(ambiguous.changed(key, []string{"a", "web"})).To(BeTrue())
+		Expect(ambiguous.changed(key, []string{"a", "web"})).To(BeFalse())
+		Expect(ambiguous.changed(key, []string{"a", "b", "web"})).To(BeTrue())
+
+		// Resolved, then back: reported again.
+		Expect(ambiguous.changed(key, []string{"web"})).To(BeFalse())
+		Expect(ambiguous.changed(key, []string{"a", "web"})).To(BeTrue())
+
+		ambiguous.forget(key)
+		Expect(ambiguous.changed(key, []string{"a", "web"})).To(BeTrue())
+	})
+})
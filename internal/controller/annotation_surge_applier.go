@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// --- AnnotationSurgeApplier ---
+//
+// Records the surge as the evictionSurgeReplicas annotation on the target without
+// touching replicas. Used with surgeMode: annotation for GitOps setups where the
+// controller must not write spec fields that a sync tool owns; the tool (or a
+// policy engine) reads the annotation and applies the replica count itself.
+//
+// The annotation is written with a merge patch on metadata so it doesn't conflict
+// with a concurrent sync of the spec.
+
+type AnnotationSurgeApplier struct {
+	client client.Client
+	target Surger
+}
+
+var _ SurgeApplier = &AnnotationSurgeApplier{}
+
+func (a *AnnotationSurgeApplier) ApplySurge(ctx context.Context, surgeReplicas int32) error {
+	surgeVal := strconv.FormatInt(int64(surgeReplicas), 10)
+	if hasTargetAnnotationWithValue(a.target, surgeVal) {
+		return nil
+	}
+	patch := client.MergeFrom(a.target.Obj().DeepCopyObject().(client.Object))
+	a.target.AddAnnotation(EvictionSurgeReplicasAnnotationKey, surgeVal)
+	if err := a.client.Patch(ctx, a.target.Obj(), patch); err != nil {
+		return fmt.Errorf("annotating target with surge replicas: %w", err)
+	}
+	return nil
+}
+
+func (a *AnnotationSurgeApplier) RevertSurge(ctx context.Context, _ int32) error {
+	if !hasTargetAnnotation(a.target) {
+		return nil
+	}
+	patch := client.MergeFrom(a.target.Obj().DeepCopyObject().(client.Object))
+	a.target.RemoveAnnotation(EvictionSurgeReplicasAnnotationKey)
+	if err := a.client.Patch(ctx, a.target.Obj(), patch); err != nil {
+		return fmt.Errorf("removing surge annotation: %w", err)
+	}
+	return nil
+}
+
+func (a *AnnotationSurgeApplier) Name() string {
+	return "annotation"
+}
+
+func (a *AnnotationSurgeApplier) IsSurgeActive() bool {
+	return hasTargetAnnotation(a.target)
+}
@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("AnnotationSurgeApplier", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+		dep    *appsv1.Deployment
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		dep = &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "gitops", Namespace: "default"},
+			Spec:       appsv1.DeploymentSpec{Replicas: ptr.To(int32(2))},
+		}
+	})
+
+	It("should record the surge without changing replicas and clear it on revert", func() {
+		fc := fake.NewClientBuilder().WithScheme(scheme).WithObjects(dep).Build()
+		applier := &AnnotationSurgeApplier{client: fc, target: &DeploymentWrapper{obj: dep}}
+
+		Expect(applier.ApplySurge(ctx, 4)).To(Succeed())
+		Expect(applier.IsSurgeActive()).To(BeTrue())
+		var got appsv1.Deployment
+		Expect(fc.Get(ctx, client.ObjectKeyFromObject(dep), &got)).To(Succeed())
+		Expect(got.Annotations).To(HaveKeyWithValue(EvictionSurgeReplicasAnnotationKey, "4"))
+		Expect(*got.Spec.Replicas).To(Equal(int32(2)))
+
+		Expect(applier.RevertSurge(ctx, 2)).To(Succeed())
+		Expect(applier.IsSurgeActive()).To(BeFalse())
+		Expect(fc.Get(ctx, client.ObjectKeyFromObject(dep), &got)).To(Succeed())
+		Expect(got.Annotations).ToNot(HaveKey(EvictionSurgeReplicasAnnotationKey))
+	})
+})
+
+var _ = Describe("surgeApplierFor", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+		dep    *appsv1.Deployment
+		target Surger
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		Expect(autoscalingv2.AddToScheme(scheme)).To(Succeed())
+		Expect(kedav1alpha1.AddToScheme(scheme)).To(Succeed())
+		dep = &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"}}
+		target = &DeploymentWrapper{obj: dep}
+	})
+
+	DescribeTable("should select the applier for the mode",
+		func(mode string, want SurgeApplier) {
+			fc := fake.NewClientBuilder().WithScheme(scheme).WithObjects(dep).Build()
+			applier, err := surgeApplierFor(ctx, fc, mode, "default", "app", ResourceTypeDeployment, target)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(applier).To(BeAssignableToTypeOf(want))
+		},
+		Entry("default", "", &ScaleSurgeApplier{}),
+		Entry("auto", SurgeModeAuto, &ScaleSurgeApplier{}),
+		Entry("direct", SurgeModeDirect, &DeploymentSurgeApplier{}),
+		Entry("scale", SurgeModeScale, &ScaleSurgeApplier{}),
+		Entry("annotation", SurgeModeAnnotation, &AnnotationSurgeApplier{}),
+	)
+
+	It("should use the HPA in hpa mode", func() {
+		hpa := &autoscalingv2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: ResourceTypeDeployment, Name: "app"},
+				MaxReplicas:    5,
+			},
+		}
+		fc := fake.NewClientBuilder().WithScheme(scheme).WithObjects(dep, hpa).Build()
+		applier, err := surgeApplierFor(ctx, fc, SurgeModeHPA, "default", "app", ResourceTypeDeployment, target)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(applier).To(BeAssignableToTypeOf(&HPASurgeApplier{}))
+	})
+
+	It("should use the HPA scaling a rollout", func() {
+		hpa := &autoscalingv2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{APIVersion: rolloutGVK.GroupVersion().String(), Kind: rolloutGVK.Kind, Name: "test-rollout"},
+				MaxReplicas:    5,
+			},
+		}
+		fc := fake.NewClientBuilder().WithScheme(scheme).WithObjects(hpa).Build()
+		applier, err := surgeApplierFor(ctx, fc, SurgeModeAuto, "default", "test-rollout", string(rolloutKind), &RolloutWrapper{obj: newRollout(2, nil)})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(applier).To(BeAssignableToTypeOf(&HPASurgeApplier{}))
+	})
+
+	It("should not take a deployment's HPA for a rollout of the same name", func() {
+		hpa := &autoscalingv2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: ResourceTypeDeployment, Name: "test-rollout"},
+				MaxReplicas:    5,
+			},
+		}
+		fc := fake.NewClientBuilder().WithScheme(scheme).WithObjects(hpa).Build()
+		applier, err := surgeApplierFor(ctx, fc, SurgeModeAuto, "default", "test-rollout", string(rolloutKind), &RolloutWrapper{obj: newRollout(2, nil)})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(applier).To(BeAssignableToTypeOf(&ScaleSurgeApplier{}))
+	})
+
+	It("should reject hpa mode without an HPA", func() {
+		fc := fake.NewClientBuilder().WithScheme(scheme).WithObjects(dep).Build()
+		_, err := surgeApplierFor(ctx, fc, SurgeModeHPA, "default", "app", ResourceTypeDeployment, target)
+		Expect(errors.Is(err, errInvalidSurgeMode)).To(BeTrue())
+	})
+
+	It("should reject an unknown mode", func() {
+		fc := fake.NewClientBuilder().WithScheme(scheme).WithObjects(dep).Build()
+		_, err := surgeApplierFor(ctx, fc, "sideways", "default", "app", ResourceTypeDeployment, target)
+		Expect(errors.Is(err, errInvalidSurgeMode)).To(BeTrue())
+	})
+})
@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/azure/eviction-autoscaler/internal/annotations"
+)
+
+// FieldManager is the field manager the controller server-side applies its PDB and
+// EvictionAutoScaler writes as.
+const FieldManager = "eviction-autoscaler"
+
+// applyOwned server-side applies obj, which carries its TypeMeta and only the fields
+// the controller manages: a field it applied before and leaves out now is removed.
+// The apply sends no resourceVersion, so writers of other fields, such as a GitOps
+// controller syncing the object, no longer make it fail with a conflict. When
+// another field manager owns a field obj sets to a different value, the conflicting
+// fields are logged and reported as a FieldConflict event before the apply is
+// forced: the controller's value still wins, as it did with updates, but the fight
+// over the field is visible.
+func applyOwned(ctx context.Context, c client.Client, recorder record.EventRecorder, obj client.Object) error {
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return err
+	}
+	delete(u, "status")
+	dropNulls(u)
+	config := client.ApplyConfigurationFromUnstructured(&unstructured.Unstructured{Object: u})
+
+	err = c.Apply(ctx, config, client.FieldOwner(FieldManager))
+	conflicts := applyConflicts(err)
+	if len(conflicts) == 0 {
+		return err
+	}
+	kind := obj.GetObjectKind().GroupVersionKind().Kind
+	log.FromContext(ctx).Info("Fields managed by another field manager, taking them over",
+		"kind", kind, "namespace", obj.GetNamespace(), "name", obj.GetName(), "conflicts", conflicts)
+	if recorder != nil {
+		recorder.Eventf(obj, corev1.EventTypeWarning, "FieldConflict",
+			"%s fields also managed elsewhere were overwritten: %s", kind, strings.Join(conflicts, "; "))
+	}
+	if err := c.Apply(ctx, config, client.FieldOwner(FieldManager), client.ForceOwnership); err != nil {
+		return fmt.Errorf("forcing apply over conflicts %v: %w", conflicts, err)
+	}
+	return nil
+}
+
+// applyConflicts returns the fields a server-side apply conflicted on, each with the
+// manager it conflicted with, or nil if err is not such a conflict.
+func applyConflicts(err error) []string {
+	var status apierrors.APIStatus
+	if !apierrors.IsConflict(err) || !errors.As(err, &status) || status.Status().Details == nil {
+		return nil
+	}
+	var conflicts []string
+	for _, cause := range status.Status().Details.Causes {
+		if cause.Type == metav1.CauseTypeFieldManagerConflict {
+			conflicts = append(conflicts, fmt.Sprintf("%s (%s)", cause.Field, cause.Message))
+		}
+	}
+	return conflicts
+}
+
+// dropNulls removes the null values the unstructured converter leaves for unset
+// fields, like a zero creationTimestamp, so the apply doesn't claim them.
+func dropNulls(m map[string]interface{}) {
+	for k, v := range m {
+		switch v := v.(type) {
+		case nil:
+			delete(m, k)
+		case map[string]interface{}:
+			dropNulls(v)
+		}
+	}
+}
+
+// managedPDB returns the fields of pdb the controller applies: its annotations, its
+// owner reference to the Deployment and minAvailable, plus the selector and ownedBy
+// annotation of a PDB it created. A PDB it adopted keeps those with their owner, so
+// the owner can still drop them, or hand the PDB back, through its own applies.
+func managedPDB(pdb *policyv1.PodDisruptionBudget) *policyv1.PodDisruptionBudget {
+	applied := &policyv1.PodDisruptionBudget{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "PodDisruptionBudget",
+			APIVersion: "policy/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pdb.Name,
+			Namespace: pdb.Namespace,
+			UID:       pdb.UID,
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{MinAvailable: pdb.Spec.MinAvailable},
+	}
+	_, created := pdb.Annotations[annotations.Target]
+	keys := []string{annotations.Target, MinAvailableFloorAnnotationKey, annotations.Owner}
+	if created {
+		keys = append(keys, PDBOwnedByAnnotationKey)
+		applied.Spec.Selector = pdb.Spec.Selector
+	}
+	for _, key := range keys {
+		if val, ok := pdb.Annotations[key]; ok {
+			metav1.SetMetaDataAnnotation(&applied.ObjectMeta, key, val)
+		}
+	}
+	for _, ref := range pdb.OwnerReferences {
+		if ref.Kind == ResourceTypeDeployment {
+			applied.OwnerReferences = append(applied.OwnerReferences, ref)
+		}
+	}
+	return applied
+}
@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"context"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("server-side apply", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+		key    = types.NamespacedName{Namespace: "default", Name: "web"}
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(v1.AddToScheme(scheme)).To(Succeed())
+		Expect(policyv1.AddToScheme(scheme)).To(Succeed())
+		Expect(autoscalingv2.AddToScheme(scheme)).To(Succeed())
+		Expect(kedav1alpha1.AddToScheme(scheme)).To(Succeed())
+	})
+
+	deployment := func() *v1.Deployment {
+		return &v1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", UID: "web-uid"},
+			Spec: v1.DeploymentSpec{
+				Replicas: ptr.To[int32](3),
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			},
+		}
+	}
+
+	It("should create the PDB as the field manager and keep its fields on later applies", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithReturnManagedFields().Build()
+		created, err := NewPDBForDeployment(ctx, c, deployment())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(applyOwned(ctx, c, nil, created)).To(Succeed())
+
+		var pdb policyv1.PodDisruptionBudget
+		Expect(c.Get(ctx, key, &pdb)).To(Succeed())
+		Expect(pdb.ManagedFields).To(ContainElement(And(
+			HaveField("Manager", FieldManager), HaveField("Operation", metav1.ManagedFieldsOperationApply))))
+
+		pdb.Spec.MinAvailable = &intstr.IntOrString{IntVal: 5}
+		Expect(applyOwned(ctx, c, nil, managedPDB(&pdb))).To(Succeed())
+		Expect(c.Get(ctx, key, &pdb)).To(Succeed())
+		Expect(pdb.Spec.MinAvailable.IntValue()).To(Equal(5))
+		Expect(pdb.Spec.Selector.MatchLabels).To(HaveKeyWithValue("app", "web"))
+		Expect(pdb.Annotations).To(HaveKeyWithValue(PDBOwnedByAnnotationKey, ControllerName))
+		Expect(pdb.OwnerReferences).To(HaveLen(1))
+	})
+
+	It("should report fields another manager owns and take them over", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+		pdb, err := NewPDBForDeployment(ctx, c, deployment())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(c.Patch(ctx, pdb, client.Apply, client.FieldOwner("gitops"))).To(Succeed())
+
+		recorder := record.NewFakeRecorder(1)
+		pdb.Spec.MinAvailable = &intstr.IntOrString{IntVal: 4}
+		Expect(applyOwned(ctx, c, recorder, managedPDB(pdb))).To(Succeed())
+		Expect(recorder.Events).To(Receive(And(ContainSubstring("FieldConflict"), ContainSubstring("minAvailable"))))
+
+		Expect(c.Get(ctx, key, pdb)).To(Succeed())
+		Expect(pdb.Spec.MinAvailable.IntValue()).To(Equal(4))
+	})
+
+	It("should leave the selector and ownedBy annotation of an adopted PDB with its owner", func() {
+		pdb := &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default",
+				Annotations: map[string]string{PDBOwnedByAnnotationKey: ControllerName, MinAvailableFloorAnnotationKey: "2"}},
+			Spec: policyv1.PodDisruptionBudgetSpec{
+				MinAvailable: &intstr.IntOrString{IntVal: 2},
+				Selector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			},
+		}
+		applied := managedPDB(pdb)
+		Expect(applied.Spec.Selector).To(BeNil())
+		Expect(applied.Annotations).To(Equal(map[string]string{MinAvailableFloorAnnotationKey: "2"}))
+		Expect(applied.Spec.MinAvailable.IntValue()).To(Equal(2))
+	})
+})
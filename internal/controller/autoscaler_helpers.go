@@ -10,6 +10,8 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
 )
 
 var errNotFound = errors.New("not found")
@@ -153,3 +155,28 @@ func ResolveMinReplicas(ctx context.Context, c client.Client, namespace, targetN
 	// 3. Fall back to deployment replicas
 	return deployReplicas, false, nil
 }
+
+// kedaPausedReplicasAnnotation pins a ScaledObject's target at the given replica
+// count and pauses autoscaling until it is removed.
+const kedaPausedReplicasAnnotation = "autoscaling.keda.sh/paused-replicas"
+
+// targetDormant reports whether the workload is parked at zero replicas by its owner:
+// either spec.replicas is already 0 or a KEDA ScaledObject is paused at zero and will
+// drive it there. A PDB can't block anything with no pods behind it, so there is
+// nothing to surge for until the workload scales back up.
+func targetDormant(ctx context.Context, c client.Client, namespace, targetName string, targetKind myappsv1.TargetKind, target Surger) (bool, error) {
+	if target.GetReplicas() == 0 {
+		return true, nil
+	}
+	if targetKind != deploymentKind {
+		return false, nil
+	}
+	scaledObj, err := findScaledObjectForTarget(ctx, c, namespace, targetName, ResourceTypeDeployment)
+	if errors.Is(err, errNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return scaledObj.Annotations[kedaPausedReplicasAnnotation] == "0", nil
+}
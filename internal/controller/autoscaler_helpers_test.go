@@ -163,4 +163,75 @@ var _ = Describe("calculateSurge", func() {
 		Expect(errors.Is(err, errMaxSurgeZero)).To(BeTrue())
 		Expect(result).To(Equal(int32(3)))
 	})
+
+	It("tells single-replica targets that can't surge apart", func() {
+		single := func(surge intstr.IntOrString, replicas int32) Surger {
+			target := makeTarget(surge).(*DeploymentWrapper)
+			target.obj.Spec.Replicas = ptr.To(replicas)
+			return target
+		}
+		Expect(singleReplicaWithoutSurge(ctx, single(intstr.FromInt32(0), 1))).To(BeTrue())
+		Expect(singleReplicaWithoutSurge(ctx, single(intstr.FromString("0%"), 1))).To(BeTrue())
+		Expect(singleReplicaWithoutSurge(ctx, single(intstr.FromInt32(1), 1))).To(BeFalse())
+		Expect(singleReplicaWithoutSurge(ctx, single(intstr.FromInt32(0), 2))).To(BeFalse())
+	})
+})
+
+var _ = Describe("targetDormant", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		Expect(kedav1alpha1.AddToScheme(scheme)).To(Succeed())
+	})
+
+	deployment := func(replicas int32) *DeploymentWrapper {
+		return &DeploymentWrapper{obj: &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-deploy", Namespace: "default"},
+			Spec:       appsv1.DeploymentSpec{Replicas: ptr.To(replicas)},
+		}}
+	}
+	scaledObject := func(annotations map[string]string) *kedav1alpha1.ScaledObject {
+		return &kedav1alpha1.ScaledObject{
+			ObjectMeta: metav1.ObjectMeta{Name: "so", Namespace: "default", Annotations: annotations},
+			Spec: kedav1alpha1.ScaledObjectSpec{
+				ScaleTargetRef: &kedav1alpha1.ScaleTarget{Name: "my-deploy", Kind: "Deployment"},
+			},
+		}
+	}
+
+	It("should treat a deployment at zero replicas as dormant", func() {
+		fc := fake.NewClientBuilder().WithScheme(scheme).Build()
+		dormant, err := targetDormant(ctx, fc, "default", "my-deploy", deploymentKind, deployment(0))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(dormant).To(BeTrue())
+	})
+
+	It("should not treat a running deployment without KEDA as dormant", func() {
+		fc := fake.NewClientBuilder().WithScheme(scheme).Build()
+		dormant, err := targetDormant(ctx, fc, "default", "my-deploy", deploymentKind, deployment(3))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(dormant).To(BeFalse())
+	})
+
+	It("should treat a ScaledObject paused at zero as dormant before KEDA scales it", func() {
+		so := scaledObject(map[string]string{kedaPausedReplicasAnnotation: "0"})
+		fc := fake.NewClientBuilder().WithScheme(scheme).WithObjects(so).Build()
+		dormant, err := targetDormant(ctx, fc, "default", "my-deploy", deploymentKind, deployment(3))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(dormant).To(BeTrue())
+	})
+
+	It("should not treat a ScaledObject paused above zero as dormant", func() {
+		so := scaledObject(map[string]string{kedaPausedReplicasAnnotation: "2"})
+		fc := fake.NewClientBuilder().WithScheme(scheme).WithObjects(so).Build()
+		dormant, err := targetDormant(ctx, fc, "default", "my-deploy", deploymentKind, deployment(3))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(dormant).To(BeFalse())
+	})
 })
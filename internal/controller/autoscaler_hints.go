@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"context"
+	"slices"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/azure/eviction-autoscaler/internal/annotations"
+	"github.com/azure/eviction-autoscaler/internal/podutil"
+)
+
+// AutoscalerHintsAnnotationKey is set on a deployment while a surge is active so the
+// pod placement webhook pins its new pods' nodes too. On a pod it lists the node
+// autoscaler annotations the controller added.
+const AutoscalerHintsAnnotationKey = annotations.AutoscalerHints
+
+// autoscalerHintApplier wraps the surge applier of a deployment and keeps node
+// autoscalers from taking capacity away mid-drain. While the surge is active the
+// deployment's pods off draining nodes are annotated as not safe to evict, so the
+// Cluster Autoscaler doesn't scale their nodes down and Karpenter doesn't consolidate
+// them, while it brings up capacity for the Pending surge pods. The pod placement
+// webhook does the same for surge pods as they are created.
+type autoscalerHintApplier struct {
+	SurgeApplier
+	reader      client.Reader
+	writer      client.Client
+	target      Surger
+	drainTaints []string
+}
+
+var _ SurgeApplier = &autoscalerHintApplier{}
+
+// ApplySurge surges the target and pins the nodes of its pods. The surge itself is
+// what unblocks the drain, so failing to pin is logged rather than returned.
+func (p *autoscalerHintApplier) ApplySurge(ctx context.Context, surgeReplicas int32) error {
+	if err := p.SurgeApplier.ApplySurge(ctx, surgeReplicas); err != nil {
+		return err
+	}
+	if err := p.pinSurvivors(ctx); err != nil {
+		log.FromContext(ctx).Error(err, "failed to record node autoscaler hints", "target", p.target.Obj().GetName())
+	}
+	return nil
+}
+
+// RevertSurge reverts the surge and unpins the deployment's pods.
+func (p *autoscalerHintApplier) RevertSurge(ctx context.Context, originalMinReplicas int32) error {
+	if err := p.SurgeApplier.RevertSurge(ctx, originalMinReplicas); err != nil {
+		return err
+	}
+	return clearAutoscalerHints(ctx, p.writer, p.target)
+}
+
+// pinSurvivors marks the deployment for the webhook and pins its pods that stay on:
+// those not terminating and not on a draining node, which a drain will evict anyway.
+func (p *autoscalerHintApplier) pinSurvivors(ctx context.Context) error {
+	if p.target.Obj().GetAnnotations()[AutoscalerHintsAnnotationKey] != "true" {
+		value := "true"
+		if err := patchAnnotation(ctx, p.writer, p.target, AutoscalerHintsAnnotationKey, &value); err != nil {
+			return err
+		}
+	}
+	draining, err := drainingNodes(ctx, p.reader, p.drainTaints)
+	if err != nil {
+		return err
+	}
+	pods, err := deploymentPods(ctx, p.reader, p.target)
+	if err != nil {
+		return err
+	}
+	for i := range pods {
+		pod := &pods[i]
+		if pod.DeletionTimestamp != nil || slices.Contains(draining, pod.Spec.NodeName) {
+			continue
+		}
+		if err := patchPod(ctx, p.writer, pod, podutil.PinNode); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// clearAutoscalerHints unmarks the deployment, then unpins every pod the controller
+// or the webhook pinned. The deployment goes first so no new pod is pinned after.
+func clearAutoscalerHints(ctx context.Context, c client.Client, target Surger) error {
+	if _, ok := target.Obj().GetAnnotations()[AutoscalerHintsAnnotationKey]; !ok {
+		return nil
+	}
+	if err := patchAnnotation(ctx, c, target, AutoscalerHintsAnnotationKey, nil); err != nil {
+		return err
+	}
+	pods, err := deploymentPods(ctx, c, target)
+	if err != nil {
+		return err
+	}
+	for i := range pods {
+		if err := patchPod(ctx, c, &pods[i], podutil.UnpinNode); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deploymentPods lists the pods selected by target, a deployment.
+func deploymentPods(ctx context.Context, c client.Reader, target Surger) ([]corev1.Pod, error) {
+	deployment, ok := target.Obj().(*appsv1.Deployment)
+	if !ok || deployment.Spec.Selector == nil {
+		return nil, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+	var pods corev1.PodList
+	if err := c.List(ctx, &pods, client.InNamespace(deployment.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
+	}
+	return pods.Items, nil
+}
+
+// patchPod applies change to a copy of pod and patches the difference, if any.
+func patchPod(ctx context.Context, c client.Client, pod *corev1.Pod, change func(*corev1.Pod) bool) error {
+	changed := pod.DeepCopy()
+	if !change(changed) {
+		return nil
+	}
+	return c.Patch(ctx, changed, client.MergeFrom(pod))
+}
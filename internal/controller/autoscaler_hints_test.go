@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/azure/eviction-autoscaler/internal/annotations"
+)
+
+var _ = Describe("node autoscaler hints", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+	})
+
+	pod := func(name, node string, podAnnotations map[string]string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": "app"}, Annotations: podAnnotations},
+			Spec:       corev1.PodSpec{NodeName: node},
+		}
+	}
+
+	It("should pin surviving pods on surge and unpin them on revert", func() {
+		dep := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: ptr.To[int32](2),
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "app"}},
+			},
+		}
+		fc := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "ready"}},
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "cordoned"}, Spec: corev1.NodeSpec{Unschedulable: true}},
+			dep,
+			pod("survivor", "ready", nil),
+			pod("opted-in", "ready", map[string]string{annotations.SafeToEvict: "true"}),
+			pod("evicted", "cordoned", nil),
+		).Build()
+		wrap := func() *autoscalerHintApplier {
+			var current appsv1.Deployment
+			Expect(fc.Get(ctx, client.ObjectKeyFromObject(dep), &current)).To(Succeed())
+			target := &DeploymentWrapper{obj: &current}
+			return &autoscalerHintApplier{
+				SurgeApplier: &DeploymentSurgeApplier{client: fc, target: target},
+				reader:       fc,
+				writer:       fc,
+				target:       target,
+				drainTaints:  DefaultDrainTaintKeys,
+			}
+		}
+		podAnnotations := func(name string) map[string]string {
+			var got corev1.Pod
+			Expect(fc.Get(ctx, client.ObjectKey{Namespace: "default", Name: name}, &got)).To(Succeed())
+			return got.Annotations
+		}
+
+		Expect(wrap().ApplySurge(ctx, 3)).To(Succeed())
+		var updated appsv1.Deployment
+		Expect(fc.Get(ctx, client.ObjectKeyFromObject(dep), &updated)).To(Succeed())
+		Expect(updated.Annotations).To(HaveKeyWithValue(AutoscalerHintsAnnotationKey, "true"))
+		Expect(podAnnotations("survivor")).To(And(
+			HaveKeyWithValue(annotations.SafeToEvict, "false"),
+			HaveKeyWithValue(annotations.DoNotDisrupt, "true"),
+		))
+		Expect(podAnnotations("opted-in")).To(HaveKeyWithValue(annotations.SafeToEvict, "true"))
+		Expect(podAnnotations("evicted")).To(BeEmpty())
+
+		Expect(wrap().RevertSurge(ctx, 2)).To(Succeed())
+		Expect(fc.Get(ctx, client.ObjectKeyFromObject(dep), &updated)).To(Succeed())
+		Expect(updated.Annotations).ToNot(HaveKey(AutoscalerHintsAnnotationKey))
+		Expect(podAnnotations("survivor")).To(BeEmpty())
+		Expect(podAnnotations("opted-in")).To(Equal(map[string]string{annotations.SafeToEvict: "true"}))
+	})
+})
@@ -17,6 +17,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
@@ -112,6 +113,7 @@ func (r *AutoscalerToPDBReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 			"deployment", deploymentName)
 		return reconcile.Result{}, nil
 	}
+	minAvailable = max(minAvailable, minAvailableFloor(pdb))
 
 	// Idempotency: skip the API write if the PDB already has the correct value.
 	// This avoids unnecessary updates and the resulting watch events.
@@ -120,14 +122,14 @@ func (r *AutoscalerToPDBReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 	}
 
 	pdb.Spec.MinAvailable = &intstr.IntOrString{IntVal: minAvailable}
-	if err := r.Update(ctx, pdb); err != nil {
+	if err := applyOwned(ctx, r.Client, nil, managedPDB(pdb)); err != nil {
 		logger.Error(err, "unable to update PDB minAvailable from autoscaler",
 			"pdb", pdb.Name, "minAvailable", minAvailable)
 		return reconcile.Result{}, err
 	}
 
-	logger.Info("Updated PDB minAvailable from autoscaler floor",
-		"pdb", pdb.Name, "minAvailable", minAvailable)
+	logDecision(ctx, decisionMinAvailableUpdated, pdb.Namespace+"/"+pdb.Name, "updated PDB minAvailable from autoscaler floor",
+		"minAvailable", minAvailable)
 	return reconcile.Result{}, nil
 }
 
@@ -197,6 +199,8 @@ func (r *AutoscalerToPDBReconciler) isSurgeActiveOnAutoscaler(ctx context.Contex
 func (r *AutoscalerToPDBReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	builder := ctrl.NewControllerManagedBy(mgr).
 		Named("autoscaler-to-pdb").
+		WithOptions(controllerOptions("autoscaler-to-pdb")).
+		WithEventFilter(shardPredicate(r.Filter)).
 		Watches(&autoscalingv2.HorizontalPodAutoscaler{},
 			&handler.EnqueueRequestForObject{})
 
@@ -204,14 +208,18 @@ func (r *AutoscalerToPDBReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	// CRD discovery happens once at startup. If KEDA is installed after the controller
 	// starts, a restart is required to begin watching ScaledObjects.
 	if err := r.discoverScaledObjectCRD(mgr); err == nil {
+		// Raw sources don't get the builder's event filters, so shard them here.
 		builder = builder.WatchesRawSource(
 			source.Kind(mgr.GetCache(), &kedav1alpha1.ScaledObject{},
-				&handler.TypedEnqueueRequestForObject[*kedav1alpha1.ScaledObject]{}))
+				&handler.TypedEnqueueRequestForObject[*kedav1alpha1.ScaledObject]{},
+				predicate.NewTypedPredicateFuncs(func(so *kedav1alpha1.ScaledObject) bool {
+					return inShard(r.Filter, so.Namespace)
+				})))
 	} else {
 		mgr.GetLogger().Info("KEDA ScaledObject CRD not found, skipping ScaledObject watch")
 	}
 
-	return builder.Complete(r)
+	return builder.Complete(traced("autoscaler-to-pdb", r))
 }
 
 // discoverScaledObjectCRD checks if the KEDA ScaledObject CRD is available.
@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+)
+
+// ControllerNames are the names of the controllers in this package, as they appear
+// in controller-runtime metrics and logs.
+var ControllerNames = []string{
+	"evictionautoscaler",
+	"deployment",
+	"job",
+	"autoscaler-to-pdb",
+	"poddisruptionbudget",
+	"node",
+	"preemption-notice",
+	"pdb-disruptions",
+	"eviction-poller",
+	"surge-orphans",
+	"surge-batch",
+	"surge-scheduling",
+	"namespacestatus",
+	"namespace",
+}
+
+// concurrency holds per-controller MaxConcurrentReconciles overrides. Controllers
+// without one use the manager-wide default.
+var concurrency = map[string]int{}
+
+// SetConcurrency parses a comma-separated list of name=workers overrides, e.g.
+// "evictionautoscaler=8,node=2", and applies it to controllers set up afterwards.
+func SetConcurrency(value string) error {
+	overrides := map[string]int{}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, workers, ok := strings.Cut(item, "=")
+		if !ok {
+			return fmt.Errorf("controller concurrency %q is not name=workers", item)
+		}
+		name = strings.TrimSpace(name)
+		if !slices.Contains(ControllerNames, name) {
+			return fmt.Errorf("unknown controller %q, expected one of %s", name, strings.Join(ControllerNames, ", "))
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(workers))
+		if err != nil || n < 1 {
+			return fmt.Errorf("controller concurrency for %s must be a positive integer, got %q", name, workers)
+		}
+		overrides[name] = n
+	}
+	concurrency = overrides
+	return nil
+}
+
+// controllerOptions returns the options for the named controller. A zero
+// MaxConcurrentReconciles falls back to the manager-wide default.
+func controllerOptions(name string) controller.Options {
+	return controller.Options{MaxConcurrentReconciles: concurrency[name]}
+}
@@ -0,0 +1,27 @@
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SetConcurrency", func() {
+	AfterEach(func() {
+		Expect(SetConcurrency("")).To(Succeed())
+	})
+
+	It("should override only the named controllers", func() {
+		Expect(SetConcurrency(" evictionautoscaler=8, node = 2 ,")).To(Succeed())
+		Expect(controllerOptions("evictionautoscaler").MaxConcurrentReconciles).To(Equal(8))
+		Expect(controllerOptions("node").MaxConcurrentReconciles).To(Equal(2))
+		Expect(controllerOptions("surge-batch").MaxConcurrentReconciles).To(BeZero())
+	})
+
+	It("should reject unknown controllers and invalid worker counts", func() {
+		Expect(SetConcurrency("evictionautoscaler=8")).To(Succeed())
+		for _, value := range []string{"nodes=2", "node", "node=0", "node=two"} {
+			Expect(SetConcurrency(value)).ToNot(Succeed(), value)
+		}
+		Expect(controllerOptions("evictionautoscaler").MaxConcurrentReconciles).To(Equal(8))
+	})
+})
@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/metrics"
+)
+
+// Condition types kept on every EvictionAutoScaler. Each is always present with an
+// explicit True or False status, so readers don't have to infer state from absence.
+const (
+	// ReadyCondition is True when the last reconcile handled the EvictionAutoScaler.
+	ReadyCondition = "Ready"
+	// DegradedCondition is True when the EvictionAutoScaler can't act on evictions
+	// until its configuration is fixed.
+	DegradedCondition = "Degraded"
+	// SurgeActiveCondition is True while the target is held above its floor.
+	SurgeActiveCondition = "SurgeActive"
+	// CapacityBlockedCondition is True while pods created by the surge are still
+	// Pending, usually because the cluster has no room for them.
+	CapacityBlockedCondition = "CapacityBlocked"
+	// SurgeSuppressedCondition is True while new surges are suppressed after
+	// repeated aborted drains.
+	SurgeSuppressedCondition = "SurgeSuppressed"
+	// SurgeUnlikelyToHelpCondition is True while the target's newest pods are the
+	// failing ones, so surge pods would fail too and surges are skipped.
+	SurgeUnlikelyToHelpCondition = "SurgeUnlikelyToHelp"
+	// SurgeDeferredCondition is True while new surges wait for cluster headroom to
+	// recover above --headroom-threshold.
+	SurgeDeferredCondition = "SurgeDeferred"
+	// QuotaExceededCondition is True when the last surge was skipped because it
+	// would exceed a ResourceQuota in the namespace.
+	QuotaExceededCondition = "QuotaExceeded"
+	// SurgeInfeasibleTopologyCondition is True when the last surge was limited or
+	// skipped because the target's topology constraints leave no room for its pods.
+	SurgeInfeasibleTopologyCondition = "SurgeInfeasibleTopology"
+	// SurgeStuckPendingCondition is True when a pod created by the surge has been
+	// Pending for longer than --surge-pending-deadline.
+	SurgeStuckPendingCondition = "SurgeStuckPending"
+)
+
+// setCondition sets a condition on eas, stamped with the generation it was computed
+// from. LastTransitionTime only moves when the status flips.
+func setCondition(eas *myappsv1.EvictionAutoScaler, conditionType string, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&eas.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: eas.Generation,
+	})
+}
+
+// ready marks eas as handled and clears Degraded.
+func ready(eas *myappsv1.EvictionAutoScaler, reason string, message string) {
+	setCondition(eas, ReadyCondition, metav1.ConditionTrue, reason, message)
+	setCondition(eas, DegradedCondition, metav1.ConditionFalse, reason, message)
+	setSurgeConditions(eas)
+}
+
+// degraded marks eas as unable to act and no longer Ready.
+func degraded(eas *myappsv1.EvictionAutoScaler, reason string, message string) {
+	setCondition(eas, DegradedCondition, metav1.ConditionTrue, reason, message)
+	setCondition(eas, ReadyCondition, metav1.ConditionFalse, reason, message)
+	setSurgeConditions(eas)
+}
+
+// setSurgeConditions mirrors the surge and suppression fields of the status into
+// their conditions.
+func setSurgeConditions(eas *myappsv1.EvictionAutoScaler) {
+	if eas.Status.SurgeActive {
+		setCondition(eas, SurgeActiveCondition, metav1.ConditionTrue, "Surging",
+			fmt.Sprintf("target held at %d replicas, above its floor of %d", eas.Status.SurgeReplicas, scaleDownFloor(eas)))
+	} else {
+		setCondition(eas, SurgeActiveCondition, metav1.ConditionFalse, "NoSurge", "target is at its floor")
+	}
+	if eas.Status.SuppressedUntil != nil {
+		setCondition(eas, SurgeSuppressedCondition, metav1.ConditionTrue, "RepeatedAbortedDrains",
+			"surges suppressed until "+eas.Status.SuppressedUntil.UTC().Format(time.RFC3339)+" after repeated aborted drains")
+	} else {
+		setCondition(eas, SurgeSuppressedCondition, metav1.ConditionFalse, "NotSuppressed", "surges are allowed")
+	}
+}
+
+// setCapacityBlocked records whether pods created by the active surge are still
+// Pending.
+func setCapacityBlocked(eas *myappsv1.EvictionAutoScaler, pending int) {
+	switch {
+	case !eas.Status.SurgeActive:
+		setCondition(eas, CapacityBlockedCondition, metav1.ConditionFalse, "NoSurge", "no surge pods to schedule")
+	case pending > 0:
+		setCondition(eas, CapacityBlockedCondition, metav1.ConditionTrue, "SurgePodsPending",
+			fmt.Sprintf("%d surge pods are pending", pending))
+	default:
+		setCondition(eas, CapacityBlockedCondition, metav1.ConditionFalse, "SurgePodsScheduled", "all surge pods are scheduled")
+	}
+}
+
+// setSurgeUnlikelyToHelp records the failure pattern of the target's pods, as
+// classified by metrics.FailurePattern.
+func setSurgeUnlikelyToHelp(eas *myappsv1.EvictionAutoScaler, pattern string) {
+	switch pattern {
+	case metrics.NewestFailurePattern:
+		setCondition(eas, SurgeUnlikelyToHelpCondition, metav1.ConditionTrue, "NewestPodsFailing",
+			"the newest pods are failing, surge pods from the same template would fail too")
+	case metrics.NoFailurePattern:
+		setCondition(eas, SurgeUnlikelyToHelpCondition, metav1.ConditionFalse, "NoFailingPods", "no pods are failing")
+	default:
+		setCondition(eas, SurgeUnlikelyToHelpCondition, metav1.ConditionFalse, "OlderPodsFailing",
+			"the "+pattern+" pods are failing, new pods are expected to run")
+	}
+}
+
+// setQuotaExceeded records whether the last surge was skipped for a ResourceQuota,
+// shortfall being why, or "" if it fit.
+func setQuotaExceeded(eas *myappsv1.EvictionAutoScaler, shortfall string) {
+	if shortfall != "" {
+		setCondition(eas, QuotaExceededCondition, metav1.ConditionTrue, "SurgeOverQuota", shortfall)
+	} else {
+		setCondition(eas, QuotaExceededCondition, metav1.ConditionFalse, "WithinQuota", "no surge was held back by a resource quota")
+	}
+}
+
+// setTopologyInfeasible records whether the last surge was held back by topology
+// constraints, infeasible being why, or "" if all of it could be placed.
+func setTopologyInfeasible(eas *myappsv1.EvictionAutoScaler, infeasible string) {
+	if infeasible != "" {
+		setCondition(eas, SurgeInfeasibleTopologyCondition, metav1.ConditionTrue, "SurgePodsUnschedulable", infeasible)
+	} else {
+		setCondition(eas, SurgeInfeasibleTopologyCondition, metav1.ConditionFalse, "TopologyFits", "no surge was held back by topology constraints")
+	}
+}
+
+// setSurgeStuckPending records whether a surge pod has been Pending past the
+// deadline, stuck being why, or "" if none has.
+func setSurgeStuckPending(eas *myappsv1.EvictionAutoScaler, stuck string) {
+	if stuck != "" {
+		setCondition(eas, SurgeStuckPendingCondition, metav1.ConditionTrue, "SurgePodsPendingPastDeadline", stuck)
+	} else {
+		setCondition(eas, SurgeStuckPendingCondition, metav1.ConditionFalse, "NoStuckSurgePods", "no surge pod is pending past the deadline")
+	}
+}
@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+)
+
+var _ = Describe("EvictionAutoScaler conditions", func() {
+	var eas *myappsv1.EvictionAutoScaler
+
+	BeforeEach(func() {
+		eas = &myappsv1.EvictionAutoScaler{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Generation: 3}}
+	})
+
+	It("should keep Ready and Degraded as opposites", func() {
+		degraded(eas, "NoPdb", "PDB of same name not found")
+		Expect(meta.IsStatusConditionTrue(eas.Status.Conditions, DegradedCondition)).To(BeTrue())
+		Expect(meta.IsStatusConditionFalse(eas.Status.Conditions, ReadyCondition)).To(BeTrue())
+
+		ready(eas, "Reconciled", "no unhandled eviction")
+		Expect(meta.IsStatusConditionTrue(eas.Status.Conditions, ReadyCondition)).To(BeTrue())
+		Expect(meta.IsStatusConditionFalse(eas.Status.Conditions, DegradedCondition)).To(BeTrue())
+		Expect(meta.FindStatusCondition(eas.Status.Conditions, ReadyCondition).Message).To(Equal("no unhandled eviction"))
+	})
+
+	It("should mirror the surge state and stamp the observed generation on every condition", func() {
+		until := metav1.NewTime(time.Now().Add(time.Hour))
+		eas.Status.SuppressedUntil = &until
+		markSurge(&eas.Status, 5)
+		setCapacityBlocked(eas, 2)
+		ready(eas, "Reconciled", "eviction with scale up")
+
+		Expect(meta.IsStatusConditionTrue(eas.Status.Conditions, SurgeActiveCondition)).To(BeTrue())
+		Expect(meta.IsStatusConditionTrue(eas.Status.Conditions, SurgeSuppressedCondition)).To(BeTrue())
+		Expect(meta.FindStatusCondition(eas.Status.Conditions, CapacityBlockedCondition).Reason).To(Equal("SurgePodsPending"))
+		Expect(eas.Status.Conditions).To(HaveLen(5))
+		for _, cond := range eas.Status.Conditions {
+			Expect(cond.ObservedGeneration).To(Equal(int64(3)), cond.Type)
+			Expect(cond.Message).ToNot(BeEmpty(), cond.Type)
+		}
+
+		clearSurge(&eas.Status)
+		eas.Status.SuppressedUntil = nil
+		setCapacityBlocked(eas, 0)
+		ready(eas, "Reconciled", "evictions hit cooldown so scaled down")
+		Expect(meta.IsStatusConditionFalse(eas.Status.Conditions, SurgeActiveCondition)).To(BeTrue())
+		Expect(meta.IsStatusConditionFalse(eas.Status.Conditions, SurgeSuppressedCondition)).To(BeTrue())
+		Expect(meta.IsStatusConditionFalse(eas.Status.Conditions, CapacityBlockedCondition)).To(BeTrue())
+	})
+})
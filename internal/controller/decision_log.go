@@ -0,0 +1,41 @@
+package controllers
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// decisionLogMessage is the message of every decision line, so they can be selected
+// from the rest of the log with one filter, e.g. msg="Decision".
+const decisionLogMessage = "Decision"
+
+// Decision codes logged by controllers other than the EvictionAutoScaler one, whose
+// codes are the reasons of its Ready condition.
+const (
+	decisionPDBCreated          = "PDBCreated"
+	decisionMinAvailableUpdated = "MinAvailableUpdated"
+	decisionEASCreated          = "EvictionAutoScalerCreated"
+	decisionEvictionAnticipated = "EvictionAnticipated"
+	decisionPreSurge            = "PreSurge"
+	decisionEvictionInferred    = "EvictionInferred"
+	decisionSurgeReleased       = "SurgeReleased"
+	decisionEnrollmentChanged   = "EnrollmentChanged"
+)
+
+// logDecision writes one structured line for a decision a reconcile made: its code,
+// the namespace/name of the object acted on, and what was done. keysAndValues add
+// details. The logger controller-runtime puts in a reconcile's context already
+// carries its reconcileID, which decision traces record too, so the lines of one
+// reconcile can be correlated with each other and with its events and metrics.
+func logDecision(ctx context.Context, code, target, action string, keysAndValues ...any) {
+	args := append([]any{"decision", code, "target", target, "action", action}, keysAndValues...)
+	log.FromContext(ctx).Info(decisionLogMessage, args...)
+}
+
+// reconcileID returns the ID controller-runtime assigned to the reconcile running
+// in ctx, or "" outside of one.
+func reconcileID(ctx context.Context) string {
+	return string(controller.ReconcileIDFromContext(ctx))
+}
@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/decisions"
+)
+
+type decisionTraceKey struct{}
+
+// decisionTrace collects one reconcile of an EvictionAutoScaler for its decision log
+// line and EvictionAutoScalerReconciler.Decisions. All methods are no-ops on a nil
+// trace, so reconciles called without one pay nothing.
+type decisionTrace struct {
+	eas    *myappsv1.EvictionAutoScaler
+	ready  string
+	inputs map[string]string
+	branch string
+	action string
+	forget bool
+}
+
+// traceFrom returns the trace carried by ctx, or nil.
+func traceFrom(ctx context.Context) *decisionTrace {
+	trace, _ := ctx.Value(decisionTraceKey{}).(*decisionTrace)
+	return trace
+}
+
+// observe records the EvictionAutoScaler being reconciled. Its Ready condition at
+// the end of the reconcile names the branch taken, unless one was noted.
+func (t *decisionTrace) observe(eas *myappsv1.EvictionAutoScaler) {
+	if t == nil {
+		return
+	}
+	t.eas = eas
+	if ready := meta.FindStatusCondition(eas.Status.Conditions, ReadyCondition); ready != nil {
+		t.ready = ready.Reason + ready.Message
+	}
+	t.input("spec.lastEviction", evictionString(eas.Spec.LastEviction))
+	t.input("status.lastEviction", evictionString(eas.Status.LastEviction))
+	t.input("status.minReplicas", strconv.Itoa(int(eas.Status.MinReplicas)))
+	t.input("status.surgeActive", strconv.FormatBool(eas.Status.SurgeActive))
+}
+
+// input records a value the reconcile decided on.
+func (t *decisionTrace) input(key, value string) {
+	if t == nil {
+		return
+	}
+	if t.inputs == nil {
+		t.inputs = map[string]string{}
+	}
+	t.inputs[key] = value
+}
+
+// note names the branch taken by a reconcile that doesn't set the Ready condition.
+func (t *decisionTrace) note(branch, action string) {
+	if t == nil {
+		return
+	}
+	t.branch, t.action = branch, action
+}
+
+// changed reports whether the reconcile changed the Ready condition it observed.
+func (t *decisionTrace) changed() bool {
+	if t == nil || t.eas == nil {
+		return false
+	}
+	ready := meta.FindStatusCondition(t.eas.Status.Conditions, ReadyCondition)
+	return ready != nil && ready.Reason+ready.Message != t.ready
+}
+
+// decision summarizes the reconcile that returned result and err.
+func (t *decisionTrace) decision(result ctrl.Result, err error) decisions.Decision {
+	d := decisions.Decision{Time: time.Now(), Inputs: t.inputs, Branch: t.branch, Action: t.action}
+	if d.Branch == "" && t.eas != nil {
+		ready := meta.FindStatusCondition(t.eas.Status.Conditions, ReadyCondition)
+		if ready != nil && (err == nil || ready.Reason+ready.Message != t.ready) {
+			d.Branch, d.Action = ready.Reason, ready.Message
+		}
+	}
+	if err != nil {
+		d.Error = err.Error()
+		if d.Branch == "" {
+			d.Branch = "Error"
+		}
+	}
+	if result.RequeueAfter > 0 {
+		d.RequeueAfter = result.RequeueAfter.String()
+	}
+	return d
+}
+
+func evictionString(e myappsv1.Eviction) string {
+	if e.EvictionTime.IsZero() {
+		return ""
+	}
+	return e.PodName + "@" + e.EvictionTime.UTC().Format(time.RFC3339)
+}
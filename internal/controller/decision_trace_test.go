@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/go-logr/logr/funcr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/decisions"
+	"github.com/azure/eviction-autoscaler/internal/namespacefilter"
+)
+
+var _ = Describe("Decision traces", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+		key    = types.NamespacedName{Namespace: "default", Name: "web"}
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(policyv1.AddToScheme(scheme)).To(Succeed())
+		Expect(myappsv1.AddToScheme(scheme)).To(Succeed())
+	})
+
+	It("should record the branch taken and forget deleted EvictionAutoScalers", func() {
+		eas := &myappsv1.EvictionAutoScaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec:       myappsv1.EvictionAutoScalerSpec{TargetName: "web", TargetKind: myappsv1.TargetKindDeployment},
+			Status:     myappsv1.EvictionAutoScalerStatus{MinReplicas: 2},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}, eas,
+		).WithStatusSubresource(&myappsv1.EvictionAutoScaler{}).Build()
+		log := decisions.NewLog(5)
+		r := &EvictionAutoScalerReconciler{Client: c, Scheme: scheme, Filter: namespacefilter.New(nil, false), Decisions: log}
+
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+		trace := log.Decisions(key)
+		Expect(trace).To(HaveLen(1))
+		Expect(trace[0].Branch).To(Equal("NoPdb"))
+		Expect(trace[0].Inputs).To(HaveKeyWithValue("status.minReplicas", "2"))
+
+		Expect(c.Delete(ctx, eas)).To(Succeed())
+		_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(log.Decisions(key)).To(BeNil())
+	})
+
+	It("should log the decision of a reconcile as one structured line", func() {
+		var lines []string
+		ctx = logf.IntoContext(ctx, funcr.New(func(prefix, args string) {
+			lines = append(lines, args)
+		}, funcr.Options{}))
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+			&myappsv1.EvictionAutoScaler{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+				Spec:       myappsv1.EvictionAutoScalerSpec{TargetName: "web", TargetKind: myappsv1.TargetKindDeployment},
+			},
+		).WithStatusSubresource(&myappsv1.EvictionAutoScaler{}).Build()
+		r := &EvictionAutoScalerReconciler{Client: c, Scheme: scheme, Filter: namespacefilter.New(nil, false)}
+
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+		var decided []string
+		for _, line := range lines {
+			if strings.Contains(line, `"msg"="Decision"`) {
+				decided = append(decided, line)
+			}
+		}
+		Expect(decided).To(HaveLen(1))
+		Expect(decided[0]).To(ContainSubstring(`"decision"="NoPdb"`))
+		Expect(decided[0]).To(ContainSubstring(`"target"="default/web"`))
+	})
+
+	It("should name errors without a branch", func() {
+		d := (&decisionTrace{}).decision(ctrl.Result{}, errors.New("boom"))
+		Expect(d.Branch).To(Equal("Error"))
+		Expect(d.Error).To(Equal("boom"))
+		var nilTrace *decisionTrace
+		nilTrace.input("ignored", "value")
+		nilTrace.note("ignored", "")
+		Expect(traceFrom(ctx)).To(BeNil())
+	})
+})
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"context"
+	"errors"
 
 	"github.com/go-logr/logr"
 	"github.com/samber/lo"
@@ -16,6 +17,17 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
+// singleReplicaWithoutSurge reports whether target runs a single replica and has no
+// maxSurge to surge into. A PDB covering it blocks every eviction of its pod, and the
+// controller can't surge it to let one through.
+func singleReplicaWithoutSurge(ctx context.Context, target Surger) bool {
+	if target.GetReplicas() != 1 {
+		return false
+	}
+	_, err := calculateSurge(ctx, target, 1)
+	return errors.Is(err, errMaxSurgeZero)
+}
+
 // hasNonZeroMaxUnavailable returns true if the deployment has maxUnavailable set to a non-zero value.
 // Deployments with maxUnavailable != 0 already tolerate downtime, so PDB creation is skipped.
 func hasNonZeroMaxUnavailable(deployment *v1.Deployment) bool {
@@ -78,12 +90,14 @@ func triggerOnAnnotationChange(e event.UpdateEvent, logger logr.Logger) bool {
 	oldDeployment, okOld := e.ObjectOld.(*v1.Deployment)
 	newDeployment, okNew := e.ObjectNew.(*v1.Deployment)
 	if okOld && okNew {
-		oldVal := oldDeployment.Annotations[PDBCreateAnnotationKey]
-		newVal := newDeployment.Annotations[PDBCreateAnnotationKey]
-		if oldVal != newVal {
-			logger.Info("Update event detected, annotation value changed",
-				"oldValue", oldVal, "newValue", newVal)
-			return true
+		for _, key := range []string{PDBCreateAnnotationKey, ExcludeAnnotationKey} {
+			oldVal := oldDeployment.Annotations[key]
+			newVal := newDeployment.Annotations[key]
+			if oldVal != newVal {
+				logger.Info("Update event detected, annotation value changed",
+					"annotation", key, "oldValue", oldVal, "newValue", newVal)
+				return true
+			}
 		}
 	}
 	return false
@@ -2,14 +2,18 @@ package controllers
 
 import (
 	"context"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/annotations"
 	"github.com/azure/eviction-autoscaler/internal/metrics"
-	"github.com/azure/eviction-autoscaler/internal/namespacefilter"
 	v1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -23,21 +27,36 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
-const PDBCreateAnnotationKey = "eviction-autoscaler.azure.com/pdb-create"
-const PDBOwnedByAnnotationKey = "ownedBy"
-const ControllerName = "EvictionAutoScaler"
+const PDBCreateAnnotationKey = annotations.PDBCreate
+const PDBOwnedByAnnotationKey = annotations.OwnedBy
+const MinAvailableFloorAnnotationKey = annotations.MinAvailableFloor
+const ControllerName = annotations.OwnedByController
 const ResourceTypeDeployment = "Deployment"
 
-type filter interface {
-	Filter(ctx context.Context, c namespacefilter.Reader, ns string) (bool, error)
-}
-
 // DeploymentToPDBReconciler reconciles a Deployment object and ensures an associated PDB is created and deleted
 type DeploymentToPDBReconciler struct {
 	client.Client
 	Scheme   *runtime.Scheme
 	Recorder record.EventRecorder
 	Filter   filter
+	// SkipSingleReplica, when set, creates no PDB for deployments running a single
+	// replica without maxSurge, and deletes the one it created once they scale to it.
+	SkipSingleReplica bool
+	// MinAvailableDebounce, when set, only updates minAvailable to follow the replicas
+	// once they have stayed the same this long.
+	MinAvailableDebounce time.Duration
+	// MinAvailableFactor, when between 0 and 1, sets minAvailable to
+	// ceil(replicas * MinAvailableFactor) instead of the replicas.
+	MinAvailableFactor float64
+	// RepairInterval, when set, rechecks deployments with a controller-owned PDB this
+	// often and recreates an EvictionAutoScaler missing from the PDB for as long.
+	RepairInterval time.Duration
+	// ResyncPeriod, when set, reconciles every deployment again this often.
+	ResyncPeriod time.Duration
+
+	settle    replicaSettle
+	missing   missingEvictionAutoScalers
+	ambiguous ambiguousPDBs
 }
 
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;update;watch
@@ -49,44 +68,31 @@ func (r *DeploymentToPDBReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 	// Fetch the Deployment instance
 	var deployment v1.Deployment
 	if err := r.Get(ctx, req.NamespacedName, &deployment); err != nil {
-		// todo: decrement? DeploymentGauge when deployment not found
-		// was deployment ever tracked? permanent vs temporary not found?
-		return reconcile.Result{}, err
+		if apierrors.IsNotFound(err) {
+			// The PDB this controller created is named after the deployment.
+			r.ambiguous.forget(req.NamespacedName)
+			r.missing.found(req.NamespacedName)
+		}
+		return reconcile.Result{}, client.IgnoreNotFound(err)
 	}
 
-	// check if deployment is being deleted:
-	// if !deployment.DeletionTimestamp.IsZero() {
-	// Deployment is being deleted
-	//metrics.DeploymentGauge.WithLabelValues(deployment.Namespace, metrics.CanCreatePDBStr).Dec()
-	//return reconcile.Result{}, nil
-	//}
-
 	log := log.FromContext(ctx)
 
-	// Increment deployment count for metrics
-	metrics.DeploymentGauge.WithLabelValues(deployment.Namespace, metrics.CanCreatePDBStr).Inc()
-
 	// Check if eviction autoscaler should be enabled
-	isEnabled, err := r.Filter.Filter(ctx, r.Client, deployment.Namespace)
+	isEnabled, err := deploymentEnabled(ctx, r.Filter, r.Client, req.NamespacedName)
 	if err != nil {
 		log.Error(err, "Failed to check if eviction autoscaler is enabled", "namespace", deployment.Namespace)
 		return reconcile.Result{}, err
 	}
 	if !isEnabled {
 		log.V(1).Info("Eviction autoscaler not enabled for namespace", "namespace", deployment.Namespace)
-		// Clean up PDB if it exists and was created by this controller
-		// EvictionAutoScaler will be cascade deleted automatically via ownerReference
-		pdb, found, err := findPDBForDeployment(ctx, r.Client, &deployment, true)
-		if err != nil {
-			return reconcile.Result{}, err
-		}
-		if found {
-			log.Info("Deleting PDB for deployment in disabled namespace (EvictionAutoScaler will be cascade deleted)", "pdb", pdb.Name)
-			if err := r.Delete(ctx, pdb); err != nil {
-				return reconcile.Result{}, err
-			}
-		}
-		return reconcile.Result{}, nil
+		return reconcile.Result{}, r.deleteOwnedPDB(ctx, &deployment, "deployment in disabled namespace")
+	}
+
+	// The exclude annotation vetoes an enabled namespace for this deployment alone.
+	if exclude, reason := excluded(&deployment); exclude {
+		log.V(1).Info("Deployment excluded from eviction autoscaler", "deployment", deployment.Name, "reason", reason)
+		return reconcile.Result{}, r.deleteOwnedPDB(ctx, &deployment, "excluded deployment")
 	}
 
 	// Check if PDB creation should be skipped for this deployment
@@ -96,43 +102,101 @@ func (r *DeploymentToPDBReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		return reconcile.Result{}, nil
 	}
 
+	// Without maxSurge a single replica can't be surged, so its PDB would only block
+	// evictions of the pod.
+	if r.SkipSingleReplica && singleReplicaWithoutSurge(ctx, &DeploymentWrapper{obj: &deployment}) {
+		log.V(1).Info("Skipping PDB creation for single-replica deployment without maxSurge", "deployment", deployment.Name,
+			"namespace", deployment.Namespace)
+		return reconcile.Result{}, r.deleteOwnedPDB(ctx, &deployment, "single-replica deployment without maxSurge")
+	}
+
 	// Check if PDB already exists for this Deployment (any PDB, not just controller-owned)
-	pdb, found, err := findPDBForDeployment(ctx, r.Client, &deployment, false)
+	pdbs, err := findPDBsForDeployment(ctx, r.Client, &deployment)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
+	names := make([]string, 0, len(pdbs))
+	for _, p := range pdbs {
+		names = append(names, p.Name)
+	}
+	slices.Sort(names)
+	if r.ambiguous.changed(req.NamespacedName, names) && r.Recorder != nil {
+		r.Recorder.Eventf(&deployment, corev1.EventTypeWarning, "AmbiguousPDB",
+			"pods of deployment %s are selected by multiple PodDisruptionBudgets: %s", deployment.Name, strings.Join(names, ", "))
+	}
+	pdb, _ := preferredPDB(pdbs)
 
-	if found {
+	if pdb != nil {
 		// PDB already exists, check for EvictionAutoScaler and update if needed
 		EvictionAutoScaler := &myappsv1.EvictionAutoScaler{}
 		err := r.Get(ctx, types.NamespacedName{Name: pdb.Name, Namespace: pdb.Namespace}, EvictionAutoScaler)
+		if apierrors.IsNotFound(err) {
+			// Only the PDBs this controller owns have their minAvailable followed; the
+			// EvictionAutoScaler of an excluded one is meant to be missing.
+			if pdb.Annotations[PDBOwnedByAnnotationKey] != ControllerName {
+				return reconcile.Result{}, nil
+			}
+			if exclude, _ := excluded(pdb); exclude {
+				return reconcile.Result{}, nil
+			}
+			return r.missingEvictionAutoScaler(ctx, &deployment, pdb)
+		}
 		if err != nil {
-			//TODO don't ignore not found. Retry and fix unittest DeploymentToPDBReconciler when a deployment is created [It] should not create a PodDisruptionBudget if one already matches
-			return reconcile.Result{}, client.IgnoreNotFound(err)
+			return reconcile.Result{}, err
 		}
+		r.missing.found(client.ObjectKeyFromObject(pdb))
 		// if pdb exists get EvictionAutoScaler --> compare targetGeneration field for deployment if both not same deployment was not changed by pdb watcher
 		// update pdb minReplicas to current deployment replicas
-		return reconcile.Result{}, r.updateMinAvailableAsNecessary(ctx, &deployment, EvictionAutoScaler, *pdb)
+		result, err := r.updateMinAvailableAsNecessary(ctx, &deployment, EvictionAutoScaler, *pdb)
+		if err == nil && pdb.Annotations[PDBOwnedByAnnotationKey] == ControllerName {
+			result = r.resync(result)
+		}
+		return result, err
 	}
 
 	// Create a new PDB for the Deployment using helper function.
 	// PDB creation is always handled here (not in AutoscalerToPDBReconciler) because
-	// creation is gated by the pdb-create annotation on the Deployment. CreatePDBForDeployment
+	// creation is gated by the pdb-create annotation on the Deployment. NewPDBForDeployment
 	// uses ResolveMinReplicas to pick the correct initial minAvailable from the autoscaler floor.
 	// After creation, the autoscaler controller takes over minAvailable updates.
-	if err := CreatePDBForDeployment(ctx, r.Client, &deployment); err != nil {
+	pdb, err = NewPDBForDeployment(ctx, r.Client, &deployment)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	// A PDB of the same name that doesn't select the deployment's pods belongs to
+	// someone else: the forced apply would take it over, selector and all.
+	var existing policyv1.PodDisruptionBudget
+	if err := r.Get(ctx, client.ObjectKeyFromObject(pdb), &existing); client.IgnoreNotFound(err) != nil {
+		return reconcile.Result{}, err
+	} else if err == nil && existing.Annotations[PDBOwnedByAnnotationKey] != ControllerName {
+		log.Info("Not creating PDB, a PodDisruptionBudget of the same name not created by the controller exists",
+			"deployment", deployment.Name, "namespace", deployment.Namespace, "pdb", existing.Name)
+		return reconcile.Result{}, nil
+	}
+	if r.MinAvailableFactor > 0 && r.MinAvailableFactor < 1 {
+		hasAS, err := HasAutoscaler(ctx, r.Client, deployment.Namespace, deployment.Name, ResourceTypeDeployment)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		// An autoscaler's floor is kept as is, like in updateMinAvailableAsNecessary.
+		if !hasAS {
+			pdb.Spec.MinAvailable = &intstr.IntOrString{IntVal: scaledMinAvailable(pdb.Spec.MinAvailable.IntVal, r.MinAvailableFactor)}
+		}
+	}
+	if err := applyOwned(ctx, r.Client, nil, pdb); err != nil {
 		return reconcile.Result{}, err
 	}
 
 	// Track PDB creation event
-	metrics.PDBCreationCounter.WithLabelValues(deployment.Namespace, deployment.Name).Inc()
+	metrics.PDBCreationCounter.WithLabelValues(deployment.Namespace, metrics.Name(deployment.Name)).Inc()
 
-	log.Info("Created PodDisruptionBudget", "namespace", deployment.Namespace, "name", deployment.Name)
+	logDecision(ctx, decisionPDBCreated, deployment.Namespace+"/"+deployment.Name, "created PodDisruptionBudget",
+		"minAvailable", pdb.Spec.MinAvailable.IntVal)
 	return reconcile.Result{}, nil
 }
 
 func (r *DeploymentToPDBReconciler) updateMinAvailableAsNecessary(ctx context.Context,
-	deployment *v1.Deployment, EvictionAutoScaler *myappsv1.EvictionAutoScaler, pdb policyv1.PodDisruptionBudget) error {
+	deployment *v1.Deployment, EvictionAutoScaler *myappsv1.EvictionAutoScaler, pdb policyv1.PodDisruptionBudget) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 
 	// Check if PDB has the ownedBy annotation - if not, skip updates (user owns it)
@@ -141,7 +205,7 @@ func (r *DeploymentToPDBReconciler) updateMinAvailableAsNecessary(ctx context.Co
 	if !hasAnnotation {
 		logger.Info("Skipping PDB update - not owned by DeploymentToPDBController",
 			"namespace", pdb.Namespace, "name", pdb.Name)
-		return nil
+		return ctrl.Result{}, nil
 	}
 
 	// When HPA/KEDA targets this deployment, a separate controller (AutoscalerToPDBReconciler)
@@ -149,17 +213,28 @@ func (r *DeploymentToPDBReconciler) updateMinAvailableAsNecessary(ctx context.Co
 	// This controller should not interfere with autoscaler-driven replica changes.
 	hasAS, err := HasAutoscaler(ctx, r.Client, deployment.Namespace, deployment.Name, ResourceTypeDeployment)
 	if err != nil {
-		return err
+		return ctrl.Result{}, err
 	}
 	if hasAS {
 		logger.V(1).Info("HPA/KEDA controls this deployment, skipping PDB minAvailable update",
 			"target", deployment.Name)
-		return nil
+		return ctrl.Result{}, nil
+	}
+
+	// A surge in flight belongs to the EvictionAutoScaler, even when the PDB changed
+	// hands while it ran: never adopt the surged replica count as the floor. The
+	// EvictionAutoScaler reverts the target to its recorded MinReplicas.
+	if EvictionAutoScaler.Status.SurgeActive {
+		logger.V(1).Info("Surge in progress, skipping PDB minAvailable update",
+			"namespace", pdb.Namespace, "name", pdb.Name, "surgeReplicas", EvictionAutoScaler.Status.SurgeReplicas)
+		return ctrl.Result{}, nil
 	}
 
-	// No autoscaler — only proceed if the deployment generation actually changed
-	if EvictionAutoScaler.Status.TargetGeneration == deployment.GetGeneration() {
-		return nil
+	// No autoscaler — only proceed if the deployment actually changed. A change still
+	// settling counts even after the EvictionAutoScaler has recorded the new spec.
+	key := types.NamespacedName{Namespace: deployment.Namespace, Name: deployment.Name}
+	if !targetChanged(EvictionAutoScaler, &DeploymentWrapper{obj: deployment}) && !r.settle.pending(key) {
+		return ctrl.Result{}, nil
 	}
 
 	// Track deployment.spec.replicas directly.
@@ -172,26 +247,50 @@ func (r *DeploymentToPDBReconciler) updateMinAvailableAsNecessary(ctx context.Co
 		if err != nil {
 			logger.Error(err, "unable to parse surge replicas from annotation NOT updating",
 				"namespace", deployment.Namespace, "name", deployment.Name, "replicas", surgeReplicas)
-			return err
+			return ctrl.Result{}, err
 		}
 		if int32(newReplicas) == *deployment.Spec.Replicas {
-			return nil
+			return ctrl.Result{}, nil
+		}
+	}
+
+	if r.MinAvailableDebounce > 0 {
+		if wait := r.settle.wait(key, *deployment.Spec.Replicas, time.Now(), r.MinAvailableDebounce); wait > 0 {
+			logger.V(1).Info("Waiting for replicas to settle before updating pdb minAvailable",
+				"namespace", pdb.Namespace, "name", pdb.Name, "replicas", *deployment.Spec.Replicas, "wait", wait)
+			return ctrl.Result{RequeueAfter: wait}, nil
 		}
 	}
-	minAvailable := *deployment.Spec.Replicas
+	// An adopted PDB keeps the minAvailable its owner chose as a floor.
+	minAvailable := max(scaledMinAvailable(*deployment.Spec.Replicas, r.MinAvailableFactor), minAvailableFloor(&pdb))
 
 	if pdb.Spec.MinAvailable != nil && pdb.Spec.MinAvailable.IntVal == minAvailable {
-		return nil // already correct
+		return ctrl.Result{}, nil // already correct
 	}
 
 	pdb.Spec.MinAvailable = &intstr.IntOrString{IntVal: minAvailable}
-	if err = r.Update(ctx, &pdb); err != nil {
+	if err = applyOwned(ctx, r.Client, r.Recorder, managedPDB(&pdb)); err != nil {
 		logger.Error(err, "unable to update pdb minAvailable",
 			"namespace", pdb.Namespace, "name", pdb.Name, "minAvailable", minAvailable)
+		return ctrl.Result{}, err
+	}
+	logDecision(ctx, decisionMinAvailableUpdated, pdb.Namespace+"/"+pdb.Name, "updated PDB minAvailable",
+		"minAvailable", minAvailable)
+	return ctrl.Result{}, nil
+}
+
+// deleteOwnedPDB deletes the PDB this controller created for deployment, if any.
+// The EvictionAutoScaler is cascade deleted through its owner reference.
+func (r *DeploymentToPDBReconciler) deleteOwnedPDB(ctx context.Context, deployment *v1.Deployment, why string) error {
+	pdb, found, err := findPDBForDeployment(ctx, r.Client, deployment, true)
+	if err != nil || !found {
+		return err
+	}
+	log.FromContext(ctx).Info("Deleting PDB for "+why+" (EvictionAutoScaler will be cascade deleted)", "pdb", pdb.Name)
+	if err := r.Delete(ctx, pdb); err != nil {
 		return err
 	}
-	logger.Info("Successfully updated pdb minAvailable",
-		"namespace", pdb.Namespace, "name", pdb.Name, "minAvailable", minAvailable)
+	r.missing.found(client.ObjectKeyFromObject(pdb))
 	return nil
 }
 
@@ -202,7 +301,9 @@ func (r *DeploymentToPDBReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	// when controller restarts everything is seen as a create event
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1.Deployment{}).
+		WithOptions(controllerOptions("deployment")).
 		Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(requeueDeploymentsOnNamespaceChange(r.Client))).
+		WithEventFilter(shardPredicate(r.Filter)).
 		WithEventFilter(predicate.Funcs{
 			UpdateFunc: func(e event.UpdateEvent) bool {
 				// Only filter Deployment updates, let Namespace updates through
@@ -213,12 +314,15 @@ func (r *DeploymentToPDBReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				return true
 			},
 			DeleteFunc: func(e event.DeleteEvent) bool {
-				return false
+				// A deleted deployment is reconciled once, to forget what is tracked
+				// for it in memory.
+				_, ok := e.Object.(*v1.Deployment)
+				return ok
 			},
 		}).
 		// Owns establishes ownership relationship between this controller and PDBs.
 		// This ensures that:
 		// 1. Only ONE controller (DeploymentToPDBReconciler) manages the PDB lifecycle
 		Owns(&policyv1.PodDisruptionBudget{}).
-		Complete(r)
+		Complete(traced("deployment", resynced(r.ResyncPeriod, r.Client, func() client.Object { return &v1.Deployment{} }, r)))
 }
@@ -3,6 +3,7 @@ package controllers
 import (
 	"context"
 
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
 	"github.com/azure/eviction-autoscaler/internal/namespacefilter"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -188,6 +189,30 @@ var _ = Describe("DeploymentToPDBReconciler", func() {
 			//should we list it?
 		})
 
+		It("should not take over a PodDisruptionBudget of the same name it didn't create", func() {
+			minavailable := intstr.FromInt(1)
+			userpdb := &policyv1.PodDisruptionBudget{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      deploymentName,
+					Namespace: namespace,
+				},
+				Spec: policyv1.PodDisruptionBudgetSpec{
+					Selector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "other"}},
+					MinAvailable: &minavailable,
+				},
+			}
+			Expect(r.Client.Create(ctx, userpdb)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKey{Namespace: namespace, Name: deploymentName}})
+			Expect(err).ToNot(HaveOccurred())
+
+			pdb := &policyv1.PodDisruptionBudget{}
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(userpdb), pdb)).To(Succeed())
+			Expect(pdb.Spec.Selector.MatchLabels).To(HaveKeyWithValue("app", "other"))
+			Expect(pdb.Spec.MinAvailable.IntValue()).To(Equal(1))
+			Expect(pdb.Annotations).ToNot(HaveKey(PDBOwnedByAnnotationKey))
+		})
+
 		It("should not create a PodDisruptionBudget if maxUnavailable is not 0", func() {
 			// Create a deployment with maxUnavailable set to 25%
 			maxUnavailablePercent := intstr.FromString("25%")
@@ -360,6 +385,47 @@ var _ = Describe("DeploymentToPDBReconciler", func() {
 			Expect(err).ToNot(HaveOccurred())
 		})
 	})
+
+	Describe("when the controller takes PDB ownership back during a surge", func() {
+		It("should not adopt the surged replica count as minAvailable", func() {
+			pdb := &policyv1.PodDisruptionBudget{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        deploymentName,
+					Namespace:   namespace,
+					Annotations: map[string]string{PDBOwnedByAnnotationKey: ControllerName},
+				},
+				Spec: policyv1.PodDisruptionBudgetSpec{
+					MinAvailable: &intstr.IntOrString{IntVal: 3},
+					Selector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "example"}},
+				},
+			}
+			Expect(r.Client.Create(ctx, pdb)).To(Succeed())
+
+			eas := &myappsv1.EvictionAutoScaler{
+				ObjectMeta: metav1.ObjectMeta{Name: deploymentName, Namespace: namespace},
+				Spec:       myappsv1.EvictionAutoScalerSpec{TargetName: deploymentName, TargetKind: deploymentKind},
+			}
+			Expect(r.Client.Create(ctx, eas)).To(Succeed())
+			eas.Status.MinReplicas = 3
+			eas.Status.TargetGeneration = deployment.Generation
+			markSurge(&eas.Status, 5)
+			Expect(r.Client.Status().Update(ctx, eas)).To(Succeed())
+
+			// Surged while the user owned the PDB. Without a surge annotation on the
+			// deployment, only the EvictionAutoScaler status tells it from a scale-up.
+			deployment.Spec.Replicas = int32Ptr(5)
+			Expect(r.Client.Update(ctx, deployment)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{
+				NamespacedName: client.ObjectKey{Namespace: namespace, Name: deploymentName},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			updatedPDB := &policyv1.PodDisruptionBudget{}
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(pdb), updatedPDB)).To(Succeed())
+			Expect(updatedPDB.Spec.MinAvailable.IntVal).To(Equal(int32(3)))
+		})
+	})
 })
 
 var _ = Describe("DeploymentToPDBReconciler PDB creation control", func() {
@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+)
+
+// drainAborted reports whether the drain behind eviction was abandoned: the evicted
+// pod is still running on a node that is no longer cordoned or tainted for drain. A
+// pod that is gone or terminating, or still on a draining node, counts as drained.
+func drainAborted(ctx context.Context, c client.Reader, namespace string, eviction myappsv1.Eviction, drainTaints []string) (bool, error) {
+	if eviction.PodName == "" {
+		return false, nil
+	}
+	var pod corev1.Pod
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: eviction.PodName}, &pod); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	if pod.DeletionTimestamp != nil || pod.Spec.NodeName == "" {
+		return false, nil
+	}
+	var node corev1.Node
+	if err := c.Get(ctx, types.NamespacedName{Name: pod.Spec.NodeName}, &node); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	return !nodeDraining(&node, drainTaints), nil
+}
+
+// recordDrainOutcome counts consecutive aborted drains on status. Once threshold is
+// reached it starts a suppression window of length suppression and resets the count;
+// a completed drain resets it too. It reports whether a window was started.
+func recordDrainOutcome(status *myappsv1.EvictionAutoScalerStatus, aborted bool, threshold int32, suppression time.Duration, now time.Time) bool {
+	if !aborted {
+		status.AbortedDrains = 0
+		return false
+	}
+	status.AbortedDrains++
+	if threshold <= 0 || status.AbortedDrains < threshold {
+		return false
+	}
+	until := metav1.NewTime(now.Add(suppression))
+	status.SuppressedUntil = &until
+	status.AbortedDrains = 0
+	return true
+}
+
+// suppressionRemaining returns how long new surges are still suppressed. Once the
+// window has passed it clears the deadline.
+func suppressionRemaining(status *myappsv1.EvictionAutoScalerStatus, now time.Time) time.Duration {
+	if status.SuppressedUntil != nil {
+		if remaining := status.SuppressedUntil.Sub(now); remaining > 0 {
+			return remaining
+		}
+	}
+	status.SuppressedUntil = nil
+	return 0
+}
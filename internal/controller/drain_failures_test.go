@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+)
+
+var _ = Describe("drain failure suppression", func() {
+	var (
+		ctx      context.Context
+		scheme   *runtime.Scheme
+		eviction myappsv1.Eviction
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		eviction = myappsv1.Eviction{PodName: "app-1", EvictionTime: metav1.Now()}
+	})
+
+	build := func(nodes ...*corev1.Node) client.Client {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "app-1", Namespace: "default"},
+			Spec:       corev1.PodSpec{NodeName: "node-1"},
+		}
+		objs := []client.Object{pod}
+		for _, node := range nodes {
+			objs = append(objs, node)
+		}
+		return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+	}
+
+	It("should treat a pod still running on a schedulable node as an aborted drain", func() {
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}
+		aborted, err := drainAborted(ctx, build(node), "default", eviction, DefaultDrainTaintKeys)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(aborted).To(BeTrue())
+
+		node.Spec.Unschedulable = true
+		aborted, err = drainAborted(ctx, build(node), "default", eviction, DefaultDrainTaintKeys)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(aborted).To(BeFalse())
+	})
+
+	It("should treat an evicted pod that is gone as a completed drain", func() {
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}
+		eviction.PodName = "app-2"
+		aborted, err := drainAborted(ctx, build(node), "default", eviction, DefaultDrainTaintKeys)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(aborted).To(BeFalse())
+	})
+
+	It("should start a suppression window after consecutive aborted drains", func() {
+		now := time.Now()
+		status := &myappsv1.EvictionAutoScalerStatus{}
+		Expect(recordDrainOutcome(status, true, 2, time.Hour, now)).To(BeFalse())
+		Expect(status.AbortedDrains).To(Equal(int32(1)))
+		Expect(recordDrainOutcome(status, false, 2, time.Hour, now)).To(BeFalse())
+		Expect(status.AbortedDrains).To(BeZero())
+
+		Expect(recordDrainOutcome(status, true, 2, time.Hour, now)).To(BeFalse())
+		Expect(recordDrainOutcome(status, true, 2, time.Hour, now)).To(BeTrue())
+		Expect(status.AbortedDrains).To(BeZero())
+		Expect(status.SuppressedUntil.Time).To(BeTemporally("~", now.Add(time.Hour), time.Second))
+
+		Expect(suppressionRemaining(status, now.Add(time.Minute))).To(BeNumerically("~", 59*time.Minute, time.Second))
+		Expect(suppressionRemaining(status, now.Add(2*time.Hour))).To(BeZero())
+		Expect(status.SuppressedUntil).To(BeNil())
+	})
+})
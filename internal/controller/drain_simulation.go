@@ -0,0 +1,288 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+)
+
+// mirrorPodAnnotationKey marks static pods the kubelet mirrors to the API server;
+// drains skip them because they can't be evicted.
+const mirrorPodAnnotationKey = "kubernetes.io/config.mirror"
+
+// DrainSimulation is the predicted outcome of draining a node.
+type DrainSimulation struct {
+	Node string `json:"node"`
+	// Workloads has an entry per PDB covering pods on the node.
+	Workloads []SimulatedWorkload `json:"workloads"`
+	// UnprotectedPods are evicted without a PDB check, as namespace/name: pods no PDB
+	// covers, and pods that aren't Ready under a PDB always allowing their eviction.
+	UnprotectedPods []string `json:"unprotectedPods,omitempty"`
+	// ExtraReplicas and ExtraRequests total the surges over all workloads.
+	ExtraReplicas int32               `json:"extraReplicas"`
+	ExtraRequests corev1.ResourceList `json:"extraRequests,omitempty"`
+}
+
+// SimulatedWorkload is the predicted outcome for the pods of one PDB on the node.
+type SimulatedWorkload struct {
+	Namespace string   `json:"namespace"`
+	PDB       string   `json:"pdb"`
+	Pods      []string `json:"pods"`
+	// DisruptionsAllowed is the PDB's current budget; evictions past it block.
+	DisruptionsAllowed int32 `json:"disruptionsAllowed"`
+	BlockedEvictions   int32 `json:"blockedEvictions"`
+	// TargetKind and TargetName are set when an EvictionAutoScaler covers the PDB.
+	TargetKind      myappsv1.TargetKind `json:"targetKind,omitempty"`
+	TargetName      string              `json:"targetName,omitempty"`
+	CurrentReplicas int32               `json:"currentReplicas,omitempty"`
+	SurgeReplicas   int32               `json:"surgeReplicas,omitempty"`
+	ExtraReplicas   int32               `json:"extraReplicas"`
+	// ExtraRequests is ExtraReplicas times the resource requests of one pod.
+	ExtraRequests corev1.ResourceList `json:"extraRequests,omitempty"`
+	// Reason explains why blocked evictions get no surge.
+	Reason string `json:"reason,omitempty"`
+}
+
+// SimulateDrain predicts what draining the named node would do: which pods' evictions
+// their PDBs would block, which targets the EvictionAutoScalers would surge and by
+// how much, using the same sizing as a real eviction. Like kubectl drain it skips
+// DaemonSet, mirror and finished pods. Nothing is written.
+func SimulateDrain(ctx context.Context, c client.Client, nodeName string) (*DrainSimulation, error) {
+	var node corev1.Node
+	if err := c.Get(ctx, types.NamespacedName{Name: nodeName}, &node); err != nil {
+		return nil, err
+	}
+	var pods corev1.PodList
+	if err := c.List(ctx, &pods, client.MatchingFields{NodeNameIndex: nodeName}); err != nil {
+		return nil, err
+	}
+
+	sim := &DrainSimulation{Node: nodeName}
+	pdbs := map[string][]policyv1.PodDisruptionBudget{}
+	workloads := map[types.NamespacedName]*SimulatedWorkload{}
+	var order []types.NamespacedName
+	podFor := map[types.NamespacedName]*corev1.Pod{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if !drainEvicts(pod) {
+			continue
+		}
+		if _, ok := pdbs[pod.Namespace]; !ok {
+			var list policyv1.PodDisruptionBudgetList
+			if err := c.List(ctx, &list, client.InNamespace(pod.Namespace)); err != nil {
+				return nil, err
+			}
+			pdbs[pod.Namespace] = list.Items
+		}
+		pdb := pdbForPod(pdbs[pod.Namespace], pod)
+		if pdb == nil {
+			sim.UnprotectedPods = append(sim.UnprotectedPods, pod.Namespace+"/"+pod.Name)
+			continue
+		}
+		if unhealthyPodEvictable(pdb, pod) {
+			sim.UnprotectedPods = append(sim.UnprotectedPods, pod.Namespace+"/"+pod.Name)
+			continue
+		}
+		key := types.NamespacedName{Namespace: pdb.Namespace, Name: pdb.Name}
+		w, ok := workloads[key]
+		if !ok {
+			w = &SimulatedWorkload{Namespace: pdb.Namespace, PDB: pdb.Name, DisruptionsAllowed: pdb.Status.DisruptionsAllowed}
+			workloads[key] = w
+			order = append(order, key)
+			podFor[key] = pod
+		}
+		w.Pods = append(w.Pods, pod.Name)
+	}
+
+	for _, key := range order {
+		w := workloads[key]
+		w.BlockedEvictions = max(int32(len(w.Pods))-w.DisruptionsAllowed, 0)
+		pdb := pdbForName(pdbs[key.Namespace], key.Name)
+		if err := simulateSurge(ctx, c, &node, pdb, podFor[key], w); err != nil {
+			return nil, err
+		}
+		sim.Workloads = append(sim.Workloads, *w)
+		sim.ExtraReplicas += w.ExtraReplicas
+		sim.ExtraRequests = addRequests(sim.ExtraRequests, w.ExtraRequests, 1)
+	}
+	return sim, nil
+}
+
+// simulateSurge fills in the surge the EvictionAutoScaler of pdb would apply for w.
+// Workloads that get no surge are left with a Reason when evictions would block.
+func simulateSurge(ctx context.Context, c client.Client, node *corev1.Node, pdb *policyv1.PodDisruptionBudget, pod *corev1.Pod, w *SimulatedWorkload) error {
+	var eas myappsv1.EvictionAutoScaler
+	if err := c.Get(ctx, types.NamespacedName{Namespace: pdb.Namespace, Name: pdb.Name}, &eas); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		if w.BlockedEvictions > 0 {
+			w.Reason = "no EvictionAutoScaler for the PDB"
+		}
+		return nil
+	}
+	w.TargetKind = eas.Spec.TargetKind
+	w.TargetName = eas.Spec.TargetName
+	kind, err := myappsv1.NormalizeTargetKind(eas.Spec.TargetKind)
+	if err != nil {
+		w.Reason = err.Error()
+		return nil
+	}
+	w.TargetKind = kind
+
+	target, err := GetSurger(kind)
+	if err != nil {
+		return err
+	}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: eas.Namespace, Name: eas.Spec.TargetName}, target.Obj()); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		w.Reason = "target not found"
+		return nil
+	}
+	w.CurrentReplicas = target.GetReplicas()
+	if w.BlockedEvictions == 0 {
+		return nil
+	}
+
+	disabled, err := surgeDisabled(ctx, c, target)
+	if err != nil {
+		w.Reason = err.Error()
+		return nil
+	}
+	if disabled {
+		w.Reason = "surge disabled by " + SurgeAnnotationKey
+		return nil
+	}
+
+	// A target changed since the controller last looked gets its floor reset first.
+	minReplicas := eas.Status.MinReplicas
+	if !eas.Status.SurgeActive && targetChanged(&eas, target) {
+		if minReplicas, _, err = ResolveMinReplicas(ctx, c, eas.Namespace, eas.Spec.TargetName, string(kind), target.GetReplicas()); err != nil {
+			return err
+		}
+	}
+	if eas.Spec.MinReplicas != nil {
+		minReplicas = max(minReplicas, *eas.Spec.MinReplicas)
+	}
+	maxSurgeTarget, err := calculateSurge(ctx, target, minReplicas)
+	if err != nil {
+		if errors.Is(err, errMaxSurgeZero) {
+			w.Reason = "maxSurge is 0"
+		} else {
+			w.Reason = err.Error()
+		}
+		return nil
+	}
+
+	// Pods already on cordoned nodes are counted there; the rest are displaced by
+	// cordoning this one.
+	displaced, err := countPodsOnCordoned(ctx, c, pdb)
+	if err != nil {
+		return err
+	}
+	if !node.Spec.Unschedulable {
+		displaced += int32(len(w.Pods))
+	}
+	w.SurgeReplicas = min(minReplicas+displaced, maxSurgeTarget)
+	w.ExtraReplicas = max(w.SurgeReplicas-w.CurrentReplicas, 0)
+	if w.ExtraReplicas == 0 {
+		w.Reason = fmt.Sprintf("already at %d replicas", w.CurrentReplicas)
+	}
+	w.ExtraRequests = addRequests(nil, podRequests(pod), w.ExtraReplicas)
+	return nil
+}
+
+// drainEvicts reports whether a drain would evict pod.
+func drainEvicts(pod *corev1.Pod) bool {
+	if pod.DeletionTimestamp != nil || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return false
+	}
+	if _, ok := pod.Annotations[mirrorPodAnnotationKey]; ok {
+		return false
+	}
+	return controllerOf(pod) == ""
+}
+
+// pdbForPod returns the first of pdbs selecting pod, or nil.
+func pdbForPod(pdbs []policyv1.PodDisruptionBudget, pod *corev1.Pod) *policyv1.PodDisruptionBudget {
+	for i := range pdbs {
+		selector, err := metav1.LabelSelectorAsSelector(pdbs[i].Spec.Selector)
+		if err != nil {
+			continue
+		}
+		if !selector.Empty() && selector.Matches(labels.Set(pod.Labels)) {
+			return &pdbs[i]
+		}
+	}
+	return nil
+}
+
+func pdbForName(pdbs []policyv1.PodDisruptionBudget, name string) *policyv1.PodDisruptionBudget {
+	i := slices.IndexFunc(pdbs, func(p policyv1.PodDisruptionBudget) bool { return p.Name == name })
+	return &pdbs[i]
+}
+
+// podRequests sums the resource requests of pod's containers.
+func podRequests(pod *corev1.Pod) corev1.ResourceList {
+	var requests corev1.ResourceList
+	for _, container := range pod.Spec.Containers {
+		requests = addRequests(requests, container.Resources.Requests, 1)
+	}
+	return requests
+}
+
+// addRequests adds times copies of add to sum and returns it.
+func addRequests(sum, add corev1.ResourceList, times int32) corev1.ResourceList {
+	if times == 0 || len(add) == 0 {
+		return sum
+	}
+	if sum == nil {
+		sum = corev1.ResourceList{}
+	}
+	for name, quantity := range add {
+		total := sum[name]
+		for range times {
+			total.Add(quantity)
+		}
+		sum[name] = total
+	}
+	return sum
+}
+
+// String renders the simulation for a terminal.
+func (s *DrainSimulation) String() string {
+	out := fmt.Sprintf("Draining node %s:\n", s.Node)
+	for _, w := range s.Workloads {
+		out += fmt.Sprintf("  %s/%s: %d pod(s), %d disruption(s) allowed, %d eviction(s) would block", w.Namespace, w.PDB, len(w.Pods), w.DisruptionsAllowed, w.BlockedEvictions)
+		switch {
+		case w.ExtraReplicas > 0:
+			out += fmt.Sprintf("; %s %s would surge %d -> %d", w.TargetKind, w.TargetName, w.CurrentReplicas, w.SurgeReplicas)
+		case w.Reason != "":
+			out += "; no surge: " + w.Reason
+		}
+		out += "\n"
+	}
+	if len(s.UnprotectedPods) > 0 {
+		out += fmt.Sprintf("  %d pod(s) not held by a PDB would be evicted immediately\n", len(s.UnprotectedPods))
+	}
+	out += fmt.Sprintf("Extra replicas: %d\n", s.ExtraReplicas)
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		if quantity, ok := s.ExtraRequests[name]; ok {
+			out += fmt.Sprintf("Extra %s requests: %s\n", name, quantity.String())
+		}
+	}
+	return out
+}
@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"context"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+)
+
+var _ = Describe("SimulateDrain", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+		objs   []client.Object
+	)
+
+	pod := func(name, app string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop", Labels: map[string]string{"app": app}},
+			Spec: corev1.PodSpec{NodeName: "node-1", Containers: []corev1.Container{{Name: "main", Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m"), corev1.ResourceMemory: resource.MustParse("1Gi")},
+			}}}},
+		}
+	}
+	pdb := func(app string, allowed int32) *policyv1.PodDisruptionBudget {
+		return &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: app, Namespace: "shop"},
+			Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": app}}},
+			Status:     policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: allowed},
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		Expect(policyv1.AddToScheme(scheme)).To(Succeed())
+		Expect(autoscalingv2.AddToScheme(scheme)).To(Succeed())
+		Expect(kedav1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(myappsv1.AddToScheme(scheme)).To(Succeed())
+		objs = []client.Object{
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop"}},
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop", Generation: 1},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: ptr.To(int32(2)),
+					Strategy: appsv1.DeploymentStrategy{RollingUpdate: &appsv1.RollingUpdateDeployment{MaxSurge: ptr.To(intstr.FromInt32(1))}},
+				},
+			},
+			&myappsv1.EvictionAutoScaler{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+				Spec:       myappsv1.EvictionAutoScalerSpec{TargetName: "web", TargetKind: myappsv1.TargetKindDeployment},
+				Status:     myappsv1.EvictionAutoScalerStatus{MinReplicas: 2, TargetGeneration: 1},
+			},
+			pdb("web", 0),
+			pod("web-1", "web"),
+			pod("web-2", "web"),
+		}
+	})
+
+	simulate := func() *DrainSimulation {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).
+			WithIndex(&corev1.Pod{}, NodeNameIndex, func(obj client.Object) []string {
+				return []string{obj.(*corev1.Pod).Spec.NodeName}
+			}).Build()
+		sim, err := SimulateDrain(ctx, c, "node-1")
+		Expect(err).ToNot(HaveOccurred())
+		return sim
+	}
+
+	It("should report blocked evictions and the surge capped at maxSurge", func() {
+		sim := simulate()
+		Expect(sim.Workloads).To(HaveLen(1))
+		w := sim.Workloads[0]
+		Expect(w.Pods).To(ConsistOf("web-1", "web-2"))
+		Expect(w.BlockedEvictions).To(Equal(int32(2)))
+		Expect(w.TargetName).To(Equal("web"))
+		Expect(w.SurgeReplicas).To(Equal(int32(3)))
+		Expect(sim.ExtraReplicas).To(Equal(int32(1)))
+		Expect(sim.ExtraRequests.Cpu().String()).To(Equal("500m"))
+		Expect(sim.ExtraRequests.Memory().String()).To(Equal("1Gi"))
+	})
+
+	It("should not surge workloads whose PDB allows the evictions", func() {
+		objs[4] = pdb("web", 2)
+		sim := simulate()
+		Expect(sim.Workloads[0].BlockedEvictions).To(BeZero())
+		Expect(sim.Workloads[0].Reason).To(BeEmpty())
+		Expect(sim.ExtraReplicas).To(BeZero())
+		Expect(sim.ExtraRequests).To(BeEmpty())
+	})
+
+	It("should explain blocked evictions that get no surge", func() {
+		objs = append(objs, pdb("db", 0), pod("db-0", "db"))
+		sim := simulate()
+		Expect(sim.Workloads).To(ContainElement(And(
+			HaveField("PDB", "db"),
+			HaveField("BlockedEvictions", int32(1)),
+			HaveField("Reason", "no EvictionAutoScaler for the PDB"),
+		)))
+	})
+
+	It("should skip DaemonSet pods and list pods without a PDB", func() {
+		agent := pod("agent-x", "agent")
+		agent.OwnerReferences = []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "DaemonSet", Name: "agent", UID: "ds-uid", Controller: ptr.To(true)}}
+		objs = append(objs, agent, pod("batch-1", "batch"))
+		sim := simulate()
+		Expect(sim.UnprotectedPods).To(ConsistOf("shop/batch-1"))
+		Expect(sim.Workloads).To(HaveLen(1))
+	})
+})
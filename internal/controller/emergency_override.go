@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/annotations"
+	"github.com/azure/eviction-autoscaler/internal/metrics"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// EmergencySurgeUntilAnnotationKey lets an operator unblock a stuck drain without
+// hand-editing the workload. The value is an RFC3339 timestamp; until then the
+// controller surges the target to one above the PDB floor, ignoring cooldown and
+// the maxSurge cap.
+const EmergencySurgeUntilAnnotationKey = annotations.EmergencySurgeUntil
+
+// maxEmergencySurgeWindow bounds how far in the future an override may expire so a
+// forgotten annotation cannot pin a workload at surged capacity indefinitely.
+const maxEmergencySurgeWindow = 1 * time.Hour
+
+// emergencySurgeUntil returns the expiry of the emergency override on the
+// EvictionAutoScaler. found is false when the annotation is absent. An error is
+// returned for unparseable values or an expiry beyond maxEmergencySurgeWindow.
+func emergencySurgeUntil(eas *myappsv1.EvictionAutoScaler, now time.Time) (time.Time, bool, error) {
+	val, ok := eas.Annotations[EmergencySurgeUntilAnnotationKey]
+	if !ok {
+		return time.Time{}, false, nil
+	}
+	until, err := annotations.Timestamp(EmergencySurgeUntilAnnotationKey, val)
+	if err != nil {
+		return time.Time{}, true, err
+	}
+	if until.After(now.Add(maxEmergencySurgeWindow)) {
+		return time.Time{}, true, fmt.Errorf("%s annotation %q is more than %s in the future",
+			EmergencySurgeUntilAnnotationKey, val, maxEmergencySurgeWindow)
+	}
+	return until, true, nil
+}
+
+// applyEmergencySurge surges the target to one replica above the PDB floor while the
+// override is active. The surge is recorded as an event on the EvictionAutoScaler
+// so overrides are auditable, and the reconcile is requeued for the expiry.
+func (r *EvictionAutoScalerReconciler) applyEmergencySurge(ctx context.Context, eas *myappsv1.EvictionAutoScaler,
+	pdb *policyv1.PodDisruptionBudget, target Surger, surgeApplier SurgeApplier, until time.Time) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	emergencyTarget := max(scaleDownFloor(eas), pdb.Status.DesiredHealthy) + 1
+	if target.GetReplicas() < emergencyTarget {
+		logger.Info("Emergency surge override active, surging", "pdb", pdb.Name,
+			"target", eas.Spec.TargetName, "surgeTarget", emergencyTarget, "until", until, "strategy", surgeApplier.Name())
+		if err := r.addSurgeFinalizer(ctx, eas); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := surgeApplier.ApplySurge(ctx, emergencyTarget); err != nil {
+			logger.Error(err, "failed to apply emergency surge", "kind", eas.Spec.TargetKind, "targetname", eas.Spec.TargetName)
+			return ctrl.Result{}, err
+		}
+		metrics.ActualScalingCounter.WithLabelValues(eas.Namespace, metrics.Name(eas.Spec.TargetName), metrics.ScaleUpAction).Inc()
+		markSurge(&eas.Status, emergencyTarget)
+		r.event(eas, corev1.EventTypeWarning, "EmergencySurge",
+			fmt.Sprintf("emergency override surged %s to %d replicas until %s", eas.Spec.TargetName, emergencyTarget, until.Format(time.RFC3339)))
+		recordTarget(&eas.Status, target)
+	}
+
+	ready(eas, "EmergencySurge", fmt.Sprintf("emergency surge to %d replicas until %s", emergencyTarget, until.Format(time.RFC3339)))
+	return ctrl.Result{RequeueAfter: time.Until(until)}, r.Status().Update(ctx, eas)
+}
+
+// revertExpiredEmergencySurge scales the target back once the override has expired.
+// It only runs when there is no unhandled eviction; otherwise the regular
+// cooldown path owns the revert.
+func (r *EvictionAutoScalerReconciler) revertExpiredEmergencySurge(ctx context.Context, eas *myappsv1.EvictionAutoScaler,
+	target Surger, surgeApplier SurgeApplier) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	if err := r.revertSurge(ctx, eas, target, surgeApplier); err != nil {
+		return ctrl.Result{}, err
+	}
+	logger.Info("Emergency surge override expired, reverted surge", "target", eas.Spec.TargetName, "minReplicas", scaleDownFloor(eas))
+	r.event(eas, corev1.EventTypeNormal, "EmergencySurgeExpired",
+		fmt.Sprintf("emergency override expired, reverted %s to %d replicas", eas.Spec.TargetName, scaleDownFloor(eas)))
+
+	ready(eas, "EmergencySurgeExpired", "emergency override expired so scaled down")
+	return ctrl.Result{}, r.Status().Update(ctx, eas)
+}
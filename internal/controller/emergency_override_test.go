@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"time"
+
+	v1 "github.com/azure/eviction-autoscaler/api/v1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("emergencySurgeUntil", func() {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	easWith := func(annotations map[string]string) *v1.EvictionAutoScaler {
+		return &v1.EvictionAutoScaler{ObjectMeta: metav1.ObjectMeta{Name: "eas", Annotations: annotations}}
+	}
+
+	It("should report not found when the annotation is absent", func() {
+		_, found, err := emergencySurgeUntil(easWith(nil), now)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(found).To(BeFalse())
+	})
+
+	It("should parse an RFC3339 expiry within the allowed window", func() {
+		until := now.Add(30 * time.Minute)
+		got, found, err := emergencySurgeUntil(easWith(map[string]string{
+			EmergencySurgeUntilAnnotationKey: until.Format(time.RFC3339),
+		}), now)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(found).To(BeTrue())
+		Expect(got).To(BeTemporally("==", until))
+	})
+
+	It("should reject an unparseable value", func() {
+		_, found, err := emergencySurgeUntil(easWith(map[string]string{
+			EmergencySurgeUntilAnnotationKey: "soon",
+		}), now)
+		Expect(found).To(BeTrue())
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should reject an expiry beyond the maximum window", func() {
+		_, found, err := emergencySurgeUntil(easWith(map[string]string{
+			EmergencySurgeUntilAnnotationKey: now.Add(maxEmergencySurgeWindow + time.Minute).Format(time.RFC3339),
+		}), now)
+		Expect(found).To(BeTrue())
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should return an expired timestamp so the caller can revert", func() {
+		until := now.Add(-time.Minute)
+		got, found, err := emergencySurgeUntil(easWith(map[string]string{
+			EmergencySurgeUntilAnnotationKey: until.Format(time.RFC3339),
+		}), now)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(found).To(BeTrue())
+		Expect(got.Before(now)).To(BeTrue())
+	})
+})
+
+var _ = Describe("surge status", func() {
+	It("should record the surge and keep the start time across top-ups", func() {
+		var status v1.EvictionAutoScalerStatus
+		markSurge(&status, 3)
+		Expect(status.SurgeActive).To(BeTrue())
+		Expect(status.SurgeReplicas).To(Equal(int32(3)))
+		Expect(status.SurgeStartTime).ToNot(BeNil())
+		start := *status.SurgeStartTime
+
+		markSurge(&status, 4)
+		Expect(status.SurgeReplicas).To(Equal(int32(4)))
+		Expect(*status.SurgeStartTime).To(Equal(start))
+	})
+
+	It("should clear all surge fields", func() {
+		var status v1.EvictionAutoScalerStatus
+		markSurge(&status, 3)
+		clearSurge(&status)
+		Expect(status.SurgeActive).To(BeFalse())
+		Expect(status.SurgeReplicas).To(BeZero())
+		Expect(status.SurgeStartTime).To(BeNil())
+	})
+})
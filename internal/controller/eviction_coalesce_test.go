@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/namespacefilter"
+)
+
+var _ = Describe("Eviction coalescing", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+		key    = client.ObjectKey{Namespace: "default", Name: "web"}
+		now    = time.Now()
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		Expect(policyv1.AddToScheme(scheme)).To(Succeed())
+		Expect(autoscalingv2.AddToScheme(scheme)).To(Succeed())
+		Expect(kedav1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(myappsv1.AddToScheme(scheme)).To(Succeed())
+	})
+
+	// evicted is an EvictionAutoScaler whose latest eviction, at evictedAt, is unhandled.
+	evicted := func(evictedAt time.Time) *myappsv1.EvictionAutoScaler {
+		return &myappsv1.EvictionAutoScaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: myappsv1.EvictionAutoScalerSpec{TargetName: "web", TargetKind: myappsv1.TargetKindDeployment, SurgeMode: SurgeModeDirect,
+				LastEviction: myappsv1.Eviction{PodName: "web-1", EvictionTime: metav1.NewTime(evictedAt)}},
+			Status: myappsv1.EvictionAutoScalerStatus{MinReplicas: 2, TargetGeneration: 1},
+		}
+	}
+
+	It("should not coalesce without a window", func() {
+		eas := evicted(now)
+		eas.Status.CoalescingSince = &metav1.Time{Time: now}
+		Expect(coalesceRemaining(eas, 0, now)).To(BeZero())
+		Expect(eas.Status.CoalescingSince).To(BeNil())
+	})
+
+	It("should open the window at the first unhandled eviction and keep it for later ones", func() {
+		eas := evicted(now)
+		Expect(coalesceRemaining(eas, 10*time.Second, now.Add(2*time.Second))).To(Equal(8 * time.Second))
+		Expect(eas.Status.CoalescingSince.Time).To(BeTemporally("==", now))
+
+		eas.Spec.LastEviction.EvictionTime = metav1.NewTime(now.Add(5 * time.Second))
+		Expect(coalesceRemaining(eas, 10*time.Second, now.Add(6*time.Second))).To(Equal(4 * time.Second))
+
+		Expect(coalesceRemaining(eas, 10*time.Second, now.Add(11*time.Second))).To(BeZero())
+		Expect(eas.Status.CoalescingSince).To(BeNil())
+	})
+
+	It("should reopen a window left over from an eviction since handled", func() {
+		eas := evicted(now.Add(time.Minute))
+		eas.Status.CoalescingSince = &metav1.Time{Time: now}
+		eas.Status.LastEviction = myappsv1.Eviction{PodName: "web-0", EvictionTime: metav1.NewTime(now)}
+		Expect(coalesceRemaining(eas, 10*time.Second, now.Add(time.Minute))).To(Equal(10 * time.Second))
+		Expect(eas.Status.CoalescingSince.Time).To(BeTemporally("==", now.Add(time.Minute)))
+	})
+
+	// reconcile runs eas over a deployment with two of its pods on a cordoned node,
+	// and returns the result, the EvictionAutoScaler and the deployment's replicas.
+	reconcile := func(eas *myappsv1.EvictionAutoScaler) (ctrl.Result, *myappsv1.EvictionAutoScaler, int32) {
+		pod := func(name string) *corev1.Pod {
+			return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": "web"}},
+				Spec: corev1.PodSpec{NodeName: "node-1"}}
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}, Spec: corev1.NodeSpec{Unschedulable: true}},
+			pod("web-1"), pod("web-2"),
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Generation: 1},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: ptr.To(int32(2)),
+					Strategy: appsv1.DeploymentStrategy{RollingUpdate: &appsv1.RollingUpdateDeployment{MaxSurge: ptr.To(intstr.FromInt32(2))}},
+				},
+			},
+			&policyv1.PodDisruptionBudget{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+				Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+			},
+			eas,
+		).WithStatusSubresource(&myappsv1.EvictionAutoScaler{}).Build()
+		r := &EvictionAutoScalerReconciler{Client: c, Scheme: scheme, Filter: namespacefilter.New(nil, false), APIReader: c,
+			EvictionCoalesceWindow: 10 * time.Second}
+		result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+
+		var got myappsv1.EvictionAutoScaler
+		Expect(c.Get(ctx, key, &got)).To(Succeed())
+		var dep appsv1.Deployment
+		Expect(c.Get(ctx, key, &dep)).To(Succeed())
+		return result, &got, *dep.Spec.Replicas
+	}
+
+	It("should hold a new eviction until the window passes", func() {
+		result, eas, replicas := reconcile(evicted(time.Now()))
+		Expect(replicas).To(Equal(int32(2)))
+		Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+		Expect(result.RequeueAfter).To(BeNumerically("<=", 10*time.Second))
+		Expect(eas.Status.CoalescingSince).ToNot(BeNil())
+		Expect(eas.Status.LastEviction).To(Equal(myappsv1.Eviction{}))
+		Expect(eas.Status.Conditions).To(ContainElement(HaveField("Reason", "CoalescingEvictions")))
+	})
+
+	It("should size one surge from every displaced pod once the window has passed", func() {
+		eas := evicted(time.Now().Add(-20 * time.Second))
+		since := eas.Spec.LastEviction.EvictionTime
+		eas.Status.CoalescingSince = &since
+		_, eas, replicas := reconcile(eas)
+		Expect(replicas).To(Equal(int32(4)))
+		Expect(eas.Status.CoalescingSince).To(BeNil())
+		Expect(eas.Status.SurgeActive).To(BeTrue())
+	})
+})
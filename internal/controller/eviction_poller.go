@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+)
+
+// EvictionPollReconciler is a fallback for environments where no admission webhook
+// can be installed. Every Interval it looks at each PDB, and if it is blocking
+// disruptions while pods it covers are being deleted from cordoned nodes, it
+// records the most recent deletion as an inferred eviction on the matching
+// EvictionAutoScaler. The regular surge/cooldown/revert path takes over from there.
+type EvictionPollReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Filter   filter
+	Interval time.Duration
+}
+
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=eviction-autoscaler.azure.com,resources=evictionautoscalers,verbs=get;list;watch;update
+
+func (r *EvictionPollReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	var pdb policyv1.PodDisruptionBudget
+	if err := r.Get(ctx, req.NamespacedName, &pdb); err != nil {
+		// Deleted PDBs drop out of the poll.
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+	next := reconcile.Result{RequeueAfter: r.Interval}
+
+	isEnabled, err := autoscalerEnabled(ctx, r.Filter, r.Client, req.NamespacedName)
+	if err != nil {
+		logger.Error(err, "Failed to check if eviction autoscaler is enabled", "namespace", req.Namespace)
+		return reconcile.Result{}, err
+	}
+	if !isEnabled || pdb.Status.DisruptionsAllowed != 0 {
+		return next, nil
+	}
+
+	var eas myappsv1.EvictionAutoScaler
+	if err := r.Get(ctx, req.NamespacedName, &eas); err != nil {
+		return next, client.IgnoreNotFound(err)
+	}
+	// An eviction is already being handled; the surge path will size for it.
+	if eas.Spec.LastEviction != eas.Status.LastEviction {
+		return next, nil
+	}
+
+	onCordoned, err := podsOnCordoned(ctx, r.Client, &pdb)
+	if err != nil {
+		logger.Error(err, "failed to list pods on cordoned nodes", "pdb", pdb.Name)
+		return reconcile.Result{}, err
+	}
+	pod, requested := latestDeletion(onCordoned)
+	if pod == nil || !requested.After(eas.Spec.LastEviction.EvictionTime.Time) {
+		return next, nil
+	}
+
+	logDecision(ctx, decisionEvictionInferred, pdb.Namespace+"/"+pdb.Name, "inferred eviction from pod deletion on cordoned node",
+		"podname", pod.Name, "requested", requested)
+	eas.Spec.LastEviction = myappsv1.Eviction{
+		PodName:      pod.Name,
+		EvictionTime: metav1.NewTime(requested),
+		Inferred:     true,
+	}
+	if err := r.Update(ctx, &eas); err != nil {
+		logger.Error(err, "unable to update EvictionAutoScaler", "name", eas.Name)
+		return reconcile.Result{}, err
+	}
+	return next, nil
+}
+
+// latestDeletion returns the pod whose deletion was requested last, and when. The
+// deletion timestamp is when the pod will be gone; subtracting its grace period
+// gives the time of the eviction or delete call.
+func latestDeletion(pods []corev1.Pod) (*corev1.Pod, time.Time) {
+	var latest *corev1.Pod
+	var requested time.Time
+	for i := range pods {
+		pod := &pods[i]
+		if pod.DeletionTimestamp == nil {
+			continue
+		}
+		at := pod.DeletionTimestamp.Time
+		if pod.DeletionGracePeriodSeconds != nil {
+			at = at.Add(-time.Duration(*pod.DeletionGracePeriodSeconds) * time.Second)
+		}
+		if latest == nil || at.After(requested) {
+			latest, requested = pod, at
+		}
+	}
+	return latest, requested
+}
+
+func (r *EvictionPollReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("eviction-poller").
+		WithOptions(controllerOptions("eviction-poller")).
+		For(&policyv1.PodDisruptionBudget{}).
+		WithEventFilter(shardPredicate(r.Filter)).
+		Complete(traced("eviction-poller", r))
+}
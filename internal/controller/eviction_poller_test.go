@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	v1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/namespacefilter"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("EvictionPollReconciler", func() {
+	var (
+		ctx       context.Context
+		scheme    *runtime.Scheme
+		key       types.NamespacedName
+		requested time.Time
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(policyv1.AddToScheme(scheme)).To(Succeed())
+		Expect(v1.AddToScheme(scheme)).To(Succeed())
+		key = types.NamespacedName{Name: "app", Namespace: "default"}
+		requested = time.Now().Add(-10 * time.Second).Truncate(time.Second)
+	})
+
+	pod := func(name string, deleting bool) *corev1.Pod {
+		p := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": "app"}},
+			Spec:       corev1.PodSpec{NodeName: "node1"},
+		}
+		if deleting {
+			p.DeletionTimestamp = ptr.To(metav1.NewTime(requested.Add(30 * time.Second)))
+			p.DeletionGracePeriodSeconds = ptr.To(int64(30))
+			p.Finalizers = []string{"test/keep"}
+		}
+		return p
+	}
+	objects := func(disruptionsAllowed int32, pods ...client.Object) []client.Object {
+		return append(pods,
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}, Spec: corev1.NodeSpec{Unschedulable: true}},
+			&policyv1.PodDisruptionBudget{
+				ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+				Spec: policyv1.PodDisruptionBudgetSpec{
+					Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "app"}},
+				},
+				Status: policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: disruptionsAllowed},
+			},
+			&v1.EvictionAutoScaler{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"}},
+		)
+	}
+	reconcileWith := func(objs []client.Object) *v1.EvictionAutoScaler {
+		fc := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+		r := &EvictionPollReconciler{Client: fc, Scheme: scheme, Filter: namespacefilter.New(nil, false), Interval: time.Minute}
+		result, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(time.Minute))
+		var eas v1.EvictionAutoScaler
+		Expect(fc.Get(ctx, key, &eas)).To(Succeed())
+		return &eas
+	}
+
+	It("infers an eviction from a pod deleted off a cordoned node", func() {
+		eas := reconcileWith(objects(0, pod("app-1", false), pod("app-2", true)))
+		Expect(eas.Spec.LastEviction.PodName).To(Equal("app-2"))
+		Expect(eas.Spec.LastEviction.EvictionTime.Time).To(BeTemporally("==", requested))
+		Expect(eas.Spec.LastEviction.Inferred).To(BeTrue())
+	})
+
+	It("ignores a blocking PDB when no covered pod is being deleted", func() {
+		eas := reconcileWith(objects(0, pod("app-1", false)))
+		Expect(eas.Spec.LastEviction).To(Equal(v1.Eviction{}))
+	})
+
+	It("ignores a PDB that allows disruptions", func() {
+		eas := reconcileWith(objects(1, pod("app-2", true)))
+		Expect(eas.Spec.LastEviction).To(Equal(v1.Eviction{}))
+	})
+})
@@ -0,0 +1,43 @@
+package controllers
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/metrics"
+)
+
+// evictionTally counts each eviction in eviction_autoscaler_evictions_total once,
+// however many reconciles it takes to handle: deferrals and coalescing requeue the
+// same Spec.LastEviction until it is marked handled. The last eviction counted is
+// kept in memory, so one still unhandled across a restart or failover is counted
+// again by the new leader.
+type evictionTally struct {
+	mu   sync.Mutex
+	seen map[types.NamespacedName]myappsv1.Eviction
+}
+
+// count increments the eviction counter unless eas's last eviction was already counted.
+func (t *evictionTally) count(eas *myappsv1.EvictionAutoScaler) {
+	key := types.NamespacedName{Namespace: eas.Namespace, Name: eas.Name}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if last, ok := t.seen[key]; ok && last == eas.Spec.LastEviction {
+		return
+	}
+	if t.seen == nil {
+		t.seen = map[types.NamespacedName]myappsv1.Eviction{}
+	}
+	t.seen[key] = eas.Spec.LastEviction
+	metrics.EvictionCounter.WithLabelValues(eas.Namespace).Inc()
+}
+
+// forget drops the last eviction counted for a deleted EvictionAutoScaler.
+func (t *evictionTally) forget(key types.NamespacedName) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.seen, key)
+}
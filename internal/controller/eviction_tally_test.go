@@ -0,0 +1,37 @@
+package controllers
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/metrics"
+)
+
+var _ = Describe("Eviction tally", func() {
+	It("should count each eviction once across requeues", func() {
+		eas := &myappsv1.EvictionAutoScaler{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "tally"}}
+		eas.Spec.LastEviction = myappsv1.Eviction{PodName: "web-1", EvictionTime: metav1.NewTime(time.Now())}
+		counter := metrics.EvictionCounter.WithLabelValues("tally")
+		before := testutil.ToFloat64(counter)
+
+		var tally evictionTally
+		tally.count(eas)
+		tally.count(eas)
+		Expect(testutil.ToFloat64(counter)).To(Equal(before + 1))
+
+		eas.Spec.LastEviction = myappsv1.Eviction{PodName: "web-2", EvictionTime: metav1.NewTime(time.Now().Add(time.Second))}
+		tally.count(eas)
+		Expect(testutil.ToFloat64(counter)).To(Equal(before + 2))
+
+		// Recreated under the same name: its eviction is counted anew.
+		tally.forget(types.NamespacedName{Namespace: "tally", Name: "web"})
+		tally.count(eas)
+		Expect(testutil.ToFloat64(counter)).To(Equal(before + 3))
+	})
+})
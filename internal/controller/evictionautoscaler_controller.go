@@ -10,10 +10,17 @@ import (
 	"time"
 
 	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/annotations"
+	"github.com/azure/eviction-autoscaler/internal/decisions"
+	"github.com/azure/eviction-autoscaler/internal/features"
 	"github.com/azure/eviction-autoscaler/internal/metrics"
+	"github.com/azure/eviction-autoscaler/internal/tracing"
 
-	//v1 "k8s.io/api/apps/v1"
-
+	"github.com/samber/lo"
+	"go.opentelemetry.io/otel/attribute"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	policyv1 "k8s.io/api/policy/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -23,14 +30,17 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
-const EvictionSurgeReplicasAnnotationKey = "evictionSurgeReplicas"
-const OriginalMinReplicasAnnotationKey = "eviction-autoscaler.azure.com/original-min-replicas"
+const EvictionSurgeReplicasAnnotationKey = annotations.SurgeReplicas
+const OriginalMinReplicasAnnotationKey = annotations.OriginalMinReplicas
 
 // EvictionAutoScalerReconciler reconciles a EvictionAutoScaler object
 type EvictionAutoScalerReconciler struct {
@@ -38,6 +48,94 @@ type EvictionAutoScalerReconciler struct {
 	Scheme   *runtime.Scheme
 	Recorder record.EventRecorder
 	Filter   filter
+	// EvictionFreshness is how old a new LastEviction may be and still trigger a
+	// surge. Older evictions, e.g. seen after controller downtime, are recorded
+	// without surging. Zero acts on evictions of any age.
+	EvictionFreshness time.Duration
+	// EvictionRetention is how long a handled LastEviction is kept before it is
+	// cleared from spec and status. Zero keeps it forever.
+	EvictionRetention time.Duration
+	// APIReader, when set, re-reads the PDB and the target from the API server
+	// before deciding whether to surge. The cache can lag behind a surge that was
+	// just applied, and acting on it would surge a second time.
+	APIReader client.Reader
+	// Impersonator, when set, routes surge writes in namespaces that name a tenant
+	// service account through a client impersonating it.
+	Impersonator *Impersonator
+	// PlacementHints, when set, records the draining nodes on a surged deployment so
+	// the pod placement webhook keeps surge pods off them.
+	PlacementHints bool
+	// SurgeBatches, when set, records the current wave on a surged deployment so the
+	// pod placement webhook admits its pods gated, for SurgeBatchReconciler to release together.
+	SurgeBatches bool
+	// AutoscalerHints, when set, keeps node autoscalers from scaling down or
+	// consolidating the nodes of a surged deployment's pods while the surge is active.
+	AutoscalerHints bool
+	// DrainTaintKeys are node taints that mark a node as about to be drained, in
+	// addition to a cordon.
+	DrainTaintKeys []string
+	// NodeWarmupTimeout, when set, holds scale-down after a surge while a node that
+	// joined less than this long ago has not finished warming up.
+	NodeWarmupTimeout time.Duration
+	// DrainFailureThreshold is how many consecutive surges may end with their drain
+	// aborted before new surges are suppressed for DrainFailureSuppression. Zero
+	// disables suppression.
+	DrainFailureThreshold   int32
+	DrainFailureSuppression time.Duration
+	// Headroom, when set, defers new surges while cluster headroom is below its
+	// threshold.
+	Headroom *HeadroomGuard
+	// SurgeApproval, when set, publishes each surge as a SurgePlan and only makes it
+	// once the plan is approved, by annotation or after SurgeAutoApproveAfter if
+	// that is non-zero.
+	SurgeApproval         bool
+	SurgeAutoApproveAfter time.Duration
+	// EvictionCoalesceWindow, when set, holds the decision on a new surge this long
+	// after the first unhandled eviction, so evictions arriving back to back during a
+	// drain are sized into one surge. Zero decides on each eviction as it arrives.
+	EvictionCoalesceWindow time.Duration
+	// Decisions, when set, keeps a trace of the last reconcile decisions of each
+	// EvictionAutoScaler.
+	Decisions *decisions.Log
+	// Cooldown is how long a surge is held after the last eviction before it is
+	// reverted. Zero uses the default of one minute.
+	Cooldown time.Duration
+	// HysteresisWindow, when set, doubles the cooldown for each surge of the same
+	// target reverted within the window, up to HysteresisMaxCooldown, so rolling
+	// drains hold one surge instead of surging and reverting node after node.
+	HysteresisWindow      time.Duration
+	HysteresisMaxCooldown time.Duration
+	// UnmarkedSurges, when set, records surges of deployments, statefulsets and
+	// Rollouts only in status, without the evictionSurgeReplicas annotation on the
+	// target, so surging needs no write access to workload metadata.
+	UnmarkedSurges bool
+	// FinishOnShutdown, when set, lets a reconcile in flight when the manager stops
+	// finish its surge or scale-down and record it, instead of failing on the
+	// cancelled context and leaving it to the next leader. The manager's graceful
+	// shutdown timeout bounds how long it may take.
+	FinishOnShutdown bool
+	// SurgePendingDeadline, when set, is how long a pod created by a surge may stay
+	// Pending before the surge is reported as stuck. RollbackStuckSurges then also
+	// reverts the surge instead of holding pods the cluster has no room for.
+	SurgePendingDeadline time.Duration
+	RollbackStuckSurges  bool
+	// TopologyLimitedSurges, when set, limits a surge to the pods its topology
+	// constraints leave room for, and holds it while they leave room for none.
+	// Otherwise a shortfall is only reported.
+	TopologyLimitedSurges bool
+	// StatefulSetSurge, when set, surges StatefulSet targets by their max-surge
+	// annotation. Otherwise they are skipped.
+	StatefulSetSurge bool
+	// Features, when set, narrows surge batching and the capacity pre-check to the
+	// namespaces its runtime feature flags turn them on in.
+	Features *features.Set
+
+	// blockage times how long each PDB blocks an outstanding eviction.
+	blockage blockageClock
+	// surgeSeconds integrates the extra replicas each surge holds over time.
+	surgeSeconds surgeClock
+	// evictions counts each eviction once across the reconciles handling it.
+	evictions evictionTally
 }
 
 const cooldown = 1 * time.Minute
@@ -45,277 +143,1268 @@ const cooldown = 1 * time.Minute
 // +kubebuilder:rbac:groups=eviction-autoscaler.azure.com,resources=evictionautoscalers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=eviction-autoscaler.azure.com,resources=evictionautoscalers/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=eviction-autoscaler.azure.com,resources=evictionautoscalers/finalizers,verbs=update
-// +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=watch;get;list;update
+// +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=watch;get;list;update;patch
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=patch
+// +kubebuilder:rbac:groups=apps,resources=deployments/scale;statefulsets/scale,verbs=get;update;patch
 // +kubebuilder:rbac:groups=core,resources=pods,verbs=watch;get;list
 // +kubebuilder:rbac:groups=core,resources=pods/status,verbs=update
 // +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;update
+// +kubebuilder:rbac:groups=keda.sh,resources=scaledobjects,verbs=get;list;watch;update
+// +kubebuilder:rbac:groups=argoproj.io,resources=rollouts,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=argoproj.io,resources=rollouts/scale,verbs=get;update;patch
+// +kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=impersonate
+// +kubebuilder:rbac:groups=metrics.k8s.io,resources=nodes,verbs=get;list
+// +kubebuilder:rbac:groups=eviction-autoscaler.azure.com,resources=surgeplans,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=eviction-autoscaler.azure.com,resources=surgeplans/status,verbs=get;update;patch
 
+// Reconcile reconciles one EvictionAutoScaler, logs what it decided and, with
+// Decisions set, records what it observed and decided. Decisions that leave the
+// Ready condition as it was are only logged at V(1), so resyncs don't flood the log.
 func (r *EvictionAutoScalerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if r.FinishOnShutdown {
+		ctx = context.WithoutCancel(ctx)
+	}
+	trace := &decisionTrace{}
+	result, err := r.reconcile(context.WithValue(ctx, decisionTraceKey{}, trace), req)
+	if trace.forget {
+		r.Decisions.Forget(req.NamespacedName)
+		return result, err
+	}
+	d := trace.decision(result, err)
+	d.ReconcileID = reconcileID(ctx)
+	oteltrace.SpanFromContext(ctx).SetAttributes(attribute.String("decision", d.Branch), attribute.String("decision.action", d.Action))
+	if d.Branch != "" {
+		logCtx := ctx
+		if err == nil && !trace.changed() {
+			logCtx = log.IntoContext(ctx, log.FromContext(ctx).V(1))
+		}
+		details := []any{"requeueAfter", d.RequeueAfter}
+		if d.Error != "" {
+			details = append(details, "error", d.Error)
+		}
+		logDecision(logCtx, d.Branch, req.String(), d.Action, details...)
+	}
+	r.Decisions.Record(req.NamespacedName, d)
+	return result, err
+}
+
+func (r *EvictionAutoScalerReconciler) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
+	trace := traceFrom(ctx)
 
 	// Fetch the EvictionAutoScaler instance
 	EvictionAutoScaler := &myappsv1.EvictionAutoScaler{}
 	err := r.Get(ctx, req.NamespacedName, EvictionAutoScaler)
 	if err != nil {
-		//should we use a finalizer to scale back down on deletion?
 		if apierrors.IsNotFound(err) {
+			if trace != nil {
+				trace.forget = true
+			}
+			r.blockage.forget(req.NamespacedName)
+			r.surgeSeconds.forget(req.NamespacedName)
+			r.evictions.forget(req.NamespacedName)
+			metrics.ForgetPDB(req.Namespace, req.Name)
 			return ctrl.Result{}, nil // EvictionAutoScaler not found, could be deleted, nothing to do
 		}
 		return ctrl.Result{}, err // Error fetching EvictionAutoScaler
 	}
 	EvictionAutoScaler = EvictionAutoScaler.DeepCopy() //don't mutate the cache
+	trace.observe(EvictionAutoScaler)
 
-	// Check if eviction autoscaler should be enabled for this namespace
-	isEnabled, err := r.Filter.Filter(ctx, r.Client, EvictionAutoScaler.Namespace)
-	if err != nil {
-		logger.Error(err, "Failed to check if eviction autoscaler is enabled", "namespace", EvictionAutoScaler.Namespace)
-		return ctrl.Result{}, err
+	// Deleted mid-surge: scale the target back before letting it go, even if the
+	// namespace has since been disabled.
+	if !EvictionAutoScaler.DeletionTimestamp.IsZero() {
+		trace.note("Finalizing", "deleted, reverting any surge before removing the finalizer")
+		r.surgeSeconds.forget(req.NamespacedName)
+		metrics.ForgetTarget(EvictionAutoScaler.Namespace, EvictionAutoScaler.Spec.TargetName)
+		return ctrl.Result{}, r.finalize(ctx, EvictionAutoScaler)
 	}
-	if !isEnabled {
-		logger.V(1).Info("Eviction autoscaler not enabled for namespace", "namespace", EvictionAutoScaler.Namespace)
-		// Don't process evictions for namespaces without the annotation
-		return ctrl.Result{}, nil
+
+	pdb, stop, err := r.resolvePDB(ctx, EvictionAutoScaler)
+	if stop != nil || err != nil {
+		return lo.FromPtr(stop), err
+	}
+	target, stop, err := r.fetchTarget(ctx, EvictionAutoScaler, pdb)
+	if stop != nil || err != nil {
+		return lo.FromPtr(stop), err
 	}
 
-	// Fetch the PDB using a 1:1 name mapping
-	pdb := &policyv1.PodDisruptionBudget{}
-	err = r.Get(ctx, types.NamespacedName{Name: EvictionAutoScaler.Name, Namespace: EvictionAutoScaler.Namespace}, pdb)
-	if err != nil {
-		if apierrors.IsNotFound(err) {
-			degraded(&EvictionAutoScaler.Status.Conditions, "NoPdb", "PDB of same name not found")
-			logger.Error(err, "no matching pdb", "namespace", EvictionAutoScaler.Namespace, "name", EvictionAutoScaler.Name)
-			return ctrl.Result{}, r.Status().Update(ctx, EvictionAutoScaler)
+	// TODO: Move PDB configuration tracking to PDB controller with aggregate labels
+	// Consider tracking: maxUnavailable==0 and minAvailable==replicas as PDBGauge labels
+
+	writer := client.Client(r.Client)
+	if r.Impersonator != nil {
+		writer, err = r.Impersonator.ClientFor(ctx, r.Client, EvictionAutoScaler.Namespace)
+		if err != nil {
+			logger.Error(err, "failed to resolve impersonated client", "namespace", EvictionAutoScaler.Namespace)
+			return ctrl.Result{}, err
 		}
-		return ctrl.Result{}, err
 	}
 
-	if EvictionAutoScaler.Spec.TargetName == "" {
-		degraded(&EvictionAutoScaler.Status.Conditions, "EmptyTarget", "no specified target")
-		logger.Error(err, "no specified target name", "targetname", EvictionAutoScaler.Spec.TargetName)
-		return ctrl.Result{}, r.Status().Update(ctx, EvictionAutoScaler)
+	gates := r.featureGates(ctx, EvictionAutoScaler)
+	surgeApplier, err := r.surgeApplier(ctx, EvictionAutoScaler, writer, target, gates)
+	if err != nil {
+		return r.surgeStrategyFailed(ctx, EvictionAutoScaler, err)
 	}
 
-	// StatefulSets are intentionally skipped — their ordered pod management
-	// semantics conflict with the eviction surge strategy.
-	if strings.EqualFold(EvictionAutoScaler.Spec.TargetKind, statefulSetKind) {
-		logger.V(1).Info("skipping StatefulSet target, not supported for eviction surge",
-			"targetname", EvictionAutoScaler.Spec.TargetName)
-		return ctrl.Result{}, nil
+	surgeHeld, surgedTo, stop, err := r.syncSurge(ctx, EvictionAutoScaler, writer, target, surgeApplier)
+	if stop != nil || err != nil {
+		return lo.FromPtr(stop), err
+	}
+	if stop, err := r.syncTargetGeneration(ctx, EvictionAutoScaler, target, surgeApplier, surgedTo); stop != nil || err != nil {
+		return lo.FromPtr(stop), err
 	}
 
-	// Fetch the Deployment target
-	// TODO enum validation https://book.kubebuilder.io/reference/generating-crd#validation
-	target, err := GetSurger(EvictionAutoScaler.Spec.TargetKind)
+	// Surge opted out on the workload or its namespace: no replica changes at all,
+	// not even for an emergency override.
+	disabled, err := surgeDisabled(ctx, r.Client, target)
 	if err != nil {
-		logger.Error(err, "invalid target kind", "kind", EvictionAutoScaler.Spec.TargetKind)
-		degraded(&EvictionAutoScaler.Status.Conditions, "InvalidTarget", "Invalid Target Kind: "+EvictionAutoScaler.Spec.TargetKind)
+		logger.Error(err, "invalid surge annotation", "targetname", EvictionAutoScaler.Spec.TargetName)
+		degraded(EvictionAutoScaler, "InvalidSurgeAnnotation", err.Error())
 		return ctrl.Result{}, r.Status().Update(ctx, EvictionAutoScaler)
 	}
-	err = r.Get(ctx, types.NamespacedName{Name: EvictionAutoScaler.Spec.TargetName, Namespace: EvictionAutoScaler.Namespace}, target.Obj())
-	if err != nil {
-		if apierrors.IsNotFound(err) {
-			logger.Error(err, "pdb watcher target does not exist", "kind", EvictionAutoScaler.Spec.TargetKind, "targetname", EvictionAutoScaler.Spec.TargetName)
-			degraded(&EvictionAutoScaler.Status.Conditions, "MissingTarget", "Misssing  Target "+EvictionAutoScaler.Spec.TargetName)
-			return ctrl.Result{}, r.Status().Update(ctx, EvictionAutoScaler)
-		}
-		return ctrl.Result{}, err
-	}
 
-	// TODO: Move PDB configuration tracking to PDB controller with aggregate labels
-	// Consider tracking: maxUnavailable==0 and minAvailable==replicas as PDBGauge labels
+	if stop, err := r.syncSurgeConditions(ctx, EvictionAutoScaler, pdb, target, surgeApplier, disabled); stop != nil || err != nil {
+		return lo.FromPtr(stop), err
+	}
 
-	// Detect surge strategy based on KEDA, HPA, or plain deployment
-	surgeApplier, err := detectSurgeApplier(ctx, r.Client, EvictionAutoScaler.Namespace, EvictionAutoScaler.Spec.TargetName, EvictionAutoScaler.Spec.TargetKind, target)
-	if err != nil {
-		if errors.Is(err, errUnsupportedAutoscalerConfig) {
-			logger.Error(err, "unsupported autoscaler configuration, not requeueing")
-			degraded(&EvictionAutoScaler.Status.Conditions, "UnsupportedAutoscalerConfiguration", err.Error())
-			return ctrl.Result{}, r.Status().Update(ctx, EvictionAutoScaler)
-		}
-		logger.Error(err, "failed to detect surge strategy")
-		return ctrl.Result{}, err
+	// Keep SurgeDeferred current so it clears once the cluster has room again.
+	alreadyDeferred := surgeWasDeferred(EvictionAutoScaler)
+	headroom := r.Headroom
+	if !gates.capacityPrecheck {
+		headroom = nil
 	}
+	deferred := headroom.surgeDeferred(ctx, EvictionAutoScaler)
 
-	// Check if the resource version has changed or if it's empty (initial state)
-	if EvictionAutoScaler.Status.TargetGeneration == 0 || EvictionAutoScaler.Status.TargetGeneration != target.Obj().GetGeneration() {
-		EvictionAutoScaler.Status.TargetGeneration = target.Obj().GetGeneration()
-		// Don't reset MinReplicas if a surge is in progress (e.g., HPA/KEDA-driven scaling
-		// changes the deployment generation as part of the surge, not a user change).
-		if surgeApplier.IsSurgeActive() {
-			logger.Info("Target generation changed during active surge, preserving min replicas", "kind", EvictionAutoScaler.Spec.TargetKind, "targetname", EvictionAutoScaler.Spec.TargetName, "currentGeneration", target.Obj().GetGeneration(), "previousGeneration", EvictionAutoScaler.Status.TargetGeneration, "minReplicas", EvictionAutoScaler.Status.MinReplicas)
-		} else {
-			logger.Info("Target resource version changed resetting min replicas", "kind", EvictionAutoScaler.Spec.TargetKind, "targetname", EvictionAutoScaler.Spec.TargetName, "currentGeneration", target.Obj().GetGeneration(), "previousGeneration", EvictionAutoScaler.Status.TargetGeneration)
-			// The resource version has changed, which means someone else has modified the Target.
-			// To avoid conflicts, we update our status to reflect the new state and avoid making further changes.
-			// Use ResolveMinReplicas to track the effective floor (HPA minReplicas, KEDA minReplicaCount, or deployment replicas).
-			minReplicas, _, resolveErr := ResolveMinReplicas(ctx, r.Client, EvictionAutoScaler.Namespace, EvictionAutoScaler.Spec.TargetName, EvictionAutoScaler.Spec.TargetKind, target.GetReplicas())
-			if resolveErr != nil {
-				return ctrl.Result{}, resolveErr
-			}
-			EvictionAutoScaler.Status.MinReplicas = minReplicas
-		}
-		ready(&EvictionAutoScaler.Status.Conditions, "TargetSpecChange", fmt.Sprintf("resetting min replicas to %d", EvictionAutoScaler.Status.MinReplicas))
-		return ctrl.Result{}, r.Status().Update(ctx, EvictionAutoScaler) //should we go rety in case there is also an eviction or just wait till the next eviction
+	if stop, err := r.emergencySurge(ctx, EvictionAutoScaler, pdb, target, surgeApplier, disabled); stop != nil || err != nil {
+		return lo.FromPtr(stop), err
 	}
 
 	// Log current state before checks
 	logger.Info(fmt.Sprintf("Checking PDB for %s: DisruptionsAllowed=%d, MinReplicas=%d", pdb.Name, pdb.Status.DisruptionsAllowed, EvictionAutoScaler.Status.MinReplicas))
 
+	// Clear a handled eviction once it is past retention so it doesn't linger in spec.
+	// Spec is cleared first; a zero spec eviction counts as handled below, so a crash
+	// before the status write can't be mistaken for a new eviction.
+	if lastEvictionExpired(EvictionAutoScaler, r.EvictionRetention, time.Now()) {
+		logger.Info("Clearing handled eviction past retention", "lastEviction", EvictionAutoScaler.Spec.LastEviction, "retention", r.EvictionRetention)
+		EvictionAutoScaler.Spec.LastEviction = myappsv1.Eviction{}
+		if err := r.Update(ctx, EvictionAutoScaler); err != nil {
+			logger.Error(err, "unable to clear LastEviction", "name", EvictionAutoScaler.Name)
+			return ctrl.Result{}, err
+		}
+	}
+
+	r.blockage.observe(EvictionAutoScaler, pdb.Status.DisruptionsAllowed, time.Now())
+	r.surgeSeconds.observe(EvictionAutoScaler, time.Now())
+
+	// Drops an expired suppression window along with its condition.
+	suppressed := suppressionRemaining(&EvictionAutoScaler.Status, time.Now())
+
 	// Have we processed all evictions okay don't do anything else
-	if EvictionAutoScaler.Spec.LastEviction == EvictionAutoScaler.Status.LastEviction {
-		logger.Info("No unhandled eviction ", "pdbname", pdb.Name)
-		ready(&EvictionAutoScaler.Status.Conditions, "Reconciled", "no unhandled eviction")
-		return ctrl.Result{}, r.Status().Update(ctx, EvictionAutoScaler)
+	if EvictionAutoScaler.Spec.LastEviction == EvictionAutoScaler.Status.LastEviction || EvictionAutoScaler.Spec.LastEviction.EvictionTime.IsZero() {
+		return r.noUnhandledEviction(ctx, EvictionAutoScaler, pdb, target, surgeApplier, surgeHeld, suppressed)
 	}
 
 	// Last eviction already tracked above so we can just log it
 	logger.V(1).Info("Detected new eviction",
 		"podName", EvictionAutoScaler.Spec.LastEviction.PodName,
 		"evictionTime", EvictionAutoScaler.Spec.LastEviction.EvictionTime)
-	metrics.EvictionCounter.WithLabelValues(EvictionAutoScaler.Namespace).Inc()
+	r.evictions.count(EvictionAutoScaler)
+
+	if !surgeHeld {
+		if stop, err := r.holdNewSurge(ctx, EvictionAutoScaler, pdb, disabled, suppressed, deferred, alreadyDeferred); stop != nil || err != nil {
+			return lo.FromPtr(stop), err
+		}
+	}
+
+	// Everything below sizes a surge from the PDB's allowed disruptions and the
+	// target's replicas, so read those live rather than from the cache.
+	if err := r.refreshForSurge(ctx, pdb, target); err != nil {
+		logger.Error(err, "failed to read PDB and target from the API server", "pdb", pdb.Name, "targetname", EvictionAutoScaler.Spec.TargetName)
+		return ctrl.Result{}, err
+	}
+	if !surgeHeld && targetChanged(EvictionAutoScaler, target) {
+		// Someone changed the target since the cached copy; let the generation check
+		// above reset MinReplicas once the cache has caught up.
+		logger.Info("Target changed since the cached copy, requeueing before surging", "targetname", EvictionAutoScaler.Spec.TargetName)
+		trace.note("TargetChanged", "target changed since the cached copy, requeueing before surging")
+		return ctrl.Result{RequeueAfter: time.Second}, nil
+	}
+
+	// Persist the cooldown deadline so a new leader resumes the same clock.
+	surgeCooldown := r.surgeCooldown(&EvictionAutoScaler.Status, time.Now())
+	trace.input("cooldown", surgeCooldown.String())
+	deadline := metav1.NewTime(EvictionAutoScaler.Spec.LastEviction.EvictionTime.Add(surgeCooldown))
+	EvictionAutoScaler.Status.CooldownUntil = &deadline
 
 	// surgeTarget = minReplicas + displaced, capped at minReplicas + maxSurge.
 	// If displaced == 0 the formula yields minReplicas, so no scale-up fires and
 	// we fall through to the cooldown/scale-down path — which is correct.
-	maxSurgeTarget, surgeErr := calculateSurge(ctx, target, EvictionAutoScaler.Status.MinReplicas)
+	maxSurgeTarget, surgeErr := calculateSurge(ctx, target, scaleDownFloor(EvictionAutoScaler))
 	if surgeErr != nil {
 		switch {
 		case errors.Is(surgeErr, errMaxSurgeZero):
 			// maxSurge is 0 (explicit or not configured) — can't surge, degrade.
-			degraded(&EvictionAutoScaler.Status.Conditions, "UnsupportedAutoscalerConfiguration", surgeErr.Error())
+			degraded(EvictionAutoScaler, "UnsupportedAutoscalerConfiguration", surgeErr.Error())
 			return ctrl.Result{}, r.Status().Update(ctx, EvictionAutoScaler)
 		default:
 			// Parse error or unexpected — degrade.
-			degraded(&EvictionAutoScaler.Status.Conditions, "InvalidSurgeConfiguration", surgeErr.Error())
+			degraded(EvictionAutoScaler, "InvalidSurgeConfiguration", surgeErr.Error())
 			return ctrl.Result{}, r.Status().Update(ctx, EvictionAutoScaler)
 		}
-	} else if pdb.Status.DisruptionsAllowed == 0 {
-		displaced, countErr := countPodsOnCordoned(ctx, r.Client, pdb)
-		if countErr != nil {
-			logger.Error(countErr, "failed to count displaced pods on cordoned nodes")
-			return ctrl.Result{}, countErr
+	}
+	if pdb.Status.DisruptionsAllowed == 0 {
+		return r.surge(ctx, EvictionAutoScaler, pdb, target, surgeApplier, maxSurgeTarget, surgeHeld, gates.capacityPrecheck)
+	}
+
+	return r.scaleDown(ctx, EvictionAutoScaler, target, surgeApplier)
+}
+
+// The steps of reconcile below return a non-nil result, or an error, when the
+// reconcile ends there; reconcile then returns them as they are.
+
+// resolvePDB reads the PDB of eas and checks that eviction autoscaler can act on
+// it: the namespace is enabled, the target is named in eas's own namespace and the
+// PDB overlaps no other. Otherwise it records why on eas, or rewrites a namespaced
+// targetName.
+func (r *EvictionAutoScalerReconciler) resolvePDB(ctx context.Context, eas *myappsv1.EvictionAutoScaler) (*policyv1.PodDisruptionBudget, *ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	trace := traceFrom(ctx)
+
+	// Check if eviction autoscaler should be enabled for this namespace
+	isEnabled, err := autoscalerEnabled(ctx, r.Filter, r.Client, client.ObjectKeyFromObject(eas))
+	if err != nil {
+		logger.Error(err, "Failed to check if eviction autoscaler is enabled", "namespace", eas.Namespace)
+		return nil, nil, err
+	}
+	if !isEnabled {
+		logger.V(1).Info("Eviction autoscaler not enabled for namespace", "namespace", eas.Namespace)
+		trace.note("NamespaceDisabled", "eviction autoscaler is not enabled for the namespace")
+		// Don't process evictions for namespaces without the annotation
+		return nil, &ctrl.Result{}, nil
+	}
+
+	// Fetch the PDB using a 1:1 name mapping
+	pdb := &policyv1.PodDisruptionBudget{}
+	err = r.Get(ctx, types.NamespacedName{Name: eas.Name, Namespace: eas.Namespace}, pdb)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			// Without an owner reference nothing else deletes it with its PDB.
+			if deleted, err := collectOrphan(ctx, r.Client, eas); deleted || err != nil {
+				return nil, &ctrl.Result{}, err
+			}
+			degraded(eas, "NoPdb", "PDB of same name not found")
+			logger.Error(err, "no matching pdb", "namespace", eas.Namespace, "name", eas.Name)
+			return nil, &ctrl.Result{}, r.Status().Update(ctx, eas)
 		}
+		return nil, nil, err
+	}
+	trace.input("pdb.disruptionsAllowed", strconv.Itoa(int(pdb.Status.DisruptionsAllowed)))
+
+	if eas.Spec.TargetName == "" {
+		degraded(eas, "EmptyTarget", "no specified target")
+		logger.Error(err, "no specified target name", "targetname", eas.Spec.TargetName)
+		return nil, &ctrl.Result{}, r.Status().Update(ctx, eas)
+	}
 
-		surgeTarget := EvictionAutoScaler.Status.MinReplicas + displaced
-		if surgeTarget > maxSurgeTarget {
-			logger.Info("Displaced pods exceed maxSurge capacity, capping surge", "pdb", pdb.Name, "displaced", displaced, "maxSurgeTarget", maxSurgeTarget)
-			surgeTarget = maxSurgeTarget
+	// Targets are looked up in the EvictionAutoScaler's namespace. A namespace/name
+	// naming that namespace is rewritten to the bare name; any other namespace is
+	// rejected outright rather than reported as a missing target.
+	if namespace, name, ok := myappsv1.SplitTargetName(eas.Spec.TargetName); ok {
+		if namespace != eas.Namespace {
+			msg := fmt.Sprintf("target %s is in another namespace: an EvictionAutoScaler can only target workloads in its own namespace %s",
+				eas.Spec.TargetName, eas.Namespace)
+			logger.Info("Cross-namespace target, not surging", "targetname", eas.Spec.TargetName)
+			if c := meta.FindStatusCondition(eas.Status.Conditions, DegradedCondition); c == nil || c.Reason != "CrossNamespaceTarget" {
+				r.event(eas, corev1.EventTypeWarning, "CrossNamespaceTarget", msg)
+			}
+			degraded(eas, "CrossNamespaceTarget", msg)
+			return nil, &ctrl.Result{}, r.Status().Update(ctx, eas)
+		}
+		logger.Info("Dropping own namespace from targetName", "from", eas.Spec.TargetName, "to", name)
+		r.event(eas, corev1.EventTypeNormal, "TargetNameConverted",
+			fmt.Sprintf("targetName %s rewritten to %s", eas.Spec.TargetName, name))
+		trace.note("TargetNameConverted", "dropped own namespace from targetName")
+		eas.Spec.TargetName = name
+		// The update triggers the next reconcile with the converted name.
+		return nil, &ctrl.Result{}, r.Update(ctx, eas)
+	}
+
+	// The eviction API refuses pods covered by more than one PDB, so surging can't help.
+	overlapping, err := overlappingPDBs(ctx, r.Client, pdb)
+	if err != nil {
+		logger.Error(err, "failed to check for overlapping PDBs", "pdb", pdb.Name)
+		return nil, nil, err
+	}
+	if len(overlapping) > 0 {
+		msg := fmt.Sprintf("pods selected by PDB %s are also selected by %s", pdb.Name, strings.Join(overlapping, ", "))
+		logger.Info("Ambiguous PDB, not surging", "pdb", pdb.Name, "overlapping", overlapping)
+		if c := meta.FindStatusCondition(eas.Status.Conditions, DegradedCondition); c == nil || c.Reason != "AmbiguousPDB" {
+			r.event(eas, corev1.EventTypeWarning, "AmbiguousPDB", msg)
+		}
+		degraded(eas, "AmbiguousPDB", msg)
+		return nil, &ctrl.Result{}, r.Status().Update(ctx, eas)
+	}
+	return pdb, nil, nil
+}
+
+// fetchTarget reads the target of eas, checking that its kind is known and
+// surged and that neither it nor pdb is excluded. Otherwise it records why on eas.
+func (r *EvictionAutoScalerReconciler) fetchTarget(ctx context.Context, eas *myappsv1.EvictionAutoScaler, pdb *policyv1.PodDisruptionBudget) (Surger, *ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	trace := traceFrom(ctx)
+
+	// The webhook normalizes targetKind on admission, but objects created without it
+	// may still carry another casing or an alias.
+	targetKind, err := myappsv1.NormalizeTargetKind(eas.Spec.TargetKind)
+	if err != nil {
+		logger.Error(err, "invalid target kind", "kind", eas.Spec.TargetKind)
+		degraded(eas, "InvalidTarget", err.Error())
+		return nil, &ctrl.Result{}, r.Status().Update(ctx, eas)
+	}
+	eas.Spec.TargetKind = targetKind
+
+	// StatefulSets are skipped unless StatefulSetSurge is set: a surge pod is a new
+	// ordinal, with its own PVCs and DNS name.
+	if eas.Spec.TargetKind == statefulSetKind && !r.StatefulSetSurge {
+		logger.V(1).Info("skipping StatefulSet target, StatefulSet surges are off",
+			"targetname", eas.Spec.TargetName)
+		trace.note("StatefulSetSkipped", "StatefulSet targets are not surged")
+		return nil, &ctrl.Result{}, nil
+	}
+
+	// Fetch the Deployment target
+	target, err := GetSurger(eas.Spec.TargetKind)
+	if err != nil {
+		logger.Error(err, "invalid target kind", "kind", eas.Spec.TargetKind)
+		degraded(eas, "InvalidTarget", "Invalid Target Kind: "+string(eas.Spec.TargetKind))
+		return nil, &ctrl.Result{}, r.Status().Update(ctx, eas)
+	}
+	fetchCtx, span := tracing.Start(ctx, "FetchTarget", targetAttributes(eas)...)
+	err = r.Get(fetchCtx, types.NamespacedName{Name: eas.Spec.TargetName, Namespace: eas.Namespace}, target.Obj())
+	tracing.End(span, err)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.Error(err, "pdb watcher target does not exist", "kind", eas.Spec.TargetKind, "targetname", eas.Spec.TargetName)
+			degraded(eas, "MissingTarget", "Misssing  Target "+eas.Spec.TargetName)
+			return nil, &ctrl.Result{}, r.Status().Update(ctx, eas)
 		}
+		// e.g. targetKind rollout on a cluster without the Argo Rollouts CRD
+		if meta.IsNoMatchError(err) {
+			logger.Error(err, "target kind not served by the cluster", "kind", eas.Spec.TargetKind)
+			degraded(eas, "InvalidTarget", "Target Kind not installed: "+string(eas.Spec.TargetKind))
+			return nil, &ctrl.Result{}, r.Status().Update(ctx, eas)
+		}
+		return nil, nil, err
+	}
+
+	// Excluded by its owner, like a disabled namespace: PDBToEvictionAutoScalerReconciler
+	// deletes this EvictionAutoScaler, and the finalizer reverts any surge in flight.
+	for _, obj := range []client.Object{pdb, target.Obj()} {
+		if exclude, reason := excluded(obj); exclude {
+			logger.V(1).Info("Excluded from eviction autoscaler", "name", obj.GetName(), "reason", reason)
+			trace.note("Excluded", obj.GetName()+" is excluded: "+reason)
+			return nil, &ctrl.Result{}, nil
+		}
+	}
+	return target, nil, nil
+}
+
+// featureGates are the runtime feature flags as they apply to one EvictionAutoScaler.
+type featureGates struct {
+	surgeBatches     bool
+	capacityPrecheck bool
+}
+
+// featureGates reads the runtime feature flags for the namespace of eas and
+// records them on the decision trace.
+func (r *EvictionAutoScalerReconciler) featureGates(ctx context.Context, eas *myappsv1.EvictionAutoScaler) featureGates {
+	gates := featureGates{
+		surgeBatches:     r.SurgeBatches && r.Features.Enabled(features.SurgeBatches, eas.Namespace),
+		capacityPrecheck: r.Features.Enabled(features.CapacityPrecheck, eas.Namespace),
+	}
+	trace := traceFrom(ctx)
+	trace.input("feature."+string(features.SurgeBatches), strconv.FormatBool(gates.surgeBatches))
+	trace.input("feature."+string(features.CapacityPrecheck), strconv.FormatBool(gates.capacityPrecheck))
+	return gates
+}
+
+// surgeApplier picks the surge strategy for target from spec.surgeMode, detecting
+// KEDA, HPA, or plain deployment by default, and wraps it with the surge hints
+// the reconciler and gates turn on.
+func (r *EvictionAutoScalerReconciler) surgeApplier(ctx context.Context, eas *myappsv1.EvictionAutoScaler, writer client.Client, target Surger, gates featureGates) (SurgeApplier, error) {
+	surgeApplier, err := surgeApplierFor(ctx, writer, eas.Spec.SurgeMode, eas.Namespace, eas.Spec.TargetName, string(eas.Spec.TargetKind), target)
+	if err != nil {
+		return nil, err
+	}
+	withScaleDownFloor(surgeApplier, eas.Spec.MinReplicas)
+	if r.UnmarkedSurges {
+		withoutSurgeMarker(surgeApplier, surgeRecorded(&eas.Status, target))
+	}
+	if r.PlacementHints && eas.Spec.TargetKind == deploymentKind {
+		surgeApplier = &placementHintApplier{SurgeApplier: surgeApplier, reader: r.Client, writer: writer, target: target, drainTaints: r.DrainTaintKeys}
+	}
+	if eas.Spec.SurgePriorityClassName != "" && eas.Spec.TargetKind == deploymentKind {
+		surgeApplier = &surgePriorityApplier{SurgeApplier: surgeApplier, writer: writer, target: target, priorityClass: eas.Spec.SurgePriorityClassName}
+	}
+	if gates.surgeBatches && eas.Spec.TargetKind == deploymentKind {
+		surgeApplier = &surgeBatchApplier{SurgeApplier: surgeApplier, writer: writer, target: target}
+	}
+	if r.AutoscalerHints && eas.Spec.TargetKind == deploymentKind {
+		surgeApplier = &autoscalerHintApplier{SurgeApplier: surgeApplier, reader: r.Client, writer: writer, target: target, drainTaints: r.DrainTaintKeys}
+	}
+	return surgeApplier, nil
+}
+
+// surgeStrategyFailed ends a reconcile whose surge strategy couldn't be picked. A
+// configuration only the user can fix is reported on eas and not requeued.
+func (r *EvictionAutoScalerReconciler) surgeStrategyFailed(ctx context.Context, eas *myappsv1.EvictionAutoScaler, err error) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	if errors.Is(err, errUnsupportedAutoscalerConfig) {
+		logger.Error(err, "unsupported autoscaler configuration, not requeueing")
+		degraded(eas, "UnsupportedAutoscalerConfiguration", err.Error())
+		return ctrl.Result{}, r.Status().Update(ctx, eas)
+	}
+	if errors.Is(err, errInvalidSurgeMode) {
+		logger.Error(err, "invalid surge mode, not requeueing")
+		degraded(eas, "InvalidSurgeMode", err.Error())
+		return ctrl.Result{}, r.Status().Update(ctx, eas)
+	}
+	logger.Error(err, "failed to detect surge strategy")
+	return ctrl.Result{}, err
+}
+
+// syncSurge reports whether target still holds a surge and the surge count status
+// recorded. Without one it clears the surge from status along with the hints,
+// finalizer and priority class that must not outlive it, and parks eas while its
+// target is scaled to zero.
+func (r *EvictionAutoScalerReconciler) syncSurge(ctx context.Context, eas *myappsv1.EvictionAutoScaler, writer client.Client, target Surger, surgeApplier SurgeApplier) (bool, int32, *ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	trace := traceFrom(ctx)
+
+	// Status is the record of a surge: one the target still holds survives its marker
+	// going missing, and is reverted from status as usual. Otherwise keep status
+	// honest if the surge was reverted outside the controller.
+	surgedTo := eas.Status.SurgeReplicas
+	surgeHeld := surgeApplier.IsSurgeActive()
+	trace.input("target.replicas", strconv.Itoa(int(target.GetReplicas())))
+	trace.input("surgeStrategy", surgeApplier.Name())
+	if !surgeHeld && eas.Status.SurgeActive && scaledBySurge(&eas.Status, target, surgedTo) {
+		logger.Info("Surge marker missing from target, recovering surge from status", "targetname", eas.Spec.TargetName, "surgeReplicas", surgedTo, "minReplicas", eas.Status.MinReplicas)
+		surgeHeld = true
+	}
+	trace.input("surgeHeld", strconv.FormatBool(surgeHeld))
+	if surgeHeld {
+		return true, surgedTo, nil, nil
+	}
+
+	clearSurge(&eas.Status)
+	// Surge hints must never outlive their surge, or pods would keep avoiding nodes
+	// that were uncordoned since and keep preempting other workloads.
+	if err := clearAvoidNodes(ctx, writer, target); err != nil {
+		logger.Error(err, "failed to clear placement hints", "targetname", eas.Spec.TargetName)
+		return false, surgedTo, nil, err
+	}
+	if err := clearSurgePriorityClass(ctx, writer, target); err != nil {
+		logger.Error(err, "failed to clear surge priority class", "targetname", eas.Spec.TargetName)
+		return false, surgedTo, nil, err
+	}
+	if err := clearSurgeBatch(ctx, writer, target); err != nil {
+		logger.Error(err, "failed to clear surge batch", "targetname", eas.Spec.TargetName)
+		return false, surgedTo, nil, err
+	}
+	if err := r.removeSurgeFinalizer(ctx, eas); err != nil {
+		logger.Error(err, "failed to remove surge finalizer", "name", eas.Name)
+		return false, surgedTo, nil, err
+	}
 
-		if target.GetReplicas() >= surgeTarget {
-			//we've scaled up but pdb is still blockign may just be waiting for new pods to become ready
-			logger.Info("Have already scaled up to handle evictions, waiting for PDB to allow disruptions before reverting",
-				"pdb", pdb.Name,
-				"target", EvictionAutoScaler.Spec.TargetName)
-			ready(&EvictionAutoScaler.Status.Conditions, "Reconciled", "Have already scaled up to handle evictions, waiting for PDB to allow disruptions before reverting")
-			return ctrl.Result{RequeueAfter: cooldown}, r.Status().Update(ctx, EvictionAutoScaler)
+	// Scaled to zero by its owner (e.g. KEDA): there are no pods for the PDB to
+	// protect, so park instead of recording zero as the new floor. TargetGeneration
+	// is left alone so scaling back up resets MinReplicas from the new spec.
+	dormant, err := targetDormant(ctx, r.Client, eas.Namespace, eas.Spec.TargetName, eas.Spec.TargetKind, target)
+	if err != nil {
+		logger.Error(err, "failed to check whether target is scaled to zero", "targetname", eas.Spec.TargetName)
+		return false, surgedTo, nil, err
+	}
+	if dormant {
+		logger.V(1).Info("Target scaled to zero, dormant until it scales up", "kind", eas.Spec.TargetKind, "targetname", eas.Spec.TargetName)
+		eas.Status.LastEviction = eas.Spec.LastEviction
+		eas.Status.CooldownUntil = nil
+		ready(eas, "Dormant", "target scaled to zero, waiting for it to scale up")
+		return false, surgedTo, &ctrl.Result{}, r.Status().Update(ctx, eas)
+	}
+	return false, surgedTo, nil, nil
+}
+
+// syncTargetGeneration records a new generation of target. A change made by a
+// surge keeps MinReplicas; any other resets it to the target's effective floor and
+// ends the reconcile.
+func (r *EvictionAutoScalerReconciler) syncTargetGeneration(ctx context.Context, eas *myappsv1.EvictionAutoScaler, target Surger, surgeApplier SurgeApplier, surgedTo int32) (*ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	// Check if the resource version has changed or if it's empty (initial state)
+	if !targetChanged(eas, target) {
+		return nil, nil
+	}
+	// Don't reset MinReplicas if a surge is in progress: the /scale write that applied
+	// it (or HPA/KEDA-driven scaling) bumps the generation after we recorded it, so
+	// the change is ours. Record the new generation and keep handling the eviction
+	// so a top-up isn't lost.
+	if surgeApplier.IsSurgeActive() {
+		logger.Info("Target generation changed during active surge, preserving min replicas", "kind", eas.Spec.TargetKind, "targetname", eas.Spec.TargetName, "currentGeneration", target.Obj().GetGeneration(), "previousGeneration", eas.Status.TargetGeneration, "minReplicas", eas.Status.MinReplicas)
+		recordTarget(&eas.Status, target)
+		return nil, nil
+	}
+	if scaledBySurge(&eas.Status, target, surgedTo) {
+		// Still at the surge count with the rest of the spec untouched: the surge
+		// marker went missing, not the owner's intent. Scale-down reverts it as usual.
+		logger.Info("Target generation changed only by an earlier surge, preserving min replicas", "kind", eas.Spec.TargetKind, "targetname", eas.Spec.TargetName, "replicas", surgedTo, "minReplicas", eas.Status.MinReplicas)
+		recordTarget(&eas.Status, target)
+		return nil, nil
+	}
+	logger.Info("Target resource version changed resetting min replicas", "kind", eas.Spec.TargetKind, "targetname", eas.Spec.TargetName, "currentGeneration", target.Obj().GetGeneration(), "previousGeneration", eas.Status.TargetGeneration)
+	recordTarget(&eas.Status, target)
+	// The resource version has changed, which means someone else has modified the Target.
+	// To avoid conflicts, we update our status to reflect the new state and avoid making further changes.
+	// Use ResolveMinReplicas to track the effective floor (HPA minReplicas, KEDA minReplicaCount, or deployment replicas).
+	minReplicas, _, resolveErr := ResolveMinReplicas(ctx, r.Client, eas.Namespace, eas.Spec.TargetName, string(eas.Spec.TargetKind), target.GetReplicas())
+	if resolveErr != nil {
+		return nil, resolveErr
+	}
+	eas.Status.MinReplicas = minReplicas
+	ready(eas, "TargetSpecChange", fmt.Sprintf("resetting min replicas to %d", eas.Status.MinReplicas))
+	return &ctrl.Result{}, r.Status().Update(ctx, eas) //should we go rety in case there is also an eviction or just wait till the next eviction
+}
+
+// syncSurgeConditions keeps the conditions and recommendation describing how
+// surges of eas fare current, and rolls back a surge whose pods are stuck Pending
+// when RollbackStuckSurges is set.
+func (r *EvictionAutoScalerReconciler) syncSurgeConditions(ctx context.Context, eas *myappsv1.EvictionAutoScaler, pdb *policyv1.PodDisruptionBudget, target Surger, surgeApplier SurgeApplier, disabled bool) (*ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	// Pods created by the surge that still can't run point at missing capacity.
+	if eas.Status.SurgeActive {
+		if pending, oldest, err := countPendingSurgePods(ctx, r.Client, pdb, &eas.Status); err != nil {
+			logger.Error(err, "failed to count pending surge pods", "pdb", pdb.Name)
+		} else {
+			setCapacityBlocked(eas, pending)
+			if metrics.PerObject() {
+				metrics.SurgePodsPendingGauge.WithLabelValues(eas.Namespace, eas.Spec.TargetName).Set(float64(pending))
+			}
+			// An emergency override is the operator's call; it is flagged but kept.
+			_, emergency, _ := emergencySurgeUntil(eas, time.Now())
+			if r.surgeStuckPending(ctx, eas, target, pending, oldest, time.Now()) && r.RollbackStuckSurges && !emergency && !disabled {
+				result, err := r.rollBackStuckSurge(ctx, eas, target, surgeApplier)
+				return &result, err
+			}
 		}
+	} else {
+		setCapacityBlocked(eas, 0)
+		metrics.SurgePodsPendingGauge.DeleteLabelValues(eas.Namespace, eas.Spec.TargetName)
+	}
 
-		logger.Info("No disruptions allowed, scaling up", "pdb", pdb.Name, "lastEviction", EvictionAutoScaler.Spec.LastEviction, "strategy", surgeApplier.Name(), "displaced", displaced, "surgeTarget", surgeTarget)
+	// Keep SurgeUnlikelyToHelp current so it clears once the failing pods recover.
+	if !meta.IsStatusConditionFalse(eas.Status.Conditions, SurgeUnlikelyToHelpCondition) {
+		if _, err := surgeUnlikelyToHelp(ctx, r.Client, eas, pdb); err != nil {
+			logger.Error(err, "failed to classify failing pods", "pdb", pdb.Name)
+		}
+	}
+	eas.Status.Recommendation = recommend(eas, pdb, scaleDownFloor(eas), disabled)
 
-		// Track blocked eviction if the PDB is blocking the eviction
-		metrics.BlockedEvictionCounter.WithLabelValues(EvictionAutoScaler.Namespace, pdb.Name).Inc()
+	// QuotaExceeded only changes when a surge is sized; start it out False.
+	if meta.FindStatusCondition(eas.Status.Conditions, QuotaExceededCondition) == nil {
+		setQuotaExceeded(eas, "")
+	}
+	if meta.FindStatusCondition(eas.Status.Conditions, SurgeInfeasibleTopologyCondition) == nil {
+		setTopologyInfeasible(eas, "")
+	}
+	if meta.FindStatusCondition(eas.Status.Conditions, SurgeStuckPendingCondition) == nil {
+		setSurgeStuckPending(eas, "")
+	}
+	return nil, nil
+}
 
-		// Track scaling opportunity with signal label
-		signalLabel := metrics.GetScalingSignal(pdb)
-		metrics.ScalingOpportunityCounter.WithLabelValues(EvictionAutoScaler.Namespace, EvictionAutoScaler.Spec.TargetName, metrics.ScaleUpAction, signalLabel).Inc()
+// emergencySurge handles an operator-requested emergency surge: it bypasses
+// cooldown and the maxSurge cap so a stuck drain can make progress, bounded by the
+// annotation's expiry. An invalid one is reported like an invalid surge annotation
+// until it is fixed.
+func (r *EvictionAutoScalerReconciler) emergencySurge(ctx context.Context, eas *myappsv1.EvictionAutoScaler, pdb *policyv1.PodDisruptionBudget, target Surger, surgeApplier SurgeApplier, disabled bool) (*ctrl.Result, error) {
+	logger := log.FromContext(ctx)
 
-		err = surgeApplier.ApplySurge(ctx, surgeTarget)
+	until, found, err := emergencySurgeUntil(eas, time.Now())
+	if err != nil {
+		logger.Error(err, "invalid emergency surge override", "name", eas.Name)
+		if c := meta.FindStatusCondition(eas.Status.Conditions, DegradedCondition); c == nil || c.Reason != "InvalidEmergencySurge" {
+			r.event(eas, corev1.EventTypeWarning, "InvalidEmergencySurge", err.Error())
+		}
+		degraded(eas, "InvalidEmergencySurge", err.Error())
+		return &ctrl.Result{}, r.Status().Update(ctx, eas)
+	}
+	if !found {
+		return nil, nil
+	}
+	if time.Now().Before(until) {
+		if !disabled {
+			result, err := r.applyEmergencySurge(ctx, eas, pdb, target, surgeApplier, until)
+			return &result, err
+		}
+		logger.Info("Ignoring emergency surge override, surge disabled by annotation", "targetname", eas.Spec.TargetName)
+		r.event(eas, corev1.EventTypeWarning, "EmergencySurgeIgnored",
+			fmt.Sprintf("emergency override ignored, surge is disabled on %s by %s", eas.Spec.TargetName, SurgeAnnotationKey))
+	}
+	if surgeApplier.IsSurgeActive() && eas.Spec.LastEviction == eas.Status.LastEviction {
+		result, err := r.revertExpiredEmergencySurge(ctx, eas, target, surgeApplier)
+		return &result, err
+	}
+	return nil, nil
+}
+
+// noUnhandledEviction finishes a reconcile with no new eviction to handle: a surge
+// still held is scaled down once the PDB allows it, and otherwise the reconcile is
+// requeued for retention or a suppression window.
+func (r *EvictionAutoScalerReconciler) noUnhandledEviction(ctx context.Context, eas *myappsv1.EvictionAutoScaler, pdb *policyv1.PodDisruptionBudget, target Surger, surgeApplier SurgeApplier, surgeHeld bool, suppressed time.Duration) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	// A surge can outlive the eviction it was made for, e.g. when its eviction was
+	// recorded without surging further. Finish it from status instead of holding
+	// the extra replicas until the next eviction.
+	if surgeHeld {
+		if pdb.Status.DisruptionsAllowed == 0 {
+			ready(eas, "Reconciled", "no unhandled eviction, waiting for PDB to allow disruptions before reverting")
+			return ctrl.Result{RequeueAfter: cooldownOr(r.Cooldown)}, r.Status().Update(ctx, eas)
+		}
+		logger.Info("No unhandled eviction but a surge is held, scaling down", "pdbname", pdb.Name, "surgeReplicas", eas.Status.SurgeReplicas)
+		return r.scaleDown(ctx, eas, target, surgeApplier)
+	}
+	logger.Info("No unhandled eviction ", "pdbname", pdb.Name)
+	if singleReplicaWithoutSurge(ctx, target) {
+		recordWithoutSurge(eas, "SingleReplicaTarget", "no unhandled eviction; the target runs a single replica without maxSurge, so evictions of its pod can't be surged")
+	} else {
+		recordWithoutSurge(eas, "Reconciled", "no unhandled eviction")
+	}
+	var result ctrl.Result
+	if r.EvictionRetention > 0 && !eas.Spec.LastEviction.EvictionTime.IsZero() {
+		result.RequeueAfter = time.Until(eas.Spec.LastEviction.EvictionTime.Add(r.EvictionRetention))
+	}
+	if suppressed > 0 && (result.RequeueAfter <= 0 || suppressed < result.RequeueAfter) {
+		result.RequeueAfter = suppressed
+	}
+	return result, r.Status().Update(ctx, eas)
+}
+
+// recordWithoutSurge marks the last eviction of eas handled without a surge for
+// it, giving reason and message on the Ready condition.
+func recordWithoutSurge(eas *myappsv1.EvictionAutoScaler, reason, message string) {
+	eas.Status.LastEviction = eas.Spec.LastEviction
+	eas.Status.CooldownUntil = nil
+	ready(eas, reason, message)
+}
+
+// holdNewSurge checks a new eviction of eas, with no surge held yet, against
+// everything that keeps it from being surged for now: its age, the surge
+// annotation, suppression after aborted drains, a recent rollback, failing pods,
+// low cluster headroom and the coalescing window. It records the eviction without
+// surging or defers it, and ends the reconcile, when one applies.
+func (r *EvictionAutoScalerReconciler) holdNewSurge(ctx context.Context, eas *myappsv1.EvictionAutoScaler, pdb *policyv1.PodDisruptionBudget, disabled bool, suppressed time.Duration, deferred, alreadyDeferred bool) (*ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	// An eviction first seen long after it happened says nothing about a drain still
+	// in progress. A surge already in flight ages its eviction on purpose while it
+	// waits out cooldown, so it never gets here.
+	if evictionStale(eas.Spec.LastEviction, r.EvictionFreshness, time.Now()) {
+		age := time.Since(eas.Spec.LastEviction.EvictionTime.Time).Round(time.Second)
+		logger.Info("Ignoring stale eviction", "lastEviction", eas.Spec.LastEviction, "age", age, "freshness", r.EvictionFreshness)
+		metrics.StaleEvictionCounter.WithLabelValues(eas.Namespace).Inc()
+		r.event(eas, corev1.EventTypeNormal, "StaleEvictionIgnored",
+			fmt.Sprintf("eviction of %s is %s old, older than the %s freshness window", eas.Spec.LastEviction.PodName, age, r.EvictionFreshness))
+		recordWithoutSurge(eas, "StaleEvictionIgnored", "eviction recorded without surging, older than the freshness window")
+		return &ctrl.Result{}, r.Status().Update(ctx, eas)
+	}
+
+	// Surge opted out: record the eviction and leave replicas alone.
+	if disabled {
+		logger.Info("Surge disabled by annotation, recording eviction only", "targetname", eas.Spec.TargetName, "lastEviction", eas.Spec.LastEviction)
+		recordWithoutSurge(eas, "SurgeDisabled", "eviction recorded, surge disabled by "+SurgeAnnotationKey)
+		return &ctrl.Result{}, r.Status().Update(ctx, eas)
+	}
+
+	// Repeated aborted drains: record the eviction without surging until the window
+	// passes, so a drain that keeps being retried doesn't oscillate the workload.
+	if suppressed > 0 {
+		logger.Info("Surge suppressed after repeated aborted drains, recording eviction only", "targetname", eas.Spec.TargetName, "suppressedUntil", eas.Status.SuppressedUntil)
+		recordWithoutSurge(eas, "SurgeSuppressed", "eviction recorded, surges suppressed after repeated aborted drains")
+		return &ctrl.Result{RequeueAfter: suppressed}, r.Status().Update(ctx, eas)
+	}
+
+	// A surge just rolled back for pods stuck Pending: record the eviction without
+	// surging into the same shortage again until the hold passes.
+	if held := r.rolledBackRemaining(eas, time.Now()); held > 0 {
+		logger.Info("Surge rolled back recently, recording eviction only", "targetname", eas.Spec.TargetName, "heldFor", held)
+		recordWithoutSurge(eas, "SurgeRolledBack", "eviction recorded, surges held after surge pods got stuck pending")
+		return &ctrl.Result{RequeueAfter: held}, r.Status().Update(ctx, eas)
+	}
+
+	// A bad rollout: the newest pods crash, and surge pods would be more of them.
+	// Record the eviction without surging until the pattern changes.
+	unlikely, err := surgeUnlikelyToHelp(ctx, r.Client, eas, pdb)
+	if err != nil {
+		logger.Error(err, "failed to classify failing pods", "pdb", pdb.Name)
+		return nil, err
+	}
+	if unlikely {
+		logger.Info("Newest pods are failing, recording eviction without surging", "targetname", eas.Spec.TargetName, "lastEviction", eas.Spec.LastEviction)
+		metrics.SurgeUnlikelyToHelpCounter.WithLabelValues(eas.Namespace, metrics.Name(eas.Spec.TargetName)).Inc()
+		r.event(eas, corev1.EventTypeWarning, "SurgeUnlikelyToHelp",
+			fmt.Sprintf("not surging %s, its newest pods are failing", eas.Spec.TargetName))
+		recordWithoutSurge(eas, "SurgeUnlikelyToHelp", "eviction recorded, surge skipped because the newest pods are failing")
+		return &ctrl.Result{}, r.Status().Update(ctx, eas)
+	}
+
+	// Cluster short on capacity: defer the surge rather than add pods with nowhere to
+	// run. The eviction stays unhandled, so the surge goes ahead once headroom
+	// recovers, unless the eviction has gone stale by then.
+	if deferred {
+		logger.Info("Cluster headroom below threshold, deferring surge", "targetname", eas.Spec.TargetName, "threshold", r.Headroom.Threshold)
+		if !alreadyDeferred {
+			metrics.SurgeDeferredCounter.WithLabelValues(eas.Namespace, metrics.Name(eas.Spec.TargetName)).Inc()
+			r.event(eas, corev1.EventTypeWarning, "SurgeDeferred",
+				fmt.Sprintf("deferring surge of %s, cluster headroom is below %.0f%%", eas.Spec.TargetName, r.Headroom.Threshold*100))
+		}
+		ready(eas, surgeDeferredReason, "eviction pending, surge deferred until cluster headroom recovers")
+		return &ctrl.Result{RequeueAfter: r.Headroom.Interval}, r.Status().Update(ctx, eas)
+	}
+
+	// Give the rest of a drain's evictions a moment to arrive: the surge is sized from
+	// every displaced pod at once rather than topped up eviction by eviction.
+	if remaining := coalesceRemaining(eas, r.EvictionCoalesceWindow, time.Now()); remaining > 0 {
+		logger.V(1).Info("Coalescing evictions before surging", "targetname", eas.Spec.TargetName, "remaining", remaining)
+		ready(eas, "CoalescingEvictions", fmt.Sprintf("eviction pending, waiting %s for more evictions before surging", remaining.Round(time.Second)))
+		return &ctrl.Result{RequeueAfter: remaining}, r.Status().Update(ctx, eas)
+	}
+	return nil, nil
+}
+
+// surge scales target up for the pods the blocking PDB holds up: it sizes the
+// surge, checks it against quotas and topology, waits for its approval and applies
+// it. A surge already big enough waits for the PDB instead.
+func (r *EvictionAutoScalerReconciler) surge(ctx context.Context, eas *myappsv1.EvictionAutoScaler, pdb *policyv1.PodDisruptionBudget, target Surger, surgeApplier SurgeApplier, maxSurgeTarget int32, surgeHeld, capacityPrecheck bool) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	displaced, surgeTarget, stop, err := r.sizeSurge(ctx, eas, pdb, target, maxSurgeTarget, surgeHeld)
+	if stop != nil || err != nil {
+		return lo.FromPtr(stop), err
+	}
+
+	if target.GetReplicas() >= surgeTarget {
+		//we've scaled up but pdb is still blockign may just be waiting for new pods to become ready
+		logger.Info("Have already scaled up to handle evictions, waiting for PDB to allow disruptions before reverting",
+			"pdb", pdb.Name,
+			"target", eas.Spec.TargetName)
+		ready(eas, "Reconciled", "Have already scaled up to handle evictions, waiting for PDB to allow disruptions before reverting")
+		return ctrl.Result{RequeueAfter: cooldownRequeue(&eas.Status, r.Cooldown, time.Now())}, r.Status().Update(ctx, eas)
+	}
+
+	if stop, err := r.checkSurgeQuota(ctx, eas, pdb, target, surgeApplier, surgeTarget, surgeHeld, capacityPrecheck); stop != nil || err != nil {
+		return lo.FromPtr(stop), err
+	}
+	surgeTarget, stop, err = r.fitSurgeTopology(ctx, eas, pdb, target, surgeApplier, surgeTarget, surgeHeld, capacityPrecheck)
+	if stop != nil || err != nil {
+		return lo.FromPtr(stop), err
+	}
+
+	// Surge approval: publish the surge as a plan and wait until it is approved.
+	if r.SurgeApproval {
+		plan, result, err := r.awaitSurgePlan(ctx, eas, target.GetReplicas(), surgeTarget, surgePlanReason(displaced, pdb))
 		if err != nil {
-			logger.Error(err, "failed to apply surge", "kind", EvictionAutoScaler.Spec.TargetKind, "targetname", EvictionAutoScaler.Spec.TargetName, "strategy", surgeApplier.Name())
+			logger.Error(err, "failed to read or decide surge plan", "name", eas.Name)
 			return ctrl.Result{}, err
 		}
+		if plan == nil {
+			return result, r.Status().Update(ctx, eas)
+		}
+		surgeTarget = min(surgeTarget, plan.Spec.SurgeReplicas)
+	}
 
-		// Track actual scaling action
-		metrics.ActualScalingCounter.WithLabelValues(EvictionAutoScaler.Namespace, EvictionAutoScaler.Spec.TargetName, metrics.ScaleUpAction).Inc()
+	logger.Info("No disruptions allowed, scaling up", "pdb", pdb.Name, "lastEviction", eas.Spec.LastEviction, "strategy", surgeApplier.Name(), "displaced", displaced, "surgeTarget", surgeTarget)
+	return r.applySurge(ctx, eas, pdb, target, surgeApplier, surgeTarget, surgeHeld)
+}
 
-		// Log the scaling action
-		logger.Info(fmt.Sprintf("Scaled up %s %s/%s to %d replicas (via %s)", EvictionAutoScaler.Spec.TargetKind, target.Obj().GetNamespace(), target.Obj().GetName(), surgeTarget, surgeApplier.Name()))
-		logger.Info(fmt.Sprintf("TargetGeneration moving from %d->%d", EvictionAutoScaler.Status.TargetGeneration, target.Obj().GetGeneration()))
-		// Save ResourceVersion to EvictionAutoScaler status this will cause another reconcile.
-		EvictionAutoScaler.Status.TargetGeneration = target.Obj().GetGeneration()
-		//Do not update EvictionAutoScaler.Status.LastEviction because we need to keep reconciling till scale down
-		ready(&EvictionAutoScaler.Status.Conditions, "Reconciled", "eviction with scale up")
-		return ctrl.Result{RequeueAfter: cooldown}, r.Status().Update(ctx, EvictionAutoScaler)
+// sizeSurge returns the pods displaced from cordoned nodes and the replica count
+// to surge target to for them: the scale-down floor plus the displaced pods,
+// capped at maxSurgeTarget and at spec.surgeStep above the current surge. An
+// eviction the PDB doesn't hold up is recorded without surging.
+func (r *EvictionAutoScalerReconciler) sizeSurge(ctx context.Context, eas *myappsv1.EvictionAutoScaler, pdb *policyv1.PodDisruptionBudget, target Surger, maxSurgeTarget int32, surgeHeld bool) (int32, int32, *ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	displaced, err := countPodsOnCordoned(ctx, r.Client, pdb)
+	if err != nil {
+		logger.Error(err, "failed to count displaced pods on cordoned nodes")
+		return 0, 0, nil, err
+	}
+	// With unhealthyPodEvictionPolicy AlwaysAllow the PDB doesn't hold up an evicted
+	// pod that isn't Ready, so surging for it would only add replicas.
+	if displaced == 0 && !surgeHeld {
+		evictable, err := evictedPodUnhealthyEvictable(ctx, r.Client, pdb, eas.Spec.LastEviction.PodName)
+		if err != nil {
+			logger.Error(err, "failed to read evicted pod", "podname", eas.Spec.LastEviction.PodName)
+			return 0, 0, nil, err
+		}
+		if evictable {
+			logger.Info("Evicted pod is not Ready and the PDB always allows its eviction, skipping surge",
+				"pdb", pdb.Name, "podname", eas.Spec.LastEviction.PodName)
+			recordWithoutSurge(eas, "UnhealthyPodEvictable", "eviction recorded, surge skipped because the evicted pod is not Ready and the PDB's unhealthyPodEvictionPolicy is AlwaysAllow")
+			return 0, 0, &ctrl.Result{}, r.Status().Update(ctx, eas)
+		}
+	}
+
+	surgeTarget := scaleDownFloor(eas) + displaced
+	if surgeTarget > maxSurgeTarget {
+		logger.Info("Displaced pods exceed maxSurge capacity, capping surge", "pdb", pdb.Name, "displaced", displaced, "maxSurgeTarget", maxSurgeTarget)
+		surgeTarget = maxSurgeTarget
+	}
+	if stepped := stepSurge(surgeTarget, eas, target.GetReplicas()); stepped < surgeTarget {
+		logger.Info("Limiting surge to surgeStep", "pdb", pdb.Name, "surgeStep", *eas.Spec.SurgeStep, "surgeTarget", surgeTarget, "steppedTarget", stepped)
+		surgeTarget = stepped
+	}
+	return displaced, surgeTarget, nil, nil
+}
+
+// checkSurgeQuota skips a surge to surgeTarget that would exceed a ResourceQuota:
+// pods past a quota are never created, so the surge would sit unfilled until
+// cooldown reverts it. The check only runs with capacityPrecheck.
+func (r *EvictionAutoScalerReconciler) checkSurgeQuota(ctx context.Context, eas *myappsv1.EvictionAutoScaler, pdb *policyv1.PodDisruptionBudget, target Surger, surgeApplier SurgeApplier, surgeTarget int32, surgeHeld, capacityPrecheck bool) (*ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	alreadyOverQuota := meta.IsStatusConditionTrue(eas.Status.Conditions, QuotaExceededCondition)
+	var shortfall string
+	if capacityPrecheck {
+		var err error
+		shortfall, err = surgeQuotaShortfall(ctx, r.Client, pdb, surgeTarget-target.GetReplicas())
+		if err != nil {
+			logger.Error(err, "failed to check resource quotas", "namespace", pdb.Namespace)
+			return nil, err
+		}
+	}
+	setQuotaExceeded(eas, shortfall)
+	if shortfall == "" {
+		return nil, nil
+	}
+	logger.Info("Surge would exceed a resource quota, skipping it", "targetname", eas.Spec.TargetName, "shortfall", shortfall)
+	if !alreadyOverQuota {
+		metrics.SurgeQuotaExceededCounter.WithLabelValues(eas.Namespace, metrics.Name(eas.Spec.TargetName)).Inc()
+		r.event(eas, corev1.EventTypeWarning, quotaExceededReason,
+			fmt.Sprintf("not surging %s to %d replicas: %s", eas.Spec.TargetName, surgeTarget, shortfall))
+	}
+	if surgeHeld {
+		// Keep the surge already made and let cooldown revert it as usual.
+		ready(eas, quotaExceededReason, "surge held, topping it up would exceed a resource quota")
+		result, err := r.scaleDown(ctx, eas, target, surgeApplier)
+		return &result, err
+	}
+	recordWithoutSurge(eas, quotaExceededReason, "eviction recorded, surge skipped because it would exceed a resource quota")
+	return &ctrl.Result{}, r.Status().Update(ctx, eas)
+}
+
+// fitSurgeTopology returns surgeTarget limited to the surge pods topology spread
+// and anti-affinity rules leave room for, which stay Pending whatever the
+// capacity. A shortfall is reported, and only limits the surge with
+// TopologyLimitedSurges. The check only runs with capacityPrecheck.
+func (r *EvictionAutoScalerReconciler) fitSurgeTopology(ctx context.Context, eas *myappsv1.EvictionAutoScaler, pdb *policyv1.PodDisruptionBudget, target Surger, surgeApplier SurgeApplier, surgeTarget int32, surgeHeld, capacityPrecheck bool) (int32, *ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	alreadyInfeasible := meta.IsStatusConditionTrue(eas.Status.Conditions, SurgeInfeasibleTopologyCondition)
+	fits, infeasible := surgeTarget-target.GetReplicas(), ""
+	if capacityPrecheck {
+		var err error
+		fits, infeasible, err = surgeTopologyFit(ctx, r.Client, pdb, fits)
+		if err != nil {
+			logger.Error(err, "failed to check topology constraints", "pdb", pdb.Name)
+			return 0, nil, err
+		}
+	}
+	setTopologyInfeasible(eas, infeasible)
+	if infeasible == "" {
+		return surgeTarget, nil, nil
+	}
+	if !alreadyInfeasible {
+		metrics.SurgeInfeasibleTopologyCounter.WithLabelValues(eas.Namespace, metrics.Name(eas.Spec.TargetName)).Inc()
+		r.event(eas, corev1.EventTypeWarning, topologyInfeasibleReason,
+			fmt.Sprintf("topology constraints leave room for %d of %d surge pods of %s: %s", fits, surgeTarget-target.GetReplicas(), eas.Spec.TargetName, infeasible))
+	}
+	switch {
+	case !r.TopologyLimitedSurges:
+		logger.Info("Topology constraints may leave surge pods Pending, surging anyway", "targetname", eas.Spec.TargetName, "fits", fits, "reason", infeasible)
+		return surgeTarget, nil, nil
+	case fits == 0:
+		logger.Info("Topology constraints leave no room for surge pods, holding surge", "targetname", eas.Spec.TargetName, "reason", infeasible)
+		if surgeHeld {
+			ready(eas, topologyInfeasibleReason, "surge held, topology constraints leave no room to top it up")
+			result, err := r.scaleDown(ctx, eas, target, surgeApplier)
+			return 0, &result, err
+		}
+		// The eviction stays unhandled, so the surge is made once room opens up.
+		ready(eas, topologyInfeasibleReason, "surge held back until topology constraints leave room for surge pods")
+		return 0, &ctrl.Result{RequeueAfter: cooldownOr(r.Cooldown)}, r.Status().Update(ctx, eas)
+	default:
+		logger.Info("Limiting surge to the pods topology constraints can place", "targetname", eas.Spec.TargetName, "fits", fits, "reason", infeasible)
+		return target.GetReplicas() + fits, nil, nil
+	}
+}
+
+// applySurge scales target to surgeTarget and records the surge on eas, keeping
+// the eviction unhandled so the reconcile comes back to scale down after cooldown.
+func (r *EvictionAutoScalerReconciler) applySurge(ctx context.Context, eas *myappsv1.EvictionAutoScaler, pdb *policyv1.PodDisruptionBudget, target Surger, surgeApplier SurgeApplier, surgeTarget int32, surgeHeld bool) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	// Track blocked eviction if the PDB is blocking the eviction
+	metrics.BlockedEvictionCounter.WithLabelValues(eas.Namespace, metrics.Name(pdb.Name)).Inc()
+
+	// Track scaling opportunity with signal label
+	signalLabel := metrics.GetScalingSignal(pdb)
+	metrics.ScalingOpportunityCounter.WithLabelValues(eas.Namespace, metrics.Name(eas.Spec.TargetName), metrics.ScaleUpAction, signalLabel).Inc()
+
+	if err := r.addSurgeFinalizer(ctx, eas); err != nil {
+		logger.Error(err, "failed to add surge finalizer", "name", eas.Name)
+		return ctrl.Result{}, err
+	}
+	// A new surge's pods are judged on their own; a topped-up surge keeps its record.
+	if !surgeHeld {
+		setSurgeStuckPending(eas, "")
+	}
+	if r.UnmarkedSurges {
+		// Without a marker on the target, status is the only record of the surge:
+		// write it first, so a crash before the scale can't leave a surge nobody
+		// reverts. A surge recorded but never made is cleared by the next reconcile.
+		markSurge(&eas.Status, surgeTarget)
+		recordTarget(&eas.Status, target)
+		if err := r.Status().Update(ctx, eas); err != nil {
+			logger.Error(err, "failed to record surge intent", "name", eas.Name)
+			return ctrl.Result{}, err
+		}
+	}
+	scaleCtx, span := tracing.Start(ctx, "Scale", append(targetAttributes(eas),
+		attribute.String("surge.strategy", surgeApplier.Name()), attribute.Int("surge.replicas", int(surgeTarget)))...)
+	err := surgeApplier.ApplySurge(scaleCtx, surgeTarget)
+	tracing.End(span, err)
+	if err != nil {
+		logger.Error(err, "failed to apply surge", "kind", eas.Spec.TargetKind, "targetname", eas.Spec.TargetName, "strategy", surgeApplier.Name())
+		return ctrl.Result{}, err
+	}
+
+	if targetPaused(target) {
+		r.event(eas, corev1.EventTypeNormal, "TargetPaused",
+			fmt.Sprintf("deployment %s is paused: the surge scales its existing ReplicaSets and no rollout happens until it is resumed", eas.Spec.TargetName))
 	}
 
+	// Track actual scaling action
+	metrics.ActualScalingCounter.WithLabelValues(eas.Namespace, metrics.Name(eas.Spec.TargetName), metrics.ScaleUpAction).Inc()
+	markSurge(&eas.Status, surgeTarget)
+
+	// Log the scaling action
+	logger.Info(fmt.Sprintf("Scaled up %s %s/%s to %d replicas (via %s)", eas.Spec.TargetKind, target.Obj().GetNamespace(), target.Obj().GetName(), surgeTarget, surgeApplier.Name()))
+	logger.Info(fmt.Sprintf("TargetGeneration moving from %d->%d", eas.Status.TargetGeneration, target.Obj().GetGeneration()))
+	// Save ResourceVersion to EvictionAutoScaler status this will cause another reconcile.
+	recordTarget(&eas.Status, target)
+	//Do not update EvictionAutoScaler.Status.LastEviction because we need to keep reconciling till scale down
+	ready(eas, "Reconciled", "eviction with scale up")
+	return ctrl.Result{RequeueAfter: cooldownRequeue(&eas.Status, r.Cooldown, time.Now())}, r.Status().Update(ctx, eas)
+}
+
+// scaleDown reverts a surge on the target of EvictionAutoScaler once its cooldown
+// has passed, and marks the last eviction handled.
+func (r *EvictionAutoScalerReconciler) scaleDown(ctx context.Context, EvictionAutoScaler *myappsv1.EvictionAutoScaler, target Surger, surgeApplier SurgeApplier) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
 	//what if we're allowed disruptions >0 and minreplicas == replicas? Could argue that we should mark the eviction as handled
 	//BUT maybe PDB is slow to update? so just letting it requeue anyways
 
 	//Cool down time makes sure we're not still getting more evictions
 	//we could substantially reduce this if we looked at pods and knew that none remaining (not already evicted) had been an eviction target but that means tracking more data in EvictionAutoScaler
 	// or using pod conditons which we're not doing.....yet
-	if time.Since(EvictionAutoScaler.Spec.LastEviction.EvictionTime.Time) < cooldown {
-		logger.Info(fmt.Sprintf("Giving %s/%s cooldown of  %s after last eviction %s ", target.Obj().GetNamespace(), target.Obj().GetName(), cooldown, EvictionAutoScaler.Spec.LastEviction.EvictionTime))
-		return ctrl.Result{RequeueAfter: cooldown}, nil
+	if remaining := cooldownRemaining(&EvictionAutoScaler.Status, time.Now()); remaining > 0 {
+		logger.Info(fmt.Sprintf("Giving %s/%s cooldown until %s after last eviction %s ", target.Obj().GetNamespace(), target.Obj().GetName(), EvictionAutoScaler.Status.CooldownUntil, EvictionAutoScaler.Spec.LastEviction.EvictionTime))
+		return ctrl.Result{RequeueAfter: remaining}, r.Status().Update(ctx, EvictionAutoScaler)
 	}
 
-	//still at a scaled out state check if we can scale back down
-	if target.GetReplicas() > EvictionAutoScaler.Status.MinReplicas {
+	//still at a scaled out state check if we can scale back down. Annotation-only surges
+	//never change replicas themselves, so an active surge marker also needs reverting.
+	if target.GetReplicas() > scaleDownFloor(EvictionAutoScaler) || surgeApplier.IsSurgeActive() {
+
+		// Replacement nodes still starting their DaemonSets can't take the pods yet;
+		// scaling down now could leave the workload short and re-block the next drain.
+		if r.NodeWarmupTimeout > 0 {
+			cold, err := coldNodes(ctx, r.Client, r.NodeWarmupTimeout, time.Now())
+			if err != nil {
+				logger.Error(err, "failed to check node warmup")
+				return ctrl.Result{}, err
+			}
+			if len(cold) > 0 {
+				logger.Info("Holding scale-down until new nodes finish warming up", "nodes", cold)
+				ready(EvictionAutoScaler, "WaitingForNodeWarmup", "scale-down held until nodes finish warming up: "+strings.Join(cold, ", "))
+				return ctrl.Result{RequeueAfter: nodeWarmupRequeue}, r.Status().Update(ctx, EvictionAutoScaler)
+			}
+		}
+
+		if r.DrainFailureThreshold > 0 {
+			aborted, err := drainAborted(ctx, r.Client, EvictionAutoScaler.Namespace, EvictionAutoScaler.Spec.LastEviction, r.DrainTaintKeys)
+			if err != nil {
+				logger.Error(err, "failed to check whether the drain completed")
+				return ctrl.Result{}, err
+			}
+			if recordDrainOutcome(&EvictionAutoScaler.Status, aborted, r.DrainFailureThreshold, r.DrainFailureSuppression, time.Now()) {
+				logger.Info("Suppressing surges after repeated aborted drains", "threshold", r.DrainFailureThreshold, "suppressedUntil", EvictionAutoScaler.Status.SuppressedUntil)
+				r.event(EvictionAutoScaler, corev1.EventTypeWarning, "SurgeSuppressed",
+					fmt.Sprintf("%d consecutive drains were aborted, suppressing surges for %s", r.DrainFailureThreshold, r.DrainFailureSuppression))
+			}
+		}
 
 		// Track scaling opportunity
-		metrics.ScalingOpportunityCounter.WithLabelValues(EvictionAutoScaler.Namespace, EvictionAutoScaler.Spec.TargetName, metrics.ScaleDownAction, metrics.CooldownElapsedSignal).Inc()
+		metrics.ScalingOpportunityCounter.WithLabelValues(EvictionAutoScaler.Namespace, metrics.Name(EvictionAutoScaler.Spec.TargetName), metrics.ScaleDownAction, metrics.CooldownElapsedSignal).Inc()
 
 		//okay we have allowed disruptions, revert target to the original state
-		err = surgeApplier.RevertSurge(ctx, EvictionAutoScaler.Status.MinReplicas)
-		if err != nil {
+		// Save ResourceVersion to EvictionAutoScaler status this will cause another reconcile.
+		logger.Info(fmt.Sprintf("TargetGeneration moving from %d->%d", EvictionAutoScaler.Status.TargetGeneration, target.Obj().GetGeneration()))
+		if err := r.revertSurge(ctx, EvictionAutoScaler, target, surgeApplier); err != nil {
 			return ctrl.Result{}, err
 		}
 
-		// Track actual scaling action
-		metrics.ActualScalingCounter.WithLabelValues(EvictionAutoScaler.Namespace, EvictionAutoScaler.Spec.TargetName, metrics.ScaleDownAction).Inc()
-
 		// Log the scaling action
 		logger.Info(fmt.Sprintf("Reverted surge on %s %s/%s (via %s)", EvictionAutoScaler.Spec.TargetKind, target.Obj().GetNamespace(), target.Obj().GetName(), surgeApplier.Name()))
-		// Save ResourceVersion to EvictionAutoScaler status this will cause another reconcile.
-		logger.Info(fmt.Sprintf("TargetGeneration moving from %d->%d", EvictionAutoScaler.Status.TargetGeneration, target.Obj().GetGeneration()))
-		EvictionAutoScaler.Status.TargetGeneration = target.Obj().GetGeneration()
 		EvictionAutoScaler.Status.LastEviction = EvictionAutoScaler.Spec.LastEviction //we could still keep a log here if thats useful
-		logger.Info(fmt.Sprintf("Handled eviction %s", EvictionAutoScaler.Spec.LastEviction))
+		EvictionAutoScaler.Status.CooldownUntil = nil
+		logger.Info(fmt.Sprintf("Handled eviction %v", EvictionAutoScaler.Spec.LastEviction))
 
-		ready(&EvictionAutoScaler.Status.Conditions, "Reconciled", "evictions hit cooldown so scaled down")
+		ready(EvictionAutoScaler, "Reconciled", "evictions hit cooldown so scaled down")
 		return ctrl.Result{}, r.Status().Update(ctx, EvictionAutoScaler)
 	}
 
 	//could get here if a scale up/down was not needed because we never hit allowed diruptios == 0.
 	EvictionAutoScaler.Status.LastEviction = EvictionAutoScaler.Spec.LastEviction //we could still keep a log here if thats useful
-	ready(&EvictionAutoScaler.Status.Conditions, "Reconciled", "last eviction did not need scaling")
-	logger.Info(fmt.Sprintf("Handled eviction %s", EvictionAutoScaler.Spec.LastEviction))
+	EvictionAutoScaler.Status.CooldownUntil = nil
+	ready(EvictionAutoScaler, "Reconciled", "last eviction did not need scaling")
+	logger.Info(fmt.Sprintf("Handled eviction %v", EvictionAutoScaler.Spec.LastEviction))
 	return ctrl.Result{}, r.Status().Update(ctx, EvictionAutoScaler) //should we go rety in case there is also an eviction or just wait till the next eviction
 }
 
-func ready(conditions *[]metav1.Condition, reason string, message string) {
-	meta.SetStatusCondition(conditions, metav1.Condition{
-		Type:               "Ready",
-		Status:             metav1.ConditionTrue,
-		Reason:             reason,
-		Message:            message,
-		LastTransitionTime: metav1.Now(),
-	})
-	meta.RemoveStatusCondition(conditions, "Degraded")
+// revertSurge reverts the target to the scale-down floor and records the surge as
+// ended: its duration and hysteresis cycle, its cleared status and the target
+// generation it left behind. The caller writes status.
+func (r *EvictionAutoScalerReconciler) revertSurge(ctx context.Context, eas *myappsv1.EvictionAutoScaler, target Surger, surgeApplier SurgeApplier) error {
+	revertCtx, span := tracing.Start(ctx, "RevertSurge", append(targetAttributes(eas),
+		attribute.String("surge.strategy", surgeApplier.Name()), attribute.Int("surge.replicas", int(scaleDownFloor(eas))))...)
+	err := surgeApplier.RevertSurge(revertCtx, scaleDownFloor(eas))
+	tracing.End(span, err)
+	if err != nil {
+		return err
+	}
+
+	// Track actual scaling action
+	metrics.ActualScalingCounter.WithLabelValues(eas.Namespace, metrics.Name(eas.Spec.TargetName), metrics.ScaleDownAction).Inc()
+	observeSurgeDuration(eas, time.Now())
+	clearSurge(&eas.Status)
+	recordSurgeCycle(&eas.Status, r.HysteresisWindow, time.Now())
+	if err := r.removeSurgeFinalizer(ctx, eas); err != nil {
+		return err
+	}
+	recordTarget(&eas.Status, target)
+	return nil
+}
+
+// event records an audit event on the EvictionAutoScaler when a recorder is configured.
+func (r *EvictionAutoScalerReconciler) event(obj runtime.Object, eventType, reason, message string) {
+	if r.Recorder != nil {
+		r.Recorder.Event(obj, eventType, reason, message)
+	}
+}
+
+// cooldownRemaining returns how long scale-down is still held by the persisted
+// cooldown deadline, or 0 once it has passed or none is set.
+func cooldownRemaining(status *myappsv1.EvictionAutoScalerStatus, now time.Time) time.Duration {
+	if status.CooldownUntil == nil {
+		return 0
+	}
+	return max(status.CooldownUntil.Sub(now), 0)
+}
+
+// cooldownRequeue is when to look again while a surge is held: at the cooldown
+// deadline, or after a full configured cooldown to keep polling once the deadline
+// has passed.
+func cooldownRequeue(status *myappsv1.EvictionAutoScalerStatus, configured time.Duration, now time.Time) time.Duration {
+	if remaining := cooldownRemaining(status, now); remaining > 0 {
+		return remaining
+	}
+	return cooldownOr(configured)
+}
+
+// coalesceRemaining returns how much longer a new surge of eas waits for evictions
+// to coalesce. The window opens at the first unhandled eviction, recorded on status;
+// a window opened at an eviction since handled belongs to an earlier drain and is
+// reopened at the current one. The window is closed once it has passed.
+func coalesceRemaining(eas *myappsv1.EvictionAutoScaler, window time.Duration, now time.Time) time.Duration {
+	status := &eas.Status
+	if window <= 0 {
+		status.CoalescingSince = nil
+		return 0
+	}
+	if status.CoalescingSince == nil || !status.CoalescingSince.After(status.LastEviction.EvictionTime.Time) {
+		since := eas.Spec.LastEviction.EvictionTime
+		status.CoalescingSince = &since
+	}
+	remaining := status.CoalescingSince.Add(window).Sub(now)
+	if remaining <= 0 {
+		status.CoalescingSince = nil
+		return 0
+	}
+	return remaining
+}
+
+// evictionStale reports whether eviction happened more than freshness before now. A
+// zero freshness never treats an eviction as stale.
+func evictionStale(eviction myappsv1.Eviction, freshness time.Duration, now time.Time) bool {
+	if freshness <= 0 || eviction.EvictionTime.IsZero() {
+		return false
+	}
+	return now.Sub(eviction.EvictionTime.Time) > freshness
+}
+
+// lastEvictionExpired reports whether the EvictionAutoScaler holds a handled eviction
+// older than retention. A zero retention disables expiry.
+func lastEvictionExpired(eas *myappsv1.EvictionAutoScaler, retention time.Duration, now time.Time) bool {
+	last := eas.Spec.LastEviction
+	if retention <= 0 || last.EvictionTime.IsZero() || last != eas.Status.LastEviction {
+		return false
+	}
+	return now.Sub(last.EvictionTime.Time) > retention
 }
 
-func degraded(conditions *[]metav1.Condition, reason string, message string) {
-	meta.SetStatusCondition(conditions, metav1.Condition{
-		Type:               "Degraded",
-		Status:             metav1.ConditionTrue,
-		Reason:             reason,
-		Message:            message,
-		LastTransitionTime: metav1.Now(),
-	})
+// markSurge records an in-flight surge on status. The start time is kept across
+// top-ups so it reflects when the surge began.
+func markSurge(status *myappsv1.EvictionAutoScalerStatus, replicas int32) {
+	if !status.SurgeActive || status.SurgeStartTime == nil {
+		now := metav1.Now()
+		status.SurgeStartTime = &now
+	}
+	status.SurgeActive = true
+	status.SurgeReplicas = replicas
+}
+
+// observeSurgeDuration records how long the surge on eas held its target, from
+// status.surgeStartTime to now. A surge without a recorded start is skipped.
+func observeSurgeDuration(eas *myappsv1.EvictionAutoScaler, now time.Time) {
+	if eas.Status.SurgeStartTime == nil {
+		return
+	}
+	metrics.SurgeDurationHistogram.WithLabelValues(eas.Namespace, metrics.Name(eas.Spec.TargetName)).
+		Observe(now.Sub(eas.Status.SurgeStartTime.Time).Seconds())
+}
+
+// surgeRecorded reports whether status records a surge that target still holds.
+func surgeRecorded(status *myappsv1.EvictionAutoScalerStatus, target Surger) bool {
+	return status.SurgeActive && scaledBySurge(status, target, status.SurgeReplicas)
+}
+
+// clearSurge resets the surge fields once the target is back at its floor.
+func clearSurge(status *myappsv1.EvictionAutoScalerStatus) {
+	status.SurgeActive = false
+	status.SurgeReplicas = 0
+	status.SurgeStartTime = nil
+}
+
+// refreshForSurge re-reads pdb and target in place through the APIReader, if set.
+func (r *EvictionAutoScalerReconciler) refreshForSurge(ctx context.Context, pdb *policyv1.PodDisruptionBudget, target Surger) error {
+	if r.APIReader == nil {
+		return nil
+	}
+	if err := r.APIReader.Get(ctx, client.ObjectKeyFromObject(pdb), pdb); err != nil {
+		return err
+	}
+	return r.APIReader.Get(ctx, client.ObjectKeyFromObject(target.Obj()), target.Obj())
 }
 
 func (r *EvictionAutoScalerReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&myappsv1.EvictionAutoScaler{}).
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&myappsv1.EvictionAutoScaler{})
+	if r.SurgeApproval {
+		b = b.Owns(&myappsv1.SurgePlan{})
+	}
+	return b.
+		WithOptions(controllerOptions("evictionautoscaler")).
+		WithEventFilter(shardPredicate(r.Filter)).
 		WithEventFilter(predicate.Funcs{
 			// ignore status updates as we make those.
 			UpdateFunc: func(ue event.UpdateEvent) bool {
-				return ue.ObjectOld.GetGeneration() != ue.ObjectNew.GetGeneration()
+				// annotations don't bump generation, but the emergency override and
+				// surge plan approvals live there.
+				return ue.ObjectOld.GetGeneration() != ue.ObjectNew.GetGeneration() ||
+					ue.ObjectOld.GetAnnotations()[EmergencySurgeUntilAnnotationKey] != ue.ObjectNew.GetAnnotations()[EmergencySurgeUntilAnnotationKey] ||
+					ue.ObjectOld.GetAnnotations()[ApproveSurgeAnnotationKey] != ue.ObjectNew.GetAnnotations()[ApproveSurgeAnnotationKey] ||
+					ue.ObjectOld.GetDeletionTimestamp().IsZero() != ue.ObjectNew.GetDeletionTimestamp().IsZero()
 			},
 		}).
-		Complete(r)
+		// Wake dormant EvictionAutoScalers when their deployment scales back up (and park
+		// them when it scales to zero) without waiting for the next eviction.
+		Watches(&appsv1.Deployment{},
+			handler.EnqueueRequestsFromMapFunc(requeueEvictionAutoScalersForDeployment(r.Client)),
+			builder.WithPredicates(predicate.Funcs{
+				CreateFunc:  func(event.CreateEvent) bool { return false },
+				DeleteFunc:  func(event.DeleteEvent) bool { return false },
+				GenericFunc: func(event.GenericEvent) bool { return false },
+				UpdateFunc:  replicasCrossedZero,
+			})).
+		Complete(traced("evictionautoscaler", r))
+}
+
+// replicasCrossedZero reports whether a deployment update scaled it to or from zero.
+func replicasCrossedZero(e event.UpdateEvent) bool {
+	oldDeployment, okOld := e.ObjectOld.(*appsv1.Deployment)
+	newDeployment, okNew := e.ObjectNew.(*appsv1.Deployment)
+	if !okOld || !okNew {
+		return false
+	}
+	return (lo.FromPtrOr(oldDeployment.Spec.Replicas, 1) == 0) != (lo.FromPtrOr(newDeployment.Spec.Replicas, 1) == 0)
+}
+
+// requeueEvictionAutoScalersForDeployment maps a deployment to the EvictionAutoScalers
+// targeting it.
+func requeueEvictionAutoScalersForDeployment(c client.Client) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		var list myappsv1.EvictionAutoScalerList
+		if err := c.List(ctx, &list, client.InNamespace(obj.GetNamespace())); err != nil {
+			log.FromContext(ctx).Error(err, "Failed to list EvictionAutoScalers", "namespace", obj.GetNamespace())
+			return nil
+		}
+		var requests []reconcile.Request
+		for _, eas := range list.Items {
+			if kind, err := myappsv1.NormalizeTargetKind(eas.Spec.TargetKind); err == nil && eas.Spec.TargetName == obj.GetName() && kind == deploymentKind {
+				requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: eas.Namespace, Name: eas.Name}})
+			}
+		}
+		return requests
+	}
+}
+
+// scaleDownFloor is the replica count surges build on and revert to: the recorded
+// minReplicas, raised to spec.minReplicas when that is higher.
+func scaleDownFloor(eas *myappsv1.EvictionAutoScaler) int32 {
+	if eas.Spec.MinReplicas != nil {
+		return max(eas.Status.MinReplicas, *eas.Spec.MinReplicas)
+	}
+	return eas.Status.MinReplicas
+}
+
+// stepSurge caps surgeTarget at spec.surgeStep replicas above the current surge
+// level, the highest of the target's replicas, the recorded surge and the scale-down
+// floor.
+func stepSurge(surgeTarget int32, eas *myappsv1.EvictionAutoScaler, replicas int32) int32 {
+	step := eas.Spec.SurgeStep
+	if step == nil {
+		return surgeTarget
+	}
+	base := max(replicas, eas.Status.SurgeReplicas, scaleDownFloor(eas))
+	return min(surgeTarget, base+*step)
 }
 
 var (
@@ -6,20 +6,26 @@ import (
 	"time"
 
 	v1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/metrics"
 	"github.com/azure/eviction-autoscaler/internal/namespacefilter"
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	appsv1 "k8s.io/api/apps/v1"
 	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
 	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -168,9 +174,8 @@ var _ = Describe("EvictionAutoScaler Controller", func() {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(EvictionAutoScaler.Status.MinReplicas).To(Equal(int32(1)))
 			Expect(EvictionAutoScaler.Status.TargetGeneration).ToNot(BeZero())
-			Expect(EvictionAutoScaler.Status.Conditions).To(HaveLen(1))
-			Expect(EvictionAutoScaler.Status.Conditions[0].Type).To(Equal("Ready"))
-			Expect(EvictionAutoScaler.Status.Conditions[0].Reason).To(Equal("TargetSpecChange"))
+			Expect(meta.IsStatusConditionTrue(EvictionAutoScaler.Status.Conditions, "Ready")).To(BeTrue())
+			Expect(meta.FindStatusCondition(EvictionAutoScaler.Status.Conditions, "Ready").Reason).To(Equal("TargetSpecChange"))
 
 			// run it twice so we hit unhandled eviction == false
 			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
@@ -189,9 +194,8 @@ var _ = Describe("EvictionAutoScaler Controller", func() {
 			Expect(EvictionAutoScaler.Status.MinReplicas).To(Equal(int32(1)))
 			Expect(EvictionAutoScaler.Status.TargetGeneration).ToNot(BeZero())
 
-			Expect(EvictionAutoScaler.Status.Conditions).To(HaveLen(1))
-			Expect(EvictionAutoScaler.Status.Conditions[0].Type).To(Equal("Ready"))
-			Expect(EvictionAutoScaler.Status.Conditions[0].Reason).To(Equal("Reconciled"))
+			Expect(meta.IsStatusConditionTrue(EvictionAutoScaler.Status.Conditions, "Ready")).To(BeTrue())
+			Expect(meta.FindStatusCondition(EvictionAutoScaler.Status.Conditions, "Ready").Reason).To(Equal("Reconciled"))
 		})
 
 		It("should deal with an eviction when allowedDisruptions == 0", func() {
@@ -683,7 +687,8 @@ var _ = Describe("EvictionAutoScaler Controller", func() {
 
 			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
 			Expect(err).NotTo(HaveOccurred())
-			Expect(result.RequeueAfter).To(Equal(cooldown), "should requeue during cooldown")
+			// Requeue lands on the persisted deadline, which is at most one cooldown away.
+			Expect(result.RequeueAfter).To(BeNumerically("~", cooldown, 2*time.Second), "should requeue during cooldown")
 
 			Expect(k8sClient.Get(ctx, deploymentNamespacedName, dep)).To(Succeed())
 			Expect(*dep.Spec.Replicas).To(Equal(int32(5)), "no scale-down yet: still within cooldown")
@@ -812,7 +817,7 @@ var _ = Describe("EvictionAutoScaler Controller", func() {
 				NamespacedName: typeNamespacedName,
 			})
 			Expect(err).NotTo(HaveOccurred())
-			Expect(result.RequeueAfter).To(Equal(cooldown))
+			Expect(result.RequeueAfter).To(BeNumerically("~", cooldown, 2*time.Second))
 
 			// Deployment is not changed yet
 			err = k8sClient.Get(ctx, deploymentNamespacedName, deployment)
@@ -824,6 +829,7 @@ var _ = Describe("EvictionAutoScaler Controller", func() {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(EvictionAutoScaler.Spec.LastEviction.PodName).To(Equal("somepod"))
 			Expect(EvictionAutoScaler.Spec.LastEviction.EvictionTime).ToNot(Equal(EvictionAutoScaler.Status.LastEviction.EvictionTime))
+			Expect(EvictionAutoScaler.Status.CooldownUntil).ToNot(BeNil(), "cooldown deadline should be persisted")
 
 			By("scaling down after cooldown")
 			//okay lets say the eviction is older though
@@ -849,8 +855,8 @@ var _ = Describe("EvictionAutoScaler Controller", func() {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(EvictionAutoScaler.Spec.LastEviction.PodName).To(Equal("somepod"))
 			Expect(EvictionAutoScaler.Spec.LastEviction.EvictionTime).To(Equal(EvictionAutoScaler.Status.LastEviction.EvictionTime))
-			Expect(EvictionAutoScaler.Status.Conditions[0].Type).To(Equal("Ready"))
-			Expect(EvictionAutoScaler.Status.Conditions[0].Reason).To(Equal("Reconciled"))
+			Expect(meta.IsStatusConditionTrue(EvictionAutoScaler.Status.Conditions, "Ready")).To(BeTrue())
+			Expect(meta.FindStatusCondition(EvictionAutoScaler.Status.Conditions, "Ready").Reason).To(Equal("Reconciled"))
 
 		})
 
@@ -902,7 +908,43 @@ var _ = Describe("EvictionAutoScaler Controller", func() {
 			Expect(*deployment.Spec.Replicas).To(Equal(int32(5))) // Change as needed to verify scaling
 		})
 
-		//TODO do noting on old eviction
+		It("should ignore an eviction older than the freshness window", func() {
+			controllerReconciler := &EvictionAutoScalerReconciler{
+				Client:            k8sClient,
+				Scheme:            k8sClient.Scheme(),
+				Filter:            &evictionTestFilter{},
+				EvictionFreshness: 5 * time.Minute,
+			}
+
+			// run it once to populate target genration
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			EvictionAutoScaler := &v1.EvictionAutoScaler{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, EvictionAutoScaler)).To(Succeed())
+			EvictionAutoScaler.Spec.LastEviction = v1.Eviction{
+				PodName:      "oldpod",
+				EvictionTime: metav1.NewTime(time.Now().Add(-10 * time.Minute)),
+			}
+			Expect(k8sClient.Update(ctx, EvictionAutoScaler)).To(Succeed())
+
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeZero())
+
+			// The eviction is recorded as handled without a cooldown or surge.
+			Expect(k8sClient.Get(ctx, typeNamespacedName, EvictionAutoScaler)).To(Succeed())
+			Expect(EvictionAutoScaler.Status.LastEviction.PodName).To(Equal("oldpod"))
+			Expect(EvictionAutoScaler.Status.CooldownUntil).To(BeNil())
+			Expect(EvictionAutoScaler.Status.SurgeActive).To(BeFalse())
+			Expect(meta.IsStatusConditionTrue(EvictionAutoScaler.Status.Conditions, "Ready")).To(BeTrue())
+			Expect(meta.FindStatusCondition(EvictionAutoScaler.Status.Conditions, "Ready").Reason).To(Equal("StaleEvictionIgnored"))
+		})
+
 		//TODO test a statefulset.
 
 	})
@@ -953,9 +995,8 @@ var _ = Describe("EvictionAutoScaler Controller", func() {
 			// Verify EvictionAutoScaler resource
 			err = k8sClient.Get(ctx, typeNamespacedName, EvictionAutoScaler)
 			Expect(err).NotTo(HaveOccurred())
-			Expect(EvictionAutoScaler.Status.Conditions).To(HaveLen(1))
-			Expect(EvictionAutoScaler.Status.Conditions[0].Type).To(Equal("Degraded"))
-			Expect(EvictionAutoScaler.Status.Conditions[0].Reason).To(Equal("NoPdb"))
+			Expect(meta.IsStatusConditionTrue(EvictionAutoScaler.Status.Conditions, "Degraded")).To(BeTrue())
+			Expect(meta.FindStatusCondition(EvictionAutoScaler.Status.Conditions, "Degraded").Reason).To(Equal("NoPdb"))
 		})
 
 		It("should deal with no target ", func() {
@@ -992,9 +1033,8 @@ var _ = Describe("EvictionAutoScaler Controller", func() {
 			// Verify EvictionAutoScaler resource
 			err = k8sClient.Get(ctx, typeNamespacedName, EvictionAutoScaler)
 			Expect(err).NotTo(HaveOccurred())
-			Expect(EvictionAutoScaler.Status.Conditions).To(HaveLen(1))
-			Expect(EvictionAutoScaler.Status.Conditions[0].Type).To(Equal("Degraded"))
-			Expect(EvictionAutoScaler.Status.Conditions[0].Reason).To(Equal("EmptyTarget"))
+			Expect(meta.IsStatusConditionTrue(EvictionAutoScaler.Status.Conditions, "Degraded")).To(BeTrue())
+			Expect(meta.FindStatusCondition(EvictionAutoScaler.Status.Conditions, "Degraded").Reason).To(Equal("EmptyTarget"))
 		})
 
 		It("should deal with bad target kind", func() {
@@ -1032,9 +1072,46 @@ var _ = Describe("EvictionAutoScaler Controller", func() {
 			// Verify EvictionAutoScaler resource
 			err = k8sClient.Get(ctx, typeNamespacedName, EvictionAutoScaler)
 			Expect(err).NotTo(HaveOccurred())
-			Expect(EvictionAutoScaler.Status.Conditions).To(HaveLen(1))
-			Expect(EvictionAutoScaler.Status.Conditions[0].Type).To(Equal("Degraded"))
-			Expect(EvictionAutoScaler.Status.Conditions[0].Reason).To(Equal("InvalidTarget"))
+			Expect(meta.IsStatusConditionTrue(EvictionAutoScaler.Status.Conditions, "Degraded")).To(BeTrue())
+			Expect(meta.FindStatusCondition(EvictionAutoScaler.Status.Conditions, "Degraded").Reason).To(Equal("InvalidTarget"))
+		})
+
+		It("should normalize a capitalized target kind", func() {
+			By("resolving the target instead of reporting an invalid kind")
+			controllerReconciler := &EvictionAutoScalerReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+				Filter: &evictionTestFilter{},
+			}
+
+			EvictionAutoScaler := &v1.EvictionAutoScaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: namespace,
+				},
+				Spec: v1.EvictionAutoScalerSpec{
+					TargetName: "something",
+					TargetKind: "Deployments",
+				},
+			}
+			Expect(k8sClient.Create(ctx, EvictionAutoScaler)).To(Succeed())
+
+			pdb := &policyv1.PodDisruptionBudget{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: namespace,
+				},
+			}
+			Expect(k8sClient.Create(ctx, pdb)).To(Succeed())
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = k8sClient.Get(ctx, typeNamespacedName, EvictionAutoScaler)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(meta.IsStatusConditionTrue(EvictionAutoScaler.Status.Conditions, "Degraded")).To(BeTrue())
+			Expect(meta.FindStatusCondition(EvictionAutoScaler.Status.Conditions, "Degraded").Reason).To(Equal("MissingTarget"))
 		})
 
 		It("should deal with missing target", func() {
@@ -1074,9 +1151,8 @@ var _ = Describe("EvictionAutoScaler Controller", func() {
 			// Verify EvictionAutoScaler resource
 			err = k8sClient.Get(ctx, typeNamespacedName, EvictionAutoScaler)
 			Expect(err).NotTo(HaveOccurred())
-			Expect(EvictionAutoScaler.Status.Conditions).To(HaveLen(1))
-			Expect(EvictionAutoScaler.Status.Conditions[0].Type).To(Equal("Degraded"))
-			Expect(EvictionAutoScaler.Status.Conditions[0].Reason).To(Equal("MissingTarget"))
+			Expect(meta.IsStatusConditionTrue(EvictionAutoScaler.Status.Conditions, "Degraded")).To(BeTrue())
+			Expect(meta.FindStatusCondition(EvictionAutoScaler.Status.Conditions, "Degraded").Reason).To(Equal("MissingTarget"))
 		})
 	})
 
@@ -1614,3 +1690,292 @@ var _ = Describe("EvictionAutoScaler Controller - unsupported autoscaler config"
 		Expect(degradedCondition.Message).To(ContainSubstring("standalone HPA"))
 	})
 })
+
+var _ = Describe("lastEvictionExpired", func() {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	eviction := v1.Eviction{PodName: "pod", EvictionTime: metav1.NewTime(now.Add(-2 * time.Hour))}
+
+	easWith := func(spec, status v1.Eviction) *v1.EvictionAutoScaler {
+		return &v1.EvictionAutoScaler{
+			Spec:   v1.EvictionAutoScalerSpec{LastEviction: spec},
+			Status: v1.EvictionAutoScalerStatus{LastEviction: status},
+		}
+	}
+
+	It("should expire a handled eviction older than retention", func() {
+		Expect(lastEvictionExpired(easWith(eviction, eviction), time.Hour, now)).To(BeTrue())
+	})
+
+	It("should keep a handled eviction within retention", func() {
+		Expect(lastEvictionExpired(easWith(eviction, eviction), 3*time.Hour, now)).To(BeFalse())
+	})
+
+	It("should never expire an unhandled eviction", func() {
+		Expect(lastEvictionExpired(easWith(eviction, v1.Eviction{}), time.Hour, now)).To(BeFalse())
+	})
+
+	It("should keep evictions forever when retention is zero", func() {
+		Expect(lastEvictionExpired(easWith(eviction, eviction), 0, now)).To(BeFalse())
+	})
+
+	It("should ignore an already cleared eviction", func() {
+		Expect(lastEvictionExpired(easWith(v1.Eviction{}, v1.Eviction{}), time.Hour, now)).To(BeFalse())
+	})
+})
+
+var _ = Describe("cooldown deadline", func() {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	statusWith := func(until *time.Time) *v1.EvictionAutoScalerStatus {
+		status := &v1.EvictionAutoScalerStatus{}
+		if until != nil {
+			deadline := metav1.NewTime(*until)
+			status.CooldownUntil = &deadline
+		}
+		return status
+	}
+
+	It("should requeue at the persisted deadline", func() {
+		until := now.Add(20 * time.Second)
+		Expect(cooldownRemaining(statusWith(&until), now)).To(Equal(20 * time.Second))
+		Expect(cooldownRequeue(statusWith(&until), 0, now)).To(Equal(20 * time.Second))
+	})
+
+	It("should report no remaining cooldown once the deadline has passed", func() {
+		until := now.Add(-time.Second)
+		Expect(cooldownRemaining(statusWith(&until), now)).To(BeZero())
+		Expect(cooldownRequeue(statusWith(&until), 0, now)).To(Equal(cooldown))
+		Expect(cooldownRequeue(statusWith(&until), 5*time.Minute, now)).To(Equal(5 * time.Minute))
+	})
+
+	It("should report no remaining cooldown without a deadline", func() {
+		Expect(cooldownRemaining(statusWith(nil), now)).To(BeZero())
+	})
+})
+
+var _ = Describe("stepSurge", func() {
+	eas := func(status v1.EvictionAutoScalerStatus, step *int32) *v1.EvictionAutoScaler {
+		return &v1.EvictionAutoScaler{Spec: v1.EvictionAutoScalerSpec{SurgeStep: step}, Status: status}
+	}
+
+	It("should surge straight to the target without a step", func() {
+		Expect(stepSurge(8, eas(v1.EvictionAutoScalerStatus{MinReplicas: 3}, nil), 3)).To(Equal(int32(8)))
+	})
+
+	It("should limit a surge to one step above minReplicas", func() {
+		Expect(stepSurge(8, eas(v1.EvictionAutoScalerStatus{MinReplicas: 3}, ptr.To[int32](2)), 3)).To(Equal(int32(5)))
+	})
+
+	It("should step from the current surge on later evictions", func() {
+		surged := eas(v1.EvictionAutoScalerStatus{MinReplicas: 3, SurgeReplicas: 5}, ptr.To[int32](2))
+		Expect(stepSurge(8, surged, 5)).To(Equal(int32(7)))
+		Expect(stepSurge(8, surged, 7)).To(Equal(int32(8)))
+	})
+
+	It("should step from spec.minReplicas when it is above the recorded minReplicas", func() {
+		floored := eas(v1.EvictionAutoScalerStatus{MinReplicas: 3}, ptr.To[int32](2))
+		floored.Spec.MinReplicas = ptr.To[int32](5)
+		Expect(stepSurge(10, floored, 3)).To(Equal(int32(7)))
+	})
+})
+
+var _ = Describe("scaleDownFloor", func() {
+	eas := func(recorded int32, floor *int32) *v1.EvictionAutoScaler {
+		return &v1.EvictionAutoScaler{
+			Spec:   v1.EvictionAutoScalerSpec{MinReplicas: floor},
+			Status: v1.EvictionAutoScalerStatus{MinReplicas: recorded},
+		}
+	}
+
+	It("should revert to the recorded minReplicas without a spec floor", func() {
+		Expect(scaleDownFloor(eas(2, nil))).To(Equal(int32(2)))
+	})
+
+	It("should raise a lower recorded minReplicas to the spec floor", func() {
+		Expect(scaleDownFloor(eas(2, ptr.To[int32](4)))).To(Equal(int32(4)))
+	})
+
+	It("should keep a recorded minReplicas above the spec floor", func() {
+		Expect(scaleDownFloor(eas(5, ptr.To[int32](4)))).To(Equal(int32(5)))
+	})
+})
+
+var _ = Describe("evictionStale", func() {
+	now := time.Now()
+	at := func(age time.Duration) v1.Eviction {
+		return v1.Eviction{PodName: "pod", EvictionTime: metav1.NewTime(now.Add(-age))}
+	}
+
+	It("should only treat evictions older than the window as stale", func() {
+		Expect(evictionStale(at(time.Minute), 5*time.Minute, now)).To(BeFalse())
+		Expect(evictionStale(at(10*time.Minute), 5*time.Minute, now)).To(BeTrue())
+	})
+
+	It("should never treat an eviction as stale with a zero window", func() {
+		Expect(evictionStale(at(24*time.Hour), 0, now)).To(BeFalse())
+		Expect(evictionStale(v1.Eviction{}, 5*time.Minute, now)).To(BeFalse())
+	})
+})
+
+var _ = Describe("observeSurgeDuration", func() {
+	It("should record the surge duration only when the start time is known", func() {
+		eas := &v1.EvictionAutoScaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "surge-duration"},
+			Spec:       v1.EvictionAutoScalerSpec{TargetName: "web"},
+		}
+		before := testutil.CollectAndCount(metrics.SurgeDurationHistogram)
+		observeSurgeDuration(eas, time.Now())
+		Expect(testutil.CollectAndCount(metrics.SurgeDurationHistogram)).To(Equal(before))
+
+		start := metav1.NewTime(time.Now().Add(-90 * time.Second))
+		eas.Status.SurgeStartTime = &start
+		observeSurgeDuration(eas, time.Now())
+		Expect(testutil.CollectAndCount(metrics.SurgeDurationHistogram)).To(Equal(before + 1))
+	})
+})
+
+var _ = Describe("cross-namespace targets", func() {
+	ctx := context.Background()
+
+	reconcileTarget := func(targetName string) (*v1.EvictionAutoScaler, *record.FakeRecorder) {
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(policyv1.AddToScheme(scheme)).To(Succeed())
+		Expect(v1.AddToScheme(scheme)).To(Succeed())
+		key := types.NamespacedName{Namespace: "shop", Name: "web"}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop"}},
+			&policyv1.PodDisruptionBudget{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"}},
+			&v1.EvictionAutoScaler{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+				Spec:       v1.EvictionAutoScalerSpec{TargetName: targetName, TargetKind: v1.TargetKindDeployment},
+			},
+		).WithStatusSubresource(&v1.EvictionAutoScaler{}).Build()
+		recorder := record.NewFakeRecorder(10)
+		r := &EvictionAutoScalerReconciler{Client: c, Scheme: scheme, Recorder: recorder, Filter: namespacefilter.New(nil, false)}
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		eas := &v1.EvictionAutoScaler{}
+		Expect(c.Get(ctx, key, eas)).To(Succeed())
+		return eas, recorder
+	}
+
+	It("should reject a target in another namespace with a Degraded reason and an event", func() {
+		eas, recorder := reconcileTarget("payments/web")
+		degradedCond := meta.FindStatusCondition(eas.Status.Conditions, DegradedCondition)
+		Expect(degradedCond).NotTo(BeNil())
+		Expect(degradedCond.Reason).To(Equal("CrossNamespaceTarget"))
+		Expect(eas.Spec.TargetName).To(Equal("payments/web"))
+		Expect(recorder.Events).To(Receive(ContainSubstring("CrossNamespaceTarget")))
+	})
+
+	It("should drop the EvictionAutoScaler's own namespace from the target name", func() {
+		eas, recorder := reconcileTarget("shop/web")
+		Expect(eas.Spec.TargetName).To(Equal("web"))
+		Expect(recorder.Events).To(Receive(ContainSubstring("TargetNameConverted")))
+	})
+})
+
+var _ = Describe("reconcile steps", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+		key    = types.NamespacedName{Namespace: "default", Name: "web"}
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(policyv1.AddToScheme(scheme)).To(Succeed())
+		Expect(v1.AddToScheme(scheme)).To(Succeed())
+	})
+
+	// evicted returns a reconciler over an EvictionAutoScaler whose eviction, age
+	// old, is unhandled, and the EvictionAutoScaler as read back from its client.
+	evicted := func(age time.Duration) (*EvictionAutoScalerReconciler, *v1.EvictionAutoScaler) {
+		eas := &v1.EvictionAutoScaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: v1.EvictionAutoScalerSpec{TargetName: "web", TargetKind: v1.TargetKindDeployment,
+				LastEviction: v1.Eviction{PodName: "web-1", EvictionTime: metav1.NewTime(time.Now().Add(-age))}},
+			Status: v1.EvictionAutoScalerStatus{MinReplicas: 2},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(eas).
+			WithStatusSubresource(&v1.EvictionAutoScaler{}).Build()
+		got := &v1.EvictionAutoScaler{}
+		Expect(c.Get(ctx, key, got)).To(Succeed())
+		return &EvictionAutoScalerReconciler{Client: c, Scheme: scheme}, got
+	}
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+	}
+	readyReason := func(eas *v1.EvictionAutoScaler) string {
+		return meta.FindStatusCondition(eas.Status.Conditions, ReadyCondition).Reason
+	}
+
+	Describe("holdNewSurge", func() {
+		It("should record an eviction older than the freshness window without surging", func() {
+			r, eas := evicted(10 * time.Minute)
+			r.EvictionFreshness = 5 * time.Minute
+			stop, err := r.holdNewSurge(ctx, eas, pdb, false, 0, false, false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stop).ToNot(BeNil())
+			Expect(eas.Status.LastEviction).To(Equal(eas.Spec.LastEviction))
+			Expect(readyReason(eas)).To(Equal("StaleEvictionIgnored"))
+		})
+
+		It("should act on old evictions without a freshness window", func() {
+			r, eas := evicted(10 * time.Minute)
+			stop, err := r.holdNewSurge(ctx, eas, pdb, false, 0, false, false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stop).To(BeNil())
+			Expect(eas.Status.LastEviction).To(BeZero())
+		})
+
+		It("should record the eviction only while surging is disabled", func() {
+			r, eas := evicted(time.Second)
+			stop, err := r.holdNewSurge(ctx, eas, pdb, true, 0, false, false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stop).ToNot(BeNil())
+			Expect(readyReason(eas)).To(Equal("SurgeDisabled"))
+		})
+
+		It("should requeue a suppressed eviction when the suppression ends", func() {
+			r, eas := evicted(time.Second)
+			stop, err := r.holdNewSurge(ctx, eas, pdb, false, time.Minute, false, false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stop.RequeueAfter).To(Equal(time.Minute))
+			Expect(readyReason(eas)).To(Equal("SurgeSuppressed"))
+		})
+	})
+
+	Describe("featureGates", func() {
+		It("should turn surge batches on only when the reconciler enables them", func() {
+			_, eas := evicted(time.Second)
+			gates := (&EvictionAutoScalerReconciler{}).featureGates(ctx, eas)
+			Expect(gates.surgeBatches).To(BeFalse())
+			Expect(gates.capacityPrecheck).To(BeTrue())
+
+			gates = (&EvictionAutoScalerReconciler{SurgeBatches: true}).featureGates(ctx, eas)
+			Expect(gates.surgeBatches).To(BeTrue())
+		})
+	})
+
+	Describe("surgeStrategyFailed", func() {
+		It("should degrade without requeueing on a surge mode the user must fix", func() {
+			r, eas := evicted(time.Second)
+			result, err := r.surgeStrategyFailed(ctx, eas, fmt.Errorf("%w: %q", errInvalidSurgeMode, "sideways"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeZero())
+			Expect(meta.FindStatusCondition(eas.Status.Conditions, DegradedCondition).Reason).To(Equal("InvalidSurgeMode"))
+		})
+
+		It("should return other errors to be retried", func() {
+			r, eas := evicted(time.Second)
+			_, err := r.surgeStrategyFailed(ctx, eas, fmt.Errorf("listing HPAs: connection refused"))
+			Expect(err).To(HaveOccurred())
+			Expect(meta.FindStatusCondition(eas.Status.Conditions, DegradedCondition)).To(BeNil())
+		})
+	})
+})
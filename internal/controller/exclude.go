@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"context"
+
+	v1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/azure/eviction-autoscaler/internal/annotations"
+)
+
+// ExcludeAnnotationKey lets a workload or PDB owner opt it out inside an enabled
+// namespace.
+const ExcludeAnnotationKey = annotations.Exclude
+
+// excluded reports whether obj opts out of eviction-autoscaler through the exclude
+// annotation, and why. An invalid value excludes, as its owner meant to set it.
+func excluded(obj metav1.Object) (bool, string) {
+	val, ok := obj.GetAnnotations()[ExcludeAnnotationKey]
+	if !ok {
+		return false, ""
+	}
+	exclude, err := annotations.Bool(ExcludeAnnotationKey, val)
+	if err != nil {
+		return true, "unknown annotation value for exclude annotation " + val
+	}
+	if exclude {
+		return true, "exclude annotation set to true"
+	}
+	return false, ""
+}
+
+// triggerOnExcludeChange reports whether an update added, removed or changed the
+// exclude annotation.
+func triggerOnExcludeChange(e event.UpdateEvent) bool {
+	return e.ObjectOld.GetAnnotations()[ExcludeAnnotationKey] != e.ObjectNew.GetAnnotations()[ExcludeAnnotationKey]
+}
+
+// excludeChanged passes only updates that change the exclude annotation.
+var excludeChanged = builder.WithPredicates(predicate.Funcs{
+	CreateFunc:  func(event.CreateEvent) bool { return false },
+	DeleteFunc:  func(event.DeleteEvent) bool { return false },
+	GenericFunc: func(event.GenericEvent) bool { return false },
+	UpdateFunc:  triggerOnExcludeChange,
+})
+
+// requeuePDBsForWorkload maps a deployment or statefulset to the user-owned PDBs
+// selecting its pods. Controller-owned PDBs follow their deployment through
+// DeploymentToPDBReconciler instead.
+func requeuePDBsForWorkload(c client.Client) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		var templateLabels map[string]string
+		switch workload := obj.(type) {
+		case *v1.Deployment:
+			templateLabels = workload.Spec.Template.Labels
+		case *v1.StatefulSet:
+			templateLabels = workload.Spec.Template.Labels
+		default:
+			return nil
+		}
+
+		candidates, err := candidatePDBs(ctx, c, obj.GetNamespace(), templateLabels)
+		if err != nil {
+			log.FromContext(ctx).Error(err, "Failed to list PDBs in namespace", "namespace", obj.GetNamespace())
+			return nil
+		}
+		var requests []reconcile.Request
+		for _, pdb := range candidates {
+			if pdb.Annotations[PDBOwnedByAnnotationKey] == ControllerName {
+				continue
+			}
+			if selects, err := pdbSelectsTemplate(&pdb, templateLabels); err == nil && selects {
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&pdb)})
+			}
+		}
+		return requests
+	}
+}
@@ -0,0 +1,165 @@
+package controllers
+
+import (
+	"context"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/namespacefilter"
+)
+
+var _ = Describe("Exclude annotation", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+		key    = client.ObjectKey{Namespace: "default", Name: "web"}
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		Expect(policyv1.AddToScheme(scheme)).To(Succeed())
+		Expect(autoscalingv2.AddToScheme(scheme)).To(Succeed())
+		Expect(kedav1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(myappsv1.AddToScheme(scheme)).To(Succeed())
+	})
+
+	deployment := func(annotations map[string]string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Generation: 1, UID: "web-uid", Annotations: annotations},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: ptr.To(int32(2)),
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+				Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}}},
+				Strategy: appsv1.DeploymentStrategy{RollingUpdate: &appsv1.RollingUpdateDeployment{MaxSurge: ptr.To(intstr.FromInt32(1))}},
+			},
+		}
+	}
+	pdb := func(annotations map[string]string) *policyv1.PodDisruptionBudget {
+		return &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Annotations: annotations},
+			Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+		}
+	}
+	evictionAutoScaler := func(kind myappsv1.TargetKind) *myappsv1.EvictionAutoScaler {
+		return &myappsv1.EvictionAutoScaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec:       myappsv1.EvictionAutoScalerSpec{TargetName: "web", TargetKind: kind},
+		}
+	}
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+
+	It("should parse the annotation, excluding on invalid values", func() {
+		for val, want := range map[string]bool{"true": true, "false": false, "yes": true} {
+			exclude, reason := excluded(deployment(map[string]string{ExcludeAnnotationKey: val}))
+			Expect(exclude).To(Equal(want), "value %q", val)
+			Expect(reason == "").To(Equal(!want))
+		}
+		exclude, _ := excluded(deployment(nil))
+		Expect(exclude).To(BeFalse())
+	})
+
+	It("should delete the controller-owned PDB of an excluded deployment", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			namespace.DeepCopy(),
+			deployment(map[string]string{ExcludeAnnotationKey: "true"}),
+			pdb(map[string]string{PDBOwnedByAnnotationKey: ControllerName}),
+		).Build()
+		r := &DeploymentToPDBReconciler{Client: c, Scheme: scheme, Filter: namespacefilter.New(nil, false)}
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(apierrors.IsNotFound(c.Get(ctx, key, &policyv1.PodDisruptionBudget{}))).To(BeTrue())
+	})
+
+	It("should not create a PDB for an excluded deployment", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			namespace.DeepCopy(),
+			deployment(map[string]string{ExcludeAnnotationKey: "true"}),
+		).Build()
+		r := &DeploymentToPDBReconciler{Client: c, Scheme: scheme, Filter: namespacefilter.New(nil, false)}
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+		var pdbs policyv1.PodDisruptionBudgetList
+		Expect(c.List(ctx, &pdbs)).To(Succeed())
+		Expect(pdbs.Items).To(BeEmpty())
+	})
+
+	It("should delete the EvictionAutoScaler of an excluded PDB", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			namespace.DeepCopy(),
+			deployment(nil),
+			pdb(map[string]string{ExcludeAnnotationKey: "true"}),
+			evictionAutoScaler(myappsv1.TargetKindDeployment),
+		).Build()
+		r := &PDBToEvictionAutoScalerReconciler{Client: c, Scheme: scheme, Filter: namespacefilter.New(nil, false)}
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(apierrors.IsNotFound(c.Get(ctx, key, &myappsv1.EvictionAutoScaler{}))).To(BeTrue())
+	})
+
+	It("should delete the EvictionAutoScaler of a user-owned PDB once its statefulset is excluded", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			namespace.DeepCopy(),
+			&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Annotations: map[string]string{ExcludeAnnotationKey: "true"}}},
+			pdb(nil),
+			evictionAutoScaler(myappsv1.TargetKindStatefulSet),
+		).Build()
+		r := &PDBToEvictionAutoScalerReconciler{Client: c, Scheme: scheme, Filter: namespacefilter.New(nil, false)}
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(apierrors.IsNotFound(c.Get(ctx, key, &myappsv1.EvictionAutoScaler{}))).To(BeTrue())
+	})
+
+	It("should keep the EvictionAutoScaler when the exclude annotation is false", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			namespace.DeepCopy(),
+			deployment(map[string]string{ExcludeAnnotationKey: "false"}),
+			pdb(map[string]string{ExcludeAnnotationKey: "false"}),
+			evictionAutoScaler(myappsv1.TargetKindDeployment),
+		).Build()
+		r := &PDBToEvictionAutoScalerReconciler{Client: c, Scheme: scheme, Filter: namespacefilter.New(nil, false)}
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(c.Get(ctx, key, &myappsv1.EvictionAutoScaler{})).To(Succeed())
+	})
+
+	It("should not surge an excluded target", func() {
+		eas := evictionAutoScaler(myappsv1.TargetKindDeployment)
+		eas.Spec.SurgeMode = SurgeModeDirect
+		eas.Spec.LastEviction = myappsv1.Eviction{PodName: "web-1", EvictionTime: metav1.Now()}
+		eas.Status = myappsv1.EvictionAutoScalerStatus{MinReplicas: 2, TargetGeneration: 1}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			namespace.DeepCopy(),
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}, Spec: corev1.NodeSpec{Unschedulable: true}},
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default", Labels: map[string]string{"app": "web"}},
+				Spec: corev1.PodSpec{NodeName: "node-1"}},
+			deployment(map[string]string{ExcludeAnnotationKey: "true"}),
+			pdb(nil),
+			eas,
+		).WithStatusSubresource(&myappsv1.EvictionAutoScaler{}).Build()
+		r := &EvictionAutoScalerReconciler{Client: c, Scheme: scheme, Filter: namespacefilter.New(nil, false), APIReader: c}
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+
+		var dep appsv1.Deployment
+		Expect(c.Get(ctx, key, &dep)).To(Succeed())
+		Expect(*dep.Spec.Replicas).To(Equal(int32(2)))
+	})
+})
@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/namespacefilter"
+)
+
+// exemptFilter is implemented by namespace filters that manage single deployments
+// in namespaces they disable, like the controller's own with --protect-self.
+// Filters that don't implement it exempt nothing.
+type exemptFilter interface {
+	Exempt(deployment types.NamespacedName) bool
+}
+
+// deploymentEnabled reports whether eviction-autoscaler manages the deployment
+// named key: f enables its namespace or exempts it.
+func deploymentEnabled(ctx context.Context, f filter, c namespacefilter.Reader, key types.NamespacedName) (bool, error) {
+	if e, ok := f.(exemptFilter); ok && e.Exempt(key) {
+		return true, nil
+	}
+	return f.Filter(ctx, c, key.Namespace)
+}
+
+// autoscalerEnabled reports whether eviction-autoscaler manages the
+// EvictionAutoScaler, and the PDB, named key: f enables its namespace or exempts
+// the deployment the EvictionAutoScaler targets.
+func autoscalerEnabled(ctx context.Context, f filter, c namespacefilter.Reader, key types.NamespacedName) (bool, error) {
+	enabled, err := f.Filter(ctx, c, key.Namespace)
+	if err != nil || enabled {
+		return enabled, err
+	}
+	e, ok := f.(exemptFilter)
+	if !ok {
+		return false, nil
+	}
+	var eas myappsv1.EvictionAutoScaler
+	if err := c.Get(ctx, key, &eas); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	if eas.Spec.TargetKind.Normalized() != deploymentKind {
+		return false, nil
+	}
+	return e.Exempt(types.NamespacedName{Namespace: key.Namespace, Name: eas.Spec.TargetName}), nil
+}
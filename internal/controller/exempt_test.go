@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/namespacefilter"
+)
+
+var _ = Describe("Exempt deployments", func() {
+	It("should manage only the exempt deployment and its EvictionAutoScaler in a disabled namespace", func() {
+		ctx := context.Background()
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(myappsv1.AddToScheme(scheme)).To(Succeed())
+
+		own := types.NamespacedName{Namespace: "exempt", Name: "controller"}
+		other := types.NamespacedName{Namespace: "exempt", Name: "web"}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: own.Namespace}},
+			&myappsv1.EvictionAutoScaler{
+				ObjectMeta: metav1.ObjectMeta{Name: "controller-pdb", Namespace: own.Namespace},
+				Spec:       myappsv1.EvictionAutoScalerSpec{TargetKind: "deployments", TargetName: own.Name},
+			},
+			&myappsv1.EvictionAutoScaler{
+				ObjectMeta: metav1.ObjectMeta{Name: "web-pdb", Namespace: other.Namespace},
+				Spec:       myappsv1.EvictionAutoScalerSpec{TargetKind: deploymentKind, TargetName: other.Name},
+			},
+		).Build()
+		f := namespacefilter.New([]string{}, true).WithExempt(own)
+
+		Expect(deploymentEnabled(ctx, f, c, own)).To(BeTrue())
+		Expect(deploymentEnabled(ctx, f, c, other)).To(BeFalse())
+		Expect(autoscalerEnabled(ctx, f, c, types.NamespacedName{Namespace: own.Namespace, Name: "controller-pdb"})).To(BeTrue())
+		Expect(autoscalerEnabled(ctx, f, c, types.NamespacedName{Namespace: own.Namespace, Name: "web-pdb"})).To(BeFalse())
+		Expect(autoscalerEnabled(ctx, f, c, types.NamespacedName{Namespace: own.Namespace, Name: "missing"})).To(BeFalse())
+	})
+})
@@ -0,0 +1,32 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/metrics"
+)
+
+// surgeUnlikelyToHelp classifies the failure pattern of the pods selected by pdb and
+// records it in the SurgeUnlikelyToHelp condition. It reports true when the newest
+// pods are the failing ones: surge pods come from the newest template, so they would
+// crash the same way and never let the PDB allow a disruption.
+func surgeUnlikelyToHelp(ctx context.Context, c client.Client, eas *myappsv1.EvictionAutoScaler, pdb *policyv1.PodDisruptionBudget) (bool, error) {
+	selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+	if err != nil {
+		return false, fmt.Errorf("invalid PDB selector: %w", err)
+	}
+	var pods corev1.PodList
+	if err := c.List(ctx, &pods, client.InNamespace(pdb.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return false, fmt.Errorf("failed to list pods for PDB %s: %w", pdb.Name, err)
+	}
+	pattern := metrics.FailurePattern(pods.Items)
+	setSurgeUnlikelyToHelp(eas, pattern)
+	return pattern == metrics.NewestFailurePattern, nil
+}
@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/namespacefilter"
+)
+
+var _ = Describe("EvictionAutoScalerReconciler failure patterns", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		Expect(policyv1.AddToScheme(scheme)).To(Succeed())
+		Expect(autoscalingv2.AddToScheme(scheme)).To(Succeed())
+		Expect(kedav1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(myappsv1.AddToScheme(scheme)).To(Succeed())
+	})
+
+	pod := func(name string, age time.Duration, waiting string) *corev1.Pod {
+		p := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": "web"},
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-age))},
+			Spec: corev1.PodSpec{NodeName: "node-1"},
+		}
+		if waiting != "" {
+			p.Status.ContainerStatuses = []corev1.ContainerStatus{{State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: waiting}}}}
+		}
+		return p
+	}
+
+	// reconcile handles a fresh eviction with the given pods and returns the
+	// deployment and EvictionAutoScaler afterwards.
+	reconcile := func(pods ...client.Object) (*appsv1.Deployment, *myappsv1.EvictionAutoScaler) {
+		eas := &myappsv1.EvictionAutoScaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: myappsv1.EvictionAutoScalerSpec{TargetName: "web", TargetKind: myappsv1.TargetKindDeployment, SurgeMode: SurgeModeDirect,
+				LastEviction: myappsv1.Eviction{PodName: "web-1", EvictionTime: metav1.Now()}},
+			Status: myappsv1.EvictionAutoScalerStatus{MinReplicas: 2, TargetGeneration: 1},
+		}
+		objs := append([]client.Object{
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}, Spec: corev1.NodeSpec{Unschedulable: true}},
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Generation: 1},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: ptr.To(int32(2)),
+					Strategy: appsv1.DeploymentStrategy{RollingUpdate: &appsv1.RollingUpdateDeployment{MaxSurge: ptr.To(intstr.FromInt32(1))}},
+				},
+			},
+			&policyv1.PodDisruptionBudget{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+				Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+			},
+			eas,
+		}, pods...)
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).
+			WithStatusSubresource(&myappsv1.EvictionAutoScaler{}).Build()
+		r := &EvictionAutoScalerReconciler{Client: c, Scheme: scheme, Filter: namespacefilter.New(nil, false), APIReader: c}
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(eas)})
+		Expect(err).ToNot(HaveOccurred())
+
+		var dep appsv1.Deployment
+		Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "web"}, &dep)).To(Succeed())
+		var got myappsv1.EvictionAutoScaler
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(eas), &got)).To(Succeed())
+		return &dep, &got
+	}
+
+	It("should not surge when the newest pods are crashing", func() {
+		dep, eas := reconcile(pod("web-1", time.Hour, ""), pod("web-2", time.Minute, "CrashLoopBackOff"))
+		Expect(*dep.Spec.Replicas).To(Equal(int32(2)))
+		Expect(meta.IsStatusConditionTrue(eas.Status.Conditions, SurgeUnlikelyToHelpCondition)).To(BeTrue())
+		Expect(eas.Status.LastEviction).To(Equal(eas.Spec.LastEviction))
+	})
+
+	It("should surge when only older pods are failing", func() {
+		dep, eas := reconcile(pod("web-1", time.Hour, "CrashLoopBackOff"), pod("web-2", time.Minute, ""))
+		Expect(*dep.Spec.Replicas).To(Equal(int32(3)))
+		Expect(meta.IsStatusConditionFalse(eas.Status.Conditions, SurgeUnlikelyToHelpCondition)).To(BeTrue())
+	})
+})
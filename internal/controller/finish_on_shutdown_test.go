@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/namespacefilter"
+)
+
+var _ = Describe("Finishing reconciles on shutdown", func() {
+	key := types.NamespacedName{Namespace: "default", Name: "web"}
+
+	// reconcileStopped reconciles with a context cancelled like the manager's on
+	// shutdown, through a client that fails reads on a cancelled context as the real
+	// one does.
+	reconcileStopped := func(finish bool) (client.Client, error) {
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(myappsv1.AddToScheme(scheme)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+			&myappsv1.EvictionAutoScaler{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+				Spec:       myappsv1.EvictionAutoScalerSpec{TargetName: "web", TargetKind: myappsv1.TargetKindDeployment},
+			},
+		).WithStatusSubresource(&myappsv1.EvictionAutoScaler{}).WithInterceptorFuncs(interceptor.Funcs{
+			Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				return c.Get(ctx, key, obj, opts...)
+			},
+		}).Build()
+		r := &EvictionAutoScalerReconciler{Client: c, Scheme: scheme, Filter: namespacefilter.New(nil, false), FinishOnShutdown: finish}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		return c, err
+	}
+
+	It("should fail on the cancelled context by default", func() {
+		_, err := reconcileStopped(false)
+		Expect(err).To(MatchError(context.Canceled))
+	})
+
+	It("should finish and record the reconcile with FinishOnShutdown", func() {
+		c, err := reconcileStopped(true)
+		Expect(err).ToNot(HaveOccurred())
+		var eas myappsv1.EvictionAutoScaler
+		Expect(c.Get(context.Background(), key, &eas)).To(Succeed())
+		cond := meta.FindStatusCondition(eas.Status.Conditions, DegradedCondition)
+		Expect(cond).ToNot(BeNil())
+		Expect(cond.Reason).To(Equal("NoPdb"))
+	})
+})
@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/azure/eviction-autoscaler/internal/metrics"
+)
+
+// GaugeSynchronizer recomputes the deployment and PDB count gauges from the cache
+// every Interval, so they follow deletions and changes instead of only growing with
+// each reconcile. Only namespaces in this replica's shard are counted.
+type GaugeSynchronizer struct {
+	client.Reader
+	Filter   filter
+	Interval time.Duration
+}
+
+// Start syncs the gauges right away and then every Interval until ctx is done.
+func (s *GaugeSynchronizer) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("gauge-sync")
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+	for {
+		if err := s.sync(ctx); err != nil {
+			logger.Error(err, "failed to sync deployment and PDB gauges")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// NeedLeaderElection is true: standby replicas would report the same objects again.
+func (s *GaugeSynchronizer) NeedLeaderElection() bool {
+	return true
+}
+
+type deploymentSeries struct {
+	namespace, canCreatePDB string
+}
+
+type pdbSeries struct {
+	namespace, createdByUs, maxUnavailableZero, minAvailableEqualsReplicas string
+}
+
+// sync lists deployments and PDBs and replaces every series of both gauges. A list
+// error leaves the previous values in place.
+func (s *GaugeSynchronizer) sync(ctx context.Context) error {
+	var deployments appsv1.DeploymentList
+	if err := s.List(ctx, &deployments); err != nil {
+		return err
+	}
+	var pdbs policyv1.PodDisruptionBudgetList
+	if err := s.List(ctx, &pdbs); err != nil {
+		return err
+	}
+
+	deploymentCounts := map[deploymentSeries]int{}
+	for i := range deployments.Items {
+		deployment := &deployments.Items[i]
+		if !inShard(s.Filter, deployment.Namespace) {
+			continue
+		}
+		skip, _ := shouldSkipPDBCreation(deployment)
+		deploymentCounts[deploymentSeries{deployment.Namespace, strconv.FormatBool(!skip)}]++
+	}
+	pdbCounts := map[pdbSeries]int{}
+	for i := range pdbs.Items {
+		pdb := &pdbs.Items[i]
+		if !inShard(s.Filter, pdb.Namespace) {
+			continue
+		}
+		pdbCounts[pdbSeries{
+			namespace:                  pdb.Namespace,
+			createdByUs:                metrics.GetPDBCreatedByUsLabel(pdb.Annotations),
+			maxUnavailableZero:         strconv.FormatBool(maxUnavailableZero(pdb)),
+			minAvailableEqualsReplicas: strconv.FormatBool(minAvailableEqualsReplicas(pdb)),
+		}]++
+	}
+
+	metrics.DeploymentGauge.Reset()
+	for series, count := range deploymentCounts {
+		metrics.DeploymentGauge.WithLabelValues(series.namespace, series.canCreatePDB).Set(float64(count))
+	}
+	metrics.PDBGauge.Reset()
+	for series, count := range pdbCounts {
+		metrics.PDBGauge.WithLabelValues(series.namespace, series.createdByUs, series.maxUnavailableZero, series.minAvailableEqualsReplicas).Set(float64(count))
+	}
+	return nil
+}
+
+// maxUnavailableZero reports whether pdb never allows a pod to be unavailable.
+func maxUnavailableZero(pdb *policyv1.PodDisruptionBudget) bool {
+	if pdb.Spec.MaxUnavailable == nil {
+		return false
+	}
+	maxUnavailable, err := intstr.GetScaledValueFromIntOrPercent(pdb.Spec.MaxUnavailable, int(pdb.Status.ExpectedPods), true)
+	return err == nil && maxUnavailable == 0
+}
+
+// minAvailableEqualsReplicas reports whether pdb requires every pod it expects to be
+// available. The expected count comes from the disruption controller, so a PDB it
+// hasn't processed yet doesn't count.
+func minAvailableEqualsReplicas(pdb *policyv1.PodDisruptionBudget) bool {
+	if pdb.Spec.MinAvailable == nil || pdb.Status.ExpectedPods == 0 {
+		return false
+	}
+	minAvailable, err := intstr.GetScaledValueFromIntOrPercent(pdb.Spec.MinAvailable, int(pdb.Status.ExpectedPods), true)
+	return err == nil && minAvailable >= int(pdb.Status.ExpectedPods)
+}
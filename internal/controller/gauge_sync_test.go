@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	appsv1 "k8s.io/api/apps/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/azure/eviction-autoscaler/internal/metrics"
+)
+
+var _ = Describe("GaugeSynchronizer", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		Expect(policyv1.AddToScheme(scheme)).To(Succeed())
+	})
+
+	deployment := func(name string, annotations map[string]string) *appsv1.Deployment {
+		return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "gauges", Annotations: annotations}}
+	}
+	pdb := func(name string, minAvailable, maxUnavailable *intstr.IntOrString, expected int32, annotations map[string]string) *policyv1.PodDisruptionBudget {
+		return &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "gauges", Annotations: annotations},
+			Spec:       policyv1.PodDisruptionBudgetSpec{MinAvailable: minAvailable, MaxUnavailable: maxUnavailable},
+			Status:     policyv1.PodDisruptionBudgetStatus{ExpectedPods: expected},
+		}
+	}
+
+	It("should replace the gauges with counts from the cache", func() {
+		three, zero, one := intstr.FromInt32(3), intstr.FromInt32(0), intstr.FromInt32(1)
+		objs := []client.Object{
+			deployment("web", nil),
+			deployment("api", nil),
+			deployment("batch", map[string]string{PDBCreateAnnotationKey: "false"}),
+			pdb("web", &three, nil, 3, map[string]string{"createdBy": "DeploymentToPDBController"}),
+			pdb("api", nil, &zero, 2, nil),
+			pdb("batch", &one, nil, 3, nil),
+		}
+		// A stale series from an earlier reconcile must not survive the sync.
+		metrics.DeploymentGauge.WithLabelValues("gone", metrics.CanCreatePDBStr).Set(7)
+
+		s := &GaugeSynchronizer{Reader: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()}
+		Expect(s.sync(ctx)).To(Succeed())
+
+		Expect(testutil.ToFloat64(metrics.DeploymentGauge.WithLabelValues("gauges", "true"))).To(Equal(2.0))
+		Expect(testutil.ToFloat64(metrics.DeploymentGauge.WithLabelValues("gauges", "false"))).To(Equal(1.0))
+		Expect(metrics.DeploymentGauge.DeleteLabelValues("gone", metrics.CanCreatePDBStr)).To(BeFalse())
+		Expect(testutil.ToFloat64(metrics.PDBGauge.WithLabelValues("gauges", "true", "false", "true"))).To(Equal(1.0))
+		Expect(testutil.ToFloat64(metrics.PDBGauge.WithLabelValues("gauges", "false", "true", "false"))).To(Equal(1.0))
+		Expect(testutil.ToFloat64(metrics.PDBGauge.WithLabelValues("gauges", "false", "false", "false"))).To(Equal(1.0))
+	})
+
+	It("should tell PDBs that block every eviction apart", func() {
+		all, half, zeroPercent := intstr.FromString("100%"), intstr.FromString("50%"), intstr.FromString("0%")
+		Expect(minAvailableEqualsReplicas(pdb("a", &all, nil, 4, nil))).To(BeTrue())
+		Expect(minAvailableEqualsReplicas(pdb("b", &half, nil, 4, nil))).To(BeFalse())
+		Expect(minAvailableEqualsReplicas(pdb("c", &all, nil, 0, nil))).To(BeFalse())
+		Expect(maxUnavailableZero(pdb("d", nil, &zeroPercent, 4, nil))).To(BeTrue())
+		Expect(maxUnavailableZero(pdb("e", nil, &half, 4, nil))).To(BeFalse())
+	})
+})
@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/apps/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/annotations"
+)
+
+// GenerateForDeployment returns the PDB and EvictionAutoScaler the controllers would
+// create for the named deployment, as user-owned objects to commit to source
+// control: without owner references or the ownedBy and target annotations, so the
+// controllers neither adopt nor delete them. Deployments the controllers would not
+// create a PDB for are an error.
+func GenerateForDeployment(ctx context.Context, c client.Client, namespace, name string) (*policyv1.PodDisruptionBudget, *myappsv1.EvictionAutoScaler, error) {
+	var deployment v1.Deployment
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &deployment); err != nil {
+		return nil, nil, err
+	}
+	if exclude, reason := excluded(&deployment); exclude {
+		return nil, nil, fmt.Errorf("deployment %s/%s is excluded: %s", namespace, name, reason)
+	}
+	if skip, reason := shouldSkipPDBCreation(&deployment); skip {
+		return nil, nil, fmt.Errorf("deployment %s/%s gets no PDB: %s", namespace, name, reason)
+	}
+	pdb, err := NewPDBForDeployment(ctx, c, &deployment)
+	if err != nil {
+		return nil, nil, err
+	}
+	eas := NewEvictionAutoScalerForPDB(pdb, deploymentKind, deployment.Name)
+	userOwned(pdb)
+	userOwned(eas)
+	return pdb, eas, nil
+}
+
+// userOwned strips what marks obj as created by the controllers.
+func userOwned(obj client.Object) {
+	obj.SetOwnerReferences(nil)
+	objAnnotations := obj.GetAnnotations()
+	delete(objAnnotations, annotations.OwnedBy)
+	delete(objAnnotations, annotations.Target)
+	delete(objAnnotations, annotations.Owner)
+	if len(objAnnotations) == 0 {
+		objAnnotations = nil
+	}
+	obj.SetAnnotations(objAnnotations)
+}
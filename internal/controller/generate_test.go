@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"context"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+)
+
+var _ = Describe("GenerateForDeployment", func() {
+	var (
+		ctx        context.Context
+		scheme     *runtime.Scheme
+		deployment *v1.Deployment
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(v1.AddToScheme(scheme)).To(Succeed())
+		Expect(autoscalingv2.AddToScheme(scheme)).To(Succeed())
+		Expect(kedav1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(myappsv1.AddToScheme(scheme)).To(Succeed())
+		deployment = &v1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop", UID: "uid-1"},
+			Spec: v1.DeploymentSpec{
+				Replicas: ptr.To(int32(3)),
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			},
+		}
+	})
+
+	generate := func(objs ...client.Object) (client.Object, client.Object, error) {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+		return GenerateForDeployment(ctx, c, "shop", "web")
+	}
+
+	It("should generate user-owned objects matching what the controllers create", func() {
+		pdb, eas, err := generate(deployment)
+		Expect(err).ToNot(HaveOccurred())
+
+		created, err := NewPDBForDeployment(ctx, fake.NewClientBuilder().WithScheme(scheme).Build(), deployment)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pdb.GetName()).To(Equal("web"))
+		Expect(pdb).To(HaveField("Spec", created.Spec))
+		Expect(pdb.GetOwnerReferences()).To(BeEmpty())
+		Expect(pdb.GetAnnotations()).To(BeEmpty())
+
+		Expect(eas).To(HaveField("Spec.TargetName", "web"))
+		Expect(eas).To(HaveField("Spec.TargetKind", deploymentKind))
+		Expect(eas).To(HaveField("TypeMeta.Kind", "EvictionAutoScaler"))
+		Expect(eas.GetOwnerReferences()).To(BeEmpty())
+		Expect(eas.GetAnnotations()).To(BeEmpty())
+	})
+
+	It("should refuse deployments the controllers create no PDB for", func() {
+		deployment.Annotations = map[string]string{PDBCreateAnnotationKey: "false"}
+		_, _, err := generate(deployment)
+		Expect(err).To(MatchError(ContainSubstring("pdb-create annotation set to false")))
+	})
+})
@@ -0,0 +1,188 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/metrics"
+)
+
+// Headroom sources selectable with --headroom-source.
+const (
+	MetricsServerHeadroomSource = "metrics-server"
+	PrometheusHeadroomSource    = "prometheus"
+)
+
+// surgeDeferredReason is the Ready reason of an EvictionAutoScaler whose surge waits
+// for headroom.
+const surgeDeferredReason = "SurgeDeferred"
+
+// nodeMetricsGVK is the list kind metrics-server serves node usage as. It is read
+// as unstructured to avoid a dependency on k8s.io/metrics for two fields.
+var nodeMetricsGVK = schema.GroupVersionKind{Group: "metrics.k8s.io", Version: "v1beta1", Kind: "NodeMetricsList"}
+
+// HeadroomSource reports the fraction of the cluster's capacity that is free, from
+// 0 to 1.
+type HeadroomSource interface {
+	Headroom(ctx context.Context) (float64, error)
+}
+
+// HeadroomGuard defers new surges while cluster headroom is below Threshold, so a
+// surge doesn't make a capacity crunch worse with pods that can't be scheduled.
+// Readings are shared by all EvictionAutoScalers and refreshed every Interval.
+type HeadroomGuard struct {
+	Source    HeadroomSource
+	Threshold float64
+	Interval  time.Duration
+
+	mu       sync.Mutex
+	readAt   time.Time
+	headroom float64
+	err      error
+}
+
+// Low reports whether headroom is below the threshold. A source that fails is
+// reported as an error and never as low: losing the signal must not stop surges.
+func (g *HeadroomGuard) Low(ctx context.Context) (bool, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.readAt.IsZero() || time.Since(g.readAt) >= g.Interval {
+		g.headroom, g.err = g.Source.Headroom(ctx)
+		g.readAt = time.Now()
+		if g.err != nil {
+			ctrl.LoggerFrom(ctx).Error(g.err, "failed to read cluster headroom, surges are not deferred")
+		} else {
+			metrics.ClusterHeadroomGauge.Set(g.headroom)
+		}
+	}
+	if g.err != nil {
+		return false, g.err
+	}
+	return g.headroom < g.Threshold, nil
+}
+
+// surgeDeferred checks cluster headroom and records the result in the
+// SurgeDeferred condition of eas, reporting whether new surges should wait. A nil
+// guard never defers.
+func (g *HeadroomGuard) surgeDeferred(ctx context.Context, eas *myappsv1.EvictionAutoScaler) bool {
+	if g == nil {
+		setCondition(eas, SurgeDeferredCondition, metav1.ConditionFalse, "HeadroomNotMonitored", "no cluster headroom source is configured")
+		return false
+	}
+	low, err := g.Low(ctx)
+	threshold := fmt.Sprintf("%.0f%%", g.Threshold*100)
+	switch {
+	case err != nil:
+		setCondition(eas, SurgeDeferredCondition, metav1.ConditionFalse, "HeadroomUnknown", "cluster headroom could not be read, surges are allowed: "+err.Error())
+	case low:
+		setCondition(eas, SurgeDeferredCondition, metav1.ConditionTrue, "LowClusterHeadroom", "cluster headroom is below "+threshold+", new surges wait for it to recover")
+	default:
+		setCondition(eas, SurgeDeferredCondition, metav1.ConditionFalse, "HeadroomAvailable", "cluster headroom is at least "+threshold)
+	}
+	return low
+}
+
+// surgeWasDeferred reports whether the last reconcile of eas deferred a surge, so
+// retries of the same deferral aren't counted again.
+func surgeWasDeferred(eas *myappsv1.EvictionAutoScaler) bool {
+	readyCondition := meta.FindStatusCondition(eas.Status.Conditions, ReadyCondition)
+	return readyCondition != nil && readyCondition.Reason == surgeDeferredReason
+}
+
+// MetricsServerHeadroom reads headroom from metrics-server: the free share of CPU
+// or memory, whichever is scarcer, over the schedulable nodes that report usage.
+// Reader must not be a cached client, since node metrics can't be watched.
+type MetricsServerHeadroom struct {
+	Reader client.Reader
+}
+
+func (m MetricsServerHeadroom) Headroom(ctx context.Context) (float64, error) {
+	var nodes corev1.NodeList
+	if err := m.Reader.List(ctx, &nodes); err != nil {
+		return 0, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	usage := &unstructured.UnstructuredList{}
+	usage.SetGroupVersionKind(nodeMetricsGVK)
+	if err := m.Reader.List(ctx, usage); err != nil {
+		return 0, fmt.Errorf("failed to list node metrics: %w", err)
+	}
+	used := map[string]corev1.ResourceList{}
+	for _, item := range usage.Items {
+		values, _, err := unstructured.NestedStringMap(item.Object, "usage")
+		if err != nil {
+			return 0, fmt.Errorf("invalid usage for node %s: %w", item.GetName(), err)
+		}
+		list := corev1.ResourceList{}
+		for name, value := range values {
+			quantity, err := resource.ParseQuantity(value)
+			if err != nil {
+				return 0, fmt.Errorf("invalid %s usage for node %s: %w", name, item.GetName(), err)
+			}
+			list[corev1.ResourceName(name)] = quantity
+		}
+		used[item.GetName()] = list
+	}
+
+	var allocatable, inUse corev1.ResourceList
+	for _, node := range nodes.Items {
+		nodeUsage, ok := used[node.Name]
+		if node.Spec.Unschedulable || !ok {
+			continue
+		}
+		allocatable = addRequests(allocatable, node.Status.Allocatable, 1)
+		inUse = addRequests(inUse, nodeUsage, 1)
+	}
+	if allocatable == nil {
+		return 0, errors.New("no schedulable node reports metrics")
+	}
+	headroom := 1.0
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		capacity, ok := allocatable[name]
+		if !ok || capacity.IsZero() {
+			continue
+		}
+		usedQuantity := inUse[name]
+		headroom = min(headroom, 1-usedQuantity.AsApproximateFloat64()/capacity.AsApproximateFloat64())
+	}
+	return max(headroom, 0), nil
+}
+
+// PrometheusHeadroom reads headroom from a Prometheus query that evaluates to a
+// scalar, or a vector whose first sample is used, between 0 and 1.
+type PrometheusHeadroom struct {
+	API   promv1.API
+	Query string
+}
+
+func (p PrometheusHeadroom) Headroom(ctx context.Context) (float64, error) {
+	value, _, err := p.API.Query(ctx, p.Query, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("headroom query failed: %w", err)
+	}
+	switch v := value.(type) {
+	case *model.Scalar:
+		return float64(v.Value), nil
+	case model.Vector:
+		if len(v) == 0 {
+			return 0, errors.New("headroom query returned no samples")
+		}
+		return float64(v[0].Value), nil
+	default:
+		return 0, fmt.Errorf("headroom query returned a %s, want a scalar or vector", value.Type())
+	}
+}
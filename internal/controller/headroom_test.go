@@ -0,0 +1,180 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/namespacefilter"
+)
+
+// fixedHeadroom is a HeadroomSource returning a set reading, counting reads.
+type fixedHeadroom struct {
+	headroom float64
+	err      error
+	reads    int
+}
+
+func (f *fixedHeadroom) Headroom(context.Context) (float64, error) {
+	f.reads++
+	return f.headroom, f.err
+}
+
+var _ = Describe("EvictionAutoScalerReconciler cluster headroom", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		Expect(policyv1.AddToScheme(scheme)).To(Succeed())
+		Expect(autoscalingv2.AddToScheme(scheme)).To(Succeed())
+		Expect(kedav1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(myappsv1.AddToScheme(scheme)).To(Succeed())
+	})
+
+	// reconcile handles a fresh eviction with the given guard and returns the
+	// deployment and EvictionAutoScaler afterwards.
+	reconcile := func(guard *HeadroomGuard) (*appsv1.Deployment, *myappsv1.EvictionAutoScaler, ctrl.Result) {
+		eas := &myappsv1.EvictionAutoScaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: myappsv1.EvictionAutoScalerSpec{TargetName: "web", TargetKind: myappsv1.TargetKindDeployment, SurgeMode: SurgeModeDirect,
+				LastEviction: myappsv1.Eviction{PodName: "web-1", EvictionTime: metav1.Now()}},
+			Status: myappsv1.EvictionAutoScalerStatus{MinReplicas: 2, TargetGeneration: 1},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}, Spec: corev1.NodeSpec{Unschedulable: true}},
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default", Labels: map[string]string{"app": "web"}},
+				Spec: corev1.PodSpec{NodeName: "node-1"}},
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Generation: 1},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: ptr.To(int32(2)),
+					Strategy: appsv1.DeploymentStrategy{RollingUpdate: &appsv1.RollingUpdateDeployment{MaxSurge: ptr.To(intstr.FromInt32(1))}},
+				},
+			},
+			&policyv1.PodDisruptionBudget{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+				Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+			},
+			eas,
+		).WithStatusSubresource(&myappsv1.EvictionAutoScaler{}).Build()
+		r := &EvictionAutoScalerReconciler{Client: c, Scheme: scheme, Filter: namespacefilter.New(nil, false), APIReader: c, Headroom: guard}
+		result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(eas)})
+		Expect(err).ToNot(HaveOccurred())
+
+		var dep appsv1.Deployment
+		Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "web"}, &dep)).To(Succeed())
+		var got myappsv1.EvictionAutoScaler
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(eas), &got)).To(Succeed())
+		return &dep, &got, result
+	}
+
+	It("should defer the surge and leave the eviction pending while headroom is low", func() {
+		guard := &HeadroomGuard{Source: &fixedHeadroom{headroom: 0.05}, Threshold: 0.1, Interval: time.Minute}
+		dep, eas, result := reconcile(guard)
+		Expect(*dep.Spec.Replicas).To(Equal(int32(2)))
+		Expect(meta.IsStatusConditionTrue(eas.Status.Conditions, SurgeDeferredCondition)).To(BeTrue())
+		Expect(eas.Status.LastEviction).ToNot(Equal(eas.Spec.LastEviction))
+		Expect(result.RequeueAfter).To(Equal(time.Minute))
+	})
+
+	It("should surge when headroom is above the threshold", func() {
+		guard := &HeadroomGuard{Source: &fixedHeadroom{headroom: 0.5}, Threshold: 0.1, Interval: time.Minute}
+		dep, eas, _ := reconcile(guard)
+		Expect(*dep.Spec.Replicas).To(Equal(int32(3)))
+		Expect(meta.IsStatusConditionFalse(eas.Status.Conditions, SurgeDeferredCondition)).To(BeTrue())
+	})
+
+	It("should surge when the headroom source fails", func() {
+		guard := &HeadroomGuard{Source: &fixedHeadroom{err: errors.New("prometheus unreachable")}, Threshold: 0.1, Interval: time.Minute}
+		dep, eas, _ := reconcile(guard)
+		Expect(*dep.Spec.Replicas).To(Equal(int32(3)))
+		Expect(meta.FindStatusCondition(eas.Status.Conditions, SurgeDeferredCondition).Reason).To(Equal("HeadroomUnknown"))
+	})
+
+	It("should report SurgeDeferred False without a headroom source", func() {
+		_, eas, _ := reconcile(nil)
+		Expect(meta.FindStatusCondition(eas.Status.Conditions, SurgeDeferredCondition).Reason).To(Equal("HeadroomNotMonitored"))
+	})
+
+	It("should read the source once per interval", func() {
+		source := &fixedHeadroom{headroom: 0.5}
+		guard := &HeadroomGuard{Source: source, Threshold: 0.1, Interval: time.Hour}
+		for range 3 {
+			low, err := guard.Low(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(low).To(BeFalse())
+		}
+		Expect(source.reads).To(Equal(1))
+	})
+
+	It("should compute headroom from the scarcer of CPU and memory on schedulable nodes", func() {
+		node := func(name string, unschedulable bool) corev1.Node {
+			return corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Spec:       corev1.NodeSpec{Unschedulable: unschedulable},
+				Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("4"),
+					corev1.ResourceMemory: resource.MustParse("16Gi"),
+				}},
+			}
+		}
+		usage := func(name, cpu, memory string) unstructured.Unstructured {
+			return unstructured.Unstructured{Object: map[string]interface{}{
+				"metadata": map[string]interface{}{"name": name},
+				"usage":    map[string]interface{}{"cpu": cpu, "memory": memory},
+			}}
+		}
+		reader := nodeMetricsReader{
+			nodes: []corev1.Node{node("node-1", false), node("node-2", false), node("node-3", true)},
+			usage: []unstructured.Unstructured{usage("node-1", "2", "4Gi"), usage("node-2", "4", "4Gi"), usage("node-3", "0", "0")},
+		}
+		headroom, err := MetricsServerHeadroom{Reader: reader}.Headroom(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		// CPU is 6 of 8 cores used, memory 8 of 32Gi: CPU is scarcer.
+		Expect(headroom).To(BeNumerically("~", 0.25, 0.001))
+	})
+})
+
+// nodeMetricsReader serves nodes and metrics-server node usage, which the fake
+// client can't, as the kind isn't in any scheme.
+type nodeMetricsReader struct {
+	client.Reader
+	nodes []corev1.Node
+	usage []unstructured.Unstructured
+}
+
+func (r nodeMetricsReader) List(_ context.Context, list client.ObjectList, _ ...client.ListOption) error {
+	switch l := list.(type) {
+	case *corev1.NodeList:
+		l.Items = r.nodes
+	case *unstructured.UnstructuredList:
+		l.Items = r.usage
+	}
+	return nil
+}
@@ -29,16 +29,17 @@ import (
 // raised minReplicas prevents it from scaling back down below the surge value on
 // its next successful metrics evaluation.
 //
-// Note: the HPA controller uses the /scale subresource to set deployment replicas,
-// which is equivalent to updating deployment.spec.replicas directly. Both result in
-// the same field being set; the /scale subresource is just a convenience API that
-// avoids sending the full deployment object. However, /scale does NOT bump
-// deployment metadata.generation, while a full Update does (if spec changes).
+// Note: replicas are written through the /scale subresource, the same way the HPA
+// controller does. It sets the same field as updating deployment.spec.replicas but
+// avoids sending the full deployment object, so it can't conflict with other writers
+// of the deployment's metadata or template.
 
 type HPASurgeApplier struct {
 	client client.Client
 	hpa    *autoscalingv2.HorizontalPodAutoscaler
 	target Surger
+	// floor is set by withScaleDownFloor.
+	floor *int32
 }
 
 var _ SurgeApplier = &HPASurgeApplier{}
@@ -80,14 +81,12 @@ func (h *HPASurgeApplier) ApplySurge(ctx context.Context, surgeReplicas int32) e
 			"minReplicas", surgeReplicas, "originalMin", hpa.Annotations[OriginalMinReplicasAnnotationKey])
 	}
 
-	// Step 2: Set deployment replicas directly for immediate scale-up.
+	// Step 2: Set deployment replicas through /scale for immediate scale-up.
 	// See type-level comment for why this is needed alongside the HPA update.
-	// On 409 Conflict (stale resourceVersion), the error propagates to the
-	// reconcile loop which requeues. On retry, step 1 is skipped (idempotent)
-	// and step 2 retries with a fresh object from the informer cache.
+	// On failure the error propagates to the reconcile loop which requeues. On
+	// retry, step 1 is skipped (idempotent) and step 2 is retried.
 	if h.target.GetReplicas() != surgeReplicas {
-		h.target.SetReplicas(surgeReplicas)
-		if err := h.client.Update(ctx, h.target.Obj()); err != nil {
+		if err := scaleSubresource(ctx, h.client, h.target, surgeReplicas); err != nil {
 			return fmt.Errorf("setting deployment replicas: %w", err)
 		}
 		logger.V(1).Info("Set deployment replicas for immediate surge", "replicas", surgeReplicas)
@@ -110,6 +109,9 @@ func (h *HPASurgeApplier) RevertSurge(ctx context.Context, originalMinReplicas i
 			}
 		}
 	}
+	if h.floor != nil {
+		revertTo = max(revertTo, *h.floor)
+	}
 
 	// Revert HPA minReplicas and remove both surge annotations in a single write.
 	hpa := h.hpa.DeepCopy()
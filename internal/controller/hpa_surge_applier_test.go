@@ -95,5 +95,19 @@ var _ = Describe("HPASurgeApplier", func() {
 			Expect(reverted.Annotations).ToNot(HaveKey(EvictionSurgeReplicasAnnotationKey))
 			Expect(reverted.Annotations).ToNot(HaveKey(OriginalMinReplicasAnnotationKey))
 		})
+
+		It("should not revert below the scale-down floor", func() {
+			Expect(applier.ApplySurge(ctx, 4)).To(Succeed())
+
+			var surged autoscalingv2.HorizontalPodAutoscaler
+			Expect(applier.client.Get(ctx, keyFor(hpa), &surged)).To(Succeed())
+			applier.hpa = &surged
+			withScaleDownFloor(applier, ptr.To(int32(3)))
+			Expect(applier.RevertSurge(ctx, 3)).To(Succeed())
+
+			var reverted autoscalingv2.HorizontalPodAutoscaler
+			Expect(applier.client.Get(ctx, keyFor(hpa), &reverted)).To(Succeed())
+			Expect(*reverted.Spec.MinReplicas).To(Equal(int32(3))) // floor, not the annotated 1
+		})
 	})
 })
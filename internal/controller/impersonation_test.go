@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Impersonator", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+	})
+
+	namespace := func(annotations map[string]string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant", Annotations: annotations}}
+	}
+
+	newImpersonator := func() *Impersonator {
+		return &Impersonator{
+			Config: &rest.Config{Host: "https://example.invalid"},
+			Scheme: scheme,
+			Mapper: meta.NewDefaultRESTMapper(nil),
+		}
+	}
+
+	It("returns the controller client for namespaces without the annotation", func() {
+		fc := fake.NewClientBuilder().WithScheme(scheme).WithObjects(namespace(nil)).Build()
+		c, err := newImpersonator().ClientFor(ctx, fc, "tenant")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(c).To(BeIdenticalTo(fc))
+	})
+
+	It("returns a cached impersonating client for annotated namespaces", func() {
+		fc := fake.NewClientBuilder().WithScheme(scheme).WithObjects(namespace(map[string]string{
+			ImpersonateServiceAccountAnnotationKey: "surge-writer",
+		})).Build()
+		i := newImpersonator()
+
+		c, err := i.ClientFor(ctx, fc, "tenant")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(c).ToNot(BeIdenticalTo(fc))
+		Expect(i.clients).To(HaveKey("system:serviceaccount:tenant:surge-writer"))
+
+		again, err := i.ClientFor(ctx, fc, "tenant")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(again).To(BeIdenticalTo(c))
+	})
+
+	It("fails when the namespace can't be read", func() {
+		fc := fake.NewClientBuilder().WithScheme(scheme).Build()
+		_, err := newImpersonator().ClientFor(ctx, fc, "tenant")
+		Expect(err).To(HaveOccurred())
+	})
+})
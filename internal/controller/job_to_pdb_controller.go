@@ -0,0 +1,262 @@
+package controllers
+
+import (
+	"context"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/azure/eviction-autoscaler/internal/annotations"
+	"github.com/azure/eviction-autoscaler/internal/metrics"
+)
+
+// JobPDBAnnotationKey opts a Job, or every Job of a CronJob, into PDB creation.
+const JobPDBAnnotationKey = annotations.JobPDB
+
+// JobToPDBReconciler creates a PDB for each running Job that opts in through the
+// job-pdb annotation, so drains wait for long-running Jobs instead of killing them.
+// The PDB is deleted once the Job completes or fails. Jobs can't be surged, so these
+// PDBs get no EvictionAutoScaler.
+type JobToPDBReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	Filter   filter
+}
+
+// +kubebuilder:rbac:groups=batch,resources=jobs;cronjobs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=delete
+
+// Reconcile creates or deletes the PDB of a Job.
+func (r *JobToPDBReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	var job batchv1.Job
+	if err := r.Get(ctx, req.NamespacedName, &job); err != nil {
+		if apierrors.IsNotFound(err) && !ownerReferences {
+			return reconcile.Result{}, r.deleteOrphanedPDB(ctx, req.NamespacedName)
+		}
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+
+	isEnabled, err := r.Filter.Filter(ctx, r.Client, job.Namespace)
+	if err != nil {
+		logger.Error(err, "Failed to check if eviction autoscaler is enabled", "namespace", job.Namespace)
+		return reconcile.Result{}, err
+	}
+	if !isEnabled {
+		return reconcile.Result{}, r.deleteOwnedPDB(ctx, &job, "job in disabled namespace")
+	}
+	if exclude, reason := excluded(&job); exclude {
+		logger.V(1).Info("Job excluded from eviction autoscaler", "job", job.Name, "reason", reason)
+		return reconcile.Result{}, r.deleteOwnedPDB(ctx, &job, "excluded job")
+	}
+	optedIn, err := r.optedIn(ctx, &job)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if !optedIn {
+		return reconcile.Result{}, r.deleteOwnedPDB(ctx, &job, "job without job-pdb annotation")
+	}
+	if jobFinished(&job) || !job.DeletionTimestamp.IsZero() {
+		return reconcile.Result{}, r.deleteOwnedPDB(ctx, &job, "finished job")
+	}
+
+	// Leave the Job alone if its pods already have a PDB, ours or the user's.
+	candidates, err := candidatePDBs(ctx, r.Client, job.Namespace, job.Spec.Template.Labels)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	for i := range candidates {
+		if ok, err := pdbSelectsTemplate(&candidates[i], job.Spec.Template.Labels); err == nil && ok {
+			return reconcile.Result{}, nil
+		}
+	}
+
+	if err := r.Create(ctx, newPDBForJob(&job)); err != nil {
+		if apierrors.IsAlreadyExists(err) && r.Recorder != nil {
+			r.Recorder.Eventf(&job, corev1.EventTypeWarning, "JobPDBNameTaken",
+				"PodDisruptionBudget %s already exists for other pods; the job is not protected", job.Name)
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	metrics.PDBCreationCounter.WithLabelValues(job.Namespace, metrics.Name(job.Name)).Inc()
+	logDecision(ctx, decisionPDBCreated, job.Namespace+"/"+job.Name, "created PodDisruptionBudget for job")
+	return reconcile.Result{}, nil
+}
+
+// optedIn reports whether the Job, or failing an annotation on it the CronJob that
+// created it, sets the job-pdb annotation to true. An invalid value opts out.
+func (r *JobToPDBReconciler) optedIn(ctx context.Context, job *batchv1.Job) (bool, error) {
+	val, ok := job.Annotations[JobPDBAnnotationKey]
+	if !ok {
+		owner := metav1.GetControllerOf(job)
+		if owner == nil || owner.Kind != "CronJob" {
+			return false, nil
+		}
+		var cronJob batchv1.CronJob
+		if err := r.Get(ctx, types.NamespacedName{Namespace: job.Namespace, Name: owner.Name}, &cronJob); err != nil {
+			return false, client.IgnoreNotFound(err)
+		}
+		if val, ok = cronJob.Annotations[JobPDBAnnotationKey]; !ok {
+			return false, nil
+		}
+	}
+	optedIn, err := annotations.Bool(JobPDBAnnotationKey, val)
+	if err != nil {
+		log.FromContext(ctx).Info("Ignoring invalid job-pdb annotation", "job", job.Name, "value", val)
+		return false, nil
+	}
+	return optedIn, nil
+}
+
+// deleteOwnedPDB deletes the PDB this controller created for job, if any.
+func (r *JobToPDBReconciler) deleteOwnedPDB(ctx context.Context, job *batchv1.Job, why string) error {
+	var pdb policyv1.PodDisruptionBudget
+	if err := r.Get(ctx, types.NamespacedName{Namespace: job.Namespace, Name: job.Name}, &pdb); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if kind, name, ok := ownerOf(&pdb); !ok || kind != "Job" || name != job.Name {
+		return nil
+	}
+	log.FromContext(ctx).Info("Deleting PDB for "+why, "pdb", pdb.Name)
+	return client.IgnoreNotFound(r.Delete(ctx, &pdb))
+}
+
+// deleteOrphanedPDB deletes the PDB of the deleted Job key in ownerless mode, where
+// no owner reference lets the garbage collector do it.
+func (r *JobToPDBReconciler) deleteOrphanedPDB(ctx context.Context, key types.NamespacedName) error {
+	var pdb policyv1.PodDisruptionBudget
+	if err := r.Get(ctx, key, &pdb); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	_, err := collectOrphan(ctx, r.Client, &pdb)
+	return err
+}
+
+// jobFinished reports whether job completed or failed.
+func jobFinished(job *batchv1.Job) bool {
+	for _, c := range job.Status.Conditions {
+		if (c.Type == batchv1.JobComplete || c.Type == batchv1.JobFailed) && c.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// newPDBForJob builds the PDB protecting job's pods, owned by the Job. minAvailable
+// is the Job's parallelism, so no running pod can be evicted; the disruption
+// controller only supports an integer minAvailable for Job pods.
+func newPDBForJob(job *batchv1.Job) *policyv1.PodDisruptionBudget {
+	var parallelism int32 = 1
+	if job.Spec.Parallelism != nil {
+		parallelism = *job.Spec.Parallelism
+	}
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      job.Name,
+			Namespace: job.Namespace,
+			Annotations: map[string]string{
+				PDBOwnedByAnnotationKey: ControllerName,
+				annotations.Target:      job.Name,
+			},
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MinAvailable: &intstr.IntOrString{IntVal: parallelism},
+			Selector:     job.Spec.Selector.DeepCopy(),
+		},
+	}
+	setOwner(pdb, metav1.OwnerReference{
+		APIVersion: "batch/v1",
+		Kind:       "Job",
+		Name:       job.Name,
+		UID:        job.UID,
+	})
+	return pdb
+}
+
+// jobOwned reports whether pdb was created for a Job by JobToPDBReconciler.
+func jobOwned(pdb *policyv1.PodDisruptionBudget) bool {
+	kind, _, ok := ownerOf(pdb)
+	return ok && kind == "Job" && pdb.Annotations[PDBOwnedByAnnotationKey] == ControllerName
+}
+
+// requeueJobsForCronJob maps a CronJob to the Jobs it created.
+func requeueJobsForCronJob(c client.Client) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		var jobList batchv1.JobList
+		if err := c.List(ctx, &jobList, client.InNamespace(obj.GetNamespace())); err != nil {
+			log.FromContext(ctx).Error(err, "Failed to list jobs", "namespace", obj.GetNamespace())
+			return nil
+		}
+		var requests []reconcile.Request
+		for i := range jobList.Items {
+			if metav1.IsControlledBy(&jobList.Items[i], obj) {
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&jobList.Items[i])})
+			}
+		}
+		return requests
+	}
+}
+
+// requeueJobsOnNamespaceChange maps a namespace to every Job in it.
+func requeueJobsOnNamespaceChange(c client.Client) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		var jobList batchv1.JobList
+		if err := c.List(ctx, &jobList, client.InNamespace(obj.GetName())); err != nil {
+			log.FromContext(ctx).Error(err, "Failed to list jobs in namespace", "namespace", obj.GetName())
+			return nil
+		}
+		requests := make([]reconcile.Request, 0, len(jobList.Items))
+		for i := range jobList.Items {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&jobList.Items[i])})
+		}
+		return requests
+	}
+}
+
+// triggerOnJobChange reports whether a Job or CronJob update changed its opt-in or
+// exclusion, or finished the Job.
+func triggerOnJobChange(e event.UpdateEvent) bool {
+	if e.ObjectOld.GetAnnotations()[JobPDBAnnotationKey] != e.ObjectNew.GetAnnotations()[JobPDBAnnotationKey] ||
+		triggerOnExcludeChange(e) {
+		return true
+	}
+	oldJob, okOld := e.ObjectOld.(*batchv1.Job)
+	newJob, okNew := e.ObjectNew.(*batchv1.Job)
+	return okOld && okNew && jobFinished(oldJob) != jobFinished(newJob)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *JobToPDBReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&batchv1.Job{}, builder.WithPredicates(predicate.Funcs{UpdateFunc: triggerOnJobChange})).
+		WithOptions(controllerOptions("job")).
+		Watches(&batchv1.CronJob{}, handler.EnqueueRequestsFromMapFunc(requeueJobsForCronJob(r.Client)),
+			builder.WithPredicates(predicate.Funcs{
+				CreateFunc: func(event.CreateEvent) bool { return false },
+				DeleteFunc: func(event.DeleteEvent) bool { return false },
+				UpdateFunc: triggerOnJobChange,
+			})).
+		Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(requeueJobsOnNamespaceChange(r.Client))).
+		WithEventFilter(shardPredicate(r.Filter)).
+		// Without owner references a deleted Job's PDB is deleted by the controller.
+		WithEventFilter(predicate.Funcs{DeleteFunc: func(event.DeleteEvent) bool { return !ownerReferences }}).
+		Owns(&policyv1.PodDisruptionBudget{}).
+		Complete(traced("job", r))
+}
@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/namespacefilter"
+)
+
+var _ = Describe("JobToPDBReconciler", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+		key    = types.NamespacedName{Namespace: "batch", Name: "train"}
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(batchv1.AddToScheme(scheme)).To(Succeed())
+		Expect(policyv1.AddToScheme(scheme)).To(Succeed())
+		Expect(myappsv1.AddToScheme(scheme)).To(Succeed())
+	})
+
+	newJob := func(annotations map[string]string, owners ...metav1.OwnerReference) *batchv1.Job {
+		return &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "train", Namespace: "batch", UID: "job-uid",
+				Annotations: annotations, OwnerReferences: owners},
+			Spec: batchv1.JobSpec{
+				Parallelism: ptr.To[int32](2),
+				Selector:    &metav1.LabelSelector{MatchLabels: map[string]string{"batch.kubernetes.io/controller-uid": "job-uid"}},
+				Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"batch.kubernetes.io/controller-uid": "job-uid", "app": "train"},
+				}},
+			},
+		}
+	}
+
+	reconcileJob := func(objs ...runtime.Object) *JobToPDBReconciler {
+		objs = append(objs, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "batch"}})
+		c := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objs...).Build()
+		r := &JobToPDBReconciler{Client: c, Scheme: scheme, Filter: namespacefilter.New(nil, false)}
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+		return r
+	}
+
+	It("should protect an opted-in job and delete the PDB once it completes", func() {
+		r := reconcileJob(newJob(map[string]string{JobPDBAnnotationKey: "true"}))
+
+		var pdb policyv1.PodDisruptionBudget
+		Expect(r.Get(ctx, key, &pdb)).To(Succeed())
+		Expect(pdb.Spec.MinAvailable.IntValue()).To(Equal(2))
+		Expect(pdb.Spec.Selector.MatchLabels).To(HaveKeyWithValue("batch.kubernetes.io/controller-uid", "job-uid"))
+		Expect(jobOwned(&pdb)).To(BeTrue())
+
+		var job batchv1.Job
+		Expect(r.Get(ctx, key, &job)).To(Succeed())
+		job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+		Expect(r.Status().Update(ctx, &job)).To(Succeed())
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(apierrors.IsNotFound(r.Get(ctx, key, &pdb))).To(BeTrue())
+	})
+
+	It("should follow the annotation of the CronJob that created the job", func() {
+		cronJob := &batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "batch", UID: "cron-uid",
+			Annotations: map[string]string{JobPDBAnnotationKey: "true"}}}
+		owner := metav1.OwnerReference{APIVersion: "batch/v1", Kind: "CronJob", Name: "nightly", UID: "cron-uid", Controller: ptr.To(true)}
+		r := reconcileJob(cronJob, newJob(nil, owner))
+		Expect(r.Get(ctx, key, &policyv1.PodDisruptionBudget{})).To(Succeed())
+
+		r = reconcileJob(cronJob, newJob(map[string]string{JobPDBAnnotationKey: "false"}, owner))
+		Expect(apierrors.IsNotFound(r.Get(ctx, key, &policyv1.PodDisruptionBudget{}))).To(BeTrue())
+	})
+
+	It("should leave jobs without the annotation or with a user PDB alone", func() {
+		r := reconcileJob(newJob(nil))
+		Expect(apierrors.IsNotFound(r.Get(ctx, key, &policyv1.PodDisruptionBudget{}))).To(BeTrue())
+
+		userPDB := &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: "train-user", Namespace: "batch"},
+			Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "train"}}},
+		}
+		r = reconcileJob(userPDB, newJob(map[string]string{JobPDBAnnotationKey: "true"}))
+		Expect(apierrors.IsNotFound(r.Get(ctx, key, &policyv1.PodDisruptionBudget{}))).To(BeTrue())
+	})
+
+	It("should not create an EvictionAutoScaler for a job's PDB", func() {
+		pdb := newPDBForJob(newJob(nil))
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "batch"}}, pdb).Build()
+		r := &PDBToEvictionAutoScalerReconciler{Client: c, Scheme: scheme, Filter: namespacefilter.New(nil, false)}
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(apierrors.IsNotFound(c.Get(ctx, key, &myappsv1.EvictionAutoScaler{}))).To(BeTrue())
+	})
+})
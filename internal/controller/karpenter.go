@@ -0,0 +1,35 @@
+package controllers
+
+import (
+	"slices"
+	"time"
+)
+
+// KarpenterDisruptionTaintKey is the taint Karpenter puts on a node it has decided
+// to disrupt, before it drains it. Karpenter does not cordon the node, so in
+// Karpenter mode the taint is a preemption notice: the PreemptionNoticeReconciler
+// cordons the node and the NodeReconciler surges its workloads ahead of the drain.
+// If Karpenter abandons the disruption it removes the taint and the node is
+// uncordoned again.
+const KarpenterDisruptionTaintKey = "karpenter.sh/disruption"
+
+// KarpenterCooldown is the default cooldown in Karpenter mode. Karpenter replaces
+// nodes faster than the Cluster Autoscaler drains them, so surges are reverted
+// sooner to keep the extra replicas from piling up across back-to-back disruptions.
+const KarpenterCooldown = 30 * time.Second
+
+// WithKarpenterTaint returns keys with the Karpenter disruption taint added.
+func WithKarpenterTaint(keys []string) []string {
+	if slices.Contains(keys, KarpenterDisruptionTaintKey) {
+		return keys
+	}
+	return append(keys, KarpenterDisruptionTaintKey)
+}
+
+// cooldownOr returns configured, or the default cooldown if it is zero.
+func cooldownOr(configured time.Duration) time.Duration {
+	if configured > 0 {
+		return configured
+	}
+	return cooldown
+}
@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/namespacefilter"
+)
+
+var _ = Describe("Karpenter mode", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+		key    = types.NamespacedName{Name: "karpenter-1"}
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(policyv1.AddToScheme(scheme)).To(Succeed())
+		Expect(myappsv1.AddToScheme(scheme)).To(Succeed())
+	})
+
+	It("should cordon a node Karpenter is about to disrupt and keep looking at the configured cooldown", func() {
+		node := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "karpenter-1"},
+			Spec: corev1.NodeSpec{Taints: []corev1.Taint{
+				{Key: KarpenterDisruptionTaintKey, Value: "disrupting", Effect: corev1.TaintEffectNoSchedule},
+			}},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(node,
+			&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "shop"},
+				Spec:       corev1.PodSpec{NodeName: "karpenter-1"},
+			}).
+			WithIndex(&corev1.Pod{}, NodeNameIndex, func(obj client.Object) []string {
+				return []string{obj.(*corev1.Pod).Spec.NodeName}
+			}).Build()
+
+		notices := &PreemptionNoticeReconciler{Client: c, Scheme: scheme, TaintKeys: WithKarpenterTaint(nil)}
+		_, err := notices.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+		var got corev1.Node
+		Expect(c.Get(ctx, key, &got)).To(Succeed())
+		Expect(got.Spec.Unschedulable).To(BeTrue())
+
+		nodes := &NodeReconciler{Client: c, Scheme: scheme, Filter: namespacefilter.New(nil, false), Cooldown: KarpenterCooldown}
+		result, err := nodes.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(KarpenterCooldown))
+	})
+
+	It("should add the disruption taint once and fall back to the default cooldown", func() {
+		Expect(WithKarpenterTaint([]string{"ToBeDeletedByClusterAutoscaler", KarpenterDisruptionTaintKey})).
+			To(Equal([]string{"ToBeDeletedByClusterAutoscaler", KarpenterDisruptionTaintKey}))
+		Expect(cooldownOr(0)).To(Equal(cooldown))
+		Expect(cooldownOr(10 * time.Second)).To(Equal(10 * time.Second))
+	})
+})
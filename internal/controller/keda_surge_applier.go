@@ -27,15 +27,16 @@ import (
 // bypassing both KEDA and HPA sync loops. The raised minReplicaCount prevents
 // KEDA/HPA from scaling back down below the surge value.
 //
-// Note: the HPA controller (which KEDA manages) uses the /scale subresource to set
-// deployment replicas, which is equivalent to updating deployment.spec.replicas
-// directly. The /scale subresource does NOT bump deployment metadata.generation,
-// while a full Update does (if spec changes).
+// Note: like the HPA controller (which KEDA manages), replicas are written through
+// the /scale subresource. It sets the same field as updating deployment.spec.replicas
+// without sending the full deployment object.
 
 type KEDASurgeApplier struct {
 	client       client.Client
 	scaledObject *kedav1alpha1.ScaledObject
 	target       Surger
+	// floor is set by withScaleDownFloor.
+	floor *int32
 }
 
 var _ SurgeApplier = &KEDASurgeApplier{}
@@ -85,18 +86,16 @@ func (k *KEDASurgeApplier) ApplySurge(ctx context.Context, surgeReplicas int32)
 			"minReplicaCount", surgeReplicas, "originalMin", obj.Annotations[OriginalMinReplicasAnnotationKey])
 	}
 
-	// Step 2: Set deployment replicas directly for immediate scale-up.
+	// Step 2: Set deployment replicas through /scale for immediate scale-up.
 	// This is a time-saving optimization — KEDA would eventually propagate
 	// minReplicaCount to its managed HPA, which would then enforce it on its
 	// next sync. But that adds two hops of latency. Setting replicas directly
 	// triggers pod creation immediately. Also handles the edge case where the
 	// HPA cannot compute metrics (e.g., no metrics-server).
-	// On 409 Conflict (stale resourceVersion), the error propagates to the
-	// reconcile loop which requeues. On retry, step 1 is skipped (idempotent)
-	// and step 2 retries with a fresh object from the informer cache.
+	// On failure the error propagates to the reconcile loop which requeues. On
+	// retry, step 1 is skipped (idempotent) and step 2 is retried.
 	if k.target.GetReplicas() != surgeReplicas {
-		k.target.SetReplicas(surgeReplicas)
-		if err := k.client.Update(ctx, k.target.Obj()); err != nil {
+		if err := scaleSubresource(ctx, k.client, k.target, surgeReplicas); err != nil {
 			return fmt.Errorf("setting deployment replicas: %w", err)
 		}
 		logger.V(1).Info("Set deployment replicas for immediate surge", "replicas", surgeReplicas)
@@ -124,6 +123,9 @@ func (k *KEDASurgeApplier) RevertSurge(ctx context.Context, originalMinReplicas
 			}
 		}
 	}
+	if k.floor != nil {
+		revertTo = max(revertTo, *k.floor)
+	}
 
 	// Revert ScaledObject minReplicaCount and remove both surge annotations in a single write.
 	obj := k.scaledObject.DeepCopy()
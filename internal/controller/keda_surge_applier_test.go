@@ -220,6 +220,15 @@ var _ = Describe("KEDASurgeApplier", func() {
 			Expect(*updated.Spec.MinReplicaCount).To(Equal(int32(1))) // from annotation, not 99
 		})
 
+		It("should not revert below the scale-down floor", func() {
+			withScaleDownFloor(applier, ptr.To(int32(3)))
+			Expect(applier.RevertSurge(ctx, 3)).To(Succeed())
+
+			var updated kedav1alpha1.ScaledObject
+			Expect(applier.client.Get(ctx, keyFor(so), &updated)).To(Succeed())
+			Expect(*updated.Spec.MinReplicaCount).To(Equal(int32(3))) // floor, not the annotated 1
+		})
+
 		It("should remove both surge annotations after revert", func() {
 			Expect(applier.RevertSurge(ctx, 1)).To(Succeed())
 
@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"context"
+	"sort"
+
+	v1 "k8s.io/api/apps/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// PDBSelectorIndex indexes PDBs by the key=value pairs of their selector's
+	// matchLabels. A selector without matchLabels, which may select any pod, is
+	// indexed under anyLabels.
+	PDBSelectorIndex = "pdbSelector"
+	// TemplateLabelIndex indexes deployments and statefulsets by the key=value pairs
+	// of their pod template labels.
+	TemplateLabelIndex = "templateLabels"
+
+	anyLabels = "*"
+)
+
+// labelIndexes is set once SetupLabelIndexes registered the indexes. Without them,
+// as with the fake clients in tests, lookups list the whole namespace.
+var labelIndexes bool
+
+// SetupLabelIndexes registers the indexes that let PDBs and the workloads they
+// cover find each other without listing and matching every object in the
+// namespace, which dominates reconcile CPU during cluster-wide drains.
+func SetupLabelIndexes(ctx context.Context, indexer client.FieldIndexer) error {
+	if err := indexer.IndexField(ctx, &policyv1.PodDisruptionBudget{}, PDBSelectorIndex, indexPDBSelector); err != nil {
+		return err
+	}
+	if err := indexer.IndexField(ctx, &v1.Deployment{}, TemplateLabelIndex, indexDeploymentTemplate); err != nil {
+		return err
+	}
+	if err := indexer.IndexField(ctx, &v1.StatefulSet{}, TemplateLabelIndex, indexStatefulSetTemplate); err != nil {
+		return err
+	}
+	labelIndexes = true
+	return nil
+}
+
+// indexPDBSelector is the PDBSelectorIndex indexer. A nil selector selects nothing
+// and is not indexed.
+func indexPDBSelector(obj client.Object) []string {
+	selector := obj.(*policyv1.PodDisruptionBudget).Spec.Selector
+	if selector == nil {
+		return nil
+	}
+	if len(selector.MatchLabels) == 0 {
+		return []string{anyLabels}
+	}
+	return labelPairs(selector.MatchLabels)
+}
+
+func indexDeploymentTemplate(obj client.Object) []string {
+	return labelPairs(obj.(*v1.Deployment).Spec.Template.Labels)
+}
+
+func indexStatefulSetTemplate(obj client.Object) []string {
+	return labelPairs(obj.(*v1.StatefulSet).Spec.Template.Labels)
+}
+
+// labelPairs returns labels as key=value strings.
+func labelPairs(labels map[string]string) []string {
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, k+"="+v)
+	}
+	return pairs
+}
+
+// candidatePDBs lists the PDBs in namespace that may select pods labelled
+// templateLabels: every PDB whose matchLabels share a pair with them, and every
+// PDB without matchLabels. Callers still match each selector.
+func candidatePDBs(ctx context.Context, c client.Client, namespace string, templateLabels map[string]string) ([]policyv1.PodDisruptionBudget, error) {
+	if !labelIndexes {
+		var pdbList policyv1.PodDisruptionBudgetList
+		if err := c.List(ctx, &pdbList, client.InNamespace(namespace)); err != nil {
+			return nil, err
+		}
+		return pdbList.Items, nil
+	}
+	seen := map[string]bool{}
+	var candidates []policyv1.PodDisruptionBudget
+	for _, key := range append(labelPairs(templateLabels), anyLabels) {
+		var pdbList policyv1.PodDisruptionBudgetList
+		if err := c.List(ctx, &pdbList, client.InNamespace(namespace), client.MatchingFields{PDBSelectorIndex: key}); err != nil {
+			return nil, err
+		}
+		for _, pdb := range pdbList.Items {
+			if !seen[pdb.Name] {
+				seen[pdb.Name] = true
+				candidates = append(candidates, pdb)
+			}
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Name < candidates[j].Name })
+	return candidates, nil
+}
+
+// templateLabelFilter narrows a list of workloads to those whose pod template
+// carries the first of pdb's matchLabels pairs, which its selector requires like
+// all the others. A PDB without matchLabels can't be narrowed and gets no filter.
+func templateLabelFilter(pdb *policyv1.PodDisruptionBudget) []client.ListOption {
+	if !labelIndexes || pdb.Spec.Selector == nil || len(pdb.Spec.Selector.MatchLabels) == 0 {
+		return nil
+	}
+	pairs := labelPairs(pdb.Spec.Selector.MatchLabels)
+	sort.Strings(pairs)
+	return []client.ListOption{client.MatchingFields{TemplateLabelIndex: pairs[0]}}
+}
+
+// listWorkloadsForPDB lists the workloads in pdb's namespace into list, narrowed
+// through TemplateLabelIndex where possible.
+func listWorkloadsForPDB(ctx context.Context, c client.Client, pdb *policyv1.PodDisruptionBudget, list client.ObjectList) error {
+	opts := append([]client.ListOption{client.InNamespace(pdb.Namespace)}, templateLabelFilter(pdb)...)
+	return c.List(ctx, list, opts...)
+}
@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("label indexes", func() {
+	var (
+		ctx context.Context
+		c   client.Client
+	)
+
+	pdb := func(name string, selector *metav1.LabelSelector) *policyv1.PodDisruptionBudget {
+		return &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       policyv1.PodDisruptionBudgetSpec{Selector: selector},
+		}
+	}
+	deployment := func(name string, labels map[string]string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: labels}}},
+		}
+	}
+	names := func(pdbs []policyv1.PodDisruptionBudget) []string {
+		var out []string
+		for _, p := range pdbs {
+			out = append(out, p.Name)
+		}
+		return out
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme := runtime.NewScheme()
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		Expect(policyv1.AddToScheme(scheme)).To(Succeed())
+		c = fake.NewClientBuilder().WithScheme(scheme).
+			WithIndex(&policyv1.PodDisruptionBudget{}, PDBSelectorIndex, indexPDBSelector).
+			WithIndex(&appsv1.Deployment{}, TemplateLabelIndex, indexDeploymentTemplate).
+			WithIndex(&appsv1.StatefulSet{}, TemplateLabelIndex, indexStatefulSetTemplate).
+			WithObjects(
+				deployment("web", map[string]string{"app": "web", "tier": "frontend"}),
+				deployment("api", map[string]string{"app": "api"}),
+				&appsv1.StatefulSet{
+					ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+					Spec:       appsv1.StatefulSetSpec{Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "db"}}}},
+				},
+				pdb("web", &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}),
+				pdb("api", &metav1.LabelSelector{MatchLabels: map[string]string{"app": "api"}}),
+				pdb("everything", &metav1.LabelSelector{}),
+				pdb("expression", &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "app", Operator: metav1.LabelSelectorOpIn, Values: []string{"api"}},
+				}}),
+				pdb("nothing", nil),
+			).Build()
+		labelIndexes = true
+		DeferCleanup(func() { labelIndexes = false })
+	})
+
+	It("should find the PDBs selecting a deployment through the index", func() {
+		pdbs, err := findPDBsForDeployment(ctx, c, deployment("web", map[string]string{"app": "web", "tier": "frontend"}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(names(pdbs)).To(Equal([]string{"everything", "web"}))
+
+		pdbs, err = findPDBsForDeployment(ctx, c, deployment("api", map[string]string{"app": "api"}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(names(pdbs)).To(Equal([]string{"api", "everything", "expression"}))
+	})
+
+	It("should find the workload a PDB selects through the index", func() {
+		r := &PDBToEvictionAutoScalerReconciler{Client: c}
+		kind, name, _, err := r.discoverTarget(ctx, pdb("web", &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect([]string{string(kind), name}).To(Equal([]string{string(deploymentKind), "web"}))
+
+		kind, name, _, err = r.discoverTarget(ctx, pdb("db", &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect([]string{string(kind), name}).To(Equal([]string{string(statefulSetKind), "db"}))
+
+		deployments, err := findDeploymentsForPDB(ctx, c, pdb("expression", &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: "app", Operator: metav1.LabelSelectorOpExists},
+		}}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deployments).To(HaveLen(2))
+	})
+})
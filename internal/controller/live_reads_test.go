@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"context"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/metrics"
+	"github.com/azure/eviction-autoscaler/internal/namespacefilter"
+)
+
+var _ = Describe("EvictionAutoScalerReconciler live reads", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+		dep    *appsv1.Deployment
+		pdb    *policyv1.PodDisruptionBudget
+		eas    *myappsv1.EvictionAutoScaler
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		Expect(policyv1.AddToScheme(scheme)).To(Succeed())
+		Expect(autoscalingv2.AddToScheme(scheme)).To(Succeed())
+		Expect(kedav1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(myappsv1.AddToScheme(scheme)).To(Succeed())
+		dep = &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Generation: 1},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: ptr.To(int32(2)),
+				Strategy: appsv1.DeploymentStrategy{RollingUpdate: &appsv1.RollingUpdateDeployment{MaxSurge: ptr.To(intstr.FromInt32(1))}},
+			},
+		}
+		pdb = &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+		}
+		eas = &myappsv1.EvictionAutoScaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: myappsv1.EvictionAutoScalerSpec{TargetName: "web", TargetKind: myappsv1.TargetKindDeployment, SurgeMode: SurgeModeDirect,
+				LastEviction: myappsv1.Eviction{PodName: "web-1", EvictionTime: metav1.Now()}},
+			Status: myappsv1.EvictionAutoScalerStatus{MinReplicas: 2, TargetGeneration: 1},
+		}
+	})
+
+	// reconcile runs one reconcile whose cache holds cached, while the API server
+	// holds live, and returns the deployment as the API server has it afterwards.
+	reconcile := func(cached, live []client.Object) *appsv1.Deployment {
+		common := []client.Object{
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}, Spec: corev1.NodeSpec{Unschedulable: true}},
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default", Labels: map[string]string{"app": "web"}},
+				Spec: corev1.PodSpec{NodeName: "node-1"}},
+			eas,
+		}
+		api := fake.NewClientBuilder().WithScheme(scheme).WithObjects(append(live, common...)...).
+			WithStatusSubresource(&myappsv1.EvictionAutoScaler{}).Build()
+		cache := fake.NewClientBuilder().WithScheme(scheme).WithObjects(append(cached, common...)...).Build()
+		// Writes go to the API server; only the reconciler's reads see the stale cache.
+		r := &EvictionAutoScalerReconciler{
+			Client:    staleReads{Client: api, cache: cache},
+			Scheme:    scheme,
+			Filter:    namespacefilter.New(nil, false),
+			APIReader: api,
+		}
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(eas)})
+		Expect(err).ToNot(HaveOccurred())
+		var got appsv1.Deployment
+		Expect(api.Get(ctx, client.ObjectKeyFromObject(dep), &got)).To(Succeed())
+		return &got
+	}
+
+	It("should not surge when the live PDB already allows disruptions", func() {
+		fresh := pdb.DeepCopy()
+		fresh.Status.DisruptionsAllowed = 1
+		got := reconcile([]client.Object{dep, pdb}, []client.Object{dep, fresh})
+		Expect(*got.Spec.Replicas).To(Equal(int32(2)))
+	})
+
+	It("should surge when the live PDB is blocking", func() {
+		got := reconcile([]client.Object{dep, pdb}, []client.Object{dep, pdb})
+		Expect(*got.Spec.Replicas).To(Equal(int32(3)))
+	})
+
+	It("should not surge again on a target the cache hasn't seen surged", func() {
+		surged := dep.DeepCopy()
+		surged.Spec.Replicas = ptr.To(int32(3))
+		surged.Annotations = map[string]string{EvictionSurgeReplicasAnnotationKey: "3"}
+		surged.Generation = 2
+		scaleUps := metrics.ActualScalingCounter.WithLabelValues("default", "web", metrics.ScaleUpAction)
+		before := testutil.ToFloat64(scaleUps)
+		got := reconcile([]client.Object{dep, pdb}, []client.Object{surged, pdb})
+		Expect(*got.Spec.Replicas).To(Equal(int32(3)))
+		Expect(testutil.ToFloat64(scaleUps)).To(Equal(before))
+	})
+
+	It("should wait for the cache when the target changed outside a surge", func() {
+		scaled := dep.DeepCopy()
+		scaled.Spec.Replicas = ptr.To(int32(5))
+		scaled.Generation = 2
+		got := reconcile([]client.Object{dep, pdb}, []client.Object{scaled, pdb})
+		Expect(*got.Spec.Replicas).To(Equal(int32(5)))
+	})
+})
+
+// staleReads serves reads from a separate cache client and everything else from the
+// embedded client, like a manager client whose informers lag behind the API server.
+type staleReads struct {
+	client.Client
+	cache client.Reader
+}
+
+func (s staleReads) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	if _, ok := obj.(*myappsv1.EvictionAutoScaler); ok {
+		return s.Client.Get(ctx, key, obj, opts...)
+	}
+	return s.cache.Get(ctx, key, obj, opts...)
+}
+
+func (s staleReads) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return s.cache.List(ctx, list, opts...)
+}
@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"context"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/annotations"
+	"github.com/azure/eviction-autoscaler/internal/namespacefilter"
+)
+
+var _ = Describe("Adopted PDB minAvailable floor", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+		key    = client.ObjectKey{Namespace: "default", Name: "web"}
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		Expect(policyv1.AddToScheme(scheme)).To(Succeed())
+		Expect(autoscalingv2.AddToScheme(scheme)).To(Succeed())
+		Expect(kedav1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(myappsv1.AddToScheme(scheme)).To(Succeed())
+	})
+
+	deployment := func(replicas int32) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Generation: 2, UID: "web-uid"},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: ptr.To(replicas),
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+				Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}}},
+			},
+		}
+	}
+	pdb := func(minAvailable intstr.IntOrString, annotations map[string]string) *policyv1.PodDisruptionBudget {
+		return &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Annotations: annotations},
+			Spec: policyv1.PodDisruptionBudgetSpec{
+				MinAvailable: &minAvailable,
+				Selector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			},
+		}
+	}
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+
+	adopt := func(objs ...client.Object) *policyv1.PodDisruptionBudget {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(append(objs, namespace.DeepCopy())...).Build()
+		r := &PDBToEvictionAutoScalerReconciler{Client: c, Scheme: scheme, Filter: namespacefilter.New(nil, false)}
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+		var got policyv1.PodDisruptionBudget
+		Expect(c.Get(ctx, key, &got)).To(Succeed())
+		return &got
+	}
+
+	It("should record the minAvailable of a user-created PDB when adopting it", func() {
+		got := adopt(deployment(3), pdb(intstr.FromInt32(5), map[string]string{PDBOwnedByAnnotationKey: ControllerName}))
+		Expect(metav1.GetControllerOf(got).Name).To(Equal("web"))
+		Expect(got.Annotations).To(HaveKeyWithValue(MinAvailableFloorAnnotationKey, "5"))
+	})
+
+	It("should not record a floor for a PDB the controller created, or a percentage", func() {
+		got := adopt(deployment(3), pdb(intstr.FromInt32(3), map[string]string{PDBOwnedByAnnotationKey: ControllerName, annotations.Target: "web"}))
+		Expect(got.Annotations).ToNot(HaveKey(MinAvailableFloorAnnotationKey))
+
+		got = adopt(deployment(3), pdb(intstr.FromString("50%"), map[string]string{PDBOwnedByAnnotationKey: ControllerName}))
+		Expect(got.Annotations).ToNot(HaveKey(MinAvailableFloorAnnotationKey))
+	})
+
+	It("should keep minAvailable at the floor and track replicas above it", func() {
+		dep := deployment(2)
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			namespace.DeepCopy(),
+			dep,
+			pdb(intstr.FromInt32(5), map[string]string{PDBOwnedByAnnotationKey: ControllerName, MinAvailableFloorAnnotationKey: "4"}),
+			&myappsv1.EvictionAutoScaler{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+				Spec:       myappsv1.EvictionAutoScalerSpec{TargetName: "web", TargetKind: myappsv1.TargetKindDeployment},
+				Status:     myappsv1.EvictionAutoScalerStatus{TargetGeneration: 1},
+			},
+		).Build()
+		r := &DeploymentToPDBReconciler{Client: c, Scheme: scheme, Filter: namespacefilter.New(nil, false)}
+		minAvailable := func() int32 {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+			Expect(err).ToNot(HaveOccurred())
+			var got policyv1.PodDisruptionBudget
+			Expect(c.Get(ctx, key, &got)).To(Succeed())
+			return got.Spec.MinAvailable.IntVal
+		}
+
+		Expect(minAvailable()).To(Equal(int32(4)))
+
+		Expect(c.Get(ctx, key, dep)).To(Succeed())
+		dep.Spec.Replicas = ptr.To(int32(6))
+		dep.Generation = 3
+		Expect(c.Update(ctx, dep)).To(Succeed())
+		Expect(minAvailable()).To(Equal(int32(6)))
+	})
+})
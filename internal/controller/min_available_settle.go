@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// replicaSettle holds back PDB minAvailable updates until a deployment's replica
+// count has stopped changing, so an HPA scaling back and forth doesn't rewrite the
+// PDB on every step. Observations are kept in memory, so a change still settling
+// when the controller restarts is only picked up by the next replica change.
+type replicaSettle struct {
+	mu   sync.Mutex
+	seen map[types.NamespacedName]replicaObservation
+}
+
+type replicaObservation struct {
+	replicas int32
+	since    time.Time
+}
+
+// pending reports whether a replica change of key is still waiting to settle.
+func (s *replicaSettle) pending(key types.NamespacedName) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.seen[key]
+	return ok
+}
+
+// wait returns how much longer key must keep replicas before its PDB is updated,
+// or 0 once it has kept them for quiet. A different count restarts the wait.
+func (s *replicaSettle) wait(key types.NamespacedName, replicas int32, now time.Time, quiet time.Duration) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seen, ok := s.seen[key]
+	if !ok || seen.replicas != replicas {
+		if s.seen == nil {
+			s.seen = map[types.NamespacedName]replicaObservation{}
+		}
+		s.seen[key] = replicaObservation{replicas: replicas, since: now}
+		return quiet
+	}
+	if remaining := quiet - now.Sub(seen.since); remaining > 0 {
+		return remaining
+	}
+	delete(s.seen, key)
+	return 0
+}
+
+// scaledMinAvailable returns ceil(replicas * factor), or replicas itself for a
+// factor outside (0, 1).
+func scaledMinAvailable(replicas int32, factor float64) int32 {
+	if factor <= 0 || factor >= 1 {
+		return replicas
+	}
+	// Drop float noise so 10 * 0.7 rounds up to 7, not 8.
+	return int32(math.Ceil(float64(replicas)*factor - 1e-9))
+}
@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/namespacefilter"
+)
+
+var _ = Describe("Following deployment replicas", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+		key    = client.ObjectKey{Namespace: "default", Name: "web"}
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		Expect(policyv1.AddToScheme(scheme)).To(Succeed())
+		Expect(autoscalingv2.AddToScheme(scheme)).To(Succeed())
+		Expect(kedav1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(myappsv1.AddToScheme(scheme)).To(Succeed())
+	})
+
+	It("should round the scaled minAvailable up", func() {
+		Expect(scaledMinAvailable(10, 0.7)).To(Equal(int32(7)))
+		Expect(scaledMinAvailable(3, 0.5)).To(Equal(int32(2)))
+		Expect(scaledMinAvailable(1, 0.1)).To(Equal(int32(1)))
+		Expect(scaledMinAvailable(4, 1)).To(Equal(int32(4)))
+		Expect(scaledMinAvailable(4, 0)).To(Equal(int32(4)))
+	})
+
+	It("should restart the wait whenever the replicas change", func() {
+		var s replicaSettle
+		now := time.Now()
+		Expect(s.wait(key, 3, now, time.Minute)).To(Equal(time.Minute))
+		Expect(s.wait(key, 3, now.Add(20*time.Second), time.Minute)).To(Equal(40 * time.Second))
+		Expect(s.wait(key, 4, now.Add(30*time.Second), time.Minute)).To(Equal(time.Minute))
+		Expect(s.pending(key)).To(BeTrue())
+		Expect(s.wait(key, 4, now.Add(90*time.Second), time.Minute)).To(BeZero())
+		Expect(s.pending(key)).To(BeFalse())
+	})
+
+	It("should update minAvailable once the replicas settle, after the EvictionAutoScaler caught up", func() {
+		eas := &myappsv1.EvictionAutoScaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec:       myappsv1.EvictionAutoScalerSpec{TargetName: "web", TargetKind: myappsv1.TargetKindDeployment},
+			Status:     myappsv1.EvictionAutoScalerStatus{TargetGeneration: 1},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(eas).WithObjects(
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Generation: 2, UID: "web-uid"},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: ptr.To(int32(10)),
+					Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+					Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}}},
+				},
+			},
+			&policyv1.PodDisruptionBudget{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Annotations: map[string]string{PDBOwnedByAnnotationKey: ControllerName}},
+				Spec: policyv1.PodDisruptionBudgetSpec{
+					MinAvailable: ptr.To(intstr.FromInt32(5)),
+					Selector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+				},
+			},
+			eas,
+		).Build()
+		r := &DeploymentToPDBReconciler{Client: c, Scheme: scheme, Filter: namespacefilter.New(nil, false),
+			MinAvailableDebounce: time.Minute, MinAvailableFactor: 0.8}
+		minAvailable := func() int32 {
+			var got policyv1.PodDisruptionBudget
+			Expect(c.Get(ctx, key, &got)).To(Succeed())
+			return got.Spec.MinAvailable.IntVal
+		}
+
+		result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(time.Minute))
+		Expect(minAvailable()).To(Equal(int32(5)))
+
+		// The EvictionAutoScaler records the new generation while the change settles.
+		Expect(c.Get(ctx, key, eas)).To(Succeed())
+		eas.Status.TargetGeneration = 2
+		Expect(c.Status().Update(ctx, eas)).To(Succeed())
+		r.settle.seen[key] = replicaObservation{replicas: 10, since: time.Now().Add(-2 * time.Minute)}
+
+		result, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeZero())
+		Expect(minAvailable()).To(Equal(int32(8)))
+	})
+})
@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/azure/eviction-autoscaler/internal/metrics"
+)
+
+// The EvictionAutoScaler of a PDB this controller owns is checked for again after
+// missingEASBackoff, doubling on each retry up to missingEASMaxBackoff.
+const (
+	missingEASBackoff    = time.Second
+	missingEASMaxBackoff = 5 * time.Minute
+)
+
+// missingEvictionAutoScalers tracks controller-owned PDBs found without their
+// EvictionAutoScaler. PDBToEvictionAutoScalerReconciler normally creates it right
+// after the PDB, so a deployment reconcile running first retries until it appears
+// instead of missing the replica change it was reconciling. Observations are kept
+// in memory, like replicaSettle's.
+type missingEvictionAutoScalers struct {
+	mu   sync.Mutex
+	seen map[types.NamespacedName]missingObservation
+}
+
+type missingObservation struct {
+	since   time.Time
+	retries int
+}
+
+// retry records that the EvictionAutoScaler of the PDB named key is missing at now.
+// It returns how long to wait before checking again and how long it has been missing.
+func (m *missingEvictionAutoScalers) retry(key types.NamespacedName, now time.Time) (time.Duration, time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.seen == nil {
+		m.seen = map[types.NamespacedName]missingObservation{}
+	}
+	seen, ok := m.seen[key]
+	if !ok {
+		seen.since = now
+	}
+	wait := missingEASMaxBackoff
+	if seen.retries < 16 {
+		wait = min(missingEASBackoff<<seen.retries, missingEASMaxBackoff)
+	}
+	seen.retries++
+	m.seen[key] = seen
+	return wait, now.Sub(seen.since)
+}
+
+// found forgets the PDB named key once its EvictionAutoScaler exists.
+func (m *missingEvictionAutoScalers) found(key types.NamespacedName) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.seen, key)
+}
+
+// missingEvictionAutoScaler handles a controller-owned pdb of deployment that has
+// no EvictionAutoScaler: it retries with backoff and, once RepairInterval has
+// passed with it still missing, creates the EvictionAutoScaler itself.
+func (r *DeploymentToPDBReconciler) missingEvictionAutoScaler(ctx context.Context, deployment *v1.Deployment,
+	pdb *policyv1.PodDisruptionBudget) (ctrl.Result, error) {
+	key := client.ObjectKeyFromObject(pdb)
+	wait, missingFor := r.missing.retry(key, time.Now())
+	if r.RepairInterval <= 0 || missingFor < r.RepairInterval {
+		log.FromContext(ctx).V(1).Info("EvictionAutoScaler for PDB not found yet, retrying",
+			"namespace", pdb.Namespace, "name", pdb.Name, "retryAfter", wait)
+		return ctrl.Result{RequeueAfter: wait}, nil
+	}
+
+	eas := NewEvictionAutoScalerForPDB(pdb, deploymentKind, deployment.Name)
+	if err := applyOwned(ctx, r.Client, r.Recorder, eas); err != nil {
+		return ctrl.Result{}, fmt.Errorf("repairing EvictionAutoScaler for %s: %w", pdb.Name, err)
+	}
+	r.missing.found(key)
+	metrics.EvictionAutoScalerCreationCounter.WithLabelValues(pdb.Namespace, metrics.Name(pdb.Name), metrics.Name(deployment.Name)).Inc()
+	logDecision(ctx, decisionEASCreated, pdb.Namespace+"/"+pdb.Name, "recreated missing EvictionAutoScaler",
+		"missingFor", missingFor.Round(time.Second))
+	if r.Recorder != nil {
+		r.Recorder.Eventf(pdb, corev1.EventTypeWarning, "EvictionAutoScalerRepaired",
+			"EvictionAutoScaler was missing for %s, recreated it", missingFor.Round(time.Second))
+	}
+	return r.resync(ctrl.Result{}), nil
+}
+
+// resync requeues result after RepairInterval at the latest. Deleting an
+// EvictionAutoScaler doesn't trigger a deployment reconcile, so without it a
+// missing one is only noticed on the next change to the deployment.
+func (r *DeploymentToPDBReconciler) resync(result ctrl.Result) ctrl.Result {
+	return requeueWithin(result, r.RepairInterval)
+}
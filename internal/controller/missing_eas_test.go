@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+)
+
+var _ = Describe("Missing EvictionAutoScalers", func() {
+	var (
+		ctx        context.Context
+		c          client.Client
+		deployment *appsv1.Deployment
+		pdb        *policyv1.PodDisruptionBudget
+		key        = types.NamespacedName{Namespace: "default", Name: "web"}
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme := runtime.NewScheme()
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		Expect(policyv1.AddToScheme(scheme)).To(Succeed())
+		Expect(myappsv1.AddToScheme(scheme)).To(Succeed())
+		deployment = &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}
+		pdb = &policyv1.PodDisruptionBudget{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace,
+			Annotations: map[string]string{PDBOwnedByAnnotationKey: ControllerName}}}
+		c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(deployment, pdb).Build()
+	})
+
+	It("should back off while waiting for the EvictionAutoScaler", func() {
+		var m missingEvictionAutoScalers
+		now := time.Now()
+		wait, missingFor := m.retry(key, now)
+		Expect(wait).To(Equal(time.Second))
+		Expect(missingFor).To(BeZero())
+		wait, _ = m.retry(key, now.Add(time.Second))
+		Expect(wait).To(Equal(2 * time.Second))
+		for range 20 {
+			wait, missingFor = m.retry(key, now.Add(time.Hour))
+		}
+		Expect(wait).To(Equal(missingEASMaxBackoff))
+		Expect(missingFor).To(Equal(time.Hour))
+
+		m.found(key)
+		wait, _ = m.retry(key, now.Add(2*time.Hour))
+		Expect(wait).To(Equal(time.Second))
+	})
+
+	It("should only retry without a repair interval", func() {
+		r := &DeploymentToPDBReconciler{Client: c}
+		result, err := r.missingEvictionAutoScaler(ctx, deployment, pdb)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(time.Second))
+		Expect(apierrors.IsNotFound(c.Get(ctx, key, &myappsv1.EvictionAutoScaler{}))).To(BeTrue())
+	})
+
+	It("should recreate an EvictionAutoScaler missing for the repair interval", func() {
+		r := &DeploymentToPDBReconciler{Client: c, RepairInterval: 10 * time.Minute}
+		result, err := r.missingEvictionAutoScaler(ctx, deployment, pdb)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(time.Second))
+
+		// Missing since long enough ago.
+		r.missing.seen[key] = missingObservation{since: time.Now().Add(-11 * time.Minute), retries: 10}
+		result, err = r.missingEvictionAutoScaler(ctx, deployment, pdb)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(r.RepairInterval))
+
+		var eas myappsv1.EvictionAutoScaler
+		Expect(c.Get(ctx, key, &eas)).To(Succeed())
+		Expect(eas.Spec.TargetName).To(Equal(deployment.Name))
+		Expect(eas.Spec.TargetKind).To(Equal(myappsv1.TargetKindDeployment))
+		Expect(r.missing.seen).ToNot(HaveKey(key))
+	})
+
+	It("should forget the PDB of a deleted deployment", func() {
+		r := &DeploymentToPDBReconciler{Client: c}
+		_, err := r.missingEvictionAutoScaler(ctx, deployment, pdb)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(r.missing.seen).To(HaveKey(key))
+
+		Expect(c.Delete(ctx, deployment)).To(Succeed())
+		_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(r.missing.seen).ToNot(HaveKey(key))
+	})
+})
@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/metrics"
+)
+
+// namespaceCleanupInterval is how often a terminating namespace is checked again
+// while EvictionAutoScalers are left in it.
+const namespaceCleanupInterval = 10 * time.Second
+
+// NamespaceReconciler cleans up after a namespace being deleted. Its objects are
+// removed by the namespace's cascade, but an EvictionAutoScaler surging its target
+// holds SurgeFinalizer, and reverting a target that goes away with the namespace can
+// fail for good, e.g. once an impersonated service account is gone, which would leave
+// the namespace stuck terminating. The reconciler drops those surges and finalizers
+// and the namespace's metric series.
+type NamespaceReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	// Filter is only consulted for shard ownership.
+	Filter filter
+}
+
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups=eviction-autoscaler.azure.com,resources=evictionautoscalers,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=eviction-autoscaler.azure.com,resources=evictionautoscalers/status,verbs=get;update;patch
+
+// Reconcile releases the EvictionAutoScalers of a terminating namespace and forgets
+// the series of a terminating or deleted one. Other namespaces are left alone.
+func (r *NamespaceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var ns corev1.Namespace
+	if err := r.Get(ctx, types.NamespacedName{Name: req.Name}, &ns); err != nil {
+		if apierrors.IsNotFound(err) {
+			metrics.ForgetNamespace(req.Name)
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if ns.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	remaining, err := r.releaseSurges(ctx, ns.Name)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	metrics.ForgetNamespace(ns.Name)
+	// Look again until the cascade has removed them, in case one surged meanwhile.
+	if remaining > 0 {
+		return ctrl.Result{RequeueAfter: namespaceCleanupInterval}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// releaseSurges clears the surge recorded on each EvictionAutoScaler in namespace and
+// removes its SurgeFinalizer without reverting the target, which is deleted with the
+// namespace anyway. It returns how many EvictionAutoScalers are left.
+func (r *NamespaceReconciler) releaseSurges(ctx context.Context, namespace string) (int, error) {
+	var eases myappsv1.EvictionAutoScalerList
+	if err := r.List(ctx, &eases, client.InNamespace(namespace)); err != nil {
+		return 0, err
+	}
+	for i := range eases.Items {
+		eas := &eases.Items[i]
+		if eas.Status.SurgeActive {
+			clearSurge(&eas.Status)
+			if err := r.Status().Update(ctx, eas); client.IgnoreNotFound(err) != nil {
+				return 0, err
+			}
+		}
+		if !controllerutil.ContainsFinalizer(eas, SurgeFinalizer) {
+			continue
+		}
+		base := client.MergeFrom(eas.DeepCopy())
+		controllerutil.RemoveFinalizer(eas, SurgeFinalizer)
+		if err := r.Patch(ctx, eas, base); client.IgnoreNotFound(err) != nil {
+			return 0, err
+		}
+		logDecision(ctx, decisionSurgeReleased, namespace+"/"+eas.Name, "released surge finalizer in deleted namespace",
+			"targetname", eas.Spec.TargetName)
+	}
+	return len(eases.Items), nil
+}
+
+func (r *NamespaceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("namespace").
+		WithOptions(controllerOptions("namespace")).
+		For(&corev1.Namespace{}).
+		WithEventFilter(shardPredicate(r.Filter)).
+		// Only deletion matters: a namespace starting to terminate, or gone. A restart
+		// replays terminating namespaces as creates.
+		WithEventFilter(predicate.Funcs{
+			CreateFunc: func(e event.CreateEvent) bool {
+				return !e.Object.GetDeletionTimestamp().IsZero()
+			},
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				return !e.ObjectNew.GetDeletionTimestamp().IsZero()
+			},
+			DeleteFunc: func(e event.DeleteEvent) bool {
+				return true
+			},
+			GenericFunc: func(e event.GenericEvent) bool {
+				return false
+			},
+		}).
+		Complete(traced("namespace", r))
+}
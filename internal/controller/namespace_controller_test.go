@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/namespacefilter"
+)
+
+var _ = Describe("NamespaceReconciler", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+		key    = types.NamespacedName{Namespace: "tenant", Name: "web"}
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(myappsv1.AddToScheme(scheme)).To(Succeed())
+	})
+
+	surging := func() *myappsv1.EvictionAutoScaler {
+		return &myappsv1.EvictionAutoScaler{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace, Finalizers: []string{SurgeFinalizer}},
+			Spec:       myappsv1.EvictionAutoScalerSpec{TargetName: "web", TargetKind: myappsv1.TargetKindDeployment},
+			Status:     myappsv1.EvictionAutoScalerStatus{SurgeActive: true, SurgeReplicas: 4, MinReplicas: 3},
+		}
+	}
+	reconcileWith := func(ns *corev1.Namespace) (client.Client, ctrl.Result) {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(ns, surging()).
+			WithStatusSubresource(&myappsv1.EvictionAutoScaler{}).Build()
+		r := &NamespaceReconciler{Client: c, Scheme: scheme, Filter: namespacefilter.New(nil, true)}
+		result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: ns.Name}})
+		Expect(err).ToNot(HaveOccurred())
+		return c, result
+	}
+
+	It("should cancel surges and drop finalizers in a terminating namespace", func() {
+		c, result := reconcileWith(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name: "tenant", DeletionTimestamp: &metav1.Time{Time: metav1.Now().Time}, Finalizers: []string{"kubernetes"},
+		}})
+		Expect(result.RequeueAfter).To(Equal(namespaceCleanupInterval))
+
+		var eas myappsv1.EvictionAutoScaler
+		Expect(c.Get(ctx, key, &eas)).To(Succeed())
+		Expect(eas.Finalizers).To(BeEmpty())
+		Expect(eas.Status.SurgeActive).To(BeFalse())
+		Expect(eas.Status.SurgeReplicas).To(BeZero())
+		Expect(eas.Status.MinReplicas).To(Equal(int32(3)))
+	})
+
+	It("should leave a namespace that is not being deleted alone", func() {
+		c, result := reconcileWith(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant"}})
+		Expect(result.RequeueAfter).To(BeZero())
+
+		var eas myappsv1.EvictionAutoScaler
+		Expect(c.Get(ctx, key, &eas)).To(Succeed())
+		Expect(eas.Finalizers).To(ConsistOf(SurgeFinalizer))
+		Expect(eas.Status.SurgeActive).To(BeTrue())
+	})
+})
@@ -0,0 +1,25 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/azure/eviction-autoscaler/internal/namespacefilter"
+)
+
+// filter is the namespace filter every controller and watch predicate shares.
+type filter = namespacefilter.Interface
+
+// decider is implemented by namespace filters that also name what decided a
+// namespace.
+type decider interface {
+	Decide(ctx context.Context, c namespacefilter.Reader, ns string) (namespacefilter.Decision, error)
+}
+
+// decideNamespace returns f's decision for ns, with its source when f names one.
+func decideNamespace(ctx context.Context, f filter, c namespacefilter.Reader, ns string) (namespacefilter.Decision, error) {
+	if d, ok := f.(decider); ok {
+		return d.Decide(ctx, c, ns)
+	}
+	enabled, err := f.Filter(ctx, c, ns)
+	return namespacefilter.Decision{Enabled: enabled}, err
+}
@@ -0,0 +1,242 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/annotations"
+	"github.com/azure/eviction-autoscaler/internal/metrics"
+	"github.com/azure/eviction-autoscaler/internal/namespacefilter"
+)
+
+// NamespaceStatusReconciler maintains one EvictionAutoScalerNamespaceStatus per
+// namespace, so tenants can check a single object instead of joining PDBs,
+// EvictionAutoScalers and deployments themselves. Enrollment changes are recorded
+// as events on the Namespace and in the namespace enrollment metrics.
+type NamespaceStatusReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	Filter   filter
+}
+
+// +kubebuilder:rbac:groups=eviction-autoscaler.azure.com,resources=evictionautoscalernamespacestatuses,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=eviction-autoscaler.azure.com,resources=evictionautoscalernamespacestatuses/status,verbs=get;update;patch
+
+// Reconcile recomputes the summary for the namespace named by req. The status object
+// is created once the namespace is enrolled; after that it is kept up to date, and
+// reports Enrolled=false if the namespace opts out.
+func (r *NamespaceStatusReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	var ns corev1.Namespace
+	if err := r.Get(ctx, types.NamespacedName{Name: req.Name}, &ns); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	// NamespaceReconciler forgets the metrics of a namespace being deleted.
+	if !ns.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	decision, err := decideNamespace(ctx, r.Filter, r.Client, ns.Name)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	enrolled := decision.Enabled
+	summary, err := summarizeNamespace(ctx, r.Client, ns.Name)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	summary.Enrolled = enrolled
+
+	current := &myappsv1.EvictionAutoScalerNamespaceStatus{}
+	created := false
+	err = r.Get(ctx, types.NamespacedName{Namespace: ns.Name, Name: myappsv1.NamespaceStatusName}, current)
+	switch {
+	case apierrors.IsNotFound(err):
+		if !enrolled {
+			return ctrl.Result{}, nil
+		}
+		current = &myappsv1.EvictionAutoScalerNamespaceStatus{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns.Name, Name: myappsv1.NamespaceStatusName},
+		}
+		if err := r.Create(ctx, current); err != nil {
+			return ctrl.Result{}, err
+		}
+		logger.Info("Created namespace status", "namespace", ns.Name)
+		created = true
+	case err != nil:
+		return ctrl.Result{}, err
+	}
+
+	// The status is only created once a namespace is enrolled, so a new one counts
+	// as an enrollment.
+	if created || current.Status.Enrolled != enrolled {
+		r.recordTransition(ctx, &ns, decision)
+	}
+	if enrolled {
+		metrics.NamespaceEnrolledGauge.WithLabelValues(ns.Name).Set(1)
+	} else {
+		metrics.NamespaceEnrolledGauge.WithLabelValues(ns.Name).Set(0)
+	}
+
+	if equality.Semantic.DeepEqual(current.Status, *summary) {
+		return ctrl.Result{}, nil
+	}
+	current.Status = *summary
+	return ctrl.Result{}, r.Status().Update(ctx, current)
+}
+
+// recordTransition records ns being enrolled or opted out in the transitions metric
+// and as an event on the Namespace naming what decided it.
+func (r *NamespaceStatusReconciler) recordTransition(ctx context.Context, ns *corev1.Namespace, decision namespacefilter.Decision) {
+	transition, reason := metrics.UnenrolledTransition, "Unenrolled"
+	if decision.Enabled {
+		transition, reason = metrics.EnrolledTransition, "Enrolled"
+	}
+	source := enrollmentSource(ns, decision.Source)
+	logDecision(ctx, decisionEnrollmentChanged, ns.Name, "namespace "+transition, "source", source)
+	metrics.NamespaceEnrollmentTransitionsCounter.WithLabelValues(ns.Name, transition).Inc()
+	if r.Recorder != nil {
+		r.Recorder.Event(ns, corev1.EventTypeNormal, reason, fmt.Sprintf("namespace %s by %s", transition, source))
+	}
+}
+
+// enrollmentSource describes what decided whether ns is enrolled, given the source
+// the namespace filter reported: the enable annotation and the field manager that
+// last set it, the always-enabled list, or the controller's configuration.
+func enrollmentSource(ns *corev1.Namespace, source string) string {
+	switch source {
+	case namespacefilter.SourceAnnotation:
+	case namespacefilter.SourceAlwaysEnabled:
+		return "the controller's always-enabled namespaces"
+	default:
+		return "the controller's namespace configuration"
+	}
+	value := ns.Annotations[annotations.Enable]
+	described := fmt.Sprintf("annotation %s=%q", annotations.Enable, value)
+	field := fmt.Sprintf("%q", "f:"+annotations.Enable)
+	for _, entry := range ns.ManagedFields {
+		if entry.FieldsV1 != nil && strings.Contains(string(entry.FieldsV1.Raw), field) {
+			return described + " set by " + entry.Manager
+		}
+	}
+	return described
+}
+
+// summarizeNamespace counts the controller's objects in namespace and lists the
+// workloads it leaves unprotected. Enrolled is left for the caller to fill in.
+func summarizeNamespace(ctx context.Context, c client.Client, namespace string) (*myappsv1.NamespaceSummary, error) {
+	summary := &myappsv1.NamespaceSummary{}
+
+	var pdbs policyv1.PodDisruptionBudgetList
+	if err := c.List(ctx, &pdbs, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	for _, pdb := range pdbs.Items {
+		if pdb.Annotations[PDBOwnedByAnnotationKey] == ControllerName {
+			summary.ManagedPDBs++
+		}
+	}
+
+	var eases myappsv1.EvictionAutoScalerList
+	if err := c.List(ctx, &eases, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	for _, eas := range eases.Items {
+		summary.EvictionAutoScalers++
+		if eas.Status.SurgeActive {
+			summary.ActiveSurges++
+		}
+		if cond := meta.FindStatusCondition(eas.Status.Conditions, DegradedCondition); cond != nil && cond.Status == metav1.ConditionTrue {
+			summary.SkippedWorkloads = append(summary.SkippedWorkloads, myappsv1.SkippedWorkload{
+				Kind:   string(eas.Spec.TargetKind.Normalized()),
+				Name:   eas.Spec.TargetName,
+				Reason: cond.Reason + ": " + cond.Message,
+			})
+		}
+	}
+
+	var deployments v1.DeploymentList
+	if err := c.List(ctx, &deployments, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	for _, deployment := range deployments.Items {
+		skip, reason := excluded(&deployment)
+		if !skip {
+			skip, reason = shouldSkipPDBCreation(&deployment)
+		}
+		if skip {
+			summary.SkippedWorkloads = append(summary.SkippedWorkloads, myappsv1.SkippedWorkload{
+				Kind:   string(myappsv1.TargetKindDeployment),
+				Name:   deployment.Name,
+				Reason: reason,
+			})
+		}
+	}
+
+	var statefulSets v1.StatefulSetList
+	if err := c.List(ctx, &statefulSets, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	for _, statefulSet := range statefulSets.Items {
+		if skip, reason := excluded(&statefulSet); skip {
+			summary.SkippedWorkloads = append(summary.SkippedWorkloads, myappsv1.SkippedWorkload{
+				Kind:   string(myappsv1.TargetKindStatefulSet),
+				Name:   statefulSet.Name,
+				Reason: reason,
+			})
+		}
+	}
+
+	slices.SortFunc(summary.SkippedWorkloads, func(a, b myappsv1.SkippedWorkload) int {
+		if c := strings.Compare(a.Kind, b.Kind); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return summary, nil
+}
+
+// requeueNamespace maps an object to a reconcile of its namespace.
+func requeueNamespace(_ context.Context, obj client.Object) []reconcile.Request {
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: obj.GetNamespace()}}}
+}
+
+func (r *NamespaceStatusReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// Deployment status churns on every rollout; only spec and annotation changes
+	// affect whether a deployment is skipped.
+	deploymentChanged := predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{})
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("namespacestatus").
+		WithOptions(controllerOptions("namespacestatus")).
+		For(&corev1.Namespace{}).
+		Watches(&v1.Deployment{}, handler.EnqueueRequestsFromMapFunc(requeueNamespace), builder.WithPredicates(deploymentChanged)).
+		Watches(&policyv1.PodDisruptionBudget{}, handler.EnqueueRequestsFromMapFunc(requeueNamespace), builder.WithPredicates(predicate.AnnotationChangedPredicate{})).
+		Watches(&v1.StatefulSet{}, handler.EnqueueRequestsFromMapFunc(requeueNamespace), excludeChanged).
+		Watches(&myappsv1.EvictionAutoScaler{}, handler.EnqueueRequestsFromMapFunc(requeueNamespace)).
+		Watches(&myappsv1.EvictionAutoScalerNamespaceStatus{}, handler.EnqueueRequestsFromMapFunc(requeueNamespace)).
+		WithEventFilter(shardPredicate(r.Filter)).
+		Complete(traced("namespacestatus", r))
+}
@@ -0,0 +1,172 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/metrics"
+	"github.com/azure/eviction-autoscaler/internal/namespacefilter"
+)
+
+var _ = Describe("NamespaceStatusReconciler", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+		ns     *corev1.Namespace
+		key    types.NamespacedName
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		Expect(policyv1.AddToScheme(scheme)).To(Succeed())
+		Expect(myappsv1.AddToScheme(scheme)).To(Succeed())
+		ns = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:        "tenant",
+			Annotations: map[string]string{namespacefilter.EnableEvictionAutoscalerAnnotationKey: "true"},
+		}}
+		key = types.NamespacedName{Namespace: ns.Name, Name: myappsv1.NamespaceStatusName}
+	})
+
+	reconcileWith := func(c client.Client) {
+		r := &NamespaceStatusReconciler{Client: c, Scheme: scheme, Filter: namespacefilter.New(nil, true)}
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: ns.Name}})
+		Expect(err).ToNot(HaveOccurred())
+	}
+	build := func(objs ...client.Object) client.Client {
+		return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).
+			WithStatusSubresource(&myappsv1.EvictionAutoScalerNamespaceStatus{}).Build()
+	}
+
+	It("should summarize managed objects, active surges and skipped workloads", func() {
+		managed := &policyv1.PodDisruptionBudget{ObjectMeta: metav1.ObjectMeta{
+			Name: "web", Namespace: ns.Name, Annotations: map[string]string{PDBOwnedByAnnotationKey: ControllerName},
+		}}
+		userOwned := &policyv1.PodDisruptionBudget{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: ns.Name}}
+		surging := &myappsv1.EvictionAutoScaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: ns.Name},
+			Spec:       myappsv1.EvictionAutoScalerSpec{TargetName: "web", TargetKind: myappsv1.TargetKindDeployment},
+			Status:     myappsv1.EvictionAutoScalerStatus{SurgeActive: true},
+		}
+		broken := &myappsv1.EvictionAutoScaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: ns.Name},
+			Spec:       myappsv1.EvictionAutoScalerSpec{TargetName: "db", TargetKind: "sts"},
+			Status: myappsv1.EvictionAutoScalerStatus{Conditions: []metav1.Condition{
+				{Type: "Degraded", Status: metav1.ConditionTrue, Reason: "MissingTarget", Message: "Misssing  Target db"},
+			}},
+		}
+		maxUnavailable := intstr.FromInt32(1)
+		rolling := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "batch", Namespace: ns.Name},
+			Spec: appsv1.DeploymentSpec{Strategy: appsv1.DeploymentStrategy{
+				Type:          appsv1.RollingUpdateDeploymentStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDeployment{MaxUnavailable: &maxUnavailable},
+			}},
+		}
+		c := build(ns, managed, userOwned, surging, broken, rolling)
+		reconcileWith(c)
+
+		var status myappsv1.EvictionAutoScalerNamespaceStatus
+		Expect(c.Get(ctx, key, &status)).To(Succeed())
+		Expect(status.Status.Enrolled).To(BeTrue())
+		Expect(status.Status.ManagedPDBs).To(Equal(int32(1)))
+		Expect(status.Status.EvictionAutoScalers).To(Equal(int32(2)))
+		Expect(status.Status.ActiveSurges).To(Equal(int32(1)))
+		Expect(status.Status.SkippedWorkloads).To(Equal([]myappsv1.SkippedWorkload{
+			{Kind: string(myappsv1.TargetKindDeployment), Name: "batch", Reason: "maxUnavailable != 0"},
+			{Kind: string(myappsv1.TargetKindStatefulSet), Name: "db", Reason: "MissingTarget: Misssing  Target db"},
+		}))
+	})
+
+	It("should not create a status in a namespace that is not enrolled", func() {
+		ns.Annotations = nil
+		c := build(ns)
+		reconcileWith(c)
+		var status myappsv1.EvictionAutoScalerNamespaceStatus
+		Expect(c.Get(ctx, key, &status)).ToNot(Succeed())
+	})
+
+	It("should report a namespace that opted out", func() {
+		c := build(ns)
+		reconcileWith(c)
+
+		var current corev1.Namespace
+		Expect(c.Get(ctx, types.NamespacedName{Name: ns.Name}, &current)).To(Succeed())
+		current.Annotations[namespacefilter.EnableEvictionAutoscalerAnnotationKey] = "false"
+		Expect(c.Update(ctx, &current)).To(Succeed())
+		reconcileWith(c)
+
+		var status myappsv1.EvictionAutoScalerNamespaceStatus
+		Expect(c.Get(ctx, key, &status)).To(Succeed())
+		Expect(status.Status.Enrolled).To(BeFalse())
+	})
+})
+
+var _ = Describe("NamespaceStatusReconciler enrollment transitions", func() {
+	It("should record an event and metrics each time a namespace is enrolled or opts out", func() {
+		ctx := context.Background()
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		Expect(policyv1.AddToScheme(scheme)).To(Succeed())
+		Expect(myappsv1.AddToScheme(scheme)).To(Succeed())
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:        "audited",
+			Annotations: map[string]string{namespacefilter.EnableEvictionAutoscalerAnnotationKey: "true"},
+			ManagedFields: []metav1.ManagedFieldsEntry{{
+				Manager:    "kubectl-annotate",
+				Operation:  metav1.ManagedFieldsOperationUpdate,
+				APIVersion: "v1",
+				FieldsType: "FieldsV1",
+				FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:annotations":{"f:eviction-autoscaler.azure.com/enable":{}}}}`)},
+			}},
+		}}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(ns).
+			WithStatusSubresource(&myappsv1.EvictionAutoScalerNamespaceStatus{}).Build()
+		recorder := record.NewFakeRecorder(10)
+		r := &NamespaceStatusReconciler{Client: c, Scheme: scheme, Recorder: recorder, Filter: namespacefilter.New(nil, true)}
+		reconcileNS := func() {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: ns.Name}})
+			Expect(err).ToNot(HaveOccurred())
+		}
+		enrolledCount := metrics.NamespaceEnrollmentTransitionsCounter.WithLabelValues(ns.Name, metrics.EnrolledTransition)
+
+		reconcileNS()
+		Expect(recorder.Events).To(Receive(HavePrefix(`Normal Enrolled namespace enrolled by annotation eviction-autoscaler.azure.com/enable="true"`)))
+		Expect(testutil.ToFloat64(enrolledCount)).To(Equal(1.0))
+		Expect(testutil.ToFloat64(metrics.NamespaceEnrolledGauge.WithLabelValues(ns.Name))).To(Equal(1.0))
+
+		Expect(enrollmentSource(ns, namespacefilter.SourceAnnotation)).To(HaveSuffix("set by kubectl-annotate"))
+
+		// No transition, no event.
+		reconcileNS()
+		Expect(recorder.Events).To(BeEmpty())
+		Expect(testutil.ToFloat64(enrolledCount)).To(Equal(1.0))
+
+		var current corev1.Namespace
+		Expect(c.Get(ctx, types.NamespacedName{Name: ns.Name}, &current)).To(Succeed())
+		current.Annotations[namespacefilter.EnableEvictionAutoscalerAnnotationKey] = "false"
+		Expect(c.Update(ctx, &current)).To(Succeed())
+		reconcileNS()
+		Expect(recorder.Events).To(Receive(HavePrefix("Normal Unenrolled namespace unenrolled by annotation")))
+		Expect(testutil.ToFloat64(metrics.NamespaceEnrollmentTransitionsCounter.WithLabelValues(ns.Name, metrics.UnenrolledTransition))).To(Equal(1.0))
+		Expect(testutil.ToFloat64(metrics.NamespaceEnrolledGauge.WithLabelValues(ns.Name))).To(Equal(0.0))
+	})
+})
@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/azure/eviction-autoscaler/internal/metrics"
+)
+
+// drainProgress counts the pods a drain still has to evict from a cordoned node, and
+// how many of those are covered by a PDB that allows no disruptions right now.
+func drainProgress(ctx context.Context, c client.Client, pods []corev1.Pod) (remaining, blocked int, err error) {
+	blocking := map[string][]policyv1.PodDisruptionBudget{}
+	for i := range pods {
+		pod := &pods[i]
+		if !drainEvicts(pod) {
+			continue
+		}
+		remaining++
+		if _, ok := blocking[pod.Namespace]; !ok {
+			var list policyv1.PodDisruptionBudgetList
+			if err := c.List(ctx, &list, client.InNamespace(pod.Namespace)); err != nil {
+				return 0, 0, err
+			}
+			blocking[pod.Namespace] = []policyv1.PodDisruptionBudget{}
+			for _, pdb := range list.Items {
+				if pdb.Status.DisruptionsAllowed == 0 {
+					blocking[pod.Namespace] = append(blocking[pod.Namespace], pdb)
+				}
+			}
+		}
+		if pdb := pdbForPod(blocking[pod.Namespace], pod); pdb != nil && !unhealthyPodEvictable(pdb, pod) {
+			blocked++
+		}
+	}
+	return remaining, blocked, nil
+}
+
+// recordDrainProgress publishes the drain progress of a cordoned node. The node label
+// is an object name, so nothing is recorded in low-cardinality mode.
+func recordDrainProgress(node string, remaining, blocked int) {
+	if !metrics.PerObject() {
+		return
+	}
+	metrics.NodeDrainPodsRemainingGauge.WithLabelValues(node).Set(float64(remaining))
+	metrics.NodeDrainBlockedPodsGauge.WithLabelValues(node).Set(float64(blocked))
+}
+
+// clearDrainProgress drops the drain progress of a node that is no longer cordoned.
+func clearDrainProgress(node string) {
+	metrics.NodeDrainPodsRemainingGauge.DeleteLabelValues(node)
+	metrics.NodeDrainBlockedPodsGauge.DeleteLabelValues(node)
+}
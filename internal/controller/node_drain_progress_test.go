@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/metrics"
+	"github.com/azure/eviction-autoscaler/internal/namespacefilter"
+)
+
+var _ = Describe("Node drain progress", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(policyv1.AddToScheme(scheme)).To(Succeed())
+		Expect(myappsv1.AddToScheme(scheme)).To(Succeed())
+	})
+
+	pod := func(name, app string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop", Labels: map[string]string{"app": app}},
+			Spec:       corev1.PodSpec{NodeName: "drain-1"},
+		}
+	}
+	pdb := func(app string, allowed int32) *policyv1.PodDisruptionBudget {
+		return &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: app, Namespace: "shop"},
+			Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": app}}},
+			Status:     policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: allowed},
+		}
+	}
+	daemon := pod("agent-x", "agent")
+	daemon.OwnerReferences = []metav1.OwnerReference{{Kind: "DaemonSet", Name: "agent", UID: "agent-uid", Controller: ptr.To(true)}}
+
+	reconcile := func(node *corev1.Node, objs ...client.Object) ctrl.Result {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(append(objs, node)...).
+			WithIndex(&corev1.Pod{}, NodeNameIndex, func(obj client.Object) []string {
+				return []string{obj.(*corev1.Pod).Spec.NodeName}
+			}).Build()
+		r := &NodeReconciler{Client: c, Scheme: scheme, Filter: namespacefilter.New(nil, false)}
+		result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: node.Name}})
+		Expect(err).ToNot(HaveOccurred())
+		return result
+	}
+	cordoned := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "drain-1"}, Spec: corev1.NodeSpec{Unschedulable: true}}
+
+	It("should count the pods left to drain and those a PDB blocks", func() {
+		result := reconcile(cordoned,
+			pdb("web", 0), pdb("db", 1),
+			pod("web-1", "web"), pod("web-2", "web"), pod("db-0", "db"), pod("cron-1", "cron"), daemon)
+		Expect(result.RequeueAfter).To(Equal(cooldown))
+		Expect(testutil.ToFloat64(metrics.NodeDrainPodsRemainingGauge.WithLabelValues("drain-1"))).To(Equal(4.0))
+		Expect(testutil.ToFloat64(metrics.NodeDrainBlockedPodsGauge.WithLabelValues("drain-1"))).To(Equal(2.0))
+	})
+
+	It("should drop the gauges once the node is uncordoned", func() {
+		reconcile(cordoned, pdb("web", 0), pod("web-1", "web"))
+		Expect(testutil.CollectAndCount(metrics.NodeDrainPodsRemainingGauge)).To(BeNumerically(">=", 1))
+
+		uncordoned := cordoned.DeepCopy()
+		uncordoned.Spec.Unschedulable = false
+		Expect(reconcile(uncordoned, pdb("web", 0), pod("web-1", "web"))).To(Equal(ctrl.Result{}))
+		Expect(metrics.NodeDrainPodsRemainingGauge.DeleteLabelValues("drain-1")).To(BeFalse())
+		Expect(metrics.NodeDrainBlockedPodsGauge.DeleteLabelValues("drain-1")).To(BeFalse())
+	})
+
+	It("should stop refreshing once the node is empty", func() {
+		Expect(reconcile(cordoned, daemon)).To(Equal(ctrl.Result{}))
+		Expect(testutil.ToFloat64(metrics.NodeDrainPodsRemainingGauge.WithLabelValues("drain-1"))).To(BeZero())
+	})
+})
@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/azure/eviction-autoscaler/internal/annotations"
+)
+
+// DrainReadyAnnotationKey tells drain orchestrators such as kured whether a cordoned
+// node can be drained without being held by a PDB. The Node controller sets it to
+// true once no pod left to evict is covered by a PDB allowing no disruptions, which
+// is the case once the workloads blocking the drain have surged, and removes it when
+// the node is uncordoned.
+const DrainReadyAnnotationKey = annotations.DrainReady
+
+// setDrainReady records on node whether its drain can proceed, unless it already says so.
+func setDrainReady(ctx context.Context, c client.Client, node *corev1.Node, ready bool) error {
+	value := strconv.FormatBool(ready)
+	if current, ok := node.Annotations[DrainReadyAnnotationKey]; ok && current == value {
+		return nil
+	}
+	return patchDrainReady(ctx, c, node, &value)
+}
+
+// clearDrainReady removes the drain-ready annotation from a node that is no longer cordoned.
+func clearDrainReady(ctx context.Context, c client.Client, node *corev1.Node) error {
+	if _, ok := node.Annotations[DrainReadyAnnotationKey]; !ok {
+		return nil
+	}
+	return patchDrainReady(ctx, c, node, nil)
+}
+
+// patchDrainReady sets the drain-ready annotation to value, or removes it for nil.
+func patchDrainReady(ctx context.Context, c client.Client, node *corev1.Node, value *string) error {
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]*string{DrainReadyAnnotationKey: value},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return c.Patch(ctx, node.DeepCopy(), client.RawPatch(types.MergePatchType, patch))
+}
@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/namespacefilter"
+)
+
+var _ = Describe("Node drain readiness", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+		c      client.Client
+		r      *NodeReconciler
+		key    = types.NamespacedName{Name: "drain-1"}
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(policyv1.AddToScheme(scheme)).To(Succeed())
+		Expect(myappsv1.AddToScheme(scheme)).To(Succeed())
+		c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "drain-1"}, Spec: corev1.NodeSpec{Unschedulable: true}},
+			&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "shop", Labels: map[string]string{"app": "web"}},
+				Spec:       corev1.PodSpec{NodeName: "drain-1"},
+			},
+			&policyv1.PodDisruptionBudget{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+				Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+			},
+		).WithStatusSubresource(&policyv1.PodDisruptionBudget{}).WithIndex(&corev1.Pod{}, NodeNameIndex, func(obj client.Object) []string {
+			return []string{obj.(*corev1.Pod).Spec.NodeName}
+		}).Build()
+		r = &NodeReconciler{Client: c, Scheme: scheme, Filter: namespacefilter.New(nil, false), TrackDrainReady: true}
+	})
+
+	drainReady := func() map[string]string {
+		var node corev1.Node
+		Expect(c.Get(ctx, key, &node)).To(Succeed())
+		return node.Annotations
+	}
+
+	It("should mark the node ready once its PDBs allow the drain, and clear it on uncordon", func() {
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drainReady()).To(HaveKeyWithValue(DrainReadyAnnotationKey, "false"))
+
+		// The blocking workload surged, so its PDB allows a disruption.
+		var pdb policyv1.PodDisruptionBudget
+		Expect(c.Get(ctx, types.NamespacedName{Name: "web", Namespace: "shop"}, &pdb)).To(Succeed())
+		pdb.Status.DisruptionsAllowed = 1
+		Expect(c.Status().Update(ctx, &pdb)).To(Succeed())
+		_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drainReady()).To(HaveKeyWithValue(DrainReadyAnnotationKey, "true"))
+
+		var node corev1.Node
+		Expect(c.Get(ctx, key, &node)).To(Succeed())
+		node.Spec.Unschedulable = false
+		Expect(c.Update(ctx, &node)).To(Succeed())
+		_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drainReady()).ToNot(HaveKey(DrainReadyAnnotationKey))
+	})
+})
@@ -16,10 +16,13 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 // EvictionAutoScalerReconciler reconciles a EvictionAutoScaler object
@@ -27,12 +30,26 @@ type NodeReconciler struct {
 	client.Client
 	Scheme   *runtime.Scheme
 	Recorder record.EventRecorder
+	// Filter is only consulted for shard ownership; pods in namespaces owned by
+	// another replica are left to that replica.
+	Filter filter
+	// TrackWarmup, when set, marks nodes with the warmup-complete annotation once
+	// they are Ready and run every DaemonSet pod expected on them.
+	TrackWarmup bool
+	// TrackDrainReady, when set, marks cordoned nodes with the drain-ready annotation
+	// so external drain orchestrators can wait for blocking workloads to surge.
+	TrackDrainReady bool
+	// Cooldown is how often a cordoned node is looked at again while pods remain on
+	// it. Zero uses the default cooldown.
+	Cooldown time.Duration
 }
 
 const NodeNameIndex = "spec.nodeName"
 
 // +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=pods,verbs=watch;get;list
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=patch
+// +kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=get;list;watch
 
 // Reconcile is the main loop of the controller. It will look for unschedulded nodes and for every pod on the node
 func (r *NodeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -44,17 +61,37 @@ func (r *NodeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 	if err != nil {
 		//should we use a finalizer to scale back down on deletion?
 		if errors.IsNotFound(err) {
+			clearDrainProgress(req.Name)
 			return ctrl.Result{}, nil // EvictionAutoScaler not found, could be deleted, nothing to do
 		}
 		return ctrl.Result{}, err // Error fetching EvictionAutoScaler
 	}
 
+	if r.TrackWarmup && node.Annotations[WarmupCompleteAnnotationKey] != "true" && !node.Spec.Unschedulable {
+		warm, err := nodeWarmedUp(ctx, r.Client, node)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if warm {
+			logger.Info("Node finished warming up", "node", node.Name)
+			if err := markWarmupComplete(ctx, r.Client, node); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+	}
+
 	// Track node cordoning events
 	if node.Spec.Unschedulable {
 		metrics.NodeCordoningCounter.Inc()
 	}
 
 	if !node.Spec.Unschedulable {
+		clearDrainProgress(node.Name)
+		if r.TrackDrainReady {
+			if err := clearDrainReady(ctx, r.Client, node); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
 		return ctrl.Result{}, err
 	}
 
@@ -65,8 +102,38 @@ func (r *NodeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 		return ctrl.Result{}, err
 	}
 
+	var inShardPods []corev1.Pod
+	for _, pod := range podlist.Items {
+		if inShard(r.Filter, pod.Namespace) {
+			inShardPods = append(inShardPods, pod)
+		}
+	}
+	remaining, blocked, err := drainProgress(ctx, r.Client, inShardPods)
+	if err != nil {
+		logger.Error(err, "Error: Unable to compute drain progress", "node", node.Name)
+		return ctrl.Result{}, err
+	}
+	recordDrainProgress(node.Name, remaining, blocked)
+
+	if r.TrackDrainReady {
+		// Orchestrators drain the whole node, so with sharding every replica counts
+		// every pod and they all agree on the annotation.
+		if len(inShardPods) != len(podlist.Items) {
+			if _, blocked, err = drainProgress(ctx, r.Client, podlist.Items); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		if err := setDrainReady(ctx, r.Client, node, blocked == 0); err != nil {
+			logger.Error(err, "Error: Unable to mark node drain readiness", "node", node.Name)
+			return ctrl.Result{}, err
+		}
+	}
+
 	podchanged := false
 	for _, pod := range podlist.Items {
+		if !inShard(r.Filter, pod.Namespace) {
+			continue
+		}
 		// TODO group pods by namespace to share list/get of EvictionAutoScalers/pdbs
 		// Also  could do this to avoid list/llooku up but need to measure if either helps
 		//if !possibleTarget(pod.GetOwnerReferences()) {
@@ -111,7 +178,8 @@ func (r *NodeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 		// Track eviction and node drain events
 		metrics.EvictionCounter.WithLabelValues(pod.Namespace).Inc()
 
-		logger.Info("Found EvictionAutoScaler for pod", "name", applicableEvictionAutoScaler.Name, "namespace", pod.Namespace, "podname", pod.Name, "node", node.Name)
+		logDecision(ctx, decisionEvictionAnticipated, pod.Namespace+"/"+applicableEvictionAutoScaler.Name, "marking pod as a disruption target",
+			"podname", pod.Name, "node", node.Name)
 		pod := pod.DeepCopy()
 		updatedpod := podutil.UpdatePodCondition(&pod.Status, &corev1.PodCondition{
 			Type:    corev1.DisruptionTarget,
@@ -141,8 +209,10 @@ func (r *NodeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 	// pods till they get off or node is uncordoned.
 	//TODO pull smallest cooldown from all EvictionAutoScalers if they allow defining it.
 	var cooldownNeeded time.Duration
-	if podchanged {
-		cooldownNeeded = cooldown
+	// Pod deletions don't trigger a node reconcile, so keep refreshing drain progress
+	// until the node is empty.
+	if podchanged || remaining > 0 {
+		cooldownNeeded = cooldownOr(r.Cooldown)
 	}
 	return ctrl.Result{RequeueAfter: cooldownNeeded}, nil
 }
@@ -159,17 +229,44 @@ func (r *NodeReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		return err
 	}
 
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&corev1.Node{}).
-		WithEventFilter(predicate.Funcs{
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&corev1.Node{}, builder.WithPredicates(predicate.Funcs{
 			// ignore status updates as we only care about cordon.
 			UpdateFunc: func(ue event.UpdateEvent) bool {
 				oldNode := ue.ObjectOld.(*corev1.Node)
 				newNode := ue.ObjectNew.(*corev1.Node)
 				return oldNode.Spec.Unschedulable == newNode.Spec.Unschedulable
 			},
-		}).
-		Complete(r)
+		})).
+		WithOptions(controllerOptions("node"))
+	if r.TrackWarmup {
+		// A node finishes warming up when its last DaemonSet pod turns Ready.
+		b = b.Watches(&corev1.Pod{},
+			handler.EnqueueRequestsFromMapFunc(daemonSetPodNode),
+			builder.WithPredicates(predicate.Funcs{
+				CreateFunc:  func(event.CreateEvent) bool { return false },
+				DeleteFunc:  func(event.DeleteEvent) bool { return false },
+				GenericFunc: func(event.GenericEvent) bool { return false },
+				UpdateFunc:  podBecameReady,
+			}))
+	}
+	return b.Complete(traced("node", r))
+}
+
+// daemonSetPodNode maps a DaemonSet pod to the node it runs on.
+func daemonSetPodNode(_ context.Context, obj client.Object) []reconcile.Request {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok || pod.Spec.NodeName == "" || controllerOf(pod) == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: pod.Spec.NodeName}}}
+}
+
+// podBecameReady reports whether a pod update turned the pod Ready.
+func podBecameReady(e event.UpdateEvent) bool {
+	oldPod, okOld := e.ObjectOld.(*corev1.Pod)
+	newPod, okNew := e.ObjectNew.(*corev1.Pod)
+	return okOld && okNew && !podutil.IsPodReady(oldPod) && podutil.IsPodReady(newPod)
 }
 
 /*
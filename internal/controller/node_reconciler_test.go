@@ -2,7 +2,6 @@ package controllers
 
 import (
 	"context"
-	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -205,7 +204,8 @@ var _ = Describe("Node Controller", func() {
 				NamespacedName: nodeNamespacedName,
 			})
 			Expect(err).NotTo(HaveOccurred())
-			Expect(result.RequeueAfter).To(Equal(time.Duration(0)))
+			// Requeued only to refresh drain progress while the pod is on the node.
+			Expect(result.RequeueAfter).To(Equal(cooldown))
 
 			By("checking pod condition ")
 			err = k8sClient.Get(ctx, podNamespacedName, pod)
@@ -0,0 +1,187 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/azure/eviction-autoscaler/internal/annotations"
+	"github.com/azure/eviction-autoscaler/internal/podutil"
+)
+
+// WarmupCompleteAnnotationKey marks a node that is Ready and runs a Ready pod of
+// every DaemonSet expected on it. The Node controller sets it once; it is never
+// removed, since a node only warms up after it joins.
+const WarmupCompleteAnnotationKey = annotations.WarmupComplete
+
+// nodeWarmupRequeue is how often a scale-down held for warming nodes looks again.
+const nodeWarmupRequeue = 15 * time.Second
+
+// nodeWarmedUp reports whether node is Ready and every DaemonSet expected to run on
+// it has a Ready pod there.
+func nodeWarmedUp(ctx context.Context, c client.Reader, node *corev1.Node) (bool, error) {
+	if !nodeReady(node) {
+		return false, nil
+	}
+	var daemonSets appsv1.DaemonSetList
+	if err := c.List(ctx, &daemonSets); err != nil {
+		return false, err
+	}
+	var pods corev1.PodList
+	if err := c.List(ctx, &pods, client.MatchingFields{NodeNameIndex: node.Name}); err != nil {
+		return false, err
+	}
+	ready := map[types.UID]bool{}
+	for _, pod := range pods.Items {
+		if owner := controllerOf(&pod); owner != "" && podutil.IsPodReady(&pod) {
+			ready[owner] = true
+		}
+	}
+	for _, ds := range daemonSets.Items {
+		if daemonSetExpectedOn(&ds, node) && !ready[ds.UID] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// coldNodes returns the sorted names of schedulable nodes that joined less than
+// timeout ago and have not finished warming up. Older nodes no longer hold a
+// scale-down, so a DaemonSet that never becomes Ready can't block it forever.
+func coldNodes(ctx context.Context, c client.Reader, timeout time.Duration, now time.Time) ([]string, error) {
+	var nodeList corev1.NodeList
+	if err := c.List(ctx, &nodeList); err != nil {
+		return nil, err
+	}
+	var cold []string
+	for _, node := range nodeList.Items {
+		if node.Spec.Unschedulable || node.Annotations[WarmupCompleteAnnotationKey] == "true" {
+			continue
+		}
+		if now.Sub(node.CreationTimestamp.Time) < timeout {
+			cold = append(cold, node.Name)
+		}
+	}
+	slices.Sort(cold)
+	return cold, nil
+}
+
+// markWarmupComplete records on the node that it finished warming up.
+func markWarmupComplete(ctx context.Context, c client.Client, node *corev1.Node) error {
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]string{WarmupCompleteAnnotationKey: "true"},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return c.Patch(ctx, node.DeepCopy(), client.RawPatch(types.MergePatchType, patch))
+}
+
+func nodeReady(node *corev1.Node) bool {
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == corev1.NodeReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// controllerOf returns the UID of the DaemonSet controlling pod, if any.
+func controllerOf(pod *corev1.Pod) types.UID {
+	for _, owner := range pod.OwnerReferences {
+		if owner.Controller != nil && *owner.Controller && owner.Kind == "DaemonSet" {
+			return owner.UID
+		}
+	}
+	return ""
+}
+
+// daemonSetExpectedOn approximates the DaemonSet controller's placement: the node
+// must match the template's node selector and required node affinity, and every
+// NoSchedule or NoExecute taint must be tolerated. Taints under node.kubernetes.io/
+// are ignored because the DaemonSet controller tolerates them on every pod it creates.
+func daemonSetExpectedOn(ds *appsv1.DaemonSet, node *corev1.Node) bool {
+	spec := ds.Spec.Template.Spec
+	if !nodeAffinityMatches(&spec, node) {
+		return false
+	}
+	for _, taint := range node.Spec.Taints {
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule || strings.HasPrefix(taint.Key, "node.kubernetes.io/") {
+			continue
+		}
+		if !slices.ContainsFunc(spec.Tolerations, func(t corev1.Toleration) bool { return t.ToleratesTaint(log.Log, &taint, false) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// nodeSelectorTermMatches reports whether node satisfies all requirements of term.
+// An empty term matches nothing, as in the scheduler.
+func nodeSelectorTermMatches(term corev1.NodeSelectorTerm, node *corev1.Node) bool {
+	if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+		return false
+	}
+	for _, req := range term.MatchExpressions {
+		if !requirementMatches(req, labels.Set(node.Labels)) {
+			return false
+		}
+	}
+	// metadata.name is the only supported field; node names may exceed the label
+	// value length, so it is compared directly.
+	for _, req := range term.MatchFields {
+		if req.Key != "metadata.name" {
+			return false
+		}
+		switch req.Operator {
+		case corev1.NodeSelectorOpIn:
+			if !slices.Contains(req.Values, node.Name) {
+				return false
+			}
+		case corev1.NodeSelectorOpNotIn:
+			if slices.Contains(req.Values, node.Name) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func requirementMatches(req corev1.NodeSelectorRequirement, set labels.Set) bool {
+	var op selection.Operator
+	switch req.Operator {
+	case corev1.NodeSelectorOpIn:
+		op = selection.In
+	case corev1.NodeSelectorOpNotIn:
+		op = selection.NotIn
+	case corev1.NodeSelectorOpExists:
+		op = selection.Exists
+	case corev1.NodeSelectorOpDoesNotExist:
+		op = selection.DoesNotExist
+	case corev1.NodeSelectorOpGt:
+		op = selection.GreaterThan
+	case corev1.NodeSelectorOpLt:
+		op = selection.LessThan
+	default:
+		return false
+	}
+	r, err := labels.NewRequirement(req.Key, op, req.Values)
+	if err != nil {
+		return false
+	}
+	return r.Matches(set)
+}
@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("node warmup", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+		node   *corev1.Node
+		agent  *appsv1.DaemonSet
+		gpu    *appsv1.DaemonSet
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		node = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "new", Labels: map[string]string{"pool": "general"}},
+			Spec: corev1.NodeSpec{Taints: []corev1.Taint{
+				{Key: "node.kubernetes.io/not-ready", Effect: corev1.TaintEffectNoSchedule},
+			}},
+			Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}}},
+		}
+		agent = &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "kube-system", UID: "agent-uid"}}
+		gpu = &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "gpu", Namespace: "kube-system", UID: "gpu-uid"},
+			Spec: appsv1.DaemonSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				NodeSelector: map[string]string{"pool": "gpu"},
+			}}},
+		}
+	})
+
+	daemonPod := func(ds *appsv1.DaemonSet, ready corev1.ConditionStatus) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: ds.Name + "-new", Namespace: ds.Namespace, OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "apps/v1", Kind: "DaemonSet", Name: ds.Name, UID: ds.UID, Controller: ptr.To(true)},
+			}},
+			Spec:   corev1.PodSpec{NodeName: node.Name},
+			Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}}},
+		}
+	}
+	build := func(objs ...client.Object) client.Client {
+		return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).
+			WithIndex(&corev1.Pod{}, NodeNameIndex, func(obj client.Object) []string {
+				return []string{obj.(*corev1.Pod).Spec.NodeName}
+			}).Build()
+	}
+
+	It("should only expect DaemonSets whose node selector and tolerations fit the node", func() {
+		Expect(daemonSetExpectedOn(agent, node)).To(BeTrue())
+		Expect(daemonSetExpectedOn(gpu, node)).To(BeFalse())
+
+		node.Spec.Taints = append(node.Spec.Taints, corev1.Taint{Key: "dedicated", Value: "infra", Effect: corev1.TaintEffectNoSchedule})
+		Expect(daemonSetExpectedOn(agent, node)).To(BeFalse())
+		agent.Spec.Template.Spec.Tolerations = []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpExists}}
+		Expect(daemonSetExpectedOn(agent, node)).To(BeTrue())
+	})
+
+	It("should match required node affinity terms", func() {
+		agent.Spec.Template.Spec.Affinity = &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{
+				{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "pool", Operator: corev1.NodeSelectorOpIn, Values: []string{"gpu"}}}},
+				{MatchFields: []corev1.NodeSelectorRequirement{{Key: "metadata.name", Operator: corev1.NodeSelectorOpIn, Values: []string{"new"}}}},
+			}},
+		}}
+		Expect(daemonSetExpectedOn(agent, node)).To(BeTrue())
+		node.Name = "other"
+		Expect(daemonSetExpectedOn(agent, node)).To(BeFalse())
+	})
+
+	It("should be warm only once every expected DaemonSet pod is Ready", func() {
+		warm, err := nodeWarmedUp(ctx, build(node, agent, gpu, daemonPod(agent, corev1.ConditionFalse)), node)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(warm).To(BeFalse())
+
+		warm, err = nodeWarmedUp(ctx, build(node, agent, gpu, daemonPod(agent, corev1.ConditionTrue)), node)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(warm).To(BeTrue())
+
+		node.Status.Conditions[0].Status = corev1.ConditionFalse
+		warm, err = nodeWarmedUp(ctx, build(node, agent, gpu, daemonPod(agent, corev1.ConditionTrue)), node)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(warm).To(BeFalse())
+	})
+
+	It("should only hold scale-down for recent nodes that are not marked warm", func() {
+		now := time.Now()
+		at := func(name string, age time.Duration) *corev1.Node {
+			return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(now.Add(-age))}}
+		}
+		warm := at("warm", time.Minute)
+		warm.Annotations = map[string]string{WarmupCompleteAnnotationKey: "true"}
+		cordoned := at("cordoned", time.Minute)
+		cordoned.Spec.Unschedulable = true
+
+		cold, err := coldNodes(ctx, build(at("cold", time.Minute), at("stuck", time.Hour), warm, cordoned), 10*time.Minute, now)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cold).To(Equal([]string{"cold"}))
+	})
+
+	It("should mark a node as warmed up", func() {
+		c := build(node)
+		Expect(markWarmupComplete(ctx, c, node)).To(Succeed())
+		var updated corev1.Node
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(node), &updated)).To(Succeed())
+		Expect(updated.Annotations).To(HaveKeyWithValue(WarmupCompleteAnnotationKey, "true"))
+	})
+})
@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8s_types "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/azure/eviction-autoscaler/internal/annotations"
+)
+
+// PDBOwnerIndex indexes PDBs by the owner annotation, as Kind/name, in ownerless
+// mode.
+const PDBOwnerIndex = "pdbOwner"
+
+// ownerReferences is false in ownerless mode.
+var ownerReferences = true
+
+// SetOwnerReferences selects how the controllers link the PDBs and
+// EvictionAutoScalers they create to their owners. With owner references, the
+// default, the garbage collector deletes them with their owner. Without, for PDBs
+// synced by GitOps tools that report owner references as drift, the owner is
+// recorded in the owner annotation and the controllers delete the objects
+// themselves once it is gone. Call it before setting up the controllers.
+func SetOwnerReferences(enabled bool) {
+	ownerReferences = enabled
+}
+
+// setOwner makes owner the controller of obj: through an owner reference, or the
+// owner annotation in ownerless mode.
+func setOwner(obj client.Object, owner metav1.OwnerReference) {
+	if ownerReferences {
+		controller := true
+		blockOwnerDeletion := true
+		owner.Controller = &controller
+		owner.BlockOwnerDeletion = &blockOwnerDeletion
+		obj.SetOwnerReferences(append(obj.GetOwnerReferences(), owner))
+		return
+	}
+	objAnnotations := obj.GetAnnotations()
+	if objAnnotations == nil {
+		objAnnotations = map[string]string{}
+	}
+	objAnnotations[annotations.Owner] = owner.Kind + "/" + owner.Name
+	obj.SetAnnotations(objAnnotations)
+}
+
+// ownerOf returns the kind and name of obj's controller, from its controller owner
+// reference or else the owner annotation.
+func ownerOf(obj client.Object) (kind, name string, ok bool) {
+	if ref := metav1.GetControllerOf(obj); ref != nil {
+		return ref.Kind, ref.Name, true
+	}
+	return strings.Cut(obj.GetAnnotations()[annotations.Owner], "/")
+}
+
+// removeOwner drops the controller owner reference or owner annotation naming a
+// kind owner from obj. It reports whether there was one.
+func removeOwner(obj client.Object, kind string) bool {
+	removed := false
+	var refs []metav1.OwnerReference
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.Kind == kind {
+			removed = true
+			continue
+		}
+		refs = append(refs, ref)
+	}
+	obj.SetOwnerReferences(refs)
+	if ownerKind, _, ok := strings.Cut(obj.GetAnnotations()[annotations.Owner], "/"); ok && ownerKind == kind {
+		objAnnotations := obj.GetAnnotations()
+		delete(objAnnotations, annotations.Owner)
+		obj.SetAnnotations(objAnnotations)
+		removed = true
+	}
+	return removed
+}
+
+// ownerObjects are the kinds of owner the owner annotation can name.
+var ownerObjects = map[string]func() client.Object{
+	ResourceTypeDeployment: func() client.Object { return &appsv1.Deployment{} },
+	"Job":                  func() client.Object { return &batchv1.Job{} },
+	"PodDisruptionBudget":  func() client.Object { return &policyv1.PodDisruptionBudget{} },
+}
+
+// collectOrphan does the garbage collector's job for obj in ownerless mode: if
+// the owner its owner annotation names is gone, obj is deleted. It reports
+// whether obj was deleted.
+func collectOrphan(ctx context.Context, c client.Client, obj client.Object) (bool, error) {
+	kind, name, ok := strings.Cut(obj.GetAnnotations()[annotations.Owner], "/")
+	newOwner, known := ownerObjects[kind]
+	if !ok || !known {
+		return false, nil
+	}
+	err := c.Get(ctx, k8s_types.NamespacedName{Namespace: obj.GetNamespace(), Name: name}, newOwner())
+	if !apierrors.IsNotFound(err) {
+		return false, err
+	}
+	log.FromContext(ctx).Info("Deleting object whose owner is gone", "owner", kind+"/"+name,
+		"namespace", obj.GetNamespace(), "name", obj.GetName())
+	return true, client.IgnoreNotFound(c.Delete(ctx, obj))
+}
+
+// indexPDBOwner is the PDBOwnerIndex indexer.
+func indexPDBOwner(obj client.Object) []string {
+	if owner, ok := obj.GetAnnotations()[annotations.Owner]; ok {
+		return []string{owner}
+	}
+	return nil
+}
+
+// requeueOwnedPDBs maps a deleted owner to the PDBs naming it in their owner
+// annotation, so they are deleted after it.
+func requeueOwnedPDBs(c client.Client, kind string) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		var pdbList policyv1.PodDisruptionBudgetList
+		if err := c.List(ctx, &pdbList, client.InNamespace(obj.GetNamespace()),
+			client.MatchingFields{PDBOwnerIndex: kind + "/" + obj.GetName()}); err != nil {
+			log.FromContext(ctx).Error(err, "Failed to list PDBs by owner", "owner", kind+"/"+obj.GetName())
+			return nil
+		}
+		requests := make([]reconcile.Request, 0, len(pdbList.Items))
+		for _, pdb := range pdbList.Items {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&pdb)})
+		}
+		return requests
+	}
+}
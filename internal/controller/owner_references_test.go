@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"context"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/annotations"
+	"github.com/azure/eviction-autoscaler/internal/namespacefilter"
+)
+
+var _ = Describe("ownerless mode", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+		key    = types.NamespacedName{Namespace: "default", Name: "web"}
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		Expect(batchv1.AddToScheme(scheme)).To(Succeed())
+		Expect(policyv1.AddToScheme(scheme)).To(Succeed())
+		Expect(autoscalingv2.AddToScheme(scheme)).To(Succeed())
+		Expect(kedav1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(myappsv1.AddToScheme(scheme)).To(Succeed())
+		SetOwnerReferences(false)
+		DeferCleanup(SetOwnerReferences, true)
+	})
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", UID: "web-uid"},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr.To[int32](2),
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+		},
+	}
+
+	newClient := func(objs ...client.Object) client.Client {
+		objs = append(objs, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}})
+		return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).
+			WithIndex(&policyv1.PodDisruptionBudget{}, PDBOwnerIndex, indexPDBOwner).Build()
+	}
+
+	It("should name the owner in an annotation instead of an owner reference", func() {
+		pdb, err := NewPDBForDeployment(ctx, newClient(), deployment)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pdb.OwnerReferences).To(BeEmpty())
+		Expect(pdb.Annotations).To(HaveKeyWithValue(annotations.Owner, "Deployment/web"))
+
+		eas := NewEvictionAutoScalerForPDB(pdb, deploymentKind, "web")
+		Expect(eas.OwnerReferences).To(BeEmpty())
+		Expect(eas.Annotations).To(HaveKeyWithValue(annotations.Owner, "PodDisruptionBudget/web"))
+
+		job := &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "train", Namespace: "default"},
+			Spec:       batchv1.JobSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "train"}}},
+		}
+		Expect(jobOwned(newPDBForJob(job))).To(BeTrue())
+		Expect(jobOwned(pdb)).To(BeFalse())
+	})
+
+	It("should delete the PDB and then its EvictionAutoScaler once the deployment is gone", func() {
+		c := newClient()
+		pdb, err := NewPDBForDeployment(ctx, c, deployment)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(c.Create(ctx, pdb)).To(Succeed())
+		Expect(c.Create(ctx, NewEvictionAutoScalerForPDB(pdb, deploymentKind, "web"))).To(Succeed())
+
+		Expect(requeueOwnedPDBs(c, ResourceTypeDeployment)(ctx, deployment)).To(ConsistOf(ctrl.Request{NamespacedName: key}))
+
+		r := &PDBToEvictionAutoScalerReconciler{Client: c, Scheme: scheme, Filter: namespacefilter.New(nil, false)}
+		_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(apierrors.IsNotFound(c.Get(ctx, key, &policyv1.PodDisruptionBudget{}))).To(BeTrue())
+		Expect(c.Get(ctx, key, &myappsv1.EvictionAutoScaler{})).To(Succeed())
+
+		_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(apierrors.IsNotFound(c.Get(ctx, key, &myappsv1.EvictionAutoScaler{}))).To(BeTrue())
+	})
+
+	It("should delete the PDB of a deleted job", func() {
+		job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "train", Namespace: "default"},
+			Spec: batchv1.JobSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "train"}}}}
+		userPDB := &policyv1.PodDisruptionBudget{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}}
+		c := newClient(newPDBForJob(job), userPDB)
+		r := &JobToPDBReconciler{Client: c, Scheme: scheme, Filter: namespacefilter.New(nil, false)}
+
+		for _, name := range []string{"train", "web"} {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: name}})
+			Expect(err).ToNot(HaveOccurred())
+		}
+		Expect(apierrors.IsNotFound(c.Get(ctx, types.NamespacedName{Namespace: "default", Name: "train"}, &policyv1.PodDisruptionBudget{}))).To(BeTrue())
+		Expect(c.Get(ctx, key, &policyv1.PodDisruptionBudget{})).To(Succeed())
+	})
+})
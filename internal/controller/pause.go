@@ -0,0 +1,27 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/azure/eviction-autoscaler/internal/pause"
+)
+
+// pausedRetry is how long a reconcile whose writes were refused because the
+// controller is paused waits before trying again.
+const pausedRetry = 30 * time.Second
+
+// requeueWhilePaused turns a reconcile that failed only because the controller is
+// paused into a plain requeue. The refusal is expected, and the rate limiter's
+// growing backoff would otherwise hold work back for minutes after resuming.
+func requeueWhilePaused(ctx context.Context, result reconcile.Result, err error) (reconcile.Result, error) {
+	if !errors.Is(err, pause.ErrPaused) {
+		return result, err
+	}
+	log.FromContext(ctx).V(1).Info("Write refused while paused, retrying", "retryAfter", pausedRetry)
+	return reconcile.Result{RequeueAfter: pausedRetry}, nil
+}
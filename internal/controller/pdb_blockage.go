@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/metrics"
+)
+
+// blockageClock accumulates eviction_autoscaler_pdb_blocked_seconds_total. Each
+// reconcile that finds a PDB blocking an outstanding eviction adds the time since the
+// previous observation; the reconcile that finds it unblocked adds the last stretch.
+// Observations are kept in memory, so a blockage spanning a restart or failover is
+// counted from the first reconcile after it.
+type blockageClock struct {
+	mu   sync.Mutex
+	seen map[types.NamespacedName]time.Time
+}
+
+// observe records whether the PDB of the same name as eas is blocking an outstanding
+// eviction at now.
+func (c *blockageClock) observe(eas *myappsv1.EvictionAutoScaler, disruptionsAllowed int32, now time.Time) {
+	key := types.NamespacedName{Namespace: eas.Namespace, Name: eas.Name}
+	blocked := disruptionsAllowed == 0 && evictionOutstanding(eas)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if last, ok := c.seen[key]; ok && now.After(last) {
+		metrics.PDBBlockedSecondsCounter.WithLabelValues(eas.Namespace, metrics.Name(eas.Name)).Add(now.Sub(last).Seconds())
+	}
+	if !blocked {
+		delete(c.seen, key)
+		return
+	}
+	if c.seen == nil {
+		c.seen = map[types.NamespacedName]time.Time{}
+	}
+	c.seen[key] = now
+}
+
+// forget drops the observation of a deleted EvictionAutoScaler's PDB.
+func (c *blockageClock) forget(key types.NamespacedName) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.seen, key)
+}
+
+// evictionOutstanding reports whether eas has an eviction it hasn't handled yet. A
+// surge still held counts too: its eviction is only marked handled once the surge is
+// reverted, after the PDB allows disruptions again.
+func evictionOutstanding(eas *myappsv1.EvictionAutoScaler) bool {
+	if eas.Spec.LastEviction.EvictionTime.IsZero() {
+		return false
+	}
+	return eas.Spec.LastEviction != eas.Status.LastEviction
+}
@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/metrics"
+)
+
+var _ = Describe("PDB blockage clock", func() {
+	now := time.Now()
+
+	It("should count the seconds a PDB blocks an outstanding eviction", func() {
+		eas := &myappsv1.EvictionAutoScaler{ObjectMeta: metav1.ObjectMeta{Name: "blocked-web", Namespace: "default"}}
+		eas.Spec.LastEviction = myappsv1.Eviction{PodName: "web-1", EvictionTime: metav1.NewTime(now)}
+		counter := metrics.PDBBlockedSecondsCounter.WithLabelValues("default", "blocked-web")
+		before := testutil.ToFloat64(counter)
+
+		var clock blockageClock
+		clock.observe(eas, 0, now)
+		Expect(testutil.ToFloat64(counter)).To(Equal(before))
+		clock.observe(eas, 0, now.Add(30*time.Second))
+		Expect(testutil.ToFloat64(counter)).To(Equal(before + 30))
+
+		// Unblocked: the last stretch is counted, then nothing until it blocks again.
+		clock.observe(eas, 1, now.Add(40*time.Second))
+		Expect(testutil.ToFloat64(counter)).To(Equal(before + 40))
+		clock.observe(eas, 1, now.Add(time.Minute))
+		Expect(testutil.ToFloat64(counter)).To(Equal(before + 40))
+	})
+
+	It("should not count a PDB without an outstanding eviction", func() {
+		eas := &myappsv1.EvictionAutoScaler{ObjectMeta: metav1.ObjectMeta{Name: "idle-web", Namespace: "default"}}
+		counter := metrics.PDBBlockedSecondsCounter.WithLabelValues("default", "idle-web")
+		before := testutil.ToFloat64(counter)
+
+		var clock blockageClock
+		clock.observe(eas, 0, now)
+		clock.observe(eas, 0, now.Add(time.Minute))
+		Expect(testutil.ToFloat64(counter)).To(Equal(before))
+
+		eas.Spec.LastEviction = myappsv1.Eviction{PodName: "web-1", EvictionTime: metav1.NewTime(now)}
+		eas.Status.LastEviction = eas.Spec.LastEviction
+		clock.observe(eas, 0, now.Add(2*time.Minute))
+		Expect(testutil.ToFloat64(counter)).To(Equal(before))
+
+		eas.Status.LastEviction = myappsv1.Eviction{}
+		clock.observe(eas, 0, now.Add(3*time.Minute))
+		clock.forget(types.NamespacedName{Namespace: "default", Name: "idle-web"})
+		clock.observe(eas, 0, now.Add(4*time.Minute))
+		Expect(testutil.ToFloat64(counter)).To(Equal(before))
+	})
+})
@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"context"
+
+	pdbautoscaler "github.com/azure/eviction-autoscaler/api/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// PDBDisruptionsReconciler pre-surges when a PDB stops allowing disruptions during a
+// drain. DisruptionsAllowed dropping to 0 while covered pods sit on cordoned nodes is
+// an earlier and cheaper signal than waiting for an eviction to be recorded, and it
+// works without the eviction webhook.
+//
+// It doesn't scale anything itself: it records a LastEviction on the matching
+// EvictionAutoScaler so the regular surge/cooldown/revert path takes over.
+type PDBDisruptionsReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	Filter filter
+}
+
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=eviction-autoscaler.azure.com,resources=evictionautoscalers,verbs=get;list;watch;update
+
+// Reconcile is triggered when a PDB's DisruptionsAllowed transitions to 0. The
+// EvictionAutoScaler shares the PDB's name.
+func (r *PDBDisruptionsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	isEnabled, err := autoscalerEnabled(ctx, r.Filter, r.Client, req.NamespacedName)
+	if err != nil {
+		logger.Error(err, "Failed to check if eviction autoscaler is enabled", "namespace", req.Namespace)
+		return reconcile.Result{}, err
+	}
+	if !isEnabled {
+		return reconcile.Result{}, nil
+	}
+
+	var pdb policyv1.PodDisruptionBudget
+	if err := r.Get(ctx, req.NamespacedName, &pdb); err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+	// The PDB may have recovered before we got here.
+	if pdb.Status.DisruptionsAllowed != 0 {
+		return reconcile.Result{}, nil
+	}
+
+	var eas pdbautoscaler.EvictionAutoScaler
+	if err := r.Get(ctx, req.NamespacedName, &eas); err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+	// An eviction is already being handled; the surge path will size for it.
+	if eas.Spec.LastEviction != eas.Status.LastEviction {
+		return reconcile.Result{}, nil
+	}
+
+	// Only a drain justifies a surge. A PDB can also block because pods are
+	// unhealthy or mid-rollout, and surging wouldn't help there.
+	displaced, err := podsOnCordoned(ctx, r.Client, &pdb)
+	if err != nil {
+		logger.Error(err, "failed to list displaced pods on cordoned nodes", "pdb", pdb.Name)
+		return reconcile.Result{}, err
+	}
+	if len(displaced) == 0 {
+		logger.V(1).Info("PDB blocking disruptions without a drain in progress, ignoring", "pdb", pdb.Name)
+		return reconcile.Result{}, nil
+	}
+
+	logDecision(ctx, decisionPreSurge, pdb.Namespace+"/"+pdb.Name, "PDB stopped allowing disruptions during drain, pre-surging",
+		"displaced", len(displaced), "podname", displaced[0].Name)
+	eas.Spec.LastEviction = pdbautoscaler.Eviction{
+		PodName:      displaced[0].Name,
+		EvictionTime: metav1.Now(),
+	}
+	if err := r.Update(ctx, &eas); err != nil {
+		logger.Error(err, "unable to update EvictionAutoScaler", "name", eas.Name)
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, nil
+}
+
+// disruptionsExhausted reports whether a PDB update moved DisruptionsAllowed from
+// positive to 0.
+func disruptionsExhausted(e event.UpdateEvent) bool {
+	oldPDB, okOld := e.ObjectOld.(*policyv1.PodDisruptionBudget)
+	newPDB, okNew := e.ObjectNew.(*policyv1.PodDisruptionBudget)
+	if !okOld || !okNew {
+		return false
+	}
+	return oldPDB.Status.DisruptionsAllowed > 0 && newPDB.Status.DisruptionsAllowed == 0
+}
+
+func (r *PDBDisruptionsReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("pdb-disruptions").
+		WithOptions(controllerOptions("pdb-disruptions")).
+		For(&policyv1.PodDisruptionBudget{}).
+		WithEventFilter(shardPredicate(r.Filter)).
+		WithEventFilter(predicate.Funcs{
+			// Only the transition matters; creates, deletes and generic events are
+			// covered by the eviction path.
+			CreateFunc:  func(event.CreateEvent) bool { return false },
+			DeleteFunc:  func(event.DeleteEvent) bool { return false },
+			GenericFunc: func(event.GenericEvent) bool { return false },
+			UpdateFunc:  disruptionsExhausted,
+		}).
+		Complete(traced("pdb-disruptions", r))
+}
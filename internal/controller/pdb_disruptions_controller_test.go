@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"context"
+
+	v1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/namespacefilter"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("PDBDisruptionsReconciler", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+		key    types.NamespacedName
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(policyv1.AddToScheme(scheme)).To(Succeed())
+		Expect(v1.AddToScheme(scheme)).To(Succeed())
+		key = types.NamespacedName{Name: "app", Namespace: "default"}
+	})
+
+	objects := func(cordoned bool, disruptionsAllowed int32) []client.Object {
+		return []client.Object{
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}, Spec: corev1.NodeSpec{Unschedulable: cordoned}},
+			&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "app-1", Namespace: "default", Labels: map[string]string{"app": "app"}},
+				Spec:       corev1.PodSpec{NodeName: "node1"},
+			},
+			&policyv1.PodDisruptionBudget{
+				ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+				Spec: policyv1.PodDisruptionBudgetSpec{
+					Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "app"}},
+				},
+				Status: policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: disruptionsAllowed},
+			},
+			&v1.EvictionAutoScaler{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"}},
+		}
+	}
+
+	reconcileWith := func(objs []client.Object) *v1.EvictionAutoScaler {
+		fc := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+		r := &PDBDisruptionsReconciler{Client: fc, Scheme: scheme, Filter: namespacefilter.New(nil, false)}
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+		var eas v1.EvictionAutoScaler
+		Expect(fc.Get(ctx, key, &eas)).To(Succeed())
+		return &eas
+	}
+
+	It("records an eviction when disruptions are exhausted during a drain", func() {
+		eas := reconcileWith(objects(true, 0))
+		Expect(eas.Spec.LastEviction.PodName).To(Equal("app-1"))
+		Expect(eas.Spec.LastEviction).ToNot(Equal(eas.Status.LastEviction))
+	})
+
+	It("ignores a blocking PDB when no covered pod is on a cordoned node", func() {
+		eas := reconcileWith(objects(false, 0))
+		Expect(eas.Spec.LastEviction).To(Equal(v1.Eviction{}))
+	})
+
+	It("ignores a PDB that allows disruptions again", func() {
+		eas := reconcileWith(objects(true, 1))
+		Expect(eas.Spec.LastEviction).To(Equal(v1.Eviction{}))
+	})
+
+	It("only triggers on the transition to zero", func() {
+		pdb := func(allowed int32) *policyv1.PodDisruptionBudget {
+			return &policyv1.PodDisruptionBudget{Status: policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: allowed}}
+		}
+		Expect(disruptionsExhausted(event.UpdateEvent{ObjectOld: pdb(1), ObjectNew: pdb(0)})).To(BeTrue())
+		Expect(disruptionsExhausted(event.UpdateEvent{ObjectOld: pdb(0), ObjectNew: pdb(0)})).To(BeFalse())
+		Expect(disruptionsExhausted(event.UpdateEvent{ObjectOld: pdb(0), ObjectNew: pdb(1)})).To(BeFalse())
+	})
+})
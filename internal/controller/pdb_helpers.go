@@ -3,8 +3,9 @@ package controllers
 import (
 	"context"
 	"fmt"
-	"strconv"
 
+	"github.com/azure/eviction-autoscaler/internal/annotations"
+	"github.com/azure/eviction-autoscaler/internal/podutil"
 	"github.com/go-logr/logr"
 	v1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -27,7 +28,7 @@ import (
 func shouldSkipPDBCreation(deployment *v1.Deployment) (bool, string) {
 	// Check for pdb-create annotation on deployment
 	if val, ok := deployment.Annotations[PDBCreateAnnotationKey]; ok {
-		pdbcreate, err := strconv.ParseBool(val)
+		pdbcreate, err := annotations.Bool(PDBCreateAnnotationKey, val)
 		if err != nil {
 			return true, "unknown annotation value for pdb-create annotation " + val
 		}
@@ -45,50 +46,194 @@ func shouldSkipPDBCreation(deployment *v1.Deployment) (bool, string) {
 	return false, ""
 }
 
-// findPDBForDeployment finds and returns the PDB that matches the deployment's pod selector
+// recordMinAvailableFloor keeps the integer minAvailable of a user-created PDB the
+// controller is adopting as the floor it manages the PDB from. PDBs the controller
+// created carry the target annotation and get no floor, nor does a PDB whose floor
+// is already set or whose minAvailable is a percentage.
+func recordMinAvailableFloor(pdb *policyv1.PodDisruptionBudget) {
+	if _, created := pdb.Annotations[annotations.Target]; created {
+		return
+	}
+	if _, ok := pdb.Annotations[MinAvailableFloorAnnotationKey]; ok {
+		return
+	}
+	if pdb.Spec.MinAvailable == nil || pdb.Spec.MinAvailable.Type != intstr.Int {
+		return
+	}
+	if pdb.Annotations == nil {
+		pdb.Annotations = map[string]string{}
+	}
+	pdb.Annotations[MinAvailableFloorAnnotationKey] = pdb.Spec.MinAvailable.String()
+}
+
+// minAvailableFloor returns the lowest minAvailable the controller may set on pdb,
+// or 0 without a valid floor annotation.
+func minAvailableFloor(pdb *policyv1.PodDisruptionBudget) int32 {
+	val, ok := pdb.Annotations[MinAvailableFloorAnnotationKey]
+	if !ok {
+		return 0
+	}
+	floor, err := annotations.Int32(MinAvailableFloorAnnotationKey, val)
+	if err != nil {
+		return 0
+	}
+	return floor
+}
+
+// pdbSelectsTemplate reports whether the PDB's selector, MatchLabels and
+// MatchExpressions alike, matches the given pod template labels. A PDB without a
+// selector matches nothing.
+func pdbSelectsTemplate(pdb *policyv1.PodDisruptionBudget, templateLabels map[string]string) (bool, error) {
+	selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+	if err != nil {
+		return false, fmt.Errorf("invalid selector on PDB %s: %w", pdb.Name, err)
+	}
+	return selector.Matches(labels.Set(templateLabels)), nil
+}
+
+// findPDBsForDeployment returns every PDB in the deployment's namespace whose selector
+// matches the deployment's pod template labels.
+func findPDBsForDeployment(ctx context.Context, c client.Client, deployment *v1.Deployment) ([]policyv1.PodDisruptionBudget, error) {
+	candidates, err := candidatePDBs(ctx, c, deployment.Namespace, deployment.Spec.Template.Labels)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PDBs: %w", err)
+	}
+
+	var matches []policyv1.PodDisruptionBudget
+	for i := range candidates {
+		ok, err := pdbSelectsTemplate(&candidates[i], deployment.Spec.Template.Labels)
+		if err != nil {
+			continue
+		}
+		if ok {
+			matches = append(matches, candidates[i])
+		}
+	}
+	return matches, nil
+}
+
+// findDeploymentsForPDB returns every deployment in the PDB's namespace whose pod
+// template labels are selected by the PDB. It is the inverse of findPDBsForDeployment
+// and works before any pods exist.
+func findDeploymentsForPDB(ctx context.Context, c client.Client, pdb *policyv1.PodDisruptionBudget) ([]v1.Deployment, error) {
+	var deploymentList v1.DeploymentList
+	if err := listWorkloadsForPDB(ctx, c, pdb, &deploymentList); err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+
+	var matches []v1.Deployment
+	for _, deployment := range deploymentList.Items {
+		ok, err := pdbSelectsTemplate(pdb, deployment.Spec.Template.Labels)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matches = append(matches, deployment)
+		}
+	}
+	return matches, nil
+}
+
+// findStatefulSetsForPDB returns the statefulsets in pdb's namespace whose pod
+// template labels match pdb's selector.
+func findStatefulSetsForPDB(ctx context.Context, c client.Client, pdb *policyv1.PodDisruptionBudget) ([]v1.StatefulSet, error) {
+	var statefulSetList v1.StatefulSetList
+	if err := listWorkloadsForPDB(ctx, c, pdb, &statefulSetList); err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+
+	var matches []v1.StatefulSet
+	for _, statefulSet := range statefulSetList.Items {
+		ok, err := pdbSelectsTemplate(pdb, statefulSet.Spec.Template.Labels)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matches = append(matches, statefulSet)
+		}
+	}
+	return matches, nil
+}
+
+// preferredPDB picks the PDB to act on when several match a deployment: the one
+// owned by EvictionAutoScaler if there is one, otherwise the first. owned reports
+// whether the returned PDB is controller-owned.
+func preferredPDB(pdbs []policyv1.PodDisruptionBudget) (pdb *policyv1.PodDisruptionBudget, owned bool) {
+	for i := range pdbs {
+		if pdbs[i].Annotations != nil && pdbs[i].Annotations[PDBOwnedByAnnotationKey] == ControllerName {
+			return &pdbs[i], true
+		}
+	}
+	if len(pdbs) == 0 {
+		return nil, false
+	}
+	return &pdbs[0], false
+}
+
+// findPDBForDeployment finds and returns the PDB that matches the deployment's pod selector.
+// When several PDBs match, the controller-owned one is preferred.
+//
 // If onlyOwnedByController is true:
 //   - Returns (pdb, true, nil) if a matching PDB exists AND is owned by EvictionAutoScaler
-//   - Returns (nil, false, nil) if a matching PDB exists BUT is not owned by EvictionAutoScaler
+//   - Returns (nil, false, nil) if matching PDBs exist BUT none is owned by EvictionAutoScaler
 //   - Returns (nil, false, nil) if no matching PDB exists
 //
 // If onlyOwnedByController is false:
 //   - Returns (pdb, true, nil) if any matching PDB exists (regardless of ownership)
 //   - Returns (nil, false, nil) if no matching PDB exists
 func findPDBForDeployment(ctx context.Context, c client.Client, deployment *v1.Deployment, onlyOwnedByController bool) (*policyv1.PodDisruptionBudget, bool, error) {
+	matches, err := findPDBsForDeployment(ctx, c, deployment)
+	if err != nil {
+		return nil, false, err
+	}
+	pdb, owned := preferredPDB(matches)
+	if pdb == nil || (onlyOwnedByController && !owned) {
+		return nil, false, nil
+	}
+	return pdb, true, nil
+}
+
+// overlappingPDBs returns the names of other PDBs in the namespace that select any pod
+// selected by pdb. The eviction API refuses to evict a pod covered by more than one
+// PDB, so surging for such a pod can't unblock a drain.
+func overlappingPDBs(ctx context.Context, c client.Client, pdb *policyv1.PodDisruptionBudget) ([]string, error) {
+	selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PDB selector: %w", err)
+	}
+	var podList corev1.PodList
+	if err := c.List(ctx, &podList, client.InNamespace(pdb.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, fmt.Errorf("failed to list pods for PDB %s: %w", pdb.Name, err)
+	}
+	if len(podList.Items) == 0 {
+		return nil, nil
+	}
 	var pdbList policyv1.PodDisruptionBudgetList
-	if err := c.List(ctx, &pdbList, client.InNamespace(deployment.Namespace)); err != nil {
-		return nil, false, fmt.Errorf("failed to list PDBs: %w", err)
+	if err := c.List(ctx, &pdbList, client.InNamespace(pdb.Namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list PDBs: %w", err)
 	}
 
-	for _, pdb := range pdbList.Items {
-		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+	var overlapping []string
+	for _, other := range pdbList.Items {
+		if other.Name == pdb.Name {
+			continue
+		}
+		otherSelector, err := metav1.LabelSelectorAsSelector(other.Spec.Selector)
 		if err != nil {
 			continue
 		}
-		if selector.Matches(labels.Set(deployment.Spec.Template.Labels)) {
-			// Found a matching PDB
-			if onlyOwnedByController {
-				// Only return true if it's owned by EvictionAutoScaler
-				if pdb.Annotations != nil && pdb.Annotations[PDBOwnedByAnnotationKey] == ControllerName {
-					return &pdb, true, nil
-				}
-				// Matching PDB exists but is not owned by us - return false
-				return nil, false, nil
-			} else {
-				// Return any matching PDB regardless of ownership
-				return &pdb, true, nil
+		for _, pod := range podList.Items {
+			if otherSelector.Matches(labels.Set(pod.Labels)) {
+				overlapping = append(overlapping, other.Name)
+				break
 			}
 		}
 	}
-	// No matching PDB found
-	return nil, false, nil
+	return overlapping, nil
 }
 
-// CreatePDBForDeployment creates a PDB for the given deployment with standard configuration
-func CreatePDBForDeployment(ctx context.Context, c client.Client, deployment *v1.Deployment) error {
-	controller := true
-	blockOwnerDeletion := true
-
+// NewPDBForDeployment builds the PDB the controller creates for deployment, owned by it.
+func NewPDBForDeployment(ctx context.Context, c client.Client, deployment *v1.Deployment) (*policyv1.PodDisruptionBudget, error) {
 	// Use KEDA/HPA minReplicas when available instead of deployment.spec.replicas,
 	// since the autoscaler controls the actual replica count and may have scaled above its floor.
 	var deployReplicas int32 = 1
@@ -97,26 +242,20 @@ func CreatePDBForDeployment(ctx context.Context, c client.Client, deployment *v1
 	}
 	minAvailable, _, err := ResolveMinReplicas(ctx, c, deployment.Namespace, deployment.Name, ResourceTypeDeployment, deployReplicas)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	pdb := &policyv1.PodDisruptionBudget{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "PodDisruptionBudget",
+			APIVersion: "policy/v1",
+		},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      deployment.Name,
 			Namespace: deployment.Namespace,
 			Annotations: map[string]string{
 				PDBOwnedByAnnotationKey: ControllerName,
-				"target":                deployment.Name,
-			},
-			OwnerReferences: []metav1.OwnerReference{
-				{
-					APIVersion:         "apps/v1",
-					Kind:               ResourceTypeDeployment,
-					Name:               deployment.Name,
-					UID:                deployment.UID,
-					Controller:         &controller,
-					BlockOwnerDeletion: &blockOwnerDeletion,
-				},
+				annotations.Target:      deployment.Name,
 			},
 		},
 		Spec: policyv1.PodDisruptionBudgetSpec{
@@ -124,8 +263,13 @@ func CreatePDBForDeployment(ctx context.Context, c client.Client, deployment *v1
 			Selector:     &metav1.LabelSelector{MatchLabels: deployment.Spec.Selector.MatchLabels},
 		},
 	}
-
-	return c.Create(ctx, pdb)
+	setOwner(pdb, metav1.OwnerReference{
+		APIVersion: "apps/v1",
+		Kind:       ResourceTypeDeployment,
+		Name:       deployment.Name,
+		UID:        deployment.UID,
+	})
+	return pdb, nil
 }
 
 // Watch Namespace calls this to handle dynamic enable/disable via annotations.
@@ -204,14 +348,24 @@ func triggerOnPDBAnnotationChange(e event.UpdateEvent, logger logr.Logger) bool
 // (Unschedulable) nodes. It aggregates across all cordoned nodes, so simultaneous drains
 // are counted correctly.
 func countPodsOnCordoned(ctx context.Context, c client.Client, pdb *policyv1.PodDisruptionBudget) (int32, error) {
+	pods, err := podsOnCordoned(ctx, c, pdb)
+	if err != nil {
+		return 0, err
+	}
+	return int32(len(pods)), nil
+}
+
+// podsOnCordoned returns the pods matching the PDB selector that are currently on
+// cordoned (Unschedulable) nodes.
+func podsOnCordoned(ctx context.Context, c client.Client, pdb *policyv1.PodDisruptionBudget) ([]corev1.Pod, error) {
 	selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
 	if err != nil {
-		return 0, fmt.Errorf("invalid PDB selector: %w", err)
+		return nil, fmt.Errorf("invalid PDB selector: %w", err)
 	}
 
 	var podList corev1.PodList
 	if err := c.List(ctx, &podList, client.InNamespace(pdb.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
-		return 0, fmt.Errorf("failed to list pods for PDB %s: %w", pdb.Name, err)
+		return nil, fmt.Errorf("failed to list pods for PDB %s: %w", pdb.Name, err)
 	}
 
 	// We use node cordon (Spec.Unschedulable) as the signal for "pods need to move".
@@ -221,18 +375,42 @@ func countPodsOnCordoned(ctx context.Context, c client.Client, pdb *policyv1.Pod
 	// without needing to inspect nodes at all.
 	var nodeList corev1.NodeList
 	if err := c.List(ctx, &nodeList); err != nil {
-		return 0, fmt.Errorf("failed to list nodes: %w", err)
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
 	}
 	cordoned := make(map[string]bool, len(nodeList.Items))
 	for _, node := range nodeList.Items {
 		cordoned[node.Name] = node.Spec.Unschedulable
 	}
 
-	var count int32
+	var pods []corev1.Pod
 	for _, pod := range podList.Items {
-		if cordoned[pod.Spec.NodeName] {
-			count++
+		// Pods the PDB lets go regardless of its budget don't need a surge.
+		if cordoned[pod.Spec.NodeName] && !unhealthyPodEvictable(pdb, &pod) {
+			pods = append(pods, pod)
 		}
 	}
-	return count, nil
+	return pods, nil
+}
+
+// unhealthyPodEvictable reports whether pdb lets pod be evicted whatever its budget:
+// with unhealthyPodEvictionPolicy AlwaysAllow, pods that aren't Ready are evicted
+// without checking DisruptionsAllowed. The default IfHealthyBudget policy only allows
+// it while the budget is met, which is not the case while the PDB blocks.
+func unhealthyPodEvictable(pdb *policyv1.PodDisruptionBudget, pod *corev1.Pod) bool {
+	policy := pdb.Spec.UnhealthyPodEvictionPolicy
+	return policy != nil && *policy == policyv1.AlwaysAllow && !podutil.IsPodReady(pod)
+}
+
+// evictedPodUnhealthyEvictable reports whether the evicted pod podName is still
+// around and unhealthyPodEvictable under pdb. A pod already gone reports false.
+func evictedPodUnhealthyEvictable(ctx context.Context, c client.Client, pdb *policyv1.PodDisruptionBudget, podName string) (bool, error) {
+	policy := pdb.Spec.UnhealthyPodEvictionPolicy
+	if podName == "" || policy == nil || *policy != policyv1.AlwaysAllow {
+		return false, nil
+	}
+	var pod corev1.Pod
+	if err := c.Get(ctx, k8s_types.NamespacedName{Namespace: pdb.Namespace, Name: podName}, &pod); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	return unhealthyPodEvictable(pdb, &pod), nil
 }
@@ -5,10 +5,12 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
@@ -146,4 +148,256 @@ var _ = Describe("countPodsOnCordoned", func() {
 		Expect(err).NotTo(HaveOccurred())
 		Expect(count).To(Equal(int32(3)))
 	})
+
+	It("skips pods that aren't Ready when the PDB always allows evicting unhealthy pods", func() {
+		pdb := makePDB(map[string]string{"app": "myapp"})
+		pdb.Spec.UnhealthyPodEvictionPolicy = ptr.To(policyv1.AlwaysAllow)
+		node := makeNode("node1", true)
+		ready := makePod("ready", "node1", map[string]string{"app": "myapp"})
+		ready.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+		crashing := makePod("crashing", "node1", map[string]string{"app": "myapp"})
+		fc := fake.NewClientBuilder().WithScheme(scheme).WithObjects(node, ready, crashing).Build()
+
+		count, err := countPodsOnCordoned(ctx, fc, pdb)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(int32(1)))
+
+		evictable, err := evictedPodUnhealthyEvictable(ctx, fc, pdb, "crashing")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(evictable).To(BeTrue())
+		evictable, err = evictedPodUnhealthyEvictable(ctx, fc, pdb, "ready")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(evictable).To(BeFalse())
+	})
+})
+
+var _ = Describe("PDB matching with overlapping PDBs", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(policyv1.AddToScheme(scheme)).To(Succeed())
+	})
+
+	makePDB := func(name string, selector map[string]string, owned bool) *policyv1.PodDisruptionBudget {
+		pdb := &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: policyv1.PodDisruptionBudgetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: selector},
+			},
+		}
+		if owned {
+			pdb.Annotations = map[string]string{PDBOwnedByAnnotationKey: ControllerName}
+		}
+		return pdb
+	}
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default"},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "myapp", "tier": "web"}}},
+		},
+	}
+
+	It("prefers the controller-owned PDB when several match", func() {
+		fc := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			makePDB("a-user", map[string]string{"tier": "web"}, false),
+			makePDB("b-owned", map[string]string{"app": "myapp"}, true),
+		).Build()
+
+		pdbs, err := findPDBsForDeployment(ctx, fc, deployment)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pdbs).To(HaveLen(2))
+
+		pdb, found, err := findPDBForDeployment(ctx, fc, deployment, true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeTrue())
+		Expect(pdb.Name).To(Equal("b-owned"))
+	})
+
+	It("reports no owned PDB when only user PDBs match", func() {
+		fc := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			makePDB("user", map[string]string{"app": "myapp"}, false),
+		).Build()
+
+		_, found, err := findPDBForDeployment(ctx, fc, deployment, true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeFalse())
+
+		pdb, found, err := findPDBForDeployment(ctx, fc, deployment, false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeTrue())
+		Expect(pdb.Name).To(Equal("user"))
+	})
+
+	It("lists other PDBs selecting the same pods", func() {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name: "myapp-1", Namespace: "default", Labels: map[string]string{"app": "myapp", "tier": "web"},
+		}}
+		mine := makePDB("myapp", map[string]string{"app": "myapp"}, true)
+		fc := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			pod, mine,
+			makePDB("web", map[string]string{"tier": "web"}, false),
+			makePDB("other", map[string]string{"app": "other"}, false),
+		).Build()
+
+		overlapping, err := overlappingPDBs(ctx, fc, mine)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(overlapping).To(ConsistOf("web"))
+	})
+})
+
+var _ = Describe("PDB matching with selector expressions", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		Expect(policyv1.AddToScheme(scheme)).To(Succeed())
+	})
+
+	templateLabels := map[string]string{"app": "myapp", "tier": "web"}
+
+	newDeployment := func() *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default", UID: "myapp-uid"},
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: templateLabels}},
+			},
+		}
+	}
+
+	newPDB := func(exprs ...metav1.LabelSelectorRequirement) *policyv1.PodDisruptionBudget {
+		return &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default"},
+			Spec: policyv1.PodDisruptionBudgetSpec{
+				Selector: &metav1.LabelSelector{MatchExpressions: exprs},
+			},
+		}
+	}
+
+	DescribeTable("matches deployments and PDBs in both directions",
+		func(expr metav1.LabelSelectorRequirement, matches bool) {
+			deployment := newDeployment()
+			pdb := newPDB(expr)
+			fc := fake.NewClientBuilder().WithScheme(scheme).WithObjects(deployment, pdb).Build()
+
+			pdbs, err := findPDBsForDeployment(ctx, fc, deployment)
+			Expect(err).NotTo(HaveOccurred())
+			deployments, err := findDeploymentsForPDB(ctx, fc, pdb)
+			Expect(err).NotTo(HaveOccurred())
+			if matches {
+				Expect(pdbs).To(HaveLen(1))
+				Expect(deployments).To(HaveLen(1))
+			} else {
+				Expect(pdbs).To(BeEmpty())
+				Expect(deployments).To(BeEmpty())
+			}
+		},
+		Entry("In matching", metav1.LabelSelectorRequirement{
+			Key: "tier", Operator: metav1.LabelSelectorOpIn, Values: []string{"web", "api"}}, true),
+		Entry("In not matching", metav1.LabelSelectorRequirement{
+			Key: "tier", Operator: metav1.LabelSelectorOpIn, Values: []string{"api"}}, false),
+		Entry("NotIn matching", metav1.LabelSelectorRequirement{
+			Key: "tier", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"batch"}}, true),
+		Entry("NotIn not matching", metav1.LabelSelectorRequirement{
+			Key: "tier", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"web"}}, false),
+		Entry("Exists matching", metav1.LabelSelectorRequirement{
+			Key: "app", Operator: metav1.LabelSelectorOpExists}, true),
+		Entry("Exists not matching", metav1.LabelSelectorRequirement{
+			Key: "release", Operator: metav1.LabelSelectorOpExists}, false),
+	)
+
+	It("rejects an invalid expression", func() {
+		pdb := newPDB(metav1.LabelSelectorRequirement{Key: "tier", Operator: metav1.LabelSelectorOpIn})
+		fc := fake.NewClientBuilder().WithScheme(scheme).WithObjects(newDeployment(), pdb).Build()
+
+		_, err := findDeploymentsForPDB(ctx, fc, pdb)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("discovers the deployment from its pods", func() {
+		controller := true
+		deployment := newDeployment()
+		rs := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+			Name: "myapp-abc", Namespace: "default",
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "apps/v1", Kind: "Deployment", Name: "myapp", UID: "myapp-uid", Controller: &controller,
+			}},
+		}}
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name: "myapp-abc-1", Namespace: "default", Labels: templateLabels,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "myapp-abc", Controller: &controller,
+			}},
+		}}
+		pdb := newPDB(metav1.LabelSelectorRequirement{
+			Key: "tier", Operator: metav1.LabelSelectorOpIn, Values: []string{"web"}})
+		fc := fake.NewClientBuilder().WithScheme(scheme).WithObjects(deployment, rs, pod, pdb).Build()
+		r := &PDBToEvictionAutoScalerReconciler{Client: fc, Scheme: scheme}
+
+		name, uid, err := r.discoverDeployment(ctx, pdb)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(name).To(Equal("myapp"))
+		Expect(string(uid)).To(Equal("myapp-uid"))
+	})
+
+	It("discovers a statefulset from its pods", func() {
+		controller := true
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name: "db-0", Namespace: "default", Labels: templateLabels,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "apps/v1", Kind: "StatefulSet", Name: "db", UID: "db-uid", Controller: &controller,
+			}},
+		}}
+		pdb := newPDB(metav1.LabelSelectorRequirement{
+			Key: "tier", Operator: metav1.LabelSelectorOpIn, Values: []string{"web"}})
+		fc := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pod, pdb).Build()
+		r := &PDBToEvictionAutoScalerReconciler{Client: fc, Scheme: scheme}
+
+		kind, name, uid, err := r.discoverTarget(ctx, pdb)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kind).To(Equal(statefulSetKind))
+		Expect(name).To(Equal("db"))
+		Expect(string(uid)).To(Equal("db-uid"))
+
+		_, _, err = r.discoverDeployment(ctx, pdb)
+		Expect(err).To(MatchError(errOwnerNotFound))
+	})
+
+	It("discovers a statefulset from its template when no pods exist", func() {
+		statefulSet := &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default", UID: "db-uid"},
+			Spec:       appsv1.StatefulSetSpec{Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: templateLabels}}},
+		}
+		pdb := newPDB(metav1.LabelSelectorRequirement{Key: "app", Operator: metav1.LabelSelectorOpExists})
+		fc := fake.NewClientBuilder().WithScheme(scheme).WithObjects(statefulSet, pdb).Build()
+		r := &PDBToEvictionAutoScalerReconciler{Client: fc, Scheme: scheme}
+
+		kind, name, _, err := r.discoverTarget(ctx, pdb)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kind).To(Equal(statefulSetKind))
+		Expect(name).To(Equal("db"))
+	})
+
+	It("discovers the deployment from its template when no pods exist", func() {
+		pdb := newPDB(metav1.LabelSelectorRequirement{Key: "app", Operator: metav1.LabelSelectorOpExists})
+		fc := fake.NewClientBuilder().WithScheme(scheme).WithObjects(newDeployment(), pdb).Build()
+		r := &PDBToEvictionAutoScalerReconciler{Client: fc, Scheme: scheme}
+
+		name, uid, err := r.discoverDeployment(ctx, pdb)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(name).To(Equal("myapp"))
+		Expect(string(uid)).To(Equal("myapp-uid"))
+	})
 })
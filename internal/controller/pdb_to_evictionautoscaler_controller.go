@@ -3,18 +3,23 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	types "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/annotations"
 	"github.com/azure/eviction-autoscaler/internal/metrics"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	policyv1 "k8s.io/api/policy/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	k8s_types "k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -31,11 +36,14 @@ type PDBToEvictionAutoScalerReconciler struct {
 	Scheme   *runtime.Scheme
 	Recorder record.EventRecorder
 	Filter   filter
+	// ResyncPeriod, when set, reconciles every PDB again this often.
+	ResyncPeriod time.Duration
 }
 
-// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;create;watch;update
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;create;watch;update;patch;delete
 // +kubebuilder:rbac:groups=apps,resources=replicasets,verbs=get;list;update;watch
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;update;watch
+// +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
 
 // Reconcile reads the state of the cluster for a PDB and creates/deletes EvictionAutoScalers accordingly.
@@ -46,10 +54,29 @@ func (r *PDBToEvictionAutoScalerReconciler) Reconcile(ctx context.Context, req r
 	// Fetch the PodDisruptionBudget object based on the reconcile request
 	var pdb policyv1.PodDisruptionBudget
 	err := r.Get(ctx, req.NamespacedName, &pdb)
+	if apierrors.IsNotFound(err) && !ownerReferences {
+		// Deleted: without an owner reference its EvictionAutoScaler is ours to delete.
+		var eas types.EvictionAutoScaler
+		if err := r.Get(ctx, req.NamespacedName, &eas); err != nil {
+			return reconcile.Result{}, client.IgnoreNotFound(err)
+		}
+		_, err := collectOrphan(ctx, r.Client, &eas)
+		return reconcile.Result{}, err
+	}
 	if err != nil {
 		return reconcile.Result{}, err
 	}
 
+	// In ownerless mode a PDB whose deployment or Job is gone is deleted here.
+	if deleted, err := collectOrphan(ctx, r.Client, &pdb); deleted || err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// PDBs created for Jobs have nothing to surge and belong to JobToPDBReconciler.
+	if jobOwned(&pdb) {
+		return reconcile.Result{}, nil
+	}
+
 	// Handle ownership transfer based on ownedBy annotation
 	err = r.handleOwnershipTransfer(ctx, &pdb)
 	if err != nil {
@@ -62,7 +89,7 @@ func (r *PDBToEvictionAutoScalerReconciler) Reconcile(ctx context.Context, req r
 	metrics.PDBCounter.WithLabelValues(pdb.Namespace, createdByUsStr).Inc()
 
 	// Check if eviction autoscaler should be enabled for this PDB
-	isEnabled, err := r.Filter.Filter(ctx, r.Client, pdb.Namespace)
+	isEnabled, err := autoscalerEnabled(ctx, r.Filter, r.Client, req.NamespacedName)
 	if err != nil {
 		logger.Error(err, "Failed to check if eviction autoscaler is enabled", "namespace", pdb.Namespace)
 		return reconcile.Result{}, err
@@ -85,101 +112,152 @@ func (r *PDBToEvictionAutoScalerReconciler) Reconcile(ctx context.Context, req r
 		return reconcile.Result{}, nil
 	}
 
+	// The exclude annotation on the PDB or its workload vetoes an enabled namespace:
+	// the EvictionAutoScaler goes, as for a user-owned PDB in a disabled namespace.
+	if exclude, reason := excluded(&pdb); exclude {
+		logger.V(1).Info("PDB excluded from eviction autoscaler", "reason", reason)
+		return reconcile.Result{}, r.deleteExcluded(ctx, req.NamespacedName, "PDB")
+	}
+
 	// If the PDB exists, create a corresponding EvictionAutoScaler if it does not exist
 	var EvictionAutoScaler types.EvictionAutoScaler
 	err = r.Get(ctx, req.NamespacedName, &EvictionAutoScaler)
-	if err != nil {
-		if !apierrors.IsNotFound(err) {
-			return ctrl.Result{}, err
+	if err == nil {
+		// Existing EvictionAutoScaler: drop it once its target is excluded.
+		exclude, err := r.targetExcluded(ctx, pdb.Namespace, EvictionAutoScaler.Spec.TargetKind, EvictionAutoScaler.Spec.TargetName)
+		if err != nil || !exclude {
+			return reconcile.Result{}, err
 		}
+		return reconcile.Result{}, r.deleteExcluded(ctx, req.NamespacedName, string(EvictionAutoScaler.Spec.TargetKind))
+	}
+	if !apierrors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
 
-		deploymentName, _, e := r.discoverDeployment(ctx, &pdb)
-		if e != nil {
-			return reconcile.Result{}, e
-		}
+	targetKind, targetName, _, e := r.discoverTarget(ctx, &pdb)
+	if e != nil {
+		return reconcile.Result{}, e
+	}
+	if exclude, e := r.targetExcluded(ctx, pdb.Namespace, targetKind, targetName); e != nil || exclude {
+		return reconcile.Result{}, e
+	}
 
-		// EvictionAutoScaler not found, create it
-		//variables
-		controller := true
-		blockOwnerDeletion := true
+	// EvictionAutoScaler not found, create it
+	EvictionAutoScaler = *NewEvictionAutoScalerForPDB(&pdb, targetKind, targetName)
 
-		// Create a new EvictionAutoScaler
-		EvictionAutoScaler = types.EvictionAutoScaler{
-			TypeMeta: metav1.TypeMeta{
-				Kind:       "EvictionAutoScaler",
-				APIVersion: "eviction-autoscaler.azure.com/v1",
-			},
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      pdb.Name,
-				Namespace: pdb.Namespace,
-				Annotations: map[string]string{
-					"ownedBy": "EvictionAutoScaler",
-					"target":  deploymentName,
-				},
-				OwnerReferences: []metav1.OwnerReference{
-					{
-						APIVersion:         "policy/v1",
-						Kind:               "PodDisruptionBudget",
-						Name:               pdb.Name,
-						UID:                pdb.UID,
-						Controller:         &controller,         // Mark as managed by this controller
-						BlockOwnerDeletion: &blockOwnerDeletion, // Prevent deletion of the EvictionAutoScaler until the controller is deleted
-					},
-				},
-			},
-			Spec: types.EvictionAutoScalerSpec{
-				TargetName: deploymentName,
-				TargetKind: deploymentKind,
-			},
-		}
+	err = applyOwned(ctx, r.Client, r.Recorder, &EvictionAutoScaler)
+	if err != nil {
+		return reconcile.Result{}, fmt.Errorf("unable to create EvictionAutoScaler: %v", err)
+	}
 
-		err := r.Create(ctx, &EvictionAutoScaler)
-		if err != nil {
-			return reconcile.Result{}, fmt.Errorf("unable to create EvictionAutoScaler: %v", err)
+	// Track EvictionAutoScaler creation
+	metrics.EvictionAutoScalerCreationCounter.WithLabelValues(pdb.Namespace, metrics.Name(pdb.Name), metrics.Name(targetName)).Inc()
+
+	logDecision(ctx, decisionEASCreated, pdb.Namespace+"/"+pdb.Name, "created EvictionAutoScaler",
+		"targetKind", targetKind, "targetName", targetName)
+	// Return no error and no requeue
+	return reconcile.Result{}, nil
+}
+
+// targetExcluded reports whether the workload an EvictionAutoScaler targets carries
+// the exclude annotation. A missing workload or unknown kind is not excluded; the
+// EvictionAutoScaler reports those itself.
+func (r *PDBToEvictionAutoScalerReconciler) targetExcluded(ctx context.Context, namespace string, kind types.TargetKind, name string) (bool, error) {
+	normalized, err := types.NormalizeTargetKind(kind)
+	if err != nil {
+		return false, nil
+	}
+	target, err := GetSurger(normalized)
+	if err != nil {
+		return false, nil
+	}
+	if err := r.Get(ctx, k8s_types.NamespacedName{Namespace: namespace, Name: name}, target.Obj()); err != nil {
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return false, nil
 		}
+		return false, err
+	}
+	exclude, reason := excluded(target.Obj())
+	if exclude {
+		log.FromContext(ctx).V(1).Info("Target excluded from eviction autoscaler", "kind", normalized, "name", name, "reason", reason)
+	}
+	return exclude, nil
+}
 
-		// Track EvictionAutoScaler creation
-		metrics.EvictionAutoScalerCreationCounter.WithLabelValues(pdb.Namespace, pdb.Name, deploymentName).Inc()
+// deleteExcluded deletes the EvictionAutoScaler named key, if any, after what (the
+// PDB or the target's kind) was excluded. Deleting it mid-surge reverts the surge.
+func (r *PDBToEvictionAutoScalerReconciler) deleteExcluded(ctx context.Context, key k8s_types.NamespacedName, what string) error {
+	var eas types.EvictionAutoScaler
+	if err := r.Get(ctx, key, &eas); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	log.FromContext(ctx).Info("Deleting EvictionAutoScaler for excluded "+what, "eas", eas.Name)
+	return client.IgnoreNotFound(r.Delete(ctx, &eas))
+}
 
-		logger.Info("Created EvictionAutoScaler")
+// NewEvictionAutoScalerForPDB builds the EvictionAutoScaler the controller creates
+// for pdb, owned by it and targeting the targetKind named targetName.
+func NewEvictionAutoScalerForPDB(pdb *policyv1.PodDisruptionBudget, targetKind types.TargetKind, targetName string) *types.EvictionAutoScaler {
+	eas := &types.EvictionAutoScaler{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "EvictionAutoScaler",
+			APIVersion: "eviction-autoscaler.azure.com/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pdb.Name,
+			Namespace: pdb.Namespace,
+			Annotations: map[string]string{
+				annotations.OwnedBy: ControllerName,
+				annotations.Target:  targetName,
+			},
+		},
+		Spec: types.EvictionAutoScalerSpec{
+			TargetName: targetName,
+			TargetKind: targetKind,
+		},
 	}
-	// Return no error and no requeue
-	return reconcile.Result{}, nil
+	setOwner(eas, metav1.OwnerReference{
+		APIVersion: "policy/v1",
+		Kind:       "PodDisruptionBudget",
+		Name:       pdb.Name,
+		UID:        pdb.UID,
+	})
+	return eas
 }
 
-// handleOwnershipTransfer manages the owner reference based on the ownedBy annotation
+// handleOwnershipTransfer manages the owner reference based on the ownedBy annotation.
+// In ownerless mode the owner annotation stands in for the owner reference, and a
+// PDB written in the other mode has its owner moved over.
 func (r *PDBToEvictionAutoScalerReconciler) handleOwnershipTransfer(ctx context.Context, pdb *policyv1.PodDisruptionBudget) error {
 	logger := log.FromContext(ctx)
 
 	// Check if PDB has the ownedBy annotation
 	hasAnnotation := pdb.Annotations != nil && pdb.Annotations[PDBOwnedByAnnotationKey] == ControllerName
 
-	// Check if PDB has an owner reference to a deployment
+	// Check if PDB has an owner reference, or owner annotation, to a deployment
 	hasOwnerRef := false
-	var deploymentOwnerIdx int
-	for idx, ownerRef := range pdb.OwnerReferences {
+	for _, ownerRef := range pdb.OwnerReferences {
 		if ownerRef.Kind == ResourceTypeDeployment {
 			hasOwnerRef = true
-			deploymentOwnerIdx = idx
 			break
 		}
 	}
+	ownerKind, _, _ := strings.Cut(pdb.Annotations[annotations.Owner], "/")
+	hasOwnerAnnotation := ownerKind == ResourceTypeDeployment
+	hasOwner := hasOwnerRef || hasOwnerAnnotation
+	misplaced := (ownerReferences && hasOwnerAnnotation) || (!ownerReferences && hasOwnerRef)
 
 	// Handle annotation and owner reference synchronization
-	if !hasAnnotation && hasOwnerRef {
+	if !hasAnnotation && hasOwner {
 		// User removed annotation - remove owner reference to transfer ownership
 		logger.Info("Removing owner reference from PDB - user has taken ownership",
 			"namespace", pdb.Namespace, "name", pdb.Name)
 
-		// Remove the deployment owner reference
-		newOwnerRefs := []metav1.OwnerReference{}
-		for idx, ownerRef := range pdb.OwnerReferences {
-			if idx != deploymentOwnerIdx {
-				newOwnerRefs = append(newOwnerRefs, ownerRef)
-			}
-		}
-		pdb.OwnerReferences = newOwnerRefs
+		removeOwner(pdb, ResourceTypeDeployment)
 
+		// An update, not an apply: leaving a field out of an apply only removes it
+		// when FieldManager owns it, and PDBs created by earlier versions own their
+		// owner reference through an update.
 		if err := r.Update(ctx, pdb); err != nil {
 			logger.Error(err, "Failed to remove owner reference from PDB",
 				"namespace", pdb.Namespace, "name", pdb.Name)
@@ -187,10 +265,11 @@ func (r *PDBToEvictionAutoScalerReconciler) handleOwnershipTransfer(ctx context.
 		}
 		logger.Info("Successfully removed owner reference from PDB",
 			"namespace", pdb.Namespace, "name", pdb.Name)
-	} else if hasAnnotation && !hasOwnerRef {
+		r.noteSurgeInFlight(ctx, pdb, "user took ownership")
+	} else if hasAnnotation && (!hasOwner || misplaced) {
 		// Annotation is present but owner reference is missing - add it back
 		logger.Info("Adding owner reference to PDB - controller taking control back",
-			"namespace", pdb.Namespace, "name", pdb.Name)
+			"namespace", pdb.Namespace, "name", pdb.Name, "ownerReferences", ownerReferences)
 
 		deploymentName, deploymentUID, err := r.discoverDeployment(ctx, pdb)
 		if err != nil {
@@ -199,76 +278,174 @@ func (r *PDBToEvictionAutoScalerReconciler) handleOwnershipTransfer(ctx context.
 			return err
 		}
 
-		controller := true
-		blockOwnerDeletion := true
-
-		pdb.OwnerReferences = append(pdb.OwnerReferences, metav1.OwnerReference{
-			APIVersion:         "apps/v1",
-			Kind:               ResourceTypeDeployment,
-			Name:               deploymentName,
-			UID:                deploymentUID,
-			Controller:         &controller,
-			BlockOwnerDeletion: &blockOwnerDeletion,
+		removeOwner(pdb, ResourceTypeDeployment)
+		setOwner(pdb, metav1.OwnerReference{
+			APIVersion: "apps/v1",
+			Kind:       ResourceTypeDeployment,
+			Name:       deploymentName,
+			UID:        deploymentUID,
 		})
+		recordMinAvailableFloor(pdb)
 
-		if err := r.Update(ctx, pdb); err != nil {
+		// Moving the owner between modes drops a field, so it needs an update too.
+		if misplaced {
+			err = r.Update(ctx, pdb)
+		} else {
+			err = applyOwned(ctx, r.Client, r.Recorder, managedPDB(pdb))
+		}
+		if err != nil {
 			logger.Error(err, "Failed to add owner reference to PDB",
 				"namespace", pdb.Namespace, "name", pdb.Name)
 			return err
 		}
 		logger.Info("Successfully added owner reference to PDB",
 			"namespace", pdb.Namespace, "name", pdb.Name)
+		if !hasOwner {
+			r.noteSurgeInFlight(ctx, pdb, "controller took ownership back")
+		}
 	}
 
 	return nil
 }
 
+// noteSurgeInFlight records an event when the PDB changes hands while its
+// EvictionAutoScaler holds a surge. The surge stays with the EvictionAutoScaler
+// either way: it reverts the target and clears the surge annotation after cooldown,
+// and the PDB's minAvailable is never rewritten from the surged replica count.
+func (r *PDBToEvictionAutoScalerReconciler) noteSurgeInFlight(ctx context.Context, pdb *policyv1.PodDisruptionBudget, transfer string) {
+	var eas types.EvictionAutoScaler
+	if err := r.Get(ctx, client.ObjectKeyFromObject(pdb), &eas); err != nil || !eas.Status.SurgeActive {
+		return
+	}
+	log.FromContext(ctx).Info("PDB ownership changed during an active surge, EvictionAutoScaler keeps the surge",
+		"namespace", pdb.Namespace, "name", pdb.Name, "surgeReplicas", eas.Status.SurgeReplicas)
+	if r.Recorder != nil {
+		r.Recorder.Eventf(pdb, corev1.EventTypeNormal, "OwnershipTransferredDuringSurge",
+			"%s while %s is surged to %d replicas; EvictionAutoScaler %s will revert it after cooldown",
+			transfer, eas.Spec.TargetName, eas.Status.SurgeReplicas, eas.Name)
+	}
+}
+
 func (r *PDBToEvictionAutoScalerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	logger := mgr.GetLogger()
 	// Set up the controller to watch Deployments and trigger the reconcile function
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&policyv1.PodDisruptionBudget{}).
+		WithOptions(controllerOptions("poddisruptionbudget")).
 		Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(requeuePDBsOnNamespaceChange(r.Client))).
+		// Excluding a workload drops the EvictionAutoScalers of user-owned PDBs covering it.
+		Watches(&appsv1.Deployment{}, handler.EnqueueRequestsFromMapFunc(requeuePDBsForWorkload(r.Client)), excludeChanged).
+		Watches(&appsv1.StatefulSet{}, handler.EnqueueRequestsFromMapFunc(requeuePDBsForWorkload(r.Client)), excludeChanged).
+		WithEventFilter(shardPredicate(r.Filter)).
 		WithEventFilter(predicate.Funcs{
 			// Trigger for Create and Update events
 			UpdateFunc: func(e event.UpdateEvent) bool {
 				// Only filter PDB updates; let Namespace updates through so namespace
 				// annotation changes (enable/disable) trigger cleanup of EvictionAutoScalers
 				if _, ok := e.ObjectNew.(*policyv1.PodDisruptionBudget); ok {
-					return triggerOnPDBAnnotationChange(e, logger)
+					return triggerOnPDBAnnotationChange(e, logger) || triggerOnExcludeChange(e)
 				}
 				// For non-PDB objects (e.g. Namespace), always trigger
 				return true
 			},
-			DeleteFunc: func(e event.DeleteEvent) bool { return false },
+			// Without owner references deletes of PDBs and deployments drive cleanup.
+			DeleteFunc: func(e event.DeleteEvent) bool { return !ownerReferences },
 		}).
 		// Owns establishes ownership relationship between this controller and EvictionAutoScalers.
 		// This ensures that:
 		// 1. Only ONE controller (PDBToEvictionAutoScalerReconciler) manages the EvictionAutoScaler lifecycle
-		Owns(&types.EvictionAutoScaler{}).
-		Complete(r)
+		Owns(&types.EvictionAutoScaler{})
+	if !ownerReferences {
+		if err := mgr.GetFieldIndexer().IndexField(context.TODO(), &policyv1.PodDisruptionBudget{}, PDBOwnerIndex, indexPDBOwner); err != nil {
+			return err
+		}
+		// A deleted deployment takes the PDBs naming it in their owner annotation.
+		b = b.Watches(&appsv1.Deployment{}, handler.EnqueueRequestsFromMapFunc(requeueOwnedPDBs(r.Client, ResourceTypeDeployment)),
+			builder.WithPredicates(predicate.Funcs{
+				CreateFunc:  func(event.CreateEvent) bool { return false },
+				UpdateFunc:  func(event.UpdateEvent) bool { return false },
+				GenericFunc: func(event.GenericEvent) bool { return false },
+			}))
+	}
+	return b.Complete(traced("poddisruptionbudget", resynced(r.ResyncPeriod, r.Client, func() client.Object { return &policyv1.PodDisruptionBudget{} }, r)))
 }
 
+// discoverDeployment returns the deployment owning the pods pdb selects. Other
+// owners count as not found.
 func (r *PDBToEvictionAutoScalerReconciler) discoverDeployment(ctx context.Context, pdb *policyv1.PodDisruptionBudget) (string, k8s_types.UID, error) {
+	kind, name, uid, err := r.discoverTarget(ctx, pdb)
+	if err != nil {
+		return "", "", err
+	}
+	if kind != deploymentKind {
+		return "", "", errOwnerNotFound
+	}
+	return name, uid, nil
+}
+
+// discoverTarget returns the kind, name and UID of the workload owning the pods pdb
+// selects: a deployment through its ReplicaSet, or a statefulset. StatefulSet pods
+// are named <statefulset>-<ordinal> after their headless service, so the owner
+// reference rather than the pod name identifies the target.
+func (r *PDBToEvictionAutoScalerReconciler) discoverTarget(ctx context.Context, pdb *policyv1.PodDisruptionBudget) (types.TargetKind, string, k8s_types.UID, error) {
 	logger := log.FromContext(ctx)
 
+	// With the label indexes the workload templates the selector matches are found
+	// without listing pods. A single match is the target; otherwise the pods decide.
+	if labelIndexes {
+		deployments, err := findDeploymentsForPDB(ctx, r.Client, pdb)
+		if err != nil {
+			return "", "", "", err
+		}
+		statefulSets, err := findStatefulSetsForPDB(ctx, r.Client, pdb)
+		if err != nil {
+			return "", "", "", err
+		}
+		if len(deployments) == 1 && len(statefulSets) == 0 {
+			return deploymentKind, deployments[0].Name, deployments[0].UID, nil
+		}
+		if len(statefulSets) == 1 && len(deployments) == 0 {
+			return statefulSetKind, statefulSets[0].Name, statefulSets[0].UID, nil
+		}
+	}
+
 	// Convert PDB label selector to Kubernetes selector
 	selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
 	if err != nil {
-		return "", "", fmt.Errorf("error converting label selector: %v", err)
+		return "", "", "", fmt.Errorf("error converting label selector: %v", err)
 	}
 	logger.Info("PDB Selector", "selector", pdb.Spec.Selector)
 
 	podList := &corev1.PodList{}
 	err = r.List(ctx, podList, &client.ListOptions{Namespace: pdb.Namespace, LabelSelector: selector})
 	if err != nil {
-		return "", "", fmt.Errorf("error listing pods: %v", err)
+		return "", "", "", fmt.Errorf("error listing pods: %v", err)
 	}
 	logger.Info("Number of pods found", "count", len(podList.Items))
 
 	if len(podList.Items) == 0 {
+		// No pods yet (e.g. scaled to zero or still rolling out); match the selector
+		// against workload templates instead so expression-based PDBs still resolve.
+		deployments, err := findDeploymentsForPDB(ctx, r.Client, pdb)
+		if err != nil {
+			return "", "", "", err
+		}
+		if len(deployments) == 1 {
+			logger.Info("Found Deployment by template labels", "deployment", deployments[0].Name)
+			return deploymentKind, deployments[0].Name, deployments[0].UID, nil
+		}
+		if len(deployments) == 0 {
+			statefulSets, err := findStatefulSetsForPDB(ctx, r.Client, pdb)
+			if err != nil {
+				return "", "", "", err
+			}
+			if len(statefulSets) == 1 {
+				logger.Info("Found StatefulSet by template labels", "statefulSet", statefulSets[0].Name)
+				return statefulSetKind, statefulSets[0].Name, statefulSets[0].UID, nil
+			}
+		}
 		// TODO instead of an error which leads to a backoff retry quietly for a while then error?
-		return "", "", fmt.Errorf("no pods found matching the PDB selector %s; leaky pdb(?!)", pdb.Name)
+		return "", "", "", fmt.Errorf("no pods found matching the PDB selector %s; leaky pdb(?!)", pdb.Name)
 	}
 
 	// Iterate through each pod
@@ -279,7 +456,7 @@ func (r *PDBToEvictionAutoScalerReconciler) discoverDeployment(ctx context.Conte
 				replicaSet := &appsv1.ReplicaSet{}
 				err = r.Get(ctx, k8s_types.NamespacedName{Name: ownerRef.Name, Namespace: pdb.Namespace}, replicaSet)
 				if apierrors.IsNotFound(err) {
-					return "", "", fmt.Errorf("error fetching ReplicaSet: %v", err)
+					return "", "", "", fmt.Errorf("error fetching ReplicaSet: %v", err)
 				}
 
 				// Log ReplicaSet details
@@ -289,24 +466,17 @@ func (r *PDBToEvictionAutoScalerReconciler) discoverDeployment(ctx context.Conte
 				for _, rsOwnerRef := range replicaSet.OwnerReferences {
 					if rsOwnerRef.Kind == "Deployment" {
 						logger.Info("Found Deployment owner", "deployment", rsOwnerRef.Name)
-						return rsOwnerRef.Name, rsOwnerRef.UID, nil
+						return deploymentKind, rsOwnerRef.Name, rsOwnerRef.UID, nil
 					}
 				}
 				// no replicaset owner just move on and see if any other pods have have something.
 			}
-			//// Optional: Handle StatefulSets if necessary
-			//if ownerRef.Kind == "StatefulSet" {
-			//	statefulSet := &appsv1.StatefulSet{}
-			//	err = r.Get(ctx, k8s_types.NamespacedName{Name: ownerRef.Name, Namespace: pdb.Namespace}, statefulSet)
-			//	if apierrors.IsNotFound(err) {
-			//		return "", "", fmt.Errorf("error fetching StatefulSet: %v", err)
-			//	}
-			//	logger.Info("Found StatefulSet owner", "statefulSet", statefulSet.Name)
-			//	// Handle StatefulSet logic if required
-			//}
-
+			if ownerRef.Kind == "StatefulSet" {
+				logger.Info("Found StatefulSet owner", "statefulSet", ownerRef.Name)
+				return statefulSetKind, ownerRef.Name, ownerRef.UID, nil
+			}
 		}
 	}
-	logger.Info("No Deployment owner found")
-	return "", "", errOwnerNotFound
+	logger.Info("No Deployment or StatefulSet owner found")
+	return "", "", "", errOwnerNotFound
 }
@@ -14,6 +14,7 @@ import (
 	machinery_types "k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -490,6 +491,66 @@ var _ = Describe("PDBToEvictionAutoScalerReconciler ownership transfer", func()
 		}
 		Expect(hasDeploymentOwner).To(BeTrue())
 	})
+
+	It("should leave an active surge to the EvictionAutoScaler when the user takes ownership", func() {
+		controller := true
+		pdb := &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      deploymentName,
+				Namespace: namespace,
+				Annotations: map[string]string{
+					PDBOwnedByAnnotationKey: ControllerName,
+				},
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						APIVersion: "apps/v1",
+						Kind:       ResourceTypeDeployment,
+						Name:       deploymentName,
+						UID:        deployment.UID,
+						Controller: &controller,
+					},
+				},
+			},
+			Spec: policyv1.PodDisruptionBudgetSpec{
+				MinAvailable: &intstr.IntOrString{IntVal: 3},
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"app": "ownership-test"},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, pdb)).To(Succeed())
+
+		eas := &types.EvictionAutoScaler{
+			ObjectMeta: metav1.ObjectMeta{Name: deploymentName, Namespace: namespace},
+			Spec:       types.EvictionAutoScalerSpec{TargetName: deploymentName, TargetKind: deploymentKind},
+		}
+		Expect(k8sClient.Create(ctx, eas)).To(Succeed())
+		eas.Status.MinReplicas = 3
+		markSurge(&eas.Status, 4)
+		Expect(k8sClient.Status().Update(ctx, eas)).To(Succeed())
+
+		recorder := record.NewFakeRecorder(10)
+		reconciler.Recorder = recorder
+		reconciler.Filter = &pdbKubeSystemTestFilter{} // enabled by default
+
+		pdb.Annotations = map[string]string{}
+		Expect(k8sClient.Update(ctx, pdb)).To(Succeed())
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{
+			NamespacedName: client.ObjectKey{Name: deploymentName, Namespace: namespace},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		updatedPDB := &policyv1.PodDisruptionBudget{}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(pdb), updatedPDB)).To(Succeed())
+		Expect(updatedPDB.OwnerReferences).To(BeEmpty())
+		Expect(updatedPDB.Spec.MinAvailable.IntVal).To(Equal(int32(3)))
+
+		// The EvictionAutoScaler and its surge survive the transfer.
+		updatedEAS := &types.EvictionAutoScaler{}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(eas), updatedEAS)).To(Succeed())
+		Expect(updatedEAS.Status.SurgeActive).To(BeTrue())
+		Expect(recorder.Events).To(Receive(ContainSubstring("OwnershipTransferredDuringSurge")))
+	})
 })
 
 var _ = Describe("PDBToEvictionAutoScalerReconciler with enable annotation", func() {
@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/azure/eviction-autoscaler/internal/annotations"
+)
+
+// AvoidNodesAnnotationKey lists the draining nodes that new pods of a surged
+// deployment are kept off. The pod placement webhook turns it into node anti-affinity.
+const AvoidNodesAnnotationKey = annotations.AvoidNodes
+
+// DefaultDrainTaintKeys are the taints, besides a cordon, that mark a node as about
+// to be drained.
+var DefaultDrainTaintKeys = []string{"ToBeDeletedByClusterAutoscaler"}
+
+// placementHintApplier wraps the surge applier of a deployment and records the
+// draining nodes on it while the surge is active, so surge pods don't land back on a
+// node the scheduler hasn't seen cordoned yet or on the next node to be drained.
+// The pod template is never touched: changing it would roll every pod mid-drain.
+type placementHintApplier struct {
+	SurgeApplier
+	reader      client.Reader
+	writer      client.Client
+	target      Surger
+	drainTaints []string
+}
+
+var _ SurgeApplier = &placementHintApplier{}
+
+// ApplySurge surges the target and refreshes the list of nodes to avoid. Failing to
+// record the hint only costs placement, so it is logged rather than returned.
+func (p *placementHintApplier) ApplySurge(ctx context.Context, surgeReplicas int32) error {
+	if err := p.SurgeApplier.ApplySurge(ctx, surgeReplicas); err != nil {
+		return err
+	}
+	nodes, err := drainingNodes(ctx, p.reader, p.drainTaints)
+	if err == nil {
+		err = setAvoidNodes(ctx, p.writer, p.target, nodes)
+	}
+	if err != nil {
+		log.FromContext(ctx).Error(err, "failed to record placement hints", "target", p.target.Obj().GetName())
+	}
+	return nil
+}
+
+// RevertSurge reverts the surge and drops the placement hint with it.
+func (p *placementHintApplier) RevertSurge(ctx context.Context, originalMinReplicas int32) error {
+	if err := p.SurgeApplier.RevertSurge(ctx, originalMinReplicas); err != nil {
+		return err
+	}
+	return clearAvoidNodes(ctx, p.writer, p.target)
+}
+
+// drainingNodes returns the sorted names of nodes that are cordoned or carry one of
+// drainTaints.
+func drainingNodes(ctx context.Context, c client.Reader, drainTaints []string) ([]string, error) {
+	var nodeList corev1.NodeList
+	if err := c.List(ctx, &nodeList); err != nil {
+		return nil, err
+	}
+	var nodes []string
+	for _, node := range nodeList.Items {
+		if nodeDraining(&node, drainTaints) {
+			nodes = append(nodes, node.Name)
+		}
+	}
+	slices.Sort(nodes)
+	return nodes, nil
+}
+
+// nodeDraining reports whether node is cordoned or carries one of drainTaints.
+func nodeDraining(node *corev1.Node, drainTaints []string) bool {
+	return node.Spec.Unschedulable || slices.ContainsFunc(node.Spec.Taints, func(t corev1.Taint) bool {
+		return slices.Contains(drainTaints, t.Key)
+	})
+}
+
+func setAvoidNodes(ctx context.Context, c client.Client, target Surger, nodes []string) error {
+	if len(nodes) == 0 {
+		return clearAvoidNodes(ctx, c, target)
+	}
+	value := strings.Join(nodes, ",")
+	if target.Obj().GetAnnotations()[AvoidNodesAnnotationKey] == value {
+		return nil
+	}
+	return patchAnnotation(ctx, c, target, AvoidNodesAnnotationKey, &value)
+}
+
+// clearAvoidNodes removes the placement hint if the target carries one.
+func clearAvoidNodes(ctx context.Context, c client.Client, target Surger) error {
+	if _, ok := target.Obj().GetAnnotations()[AvoidNodesAnnotationKey]; !ok {
+		return nil
+	}
+	return patchAnnotation(ctx, c, target, AvoidNodesAnnotationKey, nil)
+}
+
+// patchAnnotation sets (or with a nil value removes) a surge hint annotation with a
+// merge patch, so it neither conflicts with nor bumps the generation of the target.
+func patchAnnotation(ctx context.Context, c client.Client, target Surger, key string, value *string) error {
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]*string{key: value},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	obj := target.Obj().DeepCopyObject().(client.Object) // don't mutate the cache
+	return c.Patch(ctx, obj, client.RawPatch(types.MergePatchType, patch))
+}
@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("placement hints", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+	})
+
+	nodes := func() []client.Object {
+		return []client.Object{
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "ready"}},
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "cordoned"}, Spec: corev1.NodeSpec{Unschedulable: true}},
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "autoscaled-away"}, Spec: corev1.NodeSpec{
+				Taints: []corev1.Taint{{Key: "ToBeDeletedByClusterAutoscaler", Effect: corev1.TaintEffectNoSchedule}},
+			}},
+		}
+	}
+
+	It("should list cordoned nodes and nodes carrying a drain taint", func() {
+		fc := fake.NewClientBuilder().WithScheme(scheme).WithObjects(nodes()...).Build()
+		draining, err := drainingNodes(ctx, fc, DefaultDrainTaintKeys)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(draining).To(Equal([]string{"autoscaled-away", "cordoned"}))
+	})
+
+	It("should record draining nodes on surge and drop them on revert", func() {
+		dep := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+			Spec:       appsv1.DeploymentSpec{Replicas: ptr.To[int32](2)},
+		}
+		fc := fake.NewClientBuilder().WithScheme(scheme).WithObjects(append(nodes(), dep)...).Build()
+		wrap := func() *placementHintApplier {
+			var current appsv1.Deployment
+			Expect(fc.Get(ctx, client.ObjectKeyFromObject(dep), &current)).To(Succeed())
+			target := &DeploymentWrapper{obj: &current}
+			return &placementHintApplier{
+				SurgeApplier: &DeploymentSurgeApplier{client: fc, target: target},
+				reader:       fc,
+				writer:       fc,
+				target:       target,
+				drainTaints:  DefaultDrainTaintKeys,
+			}
+		}
+
+		Expect(wrap().ApplySurge(ctx, 3)).To(Succeed())
+		var updated appsv1.Deployment
+		Expect(fc.Get(ctx, client.ObjectKeyFromObject(dep), &updated)).To(Succeed())
+		Expect(*updated.Spec.Replicas).To(Equal(int32(3)))
+		Expect(updated.Annotations).To(HaveKeyWithValue(AvoidNodesAnnotationKey, "autoscaled-away,cordoned"))
+
+		Expect(wrap().RevertSurge(ctx, 2)).To(Succeed())
+		Expect(fc.Get(ctx, client.ObjectKeyFromObject(dep), &updated)).To(Succeed())
+		Expect(*updated.Spec.Replicas).To(Equal(int32(2)))
+		Expect(updated.Annotations).ToNot(HaveKey(AvoidNodesAnnotationKey))
+	})
+})
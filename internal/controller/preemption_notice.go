@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/azure/eviction-autoscaler/internal/annotations"
+)
+
+// CordonedForPreemptionAnnotationKey marks a node cordoned by the
+// PreemptionNoticeReconciler, so it knows which cordons are its own to lift.
+const CordonedForPreemptionAnnotationKey = annotations.CordonedForPreemption
+
+// PreemptionNoticeReconciler cordons nodes that carry a notice of imminent preemption
+// or maintenance, such as an Azure Scheduled Event surfaced as a node condition, or a
+// taint put on a spot node about to be evicted. Spot nodes get about 30 seconds of
+// notice, too little to wait for the drain's first eviction; the cordon hands the node
+// to the NodeReconciler, which records evictions and starts surges straight away.
+//
+// Scheduled Events are read from the instance metadata service, which only answers
+// for the VM it is called from, so the notices are taken from the node objects that
+// node-local agents (e.g. AKS node-problem-detector) update rather than polled here.
+type PreemptionNoticeReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// Conditions are node condition types that are a notice while True.
+	Conditions []string
+	// TaintKeys are node taint keys that are a notice while present.
+	TaintKeys []string
+}
+
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+
+// Reconcile cordons a node with a notice, and uncordons a node it cordoned once the
+// notice is gone, e.g. when scheduled maintenance was cancelled or has completed. A
+// node cordoned by someone else is left alone, and so is one of ours that someone
+// uncordoned while the notice stands.
+func (r *PreemptionNoticeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	node := &corev1.Node{}
+	if err := r.Get(ctx, req.NamespacedName, node); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	notice := r.notice(node)
+	cordonedByUs := node.Annotations[CordonedForPreemptionAnnotationKey] == "true"
+	switch {
+	case notice != "" && !node.Spec.Unschedulable && !cordonedByUs:
+		logger.Info("Cordoning node ahead of preemption", "node", node.Name, "notice", notice)
+		if err := patchCordon(ctx, r.Client, node, true, "true"); err != nil {
+			return ctrl.Result{}, err
+		}
+		r.event(node, corev1.EventTypeWarning, "PreemptionNotice",
+			fmt.Sprintf("cordoned to surge workloads ahead of preemption, notice: %s", notice))
+	case notice == "" && cordonedByUs:
+		logger.Info("Preemption notice cleared, uncordoning node", "node", node.Name)
+		if err := patchCordon(ctx, r.Client, node, false, nil); err != nil {
+			return ctrl.Result{}, err
+		}
+		r.event(node, corev1.EventTypeNormal, "PreemptionNoticeCleared", "preemption notice cleared, uncordoned")
+	}
+	return ctrl.Result{}, nil
+}
+
+// notice describes the first preemption notice on node, or returns "" if it has none.
+func (r *PreemptionNoticeReconciler) notice(node *corev1.Node) string {
+	for _, cond := range node.Status.Conditions {
+		if cond.Status == corev1.ConditionTrue && slices.Contains(r.Conditions, string(cond.Type)) {
+			if cond.Message != "" {
+				return fmt.Sprintf("condition %s: %s", cond.Type, cond.Message)
+			}
+			return "condition " + string(cond.Type)
+		}
+	}
+	for _, taint := range node.Spec.Taints {
+		if slices.Contains(r.TaintKeys, taint.Key) {
+			return "taint " + taint.Key
+		}
+	}
+	return ""
+}
+
+// patchCordon sets node's cordon, and the annotation marking it as ours to value; a
+// nil value removes the annotation.
+func patchCordon(ctx context.Context, c client.Client, node *corev1.Node, unschedulable bool, value any) error {
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]any{CordonedForPreemptionAnnotationKey: value},
+		},
+		"spec": map[string]any{"unschedulable": unschedulable},
+	})
+	if err != nil {
+		return err
+	}
+	return c.Patch(ctx, node.DeepCopy(), client.RawPatch(types.MergePatchType, patch))
+}
+
+func (r *PreemptionNoticeReconciler) event(obj runtime.Object, eventType, reason, message string) {
+	if r.Recorder != nil {
+		r.Recorder.Event(obj, eventType, reason, message)
+	}
+}
+
+func (r *PreemptionNoticeReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("preemption-notice").
+		WithOptions(controllerOptions("preemption-notice")).
+		For(&corev1.Node{}).
+		WithEventFilter(predicate.Funcs{
+			// Nodes update their status constantly; only a notice appearing or going
+			// away, or our cordon being changed, needs a look.
+			UpdateFunc: func(ue event.UpdateEvent) bool {
+				oldNode, okOld := ue.ObjectOld.(*corev1.Node)
+				newNode, okNew := ue.ObjectNew.(*corev1.Node)
+				if !okOld || !okNew {
+					return false
+				}
+				return r.notice(oldNode) != r.notice(newNode) ||
+					oldNode.Spec.Unschedulable != newNode.Spec.Unschedulable ||
+					oldNode.Annotations[CordonedForPreemptionAnnotationKey] != newNode.Annotations[CordonedForPreemptionAnnotationKey]
+			},
+			DeleteFunc: func(event.DeleteEvent) bool { return false },
+		}).
+		Complete(traced("preemption-notice", r))
+}
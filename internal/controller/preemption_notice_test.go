@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Preemption notices", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+		key    = types.NamespacedName{Name: "spot-1"}
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+	})
+
+	// reconcile runs the reconciler over node and returns the node afterwards, and the
+	// events recorded.
+	reconcile := func(node *corev1.Node) (*corev1.Node, chan string) {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(node).Build()
+		recorder := record.NewFakeRecorder(10)
+		r := &PreemptionNoticeReconciler{Client: c, Scheme: scheme, Recorder: recorder,
+			Conditions: []string{"VMEventScheduled"}, TaintKeys: []string{"example.com/spot-preempt"}}
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+		var got corev1.Node
+		Expect(c.Get(ctx, key, &got)).To(Succeed())
+		return &got, recorder.Events
+	}
+	node := func() *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "spot-1"}}
+	}
+	scheduled := func(status corev1.ConditionStatus) corev1.NodeCondition {
+		return corev1.NodeCondition{Type: "VMEventScheduled", Status: status, Message: "Preempt scheduled"}
+	}
+
+	It("should cordon a node with a notice condition and mark the cordon as ours", func() {
+		n := node()
+		n.Status.Conditions = []corev1.NodeCondition{scheduled(corev1.ConditionTrue)}
+		got, events := reconcile(n)
+		Expect(got.Spec.Unschedulable).To(BeTrue())
+		Expect(got.Annotations).To(HaveKeyWithValue(CordonedForPreemptionAnnotationKey, "true"))
+		Expect(events).To(Receive(ContainSubstring("Preempt scheduled")))
+	})
+
+	It("should cordon a node with a notice taint", func() {
+		n := node()
+		n.Spec.Taints = []corev1.Taint{{Key: "example.com/spot-preempt", Effect: corev1.TaintEffectNoSchedule}}
+		got, _ := reconcile(n)
+		Expect(got.Spec.Unschedulable).To(BeTrue())
+	})
+
+	It("should leave nodes without a notice, or cordoned by someone else, alone", func() {
+		n := node()
+		n.Status.Conditions = []corev1.NodeCondition{scheduled(corev1.ConditionFalse)}
+		got, _ := reconcile(n)
+		Expect(got.Spec.Unschedulable).To(BeFalse())
+
+		n = node()
+		n.Spec.Unschedulable = true
+		n.Status.Conditions = []corev1.NodeCondition{scheduled(corev1.ConditionTrue)}
+		got, _ = reconcile(n)
+		Expect(got.Annotations).ToNot(HaveKey(CordonedForPreemptionAnnotationKey))
+
+		n = node()
+		n.Spec.Unschedulable = true
+		got, _ = reconcile(n)
+		Expect(got.Spec.Unschedulable).To(BeTrue())
+	})
+
+	It("should uncordon a node it cordoned once the notice clears", func() {
+		n := node()
+		n.Spec.Unschedulable = true
+		n.Annotations = map[string]string{CordonedForPreemptionAnnotationKey: "true"}
+		got, events := reconcile(n)
+		Expect(got.Spec.Unschedulable).To(BeFalse())
+		Expect(got.Annotations).ToNot(HaveKey(CordonedForPreemptionAnnotationKey))
+		Expect(events).To(Receive(ContainSubstring("PreemptionNoticeCleared")))
+	})
+
+	It("should not cordon again a node of ours someone uncordoned while the notice stands", func() {
+		n := node()
+		n.Annotations = map[string]string{CordonedForPreemptionAnnotationKey: "true"}
+		n.Status.Conditions = []corev1.NodeCondition{scheduled(corev1.ConditionTrue)}
+		got, _ := reconcile(n)
+		Expect(got.Spec.Unschedulable).To(BeFalse())
+	})
+})
@@ -0,0 +1,55 @@
+package controllers
+
+import (
+	"fmt"
+
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+)
+
+// recommend returns how to change the PDB or target of eas so evictions can
+// proceed, or "" when nothing stands out. replicas is the count the target runs
+// without a surge. A PDB that allows no disruption at that count is only worth
+// flagging with surge disabled: surging is how the controller drains such a PDB,
+// and the PDBs it creates are written that way on purpose.
+func recommend(eas *myappsv1.EvictionAutoScaler, pdb *policyv1.PodDisruptionBudget, replicas int32, surgeDisabled bool) string {
+	if meta.IsStatusConditionTrue(eas.Status.Conditions, SurgeUnlikelyToHelpCondition) {
+		return fmt.Sprintf("the newest pods of %s are failing; fix its rollout, surge pods from the same template would fail too", eas.Spec.TargetName)
+	}
+	if !surgeDisabled || replicas <= 0 {
+		return ""
+	}
+	field, budget, blocked := pdbBlocksEvictions(pdb, replicas)
+	if !blocked {
+		return ""
+	}
+	var suggestion string
+	switch {
+	case field == "maxUnavailable":
+		suggestion = "suggest 1"
+	case replicas > 1:
+		suggestion = fmt.Sprintf("suggest %d", replicas-1)
+	default:
+		suggestion = "run 2 replicas"
+	}
+	return fmt.Sprintf("%s %s blocks all evictions of this %d-replica %s; %s or enable surge",
+		field, budget.String(), replicas, eas.Spec.TargetKind, suggestion)
+}
+
+// pdbBlocksEvictions reports whether pdb allows no disruption even with all replicas
+// healthy, and which of its fields makes it so. Percentages round up, as the
+// disruption controller rounds them.
+func pdbBlocksEvictions(pdb *policyv1.PodDisruptionBudget, replicas int32) (string, *intstr.IntOrString, bool) {
+	if budget := pdb.Spec.MinAvailable; budget != nil {
+		minAvailable, err := intstr.GetScaledValueFromIntOrPercent(budget, int(replicas), true)
+		return "minAvailable", budget, err == nil && minAvailable >= int(replicas)
+	}
+	if budget := pdb.Spec.MaxUnavailable; budget != nil {
+		maxUnavailable, err := intstr.GetScaledValueFromIntOrPercent(budget, int(replicas), true)
+		return "maxUnavailable", budget, err == nil && maxUnavailable <= 0
+	}
+	return "", nil, false
+}
@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+)
+
+var _ = Describe("PDB recommendations", func() {
+	eas := func() *myappsv1.EvictionAutoScaler {
+		return &myappsv1.EvictionAutoScaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec:       myappsv1.EvictionAutoScalerSpec{TargetName: "web", TargetKind: deploymentKind},
+		}
+	}
+	minAvailable := func(v intstr.IntOrString) *policyv1.PodDisruptionBudget {
+		return &policyv1.PodDisruptionBudget{Spec: policyv1.PodDisruptionBudgetSpec{MinAvailable: &v}}
+	}
+	maxUnavailable := func(v intstr.IntOrString) *policyv1.PodDisruptionBudget {
+		return &policyv1.PodDisruptionBudget{Spec: policyv1.PodDisruptionBudgetSpec{MaxUnavailable: &v}}
+	}
+
+	It("should suggest a lower minAvailable when it blocks every eviction without surge", func() {
+		Expect(recommend(eas(), minAvailable(intstr.FromInt32(3)), 3, true)).To(
+			Equal("minAvailable 3 blocks all evictions of this 3-replica deployment; suggest 2 or enable surge"))
+		Expect(recommend(eas(), minAvailable(intstr.FromString("100%")), 4, true)).To(
+			Equal("minAvailable 100% blocks all evictions of this 4-replica deployment; suggest 3 or enable surge"))
+		Expect(recommend(eas(), minAvailable(intstr.FromInt32(1)), 1, true)).To(
+			Equal("minAvailable 1 blocks all evictions of this 1-replica deployment; run 2 replicas or enable surge"))
+		Expect(recommend(eas(), maxUnavailable(intstr.FromInt32(0)), 3, true)).To(
+			Equal("maxUnavailable 0 blocks all evictions of this 3-replica deployment; suggest 1 or enable surge"))
+	})
+
+	It("should stay quiet when the PDB allows a disruption or surge drains it", func() {
+		Expect(recommend(eas(), minAvailable(intstr.FromInt32(2)), 3, true)).To(BeEmpty())
+		Expect(recommend(eas(), minAvailable(intstr.FromString("50%")), 3, true)).To(BeEmpty())
+		Expect(recommend(eas(), maxUnavailable(intstr.FromString("10%")), 3, true)).To(BeEmpty())
+		Expect(recommend(eas(), minAvailable(intstr.FromInt32(3)), 3, false)).To(BeEmpty())
+		Expect(recommend(eas(), minAvailable(intstr.FromInt32(3)), 0, true)).To(BeEmpty())
+	})
+
+	It("should point at a failing rollout whether or not surge is enabled", func() {
+		e := eas()
+		setSurgeUnlikelyToHelp(e, "newest")
+		Expect(recommend(e, minAvailable(intstr.FromInt32(3)), 3, false)).To(ContainSubstring("newest pods of web are failing"))
+		setSurgeUnlikelyToHelp(e, "oldest")
+		Expect(recommend(e, minAvailable(intstr.FromInt32(3)), 3, false)).To(BeEmpty())
+	})
+})
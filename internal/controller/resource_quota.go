@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// +kubebuilder:rbac:groups=core,resources=resourcequotas,verbs=get;list;watch
+
+// quotaExceededReason is the Ready reason of an EvictionAutoScaler whose surge was
+// skipped because it would exceed a ResourceQuota.
+const quotaExceededReason = "QuotaExceeded"
+
+// surgeQuotaShortfall reports the first ResourceQuota in pdb's namespace that extra
+// more pods selected by pdb would exceed, as a message, or "" if they fit. The
+// ReplicaSet or StatefulSet controller can't create pods past a quota, so such a
+// surge would only sit unfilled until it is reverted.
+//
+// Each extra pod is costed like a running pod of the target. Quotas with scopes
+// are skipped, since whether they apply depends on the pod.
+func surgeQuotaShortfall(ctx context.Context, c client.Reader, pdb *policyv1.PodDisruptionBudget, extra int32) (string, error) {
+	if extra <= 0 {
+		return "", nil
+	}
+	var quotas corev1.ResourceQuotaList
+	if err := c.List(ctx, &quotas, client.InNamespace(pdb.Namespace)); err != nil {
+		return "", fmt.Errorf("failed to list resource quotas: %w", err)
+	}
+	if len(quotas.Items) == 0 {
+		return "", nil
+	}
+	usage, err := quotaUsagePerPod(ctx, c, pdb)
+	if err != nil {
+		return "", err
+	}
+	for _, quota := range quotas.Items {
+		if len(quota.Spec.Scopes) > 0 || quota.Spec.ScopeSelector != nil {
+			continue
+		}
+		for name, hard := range quota.Status.Hard {
+			perPod, ok := usage[name]
+			if !ok {
+				continue
+			}
+			total := quota.Status.Used[name].DeepCopy()
+			for range extra {
+				total.Add(perPod)
+			}
+			if total.Cmp(hard) > 0 {
+				return fmt.Sprintf("%d more pods would bring %s of ResourceQuota %s to %s, over its limit of %s",
+					extra, name, quota.Name, total.String(), hard.String()), nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// quotaUsagePerPod returns what one more pod selected by pdb counts against a
+// ResourceQuota, keyed by quota resource name. Without a running pod to go by only
+// the pod count is known.
+func quotaUsagePerPod(ctx context.Context, c client.Reader, pdb *policyv1.PodDisruptionBudget) (corev1.ResourceList, error) {
+	one := resource.MustParse("1")
+	usage := corev1.ResourceList{
+		corev1.ResourcePods:               one,
+		corev1.ResourceName("count/pods"): one,
+	}
+	selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PDB selector: %w", err)
+	}
+	var pods corev1.PodList
+	if err := c.List(ctx, &pods, client.InNamespace(pdb.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, fmt.Errorf("failed to list pods for PDB %s: %w", pdb.Name, err)
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		for name, quantity := range podRequests(pod) {
+			usage[corev1.ResourceName("requests."+string(name))] = quantity
+			if !strings.Contains(string(name), "/") {
+				// cpu, memory and ephemeral-storage are also quota'd by bare name.
+				usage[name] = quantity
+			}
+		}
+		for name, quantity := range podLimits(pod) {
+			usage[corev1.ResourceName("limits."+string(name))] = quantity
+		}
+		break
+	}
+	return usage, nil
+}
+
+// podLimits sums the resource limits of pod's containers.
+func podLimits(pod *corev1.Pod) corev1.ResourceList {
+	var limits corev1.ResourceList
+	for _, container := range pod.Spec.Containers {
+		limits = addRequests(limits, container.Resources.Limits, 1)
+	}
+	return limits
+}
@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/namespacefilter"
+)
+
+var _ = Describe("ResourceQuota awareness", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+		key    = client.ObjectKey{Namespace: "default", Name: "web"}
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		Expect(policyv1.AddToScheme(scheme)).To(Succeed())
+		Expect(autoscalingv2.AddToScheme(scheme)).To(Succeed())
+		Expect(kedav1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(myappsv1.AddToScheme(scheme)).To(Succeed())
+	})
+
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+	}
+	// pod runs on node-1, which is cordoned, and requests 500m CPU.
+	pod := func(name string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": "web"}},
+			Spec: corev1.PodSpec{NodeName: "node-1", Containers: []corev1.Container{{
+				Name:      "web",
+				Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")}},
+			}}},
+		}
+	}
+	quota := func(name string, hard, used corev1.ResourceList) *corev1.ResourceQuota {
+		return &corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Status:     corev1.ResourceQuotaStatus{Hard: hard, Used: used},
+		}
+	}
+
+	It("should find the quota a surge would exceed, costing pods like a running one", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pod("web-1"),
+			quota("pods", corev1.ResourceList{corev1.ResourcePods: resource.MustParse("10")}, corev1.ResourceList{corev1.ResourcePods: resource.MustParse("2")}),
+			quota("cpu", corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("2")}, corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("1")}),
+		).Build()
+
+		shortfall, err := surgeQuotaShortfall(ctx, c, pdb, 2)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(shortfall).To(BeEmpty())
+
+		shortfall, err = surgeQuotaShortfall(ctx, c, pdb, 3)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(shortfall).To(ContainSubstring("requests.cpu of ResourceQuota cpu to 2500m, over its limit of 2"))
+	})
+
+	It("should skip scoped quotas", func() {
+		scoped := quota("best-effort", corev1.ResourceList{corev1.ResourcePods: resource.MustParse("0")}, nil)
+		scoped.Spec.Scopes = []corev1.ResourceQuotaScope{corev1.ResourceQuotaScopeBestEffort}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pod("web-1"), scoped).Build()
+		shortfall, err := surgeQuotaShortfall(ctx, c, pdb, 1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(shortfall).To(BeEmpty())
+	})
+
+	It("should record the eviction without surging when the surge would exceed quota", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}, Spec: corev1.NodeSpec{Unschedulable: true}},
+			pod("web-1"), pod("web-2"),
+			quota("pods", corev1.ResourceList{corev1.ResourcePods: resource.MustParse("3")}, corev1.ResourceList{corev1.ResourcePods: resource.MustParse("2")}),
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Generation: 1},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: ptr.To(int32(2)),
+					Strategy: appsv1.DeploymentStrategy{RollingUpdate: &appsv1.RollingUpdateDeployment{MaxSurge: ptr.To(intstr.FromInt32(2))}},
+				},
+			},
+			pdb.DeepCopy(),
+			&myappsv1.EvictionAutoScaler{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+				Spec: myappsv1.EvictionAutoScalerSpec{TargetName: "web", TargetKind: myappsv1.TargetKindDeployment, SurgeMode: SurgeModeDirect,
+					LastEviction: myappsv1.Eviction{PodName: "web-1", EvictionTime: metav1.NewTime(time.Now())}},
+				Status: myappsv1.EvictionAutoScalerStatus{MinReplicas: 2, TargetGeneration: 1},
+			},
+		).WithStatusSubresource(&myappsv1.EvictionAutoScaler{}).Build()
+		r := &EvictionAutoScalerReconciler{Client: c, Scheme: scheme, Filter: namespacefilter.New(nil, false), APIReader: c}
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+
+		var dep appsv1.Deployment
+		Expect(c.Get(ctx, key, &dep)).To(Succeed())
+		Expect(*dep.Spec.Replicas).To(Equal(int32(2)))
+		var eas myappsv1.EvictionAutoScaler
+		Expect(c.Get(ctx, key, &eas)).To(Succeed())
+		Expect(eas.Status.LastEviction).To(Equal(eas.Spec.LastEviction))
+		Expect(eas.Status.SurgeActive).To(BeFalse())
+		Expect(meta.IsStatusConditionTrue(eas.Status.Conditions, QuotaExceededCondition)).To(BeTrue())
+		Expect(meta.FindStatusCondition(eas.Status.Conditions, ReadyCondition).Reason).To(Equal(quotaExceededReason))
+	})
+})
@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// resyncJitter spreads resyncs over up to a tenth of the period, so objects first
+// reconciled together at startup don't keep resyncing in one burst.
+const resyncJitter = 0.1
+
+// resynced wraps r so every successful reconcile of an object that still exists is
+// requeued after period at the latest. The object is then reconciled again even
+// when no watch event fires, e.g. after an out-of-band edit the event filters drop,
+// so drift is repaired. newObject returns an empty object of the reconciled kind,
+// looked up with c; a deleted one isn't resynced. A period of 0 returns r unchanged.
+func resynced(period time.Duration, c client.Reader, newObject func() client.Object, r reconcile.Reconciler) reconcile.Reconciler {
+	if period <= 0 {
+		return r
+	}
+	return reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+		result, err := r.Reconcile(ctx, req)
+		if err != nil {
+			return result, err
+		}
+		if err := c.Get(ctx, req.NamespacedName, newObject()); apierrors.IsNotFound(err) {
+			return result, nil
+		}
+		return requeueWithin(result, wait.Jitter(period, resyncJitter)), nil
+	})
+}
+
+// requeueWithin returns result requeued after period at the latest, keeping an
+// earlier requeue. A period of 0 leaves result unchanged.
+func requeueWithin(result ctrl.Result, period time.Duration) ctrl.Result {
+	if period > 0 && (result.RequeueAfter <= 0 || result.RequeueAfter > period) {
+		result.RequeueAfter = period
+	}
+	return result
+}
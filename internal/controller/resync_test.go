@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Periodic resync", func() {
+	reconciler := func(result ctrl.Result, err error) reconcile.Reconciler {
+		return reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) { return result, err })
+	}
+	existing := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "web"}}
+	c := fake.NewClientBuilder().WithObjects(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"}}).Build()
+	newObject := func() client.Object { return &corev1.ConfigMap{} }
+	resync := func(period time.Duration, r reconcile.Reconciler) reconcile.Reconciler {
+		return resynced(period, c, newObject, r)
+	}
+
+	It("should requeue successful reconciles within the period", func() {
+		result, err := resync(time.Hour, reconciler(ctrl.Result{}, nil)).Reconcile(context.Background(), existing)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeNumerically(">=", time.Hour))
+		Expect(result.RequeueAfter).To(BeNumerically("<=", time.Hour+6*time.Minute))
+
+		result, _ = resync(time.Hour, reconciler(ctrl.Result{RequeueAfter: time.Minute}, nil)).Reconcile(context.Background(), existing)
+		Expect(result.RequeueAfter).To(Equal(time.Minute))
+	})
+
+	It("should leave failed reconciles and a zero period alone", func() {
+		failed := errors.New("boom")
+		result, err := resync(time.Hour, reconciler(ctrl.Result{}, failed)).Reconcile(context.Background(), existing)
+		Expect(err).To(MatchError(failed))
+		Expect(result.RequeueAfter).To(BeZero())
+
+		result, _ = resync(0, reconciler(ctrl.Result{}, nil)).Reconcile(context.Background(), existing)
+		Expect(result.RequeueAfter).To(BeZero())
+	})
+
+	It("should not resync an object that was deleted", func() {
+		deleted := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "gone"}}
+		result, err := resync(time.Hour, reconciler(ctrl.Result{}, nil)).Reconcile(context.Background(), deleted)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeZero())
+	})
+})
@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// newRollout builds an unstructured Argo Rollout for testing.
+func newRollout(replicas int64, canary map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas": replicas,
+		},
+	}}
+	obj.SetGroupVersionKind(rolloutGVK)
+	obj.SetName("test-rollout")
+	obj.SetNamespace("default")
+	if canary != nil {
+		obj.Object["spec"].(map[string]interface{})["strategy"] = map[string]interface{}{"canary": canary}
+	}
+	return obj
+}
+
+var _ = Describe("RolloutWrapper", func() {
+	It("should be returned by GetSurger for the rollout kind", func() {
+		surger, err := GetSurger(rolloutKind)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(surger).To(BeAssignableToTypeOf(&RolloutWrapper{}))
+		Expect(surger.Obj().GetObjectKind().GroupVersionKind()).To(Equal(rolloutGVK))
+	})
+
+	It("should read and set replicas without mutating the original object", func() {
+		original := newRollout(2, nil)
+		wrapper := &RolloutWrapper{obj: original}
+		Expect(wrapper.GetReplicas()).To(Equal(int32(2)))
+
+		wrapper.SetReplicas(4)
+		Expect(wrapper.GetReplicas()).To(Equal(int32(4)))
+		replicas, _, _ := unstructured.NestedInt64(original.Object, "spec", "replicas")
+		Expect(replicas).To(Equal(int64(2)))
+	})
+
+	It("should default replicas to 1 when unset", func() {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		Expect((&RolloutWrapper{obj: obj}).GetReplicas()).To(Equal(int32(1)))
+	})
+
+	It("should read canary maxSurge as int or percentage", func() {
+		Expect((&RolloutWrapper{obj: newRollout(1, map[string]interface{}{"maxSurge": int64(2)})}).GetMaxSurge()).
+			To(Equal(intstr.FromInt32(2)))
+		Expect((&RolloutWrapper{obj: newRollout(1, map[string]interface{}{"maxSurge": "50%"})}).GetMaxSurge()).
+			To(Equal(intstr.FromString("50%")))
+	})
+
+	It("should default maxSurge to 25% when not configured", func() {
+		Expect((&RolloutWrapper{obj: newRollout(1, nil)}).GetMaxSurge()).To(Equal(intstr.FromString("25%")))
+	})
+
+	It("should add and remove annotations", func() {
+		wrapper := &RolloutWrapper{obj: newRollout(1, nil)}
+		wrapper.AddAnnotation(EvictionSurgeReplicasAnnotationKey, "3")
+		Expect(hasTargetAnnotationWithValue(wrapper, "3")).To(BeTrue())
+
+		applier := &ScaleSurgeApplier{target: wrapper}
+		Expect(applier.IsSurgeActive()).To(BeTrue())
+
+		wrapper.RemoveAnnotation(EvictionSurgeReplicasAnnotationKey)
+		Expect(applier.IsSurgeActive()).To(BeFalse())
+	})
+})
@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// --- ScaleSurgeApplier ---
+//
+// Surges by writing spec.replicas through the target's /scale subresource. It is
+// the default for any target without an HPA or KEDA ScaledObject. Rollouts are
+// only ever handled as unstructured objects so the controller does not depend on
+// the Argo API module.
+//
+// Why /scale instead of a full Update:
+//
+// A full Update sends the whole cached object back. GitOps tools and Argo's own
+// controller continuously rewrite parts of the target (annotations, status, canary
+// step state), so the write races with them and would frequently 409 or clobber
+// their fields. The /scale subresource only touches spec.replicas, which is the one
+// field we need to change.
+//
+// The evictionSurgeReplicas annotation is still placed on the target (via a merge
+// patch on metadata) so IsSurgeActive survives controller restarts, mirroring
+// DeploymentSurgeApplier, unless withoutSurgeMarker leaves the record to status.
+
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=patch
+
+type ScaleSurgeApplier struct {
+	client client.Client
+	target Surger
+	// unmarked and held are set by withoutSurgeMarker.
+	unmarked bool
+	held     bool
+}
+
+var _ SurgeApplier = &ScaleSurgeApplier{}
+
+func (r *ScaleSurgeApplier) ApplySurge(ctx context.Context, surgeReplicas int32) error {
+	logger := log.FromContext(ctx)
+	surgeVal := strconv.FormatInt(int64(surgeReplicas), 10)
+
+	// Step 1: mark the surge on the target. Skipped if already marked with this value
+	// so retries after a failed scale don't issue redundant writes.
+	if !r.unmarked && !hasTargetAnnotationWithValue(r.target, surgeVal) {
+		patch := client.MergeFrom(r.target.Obj().DeepCopyObject().(client.Object))
+		r.target.AddAnnotation(EvictionSurgeReplicasAnnotationKey, surgeVal)
+		if err := r.client.Patch(ctx, r.target.Obj(), patch); err != nil {
+			return fmt.Errorf("annotating target with surge intent: %w", err)
+		}
+	}
+
+	// Step 2: scale through the /scale subresource.
+	if r.target.GetReplicas() != surgeReplicas {
+		if err := scaleSubresource(ctx, r.client, r.target, surgeReplicas); err != nil {
+			return fmt.Errorf("scaling target: %w", err)
+		}
+		logger.V(1).Info("Scaled target via scale subresource", "replicas", surgeReplicas)
+	}
+	r.held = true
+	return nil
+}
+
+func (r *ScaleSurgeApplier) RevertSurge(ctx context.Context, originalMinReplicas int32) error {
+	logger := log.FromContext(ctx)
+
+	if err := scaleSubresource(ctx, r.client, r.target, originalMinReplicas); err != nil {
+		return fmt.Errorf("scaling target: %w", err)
+	}
+	r.held = false
+
+	if hasTargetAnnotation(r.target) {
+		patch := client.MergeFrom(r.target.Obj().DeepCopyObject().(client.Object))
+		r.target.RemoveAnnotation(EvictionSurgeReplicasAnnotationKey)
+		if err := r.client.Patch(ctx, r.target.Obj(), patch); err != nil {
+			return fmt.Errorf("removing surge annotation: %w", err)
+		}
+	}
+	logger.V(1).Info("Reverted scale subresource surge", "replicas", originalMinReplicas)
+	return nil
+}
+
+// scaleSubresource sets spec.replicas on target via the /scale subresource. The
+// subresource client decodes the returned Scale into the object it is given, so it
+// is handed a copy to keep the wrapped target intact. Typed targets can't hold a
+// Scale, so they get a Scale body.
+func scaleSubresource(ctx context.Context, c client.Client, target Surger, replicas int32) error {
+	body := fmt.Sprintf(`{"spec":{"replicas":%d}}`, replicas)
+	obj := target.Obj().DeepCopyObject().(client.Object)
+	var opts []client.SubResourcePatchOption
+	if _, ok := obj.(runtime.Unstructured); !ok {
+		opts = append(opts, client.WithSubResourceBody(&autoscalingv1.Scale{}))
+	}
+	if err := c.SubResource("scale").Patch(ctx, obj, client.RawPatch(types.MergePatchType, []byte(body)), opts...); err != nil {
+		return err
+	}
+	target.SetReplicas(replicas)
+	return nil
+}
+
+func (r *ScaleSurgeApplier) Name() string {
+	return "scale"
+}
+
+func (r *ScaleSurgeApplier) IsSurgeActive() bool {
+	return hasTargetAnnotation(r.target) || r.held
+}
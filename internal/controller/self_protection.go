@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+)
+
+// selfProtectionRetry is how long SelfProtection waits before retrying a failed
+// bootstrap.
+const selfProtectionRetry = 30 * time.Second
+
+// SelfProtection makes sure the controller's own deployment has a PDB and an
+// EvictionAutoScaler, so a drain of the node it runs on surges it like any other
+// workload instead of evicting the only replica mid-drain. The deployment is found
+// from the pod named by PodName and Namespace, which the downward API provides.
+// Existing PDBs are kept; once both objects exist the controllers maintain them.
+type SelfProtection struct {
+	client.Client
+	// APIReader reads the pod and its owners, which the cache doesn't hold.
+	APIReader client.Reader
+	Recorder  record.EventRecorder
+	Namespace string
+	PodName   string
+}
+
+// Start bootstraps the PDB and EvictionAutoScaler, retrying until it succeeds or ctx
+// is done.
+func (s *SelfProtection) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("self-protection")
+	for {
+		err := s.ensure(log.IntoContext(ctx, logger))
+		if err == nil {
+			return nil
+		}
+		logger.Error(err, "failed to protect the controller's own deployment, retrying", "retryAfter", selfProtectionRetry)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(selfProtectionRetry):
+		}
+	}
+}
+
+// NeedLeaderElection is true: only the leader writes.
+func (s *SelfProtection) NeedLeaderElection() bool {
+	return true
+}
+
+func (s *SelfProtection) ensure(ctx context.Context) error {
+	deployment, err := OwnDeployment(ctx, s.APIReader, s.Namespace, s.PodName)
+	if err != nil {
+		return err
+	}
+	pdbs, err := findPDBsForDeployment(ctx, s.Client, deployment)
+	if err != nil {
+		return err
+	}
+	pdb, _ := preferredPDB(pdbs)
+	if pdb == nil {
+		created, err := NewPDBForDeployment(ctx, s.Client, deployment)
+		if err != nil {
+			return err
+		}
+		if err := applyOwned(ctx, s.Client, nil, created); err != nil {
+			return fmt.Errorf("creating PDB for %s: %w", deployment.Name, err)
+		}
+		logDecision(ctx, decisionPDBCreated, deployment.Namespace+"/"+deployment.Name, "created PodDisruptionBudget for the controller's own deployment")
+		// The owner reference of the EvictionAutoScaler needs the PDB's UID.
+		pdb = &policyv1.PodDisruptionBudget{}
+		if err := s.APIReader.Get(ctx, client.ObjectKeyFromObject(created), pdb); err != nil {
+			return err
+		}
+	}
+
+	var eas myappsv1.EvictionAutoScaler
+	err = s.APIReader.Get(ctx, types.NamespacedName{Namespace: pdb.Namespace, Name: pdb.Name}, &eas)
+	if err == nil || !apierrors.IsNotFound(err) {
+		return err
+	}
+	if err := applyOwned(ctx, s.Client, s.Recorder, NewEvictionAutoScalerForPDB(pdb, deploymentKind, deployment.Name)); err != nil {
+		return fmt.Errorf("creating EvictionAutoScaler for %s: %w", deployment.Name, err)
+	}
+	logDecision(ctx, decisionEASCreated, pdb.Namespace+"/"+pdb.Name, "created EvictionAutoScaler for the controller's own deployment")
+	return nil
+}
+
+// OwnDeployment follows the controller owner references of the named pod to its
+// deployment.
+func OwnDeployment(ctx context.Context, c client.Reader, namespace, podName string) (*appsv1.Deployment, error) {
+	var pod corev1.Pod
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: podName}, &pod); err != nil {
+		return nil, err
+	}
+	owner := metav1.GetControllerOf(&pod)
+	if owner == nil || owner.Kind != "ReplicaSet" {
+		return nil, fmt.Errorf("pod %s/%s is not owned by a ReplicaSet", namespace, podName)
+	}
+	var rs appsv1.ReplicaSet
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: owner.Name}, &rs); err != nil {
+		return nil, err
+	}
+	owner = metav1.GetControllerOf(&rs)
+	if owner == nil || owner.Kind != ResourceTypeDeployment {
+		return nil, fmt.Errorf("ReplicaSet %s/%s is not owned by a Deployment", namespace, rs.Name)
+	}
+	var deployment appsv1.Deployment
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: owner.Name}, &deployment); err != nil {
+		return nil, err
+	}
+	return &deployment, nil
+}
@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"context"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+)
+
+var _ = Describe("SelfProtection", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+		key    = types.NamespacedName{Namespace: "eviction-autoscaler", Name: "eviction-autoscaler"}
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		Expect(policyv1.AddToScheme(scheme)).To(Succeed())
+		Expect(autoscalingv2.AddToScheme(scheme)).To(Succeed())
+		Expect(kedav1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(myappsv1.AddToScheme(scheme)).To(Succeed())
+	})
+
+	controllerOf := func(kind, name string) []metav1.OwnerReference {
+		return []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: kind, Name: name, UID: types.UID(name), Controller: ptr.To(true)}}
+	}
+	labels := map[string]string{"app.kubernetes.io/name": "eviction-autoscaler"}
+
+	It("should create a PDB and an EvictionAutoScaler for the controller's own deployment", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace, UID: "deployment-uid"},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: ptr.To[int32](1),
+					Selector: &metav1.LabelSelector{MatchLabels: labels},
+					Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: labels}},
+				},
+			},
+			&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+				Name: "eviction-autoscaler-6d4f", Namespace: key.Namespace, OwnerReferences: controllerOf("Deployment", key.Name),
+			}},
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name: "eviction-autoscaler-6d4f-x2k9p", Namespace: key.Namespace, Labels: labels,
+				OwnerReferences: controllerOf("ReplicaSet", "eviction-autoscaler-6d4f"),
+			}},
+		).Build()
+		s := &SelfProtection{Client: c, APIReader: c, Namespace: key.Namespace, PodName: "eviction-autoscaler-6d4f-x2k9p"}
+
+		Expect(s.ensure(ctx)).To(Succeed())
+		var pdb policyv1.PodDisruptionBudget
+		Expect(c.Get(ctx, key, &pdb)).To(Succeed())
+		Expect(pdb.Spec.MinAvailable.IntValue()).To(Equal(1))
+		var eas myappsv1.EvictionAutoScaler
+		Expect(c.Get(ctx, key, &eas)).To(Succeed())
+		Expect(eas.Spec.TargetName).To(Equal(key.Name))
+		Expect(eas.Spec.TargetKind).To(Equal(myappsv1.TargetKindDeployment))
+
+		// A second run, e.g. after failing over, finds both in place.
+		Expect(s.ensure(ctx)).To(Succeed())
+	})
+
+	It("should fail for a pod not managed by a deployment", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "manager", Namespace: key.Namespace}},
+		).Build()
+		s := &SelfProtection{Client: c, APIReader: c, Namespace: key.Namespace, PodName: "manager"}
+		Expect(s.ensure(ctx)).To(MatchError(ContainSubstring("not owned by a ReplicaSet")))
+	})
+})
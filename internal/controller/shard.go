@@ -0,0 +1,37 @@
+package controllers
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// shardFilter is implemented by namespace filters that partition namespaces across
+// controller replicas. Filters that don't implement it own every namespace.
+type shardFilter interface {
+	InShard(ns string) bool
+}
+
+// inShard reports whether this replica owns ns. A namespace outside the shard is
+// owned by another replica and must be skipped, never treated as disabled.
+func inShard(f filter, ns string) bool {
+	if s, ok := f.(shardFilter); ok {
+		return s.InShard(ns)
+	}
+	return true
+}
+
+// shardPredicate drops events for objects in namespaces owned by another shard so
+// they never reach the work queue. Namespace objects are keyed by their own name;
+// other cluster-scoped objects (e.g. nodes) always pass.
+func shardPredicate(f filter) predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		if ns, ok := obj.(*corev1.Namespace); ok {
+			return inShard(f, ns.Name)
+		}
+		if obj.GetNamespace() == "" {
+			return true
+		}
+		return inShard(f, obj.GetNamespace())
+	})
+}
@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/metrics"
+)
+
+// surgeClock accumulates eviction_autoscaler_surge_pod_seconds_total, the extra
+// replicas a surge holds above the target's floor integrated over time. Each
+// reconcile adds the time since the previous one times the extra replicas recorded
+// then; recording a surge or its revert updates the status, which triggers the
+// reconcile that counts from the new level. Like blockageClock it keeps observations
+// in memory, so a surge spanning a restart or failover is counted from the first
+// reconcile after it.
+type surgeClock struct {
+	mu   sync.Mutex
+	seen map[types.NamespacedName]surgeObservation
+}
+
+type surgeObservation struct {
+	at     time.Time
+	target string
+	extra  int32
+}
+
+// observe records the extra replicas the surge recorded on eas holds at now.
+func (c *surgeClock) observe(eas *myappsv1.EvictionAutoScaler, now time.Time) {
+	key := types.NamespacedName{Namespace: eas.Namespace, Name: eas.Name}
+	extra := surgeExtraReplicas(eas)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if last, ok := c.seen[key]; ok && now.After(last.at) {
+		metrics.SurgePodSecondsCounter.WithLabelValues(eas.Namespace, metrics.Name(last.target)).
+			Add(float64(last.extra) * now.Sub(last.at).Seconds())
+	}
+	if extra == 0 {
+		delete(c.seen, key)
+		return
+	}
+	if c.seen == nil {
+		c.seen = map[types.NamespacedName]surgeObservation{}
+	}
+	c.seen[key] = surgeObservation{at: now, target: eas.Spec.TargetName, extra: extra}
+}
+
+// forget drops the observation of a deleted EvictionAutoScaler.
+func (c *surgeClock) forget(key types.NamespacedName) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.seen, key)
+}
+
+// surgeExtraReplicas is how many replicas the active surge on eas holds above the
+// floor the target is scaled back to.
+func surgeExtraReplicas(eas *myappsv1.EvictionAutoScaler) int32 {
+	if !eas.Status.SurgeActive {
+		return 0
+	}
+	return max(eas.Status.SurgeReplicas-scaleDownFloor(eas), 0)
+}
@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/metrics"
+)
+
+var _ = Describe("Surge pod-seconds clock", func() {
+	now := time.Now()
+
+	It("should integrate the extra replicas of a surge over time", func() {
+		eas := &myappsv1.EvictionAutoScaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "accounted-web", Namespace: "default"},
+			Spec:       myappsv1.EvictionAutoScalerSpec{TargetName: "accounted-web"},
+			Status:     myappsv1.EvictionAutoScalerStatus{MinReplicas: 2},
+		}
+		counter := metrics.SurgePodSecondsCounter.WithLabelValues("default", "accounted-web")
+		before := testutil.ToFloat64(counter)
+
+		var clock surgeClock
+		clock.observe(eas, now)
+		markSurge(&eas.Status, 3)
+		clock.observe(eas, now.Add(10*time.Second))
+		Expect(testutil.ToFloat64(counter)).To(Equal(before))
+
+		// One extra replica for 30s, then three for 10s.
+		markSurge(&eas.Status, 5)
+		clock.observe(eas, now.Add(40*time.Second))
+		Expect(testutil.ToFloat64(counter)).To(Equal(before + 30))
+		clearSurge(&eas.Status)
+		clock.observe(eas, now.Add(50*time.Second))
+		Expect(testutil.ToFloat64(counter)).To(Equal(before + 60))
+
+		clock.observe(eas, now.Add(time.Minute))
+		Expect(testutil.ToFloat64(counter)).To(Equal(before + 60))
+	})
+
+	It("should count only replicas above the floor and forget deleted EvictionAutoScalers", func() {
+		eas := &myappsv1.EvictionAutoScaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "floored-web", Namespace: "default"},
+			Spec:       myappsv1.EvictionAutoScalerSpec{TargetName: "floored-web", MinReplicas: ptr.To[int32](3)},
+			Status:     myappsv1.EvictionAutoScalerStatus{MinReplicas: 2},
+		}
+		markSurge(&eas.Status, 3)
+		Expect(surgeExtraReplicas(eas)).To(BeZero())
+		counter := metrics.SurgePodSecondsCounter.WithLabelValues("default", "floored-web")
+		before := testutil.ToFloat64(counter)
+
+		var clock surgeClock
+		clock.observe(eas, now)
+		clock.observe(eas, now.Add(time.Minute))
+		Expect(testutil.ToFloat64(counter)).To(Equal(before))
+
+		markSurge(&eas.Status, 4)
+		clock.observe(eas, now.Add(2*time.Minute))
+		clock.forget(types.NamespacedName{Namespace: "default", Name: "floored-web"})
+		clock.observe(eas, now.Add(3*time.Minute))
+		Expect(testutil.ToFloat64(counter)).To(Equal(before))
+	})
+})
@@ -0,0 +1,188 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/azure/eviction-autoscaler/internal/annotations"
+	"github.com/azure/eviction-autoscaler/internal/metrics"
+)
+
+// SurgeBatchAnnotationKey identifies the wave of pods created by one surge. It is
+// recorded on the surged deployment, copied to the wave's pods by the pod placement
+// webhook, and is also the name of the scheduling gate those pods are admitted with.
+const SurgeBatchAnnotationKey = annotations.SurgeBatch
+
+// surgeBatchApplier wraps the surge applier of a deployment and records the batch of
+// the current wave on it while the surge is active. Top-ups start a new batch, so
+// each wave is released to the scheduler on its own.
+type surgeBatchApplier struct {
+	SurgeApplier
+	writer client.Client
+	target Surger
+}
+
+var _ SurgeApplier = &surgeBatchApplier{}
+
+// ApplySurge surges the target and records the batch. Failing to record it only
+// costs batching, so it is logged rather than returned.
+func (p *surgeBatchApplier) ApplySurge(ctx context.Context, surgeReplicas int32) error {
+	if err := p.SurgeApplier.ApplySurge(ctx, surgeReplicas); err != nil {
+		return err
+	}
+	batch := fmt.Sprintf("%s-%d", p.target.Obj().GetName(), surgeReplicas)
+	if p.target.Obj().GetAnnotations()[SurgeBatchAnnotationKey] == batch {
+		return nil
+	}
+	if err := patchAnnotation(ctx, p.writer, p.target, SurgeBatchAnnotationKey, &batch); err != nil {
+		log.FromContext(ctx).Error(err, "failed to record surge batch", "target", p.target.Obj().GetName())
+	}
+	return nil
+}
+
+// RevertSurge reverts the surge and drops the batch with it.
+func (p *surgeBatchApplier) RevertSurge(ctx context.Context, originalMinReplicas int32) error {
+	if err := p.SurgeApplier.RevertSurge(ctx, originalMinReplicas); err != nil {
+		return err
+	}
+	return clearSurgeBatch(ctx, p.writer, p.target)
+}
+
+// clearSurgeBatch removes the surge batch if the target carries one.
+func clearSurgeBatch(ctx context.Context, c client.Client, target Surger) error {
+	if _, ok := target.Obj().GetAnnotations()[SurgeBatchAnnotationKey]; !ok {
+		return nil
+	}
+	return patchAnnotation(ctx, c, target, SurgeBatchAnnotationKey, nil)
+}
+
+// SurgeBatchReconciler lifts the scheduling gate from surge pods one wave at a time.
+// Cluster Autoscaler and Karpenter ignore gated pods, so holding a wave until its
+// ReplicaSet has created all of it lets them size a single scale-up for the whole
+// wave instead of reacting to each pod as it appears. A wave is never held longer
+// than Window.
+type SurgeBatchReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	// Filter is only consulted for shard ownership.
+	Filter filter
+	Window time.Duration
+}
+
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=apps,resources=replicasets,verbs=get;list;watch
+
+func (r *SurgeBatchReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	var pod corev1.Pod
+	if err := r.Get(ctx, req.NamespacedName, &pod); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !batchGated(&pod) {
+		return ctrl.Result{}, nil
+	}
+	batch := pod.Annotations[SurgeBatchAnnotationKey]
+
+	// Siblings share the pod's labels, pod-template-hash included.
+	var pods corev1.PodList
+	if err := r.List(ctx, &pods, client.InNamespace(pod.Namespace), client.MatchingLabels(pod.Labels)); err != nil {
+		return ctrl.Result{}, err
+	}
+	var gated []*corev1.Pod
+	live := 0
+	oldest := pod.CreationTimestamp.Time
+	for i := range pods.Items {
+		p := &pods.Items[i]
+		if p.DeletionTimestamp != nil {
+			continue
+		}
+		live++
+		if batchGated(p) && p.Annotations[SurgeBatchAnnotationKey] == batch {
+			gated = append(gated, p)
+			if p.CreationTimestamp.Time.Before(oldest) {
+				oldest = p.CreationTimestamp.Time
+			}
+		}
+	}
+
+	reason := metrics.BatchCompleteReason
+	complete, err := r.waveCreated(ctx, &pod, live)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !complete {
+		if wait := time.Until(oldest.Add(r.Window)); wait > 0 {
+			return ctrl.Result{RequeueAfter: wait}, nil
+		}
+		reason = metrics.BatchWindowReason
+	}
+
+	for _, p := range gated {
+		if err := releaseBatchGate(ctx, r.Client, p); err != nil {
+			logger.Error(err, "failed to lift surge batch gate", "pod", p.Name)
+			return ctrl.Result{}, err
+		}
+	}
+	logger.Info("Released surge batch to the scheduler", "batch", batch, "pods", len(gated), "reason", reason)
+	metrics.SurgeBatchReleasedCounter.WithLabelValues(pod.Namespace, reason).Inc()
+	return ctrl.Result{}, nil
+}
+
+// waveCreated reports whether the pod's ReplicaSet has created every pod it wants,
+// given live of them exist. Pods not owned by a ReplicaSet count as complete.
+func (r *SurgeBatchReconciler) waveCreated(ctx context.Context, pod *corev1.Pod, live int) (bool, error) {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil || owner.Kind != "ReplicaSet" {
+		return true, nil
+	}
+	var rs appsv1.ReplicaSet
+	if err := r.Get(ctx, types.NamespacedName{Namespace: pod.Namespace, Name: owner.Name}, &rs); err != nil {
+		if client.IgnoreNotFound(err) == nil {
+			return true, nil
+		}
+		return false, err
+	}
+	return rs.Spec.Replicas == nil || live >= int(*rs.Spec.Replicas), nil
+}
+
+// batchGated reports whether pod is still held by the surge batch gate.
+func batchGated(pod *corev1.Pod) bool {
+	return slices.ContainsFunc(pod.Spec.SchedulingGates, func(g corev1.PodSchedulingGate) bool {
+		return g.Name == SurgeBatchAnnotationKey
+	})
+}
+
+// releaseBatchGate removes the surge batch gate from pod, leaving any other gates.
+func releaseBatchGate(ctx context.Context, c client.Client, pod *corev1.Pod) error {
+	patched := pod.DeepCopy()
+	patched.Spec.SchedulingGates = slices.DeleteFunc(patched.Spec.SchedulingGates, func(g corev1.PodSchedulingGate) bool {
+		return g.Name == SurgeBatchAnnotationKey
+	})
+	return c.Patch(ctx, patched, client.MergeFrom(pod))
+}
+
+func (r *SurgeBatchReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("surge-batch").
+		WithOptions(controllerOptions("surge-batch")).
+		For(&corev1.Pod{}).
+		WithEventFilter(shardPredicate(r.Filter)).
+		WithEventFilter(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+			pod, ok := obj.(*corev1.Pod)
+			return ok && batchGated(pod)
+		})).
+		Complete(traced("surge-batch", r))
+}
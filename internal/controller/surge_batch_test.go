@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("SurgeBatchReconciler", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+		rs     *appsv1.ReplicaSet
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		rs = &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "batch"},
+			Spec:       appsv1.ReplicaSetSpec{Replicas: ptr.To(int32(3))},
+		}
+	})
+
+	pod := func(name string, gated bool, age time.Duration) *corev1.Pod {
+		p := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name: name, Namespace: "batch",
+			Labels:            map[string]string{"app": "web", "pod-template-hash": "1"},
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+			OwnerReferences:   []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "web-1", Controller: ptr.To(true)}},
+		}}
+		if gated {
+			p.Annotations = map[string]string{SurgeBatchAnnotationKey: "web-3"}
+			p.Spec.SchedulingGates = []corev1.PodSchedulingGate{{Name: SurgeBatchAnnotationKey}}
+		}
+		return p
+	}
+	reconcile := func(c client.Client, name string) ctrl.Result {
+		r := &SurgeBatchReconciler{Client: c, Scheme: scheme, Window: time.Minute}
+		result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKey{Namespace: "batch", Name: name}})
+		Expect(err).ToNot(HaveOccurred())
+		return result
+	}
+	gated := func(c client.Client, name string) bool {
+		var p corev1.Pod
+		Expect(c.Get(ctx, client.ObjectKey{Namespace: "batch", Name: name}, &p)).To(Succeed())
+		return batchGated(&p)
+	}
+
+	It("should hold a wave until its ReplicaSet has created all of it", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(rs, pod("web-a", false, time.Hour), pod("web-b", true, time.Second)).Build()
+		Expect(reconcile(c, "web-b").RequeueAfter).To(BeNumerically(">", 50*time.Second))
+		Expect(gated(c, "web-b")).To(BeTrue())
+
+		Expect(c.Create(ctx, pod("web-c", true, 0))).To(Succeed())
+		reconcile(c, "web-c")
+		Expect(gated(c, "web-b")).To(BeFalse())
+		Expect(gated(c, "web-c")).To(BeFalse())
+	})
+
+	It("should release an incomplete wave once the window has passed", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(rs, pod("web-b", true, 2*time.Minute)).Build()
+		Expect(reconcile(c, "web-b").RequeueAfter).To(BeZero())
+		Expect(gated(c, "web-b")).To(BeFalse())
+	})
+})
@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/metrics"
+)
+
+// SurgeFinalizer holds back deletion of an EvictionAutoScaler while its target is
+// surged, so the target is scaled back to minReplicas and stripped of the
+// controller's annotations first. It is only present while a surge is active.
+const SurgeFinalizer = "eviction-autoscaler.azure.com/revert-surge"
+
+// addSurgeFinalizer adds SurgeFinalizer to eas. Call it before applying a surge, so a
+// crash in between can only leave a finalizer with nothing to revert.
+func (r *EvictionAutoScalerReconciler) addSurgeFinalizer(ctx context.Context, eas *myappsv1.EvictionAutoScaler) error {
+	return r.patchFinalizers(ctx, eas, controllerutil.AddFinalizer)
+}
+
+// removeSurgeFinalizer drops SurgeFinalizer from eas once its surge is reverted.
+func (r *EvictionAutoScalerReconciler) removeSurgeFinalizer(ctx context.Context, eas *myappsv1.EvictionAutoScaler) error {
+	return r.patchFinalizers(ctx, eas, controllerutil.RemoveFinalizer)
+}
+
+// patchFinalizers applies update to a copy of eas and patches the finalizers if they
+// changed. Only the finalizers and resourceVersion are copied back, so status changes
+// not yet written survive and the next status update doesn't conflict.
+func (r *EvictionAutoScalerReconciler) patchFinalizers(ctx context.Context, eas *myappsv1.EvictionAutoScaler,
+	update func(client.Object, string) bool) error {
+	obj := eas.DeepCopy()
+	base := client.MergeFrom(obj.DeepCopy())
+	if !update(obj, SurgeFinalizer) {
+		return nil
+	}
+	if err := r.Patch(ctx, obj, base); err != nil {
+		return err
+	}
+	eas.Finalizers = obj.Finalizers
+	eas.ResourceVersion = obj.ResourceVersion
+	return nil
+}
+
+// finalize reverts the surge on a deleted EvictionAutoScaler's target and then lets
+// the deletion go ahead. A target that is gone or can't be surged by the controller
+// has nothing to revert, so it doesn't hold up the deletion.
+func (r *EvictionAutoScalerReconciler) finalize(ctx context.Context, eas *myappsv1.EvictionAutoScaler) error {
+	if !controllerutil.ContainsFinalizer(eas, SurgeFinalizer) {
+		return nil
+	}
+	if err := r.revertOnDelete(ctx, eas); err != nil {
+		return err
+	}
+	return r.removeSurgeFinalizer(ctx, eas)
+}
+
+func (r *EvictionAutoScalerReconciler) revertOnDelete(ctx context.Context, eas *myappsv1.EvictionAutoScaler) error {
+	logger := log.FromContext(ctx)
+
+	targetKind, err := myappsv1.NormalizeTargetKind(eas.Spec.TargetKind)
+	if err != nil {
+		logger.Info("Not reverting surge of deleted EvictionAutoScaler, invalid target kind", "kind", eas.Spec.TargetKind)
+		return nil
+	}
+	target, err := GetSurger(targetKind)
+	if err != nil {
+		return nil
+	}
+	err = r.Get(ctx, types.NamespacedName{Name: eas.Spec.TargetName, Namespace: eas.Namespace}, target.Obj())
+	if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	writer := client.Client(r.Client)
+	if r.Impersonator != nil {
+		writer, err = r.Impersonator.ClientFor(ctx, r.Client, eas.Namespace)
+		if err != nil {
+			return err
+		}
+	}
+	surgeApplier, err := surgeApplierFor(ctx, writer, eas.Spec.SurgeMode, eas.Namespace, eas.Spec.TargetName, string(targetKind), target)
+	if errors.Is(err, errUnsupportedAutoscalerConfig) || errors.Is(err, errInvalidSurgeMode) {
+		logger.Error(err, "can't revert surge of deleted EvictionAutoScaler", "targetname", eas.Spec.TargetName)
+		r.event(eas, corev1.EventTypeWarning, "SurgeNotReverted", err.Error())
+		return nil
+	} else if err != nil {
+		return err
+	}
+	withScaleDownFloor(surgeApplier, eas.Spec.MinReplicas)
+	if r.UnmarkedSurges {
+		withoutSurgeMarker(surgeApplier, surgeRecorded(&eas.Status, target))
+	}
+
+	if surgeApplier.IsSurgeActive() {
+		if err := surgeApplier.RevertSurge(ctx, scaleDownFloor(eas)); err != nil {
+			logger.Error(err, "failed to revert surge of deleted EvictionAutoScaler", "targetname", eas.Spec.TargetName)
+			return err
+		}
+		metrics.ActualScalingCounter.WithLabelValues(eas.Namespace, metrics.Name(eas.Spec.TargetName), metrics.ScaleDownAction).Inc()
+		observeSurgeDuration(eas, time.Now())
+		logger.Info("Reverted surge of deleted EvictionAutoScaler", "targetname", eas.Spec.TargetName, "minReplicas", scaleDownFloor(eas))
+		r.event(eas, corev1.EventTypeNormal, "SurgeReverted",
+			fmt.Sprintf("EvictionAutoScaler deleted, reverted %s to %d replicas", eas.Spec.TargetName, scaleDownFloor(eas)))
+	}
+	if err := clearAvoidNodes(ctx, writer, target); err != nil {
+		return err
+	}
+	if err := clearSurgePriorityClass(ctx, writer, target); err != nil {
+		return err
+	}
+	if err := clearAutoscalerHints(ctx, writer, target); err != nil {
+		return err
+	}
+	return clearSurgeBatch(ctx, writer, target)
+}
@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+)
+
+var _ = Describe("surge finalizer", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+		dep    *appsv1.Deployment
+		eas    *myappsv1.EvictionAutoScaler
+		key    types.NamespacedName
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		Expect(myappsv1.AddToScheme(scheme)).To(Succeed())
+		dep = &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Annotations: map[string]string{
+				EvictionSurgeReplicasAnnotationKey: "4",
+				AvoidNodesAnnotationKey:            "node-1",
+			}},
+			Spec: appsv1.DeploymentSpec{Replicas: ptr.To(int32(4))},
+		}
+		eas = &myappsv1.EvictionAutoScaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec:       myappsv1.EvictionAutoScalerSpec{TargetName: "web", TargetKind: myappsv1.TargetKindDeployment, SurgeMode: SurgeModeDirect},
+			Status:     myappsv1.EvictionAutoScalerStatus{MinReplicas: 2, SurgeActive: true, SurgeReplicas: 4},
+		}
+		key = client.ObjectKeyFromObject(eas)
+	})
+
+	build := func(objs ...client.Object) client.Client {
+		return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).
+			WithStatusSubresource(&myappsv1.EvictionAutoScaler{}).Build()
+	}
+
+	It("should add the finalizer without losing unwritten status", func() {
+		c := build(eas)
+		r := &EvictionAutoScalerReconciler{Client: c, Scheme: scheme}
+		var current myappsv1.EvictionAutoScaler
+		Expect(c.Get(ctx, key, &current)).To(Succeed())
+		markSurge(&current.Status, 5)
+
+		Expect(r.addSurgeFinalizer(ctx, &current)).To(Succeed())
+		Expect(current.Finalizers).To(ConsistOf(SurgeFinalizer))
+		Expect(current.Status.SurgeReplicas).To(Equal(int32(5)))
+		Expect(c.Status().Update(ctx, &current)).To(Succeed())
+
+		Expect(r.removeSurgeFinalizer(ctx, &current)).To(Succeed())
+		Expect(c.Get(ctx, key, &current)).To(Succeed())
+		Expect(current.Finalizers).To(BeEmpty())
+	})
+
+	It("should revert the surge and remove the controller's annotations before deletion", func() {
+		eas.Finalizers = []string{SurgeFinalizer}
+		c := build(eas, dep)
+		Expect(c.Delete(ctx, eas)).To(Succeed())
+
+		r := &EvictionAutoScalerReconciler{Client: c, Scheme: scheme}
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+
+		var got appsv1.Deployment
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(dep), &got)).To(Succeed())
+		Expect(*got.Spec.Replicas).To(Equal(int32(2)))
+		Expect(got.Annotations).ToNot(HaveKey(EvictionSurgeReplicasAnnotationKey))
+		Expect(got.Annotations).ToNot(HaveKey(AvoidNodesAnnotationKey))
+		Expect(apierrors.IsNotFound(c.Get(ctx, key, &myappsv1.EvictionAutoScaler{}))).To(BeTrue())
+	})
+
+	It("should not hold up deletion when the target is gone", func() {
+		eas.Finalizers = []string{SurgeFinalizer}
+		c := build(eas)
+		Expect(c.Delete(ctx, eas)).To(Succeed())
+
+		r := &EvictionAutoScalerReconciler{Client: c, Scheme: scheme}
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(apierrors.IsNotFound(c.Get(ctx, key, &myappsv1.EvictionAutoScaler{}))).To(BeTrue())
+	})
+})
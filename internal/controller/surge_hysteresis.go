@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+)
+
+// DefaultHysteresisMaxCooldown caps the cooldown grown by surge hysteresis.
+const DefaultHysteresisMaxCooldown = 10 * time.Minute
+
+// maxSurgeCycles bounds status.surgeCycles. Doubling reaches any sensible ceiling well
+// before that many cycles.
+const maxSurgeCycles = 10
+
+// surgeCooldown is how long the next surge is held after its last eviction: the
+// configured cooldown, doubled for each surge of the target reverted within
+// HysteresisWindow and capped at HysteresisMaxCooldown.
+func (r *EvictionAutoScalerReconciler) surgeCooldown(status *myappsv1.EvictionAutoScalerStatus, now time.Time) time.Duration {
+	pruneSurgeCycles(status, r.HysteresisWindow, now)
+	ceiling := r.HysteresisMaxCooldown
+	if ceiling <= 0 {
+		ceiling = DefaultHysteresisMaxCooldown
+	}
+	return hysteresisCooldown(cooldownOr(r.Cooldown), len(status.SurgeCycles), ceiling)
+}
+
+// hysteresisCooldown doubles base once per cycle, up to ceiling. A ceiling below
+// base never shortens it.
+func hysteresisCooldown(base time.Duration, cycles int, ceiling time.Duration) time.Duration {
+	ceiling = max(ceiling, base)
+	d := base
+	for i := 0; i < cycles && d < ceiling; i++ {
+		d *= 2
+	}
+	return min(d, ceiling)
+}
+
+// recordSurgeCycle notes a reverted surge on status, keeping the latest
+// maxSurgeCycles within window. A zero window tracks nothing.
+func recordSurgeCycle(status *myappsv1.EvictionAutoScalerStatus, window time.Duration, now time.Time) {
+	pruneSurgeCycles(status, window, now)
+	if window <= 0 {
+		return
+	}
+	status.SurgeCycles = append(status.SurgeCycles, metav1.NewTime(now))
+	if len(status.SurgeCycles) > maxSurgeCycles {
+		status.SurgeCycles = status.SurgeCycles[len(status.SurgeCycles)-maxSurgeCycles:]
+	}
+}
+
+// pruneSurgeCycles drops the cycles older than window, or all of them if window is
+// zero.
+func pruneSurgeCycles(status *myappsv1.EvictionAutoScalerStatus, window time.Duration, now time.Time) {
+	if window <= 0 {
+		status.SurgeCycles = nil
+		return
+	}
+	recent := status.SurgeCycles[:0]
+	for _, t := range status.SurgeCycles {
+		if now.Sub(t.Time) < window {
+			recent = append(recent, t)
+		}
+	}
+	if len(recent) == 0 {
+		recent = nil
+	}
+	status.SurgeCycles = recent
+}
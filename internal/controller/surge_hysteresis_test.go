@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+)
+
+var _ = Describe("surge hysteresis", func() {
+	now := time.Now()
+
+	It("should double the cooldown per recent cycle up to the ceiling", func() {
+		Expect(hysteresisCooldown(time.Minute, 0, 10*time.Minute)).To(Equal(time.Minute))
+		Expect(hysteresisCooldown(time.Minute, 2, 10*time.Minute)).To(Equal(4 * time.Minute))
+		Expect(hysteresisCooldown(time.Minute, 5, 10*time.Minute)).To(Equal(10 * time.Minute))
+		Expect(hysteresisCooldown(time.Minute, 3, 30*time.Second)).To(Equal(time.Minute))
+	})
+
+	It("should grow the cooldown with surges reverted within the window", func() {
+		r := &EvictionAutoScalerReconciler{HysteresisWindow: 30 * time.Minute}
+		status := &myappsv1.EvictionAutoScalerStatus{}
+		Expect(r.surgeCooldown(status, now)).To(Equal(time.Minute))
+
+		recordSurgeCycle(status, r.HysteresisWindow, now.Add(-25*time.Minute))
+		recordSurgeCycle(status, r.HysteresisWindow, now.Add(-20*time.Minute))
+		recordSurgeCycle(status, r.HysteresisWindow, now.Add(-5*time.Minute))
+		Expect(r.surgeCooldown(status, now)).To(Equal(8 * time.Minute))
+		Expect(r.surgeCooldown(status, now.Add(7*time.Minute))).To(Equal(4 * time.Minute))
+		Expect(status.SurgeCycles).To(HaveLen(2))
+
+		Expect(r.surgeCooldown(status, now.Add(time.Hour))).To(Equal(time.Minute))
+		Expect(status.SurgeCycles).To(BeNil())
+	})
+
+	It("should track nothing without a window and keep the latest cycles", func() {
+		status := &myappsv1.EvictionAutoScalerStatus{SurgeCycles: []metav1.Time{metav1.NewTime(now)}}
+		recordSurgeCycle(status, 0, now)
+		Expect(status.SurgeCycles).To(BeNil())
+
+		for i := range maxSurgeCycles + 3 {
+			recordSurgeCycle(status, time.Hour, now.Add(time.Duration(i)*time.Second))
+		}
+		Expect(status.SurgeCycles).To(HaveLen(maxSurgeCycles))
+		Expect(status.SurgeCycles[maxSurgeCycles-1].Time).To(Equal(now.Add(time.Duration(maxSurgeCycles+2) * time.Second)))
+	})
+})
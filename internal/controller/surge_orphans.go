@@ -0,0 +1,161 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/metrics"
+)
+
+// SurgeOrphanReconciler repairs evictionSurgeReplicas annotations left on
+// deployments that have no surge in progress, e.g. after a crash between scaling a
+// deployment and recording it on the EvictionAutoScaler. Annotated deployments are
+// rechecked every Interval; one found orphaned on two checks an Interval apart is
+// repaired, so a surge whose status write is still in flight is left alone.
+type SurgeOrphanReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// Filter is only consulted for shard ownership.
+	Filter   filter
+	Interval time.Duration
+
+	// suspects holds when each deployment was first found orphaned.
+	suspects sync.Map
+}
+
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=apps,resources=deployments/scale,verbs=get;update;patch
+
+func (r *SurgeOrphanReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	var deployment appsv1.Deployment
+	if err := r.Get(ctx, req.NamespacedName, &deployment); err != nil {
+		r.suspects.Delete(req.NamespacedName)
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	target := &DeploymentWrapper{obj: &deployment}
+	if !hasTargetAnnotation(target) {
+		r.suspects.Delete(req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+	next := ctrl.Result{RequeueAfter: r.Interval}
+
+	eas, orphaned, err := surgeOrphaned(ctx, r.Client, &deployment)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !orphaned {
+		r.suspects.Delete(req.NamespacedName)
+		return next, nil
+	}
+	first, _ := r.suspects.LoadOrStore(req.NamespacedName, time.Now())
+	if time.Since(first.(time.Time)) < r.Interval {
+		return next, nil
+	}
+
+	if eas != nil {
+		applier := deploymentSurgeApplier(r.Client, eas.Spec.SurgeMode, target)
+		if err := applier.RevertSurge(ctx, scaleDownFloor(eas)); err != nil {
+			logger.Error(err, "failed to revert orphaned surge", "deployment", deployment.Name)
+			return ctrl.Result{}, err
+		}
+		logger.Info("Reverted orphaned surge", "deployment", deployment.Name, "evictionautoscaler", eas.Name, "minReplicas", scaleDownFloor(eas))
+		r.event(&deployment, corev1.EventTypeNormal, "OrphanedSurgeReverted",
+			fmt.Sprintf("surge annotation without an active surge on EvictionAutoScaler %s, reverted to %d replicas", eas.Name, scaleDownFloor(eas)))
+	} else {
+		// Nothing records the floor the surge started from, so only our markers go.
+		if err := patchAnnotation(ctx, r.Client, target, EvictionSurgeReplicasAnnotationKey, nil); err != nil {
+			return ctrl.Result{}, err
+		}
+		logger.Info("Removed orphaned surge annotation, no EvictionAutoScaler targets the deployment", "deployment", deployment.Name)
+		r.event(&deployment, corev1.EventTypeWarning, "OrphanedSurgeAnnotationRemoved",
+			fmt.Sprintf("surge annotation left without an EvictionAutoScaler, replicas left at %d", target.GetReplicas()))
+	}
+	if err := clearAvoidNodes(ctx, r.Client, target); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := clearSurgePriorityClass(ctx, r.Client, target); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := clearSurgeBatch(ctx, r.Client, target); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := clearAutoscalerHints(ctx, r.Client, target); err != nil {
+		return ctrl.Result{}, err
+	}
+	metrics.OrphanedSurgeRepairedCounter.WithLabelValues(deployment.Namespace).Inc()
+	r.suspects.Delete(req.NamespacedName)
+	return ctrl.Result{}, nil
+}
+
+// surgeOrphaned reports whether the surge annotation on deployment belongs to no
+// surge: the EvictionAutoScaler targeting it records none and has no eviction or
+// emergency override to act on, or no EvictionAutoScaler targets it at all. The
+// EvictionAutoScaler is returned when there is one.
+func surgeOrphaned(ctx context.Context, c client.Client, deployment *appsv1.Deployment) (*myappsv1.EvictionAutoScaler, bool, error) {
+	var list myappsv1.EvictionAutoScalerList
+	if err := c.List(ctx, &list, client.InNamespace(deployment.Namespace)); err != nil {
+		return nil, false, err
+	}
+	for i := range list.Items {
+		eas := &list.Items[i]
+		if kind, err := myappsv1.NormalizeTargetKind(eas.Spec.TargetKind); err != nil || kind != deploymentKind || eas.Spec.TargetName != deployment.Name {
+			continue
+		}
+		_, emergency := eas.Annotations[EmergencySurgeUntilAnnotationKey]
+		// Being deleted: the surge finalizer reverts it. Without a floor there's
+		// nothing to revert to.
+		if eas.Status.SurgeActive || emergency || !eas.DeletionTimestamp.IsZero() || eas.Status.MinReplicas == 0 ||
+			eas.Spec.LastEviction != eas.Status.LastEviction {
+			return eas, false, nil
+		}
+		return eas, true, nil
+	}
+	return nil, true, nil
+}
+
+// deploymentSurgeApplier returns the applier that writes the surge annotation on the
+// deployment itself for mode; autoscaler surges keep theirs on the HPA or ScaledObject.
+func deploymentSurgeApplier(c client.Client, mode string, target Surger) SurgeApplier {
+	switch mode {
+	case SurgeModeAnnotation:
+		return &AnnotationSurgeApplier{client: c, target: target}
+	case SurgeModeDirect:
+		return &DeploymentSurgeApplier{client: c, target: target}
+	default:
+		return &ScaleSurgeApplier{client: c, target: target}
+	}
+}
+
+func (r *SurgeOrphanReconciler) event(obj runtime.Object, eventType, reason, message string) {
+	if r.Recorder != nil {
+		r.Recorder.Event(obj, eventType, reason, message)
+	}
+}
+
+func (r *SurgeOrphanReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("surge-orphans").
+		WithOptions(controllerOptions("surge-orphans")).
+		For(&appsv1.Deployment{}).
+		WithEventFilter(shardPredicate(r.Filter)).
+		WithEventFilter(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+			_, ok := obj.GetAnnotations()[EvictionSurgeReplicasAnnotationKey]
+			return ok
+		})).
+		Complete(traced("surge-orphans", r))
+}
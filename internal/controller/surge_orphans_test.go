@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/metrics"
+)
+
+var _ = Describe("SurgeOrphanReconciler", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+		dep    *appsv1.Deployment
+		eas    *myappsv1.EvictionAutoScaler
+		key    types.NamespacedName
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		Expect(myappsv1.AddToScheme(scheme)).To(Succeed())
+		dep = &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "orphans", Annotations: map[string]string{
+				EvictionSurgeReplicasAnnotationKey: "4",
+			}},
+			Spec: appsv1.DeploymentSpec{Replicas: ptr.To(int32(4))},
+		}
+		eas = &myappsv1.EvictionAutoScaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-pdb", Namespace: "orphans"},
+			Spec:       myappsv1.EvictionAutoScalerSpec{TargetName: "web", TargetKind: myappsv1.TargetKindDeployment, SurgeMode: SurgeModeDirect},
+			Status:     myappsv1.EvictionAutoScalerStatus{MinReplicas: 2},
+		}
+		key = client.ObjectKeyFromObject(dep)
+	})
+
+	sweep := func(r *SurgeOrphanReconciler) ctrl.Result {
+		result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+		return result
+	}
+	build := func(objs ...client.Object) client.Client {
+		return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+	}
+
+	It("should revert an orphaned surge once it is seen on two checks", func() {
+		c := build(dep, eas)
+		r := &SurgeOrphanReconciler{Client: c, Scheme: scheme, Interval: time.Minute}
+		repaired := metrics.OrphanedSurgeRepairedCounter.WithLabelValues("orphans")
+		before := testutil.ToFloat64(repaired)
+
+		Expect(sweep(r).RequeueAfter).To(Equal(time.Minute))
+		var got appsv1.Deployment
+		Expect(c.Get(ctx, key, &got)).To(Succeed())
+		Expect(got.Annotations).To(HaveKey(EvictionSurgeReplicasAnnotationKey))
+
+		r.suspects.Store(key, time.Now().Add(-2*time.Minute))
+		sweep(r)
+		Expect(c.Get(ctx, key, &got)).To(Succeed())
+		Expect(got.Annotations).ToNot(HaveKey(EvictionSurgeReplicasAnnotationKey))
+		Expect(*got.Spec.Replicas).To(Equal(int32(2)))
+		Expect(testutil.ToFloat64(repaired)).To(Equal(before + 1))
+	})
+
+	It("should leave an active surge alone", func() {
+		eas.Status.SurgeActive = true
+		c := build(dep, eas)
+		r := &SurgeOrphanReconciler{Client: c, Scheme: scheme, Interval: time.Minute}
+		r.suspects.Store(key, time.Now().Add(-2*time.Minute))
+
+		Expect(sweep(r).RequeueAfter).To(Equal(time.Minute))
+		var got appsv1.Deployment
+		Expect(c.Get(ctx, key, &got)).To(Succeed())
+		Expect(got.Annotations).To(HaveKey(EvictionSurgeReplicasAnnotationKey))
+		_, suspected := r.suspects.Load(key)
+		Expect(suspected).To(BeFalse())
+	})
+
+	It("should only strip the annotation when no EvictionAutoScaler targets the deployment", func() {
+		c := build(dep)
+		r := &SurgeOrphanReconciler{Client: c, Scheme: scheme, Interval: time.Minute}
+		r.suspects.Store(key, time.Now().Add(-2*time.Minute))
+
+		sweep(r)
+		var got appsv1.Deployment
+		Expect(c.Get(ctx, key, &got)).To(Succeed())
+		Expect(got.Annotations).ToNot(HaveKey(EvictionSurgeReplicasAnnotationKey))
+		Expect(*got.Spec.Replicas).To(Equal(int32(4)))
+	})
+})
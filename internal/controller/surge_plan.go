@@ -0,0 +1,195 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/annotations"
+	"github.com/azure/eviction-autoscaler/internal/metrics"
+)
+
+// ApproveSurgeAnnotationKey approves or rejects a pending SurgePlan.
+const ApproveSurgeAnnotationKey = annotations.ApproveSurge
+
+// How a SurgePlan was decided, recorded in its status.
+const (
+	decidedByAnnotation = "annotation"
+	decidedByTimeout    = "timeout"
+)
+
+// awaitSurgePlan gates scaling the target of eas from current to surgeTarget
+// replicas on an approved SurgePlan. It returns the approved plan, already marked
+// Applied, or nil while the plan waits or after it was rejected. With a nil plan the
+// status of eas is set for the caller to write, and result says when to look again.
+func (r *EvictionAutoScalerReconciler) awaitSurgePlan(ctx context.Context, eas *myappsv1.EvictionAutoScaler, current, surgeTarget int32, reason string) (*myappsv1.SurgePlan, ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	now := time.Now()
+
+	plan := &myappsv1.SurgePlan{}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(eas), plan); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, ctrl.Result{}, err
+		}
+		plan = nil
+	}
+	if plan == nil || surgePlanSuperseded(plan, eas, r.Cooldown) {
+		return nil, r.proposeSurgePlan(ctx, eas, plan, current, surgeTarget, reason, now), nil
+	}
+
+	if plan.Status.Phase == myappsv1.SurgePlanPending {
+		approved, decided, by := surgePlanDecision(plan, now)
+		if !decided {
+			// The surge needed changed while waiting; approvers see the latest.
+			if plan.Spec.SurgeReplicas != surgeTarget {
+				plan.Spec.CurrentReplicas = current
+				plan.Spec.SurgeReplicas = surgeTarget
+				plan.Spec.Reason = reason
+				plan.Spec.Eviction = eas.Spec.LastEviction
+				if err := r.Update(ctx, plan); err != nil {
+					return nil, ctrl.Result{}, err
+				}
+			}
+			surgePlanPending(eas, plan)
+			var result ctrl.Result
+			if plan.Spec.AutoApproveAt != nil {
+				result.RequeueAfter = max(time.Until(plan.Spec.AutoApproveAt.Time), time.Second)
+			}
+			return nil, result, nil
+		}
+
+		// Record the decision before acting on it, so a surge that fails half way is
+		// re-proposed rather than applied again on the same approval.
+		plan.Status.Phase = myappsv1.SurgePlanRejected
+		outcome := metrics.RejectedSurgePlan
+		if approved {
+			plan.Status.Phase = myappsv1.SurgePlanApplied
+			outcome = metrics.AppliedSurgePlan
+		}
+		plan.Status.DecidedBy = by
+		plan.Status.DecidedAt = &metav1.Time{Time: now}
+		if err := r.Status().Update(ctx, plan); err != nil {
+			return nil, ctrl.Result{}, err
+		}
+		metrics.SurgePlanCounter.WithLabelValues(eas.Namespace, outcome).Inc()
+		logger.Info("Surge plan decided", "plan", plan.Name, "phase", plan.Status.Phase, "decidedBy", by)
+		if approved {
+			r.event(eas, corev1.EventTypeNormal, "SurgePlanApproved",
+				fmt.Sprintf("surge plan %s approved by %s, scaling %s to %d replicas", plan.Name, by, plan.Spec.TargetName, plan.Spec.SurgeReplicas))
+			return plan, ctrl.Result{}, nil
+		}
+		r.event(eas, corev1.EventTypeWarning, "SurgePlanRejected",
+			fmt.Sprintf("surge plan %s rejected, evictions are recorded without surging", plan.Name))
+	}
+
+	// Rejected: record the eviction without surging until the burst is over.
+	eas.Status.LastEviction = eas.Spec.LastEviction
+	eas.Status.CooldownUntil = nil
+	ready(eas, "SurgePlanRejected", fmt.Sprintf("eviction recorded, surge plan %s was rejected", plan.Name))
+	return nil, ctrl.Result{}, nil
+}
+
+// proposeSurgePlan publishes a Pending SurgePlan for the surge, replacing plan if
+// set, and marks eas as waiting on it.
+func (r *EvictionAutoScalerReconciler) proposeSurgePlan(ctx context.Context, eas *myappsv1.EvictionAutoScaler, plan *myappsv1.SurgePlan, current, surgeTarget int32, reason string, now time.Time) ctrl.Result {
+	logger := log.FromContext(ctx)
+	spec := myappsv1.SurgePlanSpec{
+		TargetKind:      eas.Spec.TargetKind,
+		TargetName:      eas.Spec.TargetName,
+		CurrentReplicas: current,
+		SurgeReplicas:   surgeTarget,
+		Reason:          reason,
+		Eviction:        eas.Spec.LastEviction,
+	}
+	var result ctrl.Result
+	if r.SurgeAutoApproveAfter > 0 {
+		spec.AutoApproveAt = &metav1.Time{Time: now.Add(r.SurgeAutoApproveAfter)}
+		result.RequeueAfter = r.SurgeAutoApproveAfter
+	}
+
+	err := func() error {
+		if plan == nil {
+			plan = &myappsv1.SurgePlan{ObjectMeta: metav1.ObjectMeta{Name: eas.Name, Namespace: eas.Namespace}, Spec: spec}
+			if err := controllerutil.SetControllerReference(eas, plan, r.Scheme); err != nil {
+				return err
+			}
+			if err := r.Create(ctx, plan); err != nil {
+				return err
+			}
+		} else {
+			// A decision on the plan being replaced doesn't carry over.
+			plan.Spec = spec
+			delete(plan.Annotations, ApproveSurgeAnnotationKey)
+			if err := r.Update(ctx, plan); err != nil {
+				return err
+			}
+		}
+		plan.Status = myappsv1.SurgePlanStatus{Phase: myappsv1.SurgePlanPending}
+		return r.Status().Update(ctx, plan)
+	}()
+	if err != nil {
+		// Nothing was surged; retry the proposal with the eviction still pending.
+		logger.Error(err, "failed to propose surge plan", "name", eas.Name)
+		degraded(eas, "SurgePlanFailed", "unable to publish surge plan: "+err.Error())
+		return ctrl.Result{RequeueAfter: time.Second * 10}
+	}
+
+	metrics.SurgePlanCounter.WithLabelValues(eas.Namespace, metrics.ProposedSurgePlan).Inc()
+	logger.Info("Proposed surge plan", "plan", plan.Name, "currentReplicas", current, "surgeReplicas", surgeTarget)
+	r.event(eas, corev1.EventTypeNormal, "SurgePlanProposed",
+		fmt.Sprintf("surge plan %s proposes scaling %s from %d to %d replicas: %s", plan.Name, eas.Spec.TargetName, current, surgeTarget, reason))
+	surgePlanPending(eas, plan)
+	return result
+}
+
+// surgePlanPending marks eas as waiting on plan. No cooldown runs until the surge
+// is made.
+func surgePlanPending(eas *myappsv1.EvictionAutoScaler, plan *myappsv1.SurgePlan) {
+	eas.Status.CooldownUntil = nil
+	ready(eas, "SurgePlanPending", fmt.Sprintf("eviction pending, surge plan %s awaits approval", plan.Name))
+}
+
+// surgePlanSuperseded reports whether a decided plan no longer covers the surge eas
+// needs. An applied plan was for an earlier surge. A rejected plan holds while
+// evictions keep arriving, and lapses once one comes more than the configured
+// cooldown after the last eviction it turned down.
+func surgePlanSuperseded(plan *myappsv1.SurgePlan, eas *myappsv1.EvictionAutoScaler, configured time.Duration) bool {
+	switch plan.Status.Phase {
+	case myappsv1.SurgePlanApplied:
+		return true
+	case myappsv1.SurgePlanRejected:
+		return eas.Spec.LastEviction.EvictionTime.Sub(eas.Status.LastEviction.EvictionTime.Time) > cooldownOr(configured)
+	default:
+		return plan.Status.Phase != myappsv1.SurgePlanPending
+	}
+}
+
+// surgePlanDecision reports whether a pending plan was decided and how: through
+// the approve annotation, or by reaching its auto-approval time. An annotation
+// that is not a valid bool is ignored.
+func surgePlanDecision(plan *myappsv1.SurgePlan, now time.Time) (approved, decided bool, by string) {
+	if val, ok := plan.Annotations[ApproveSurgeAnnotationKey]; ok {
+		if approve, err := annotations.Bool(ApproveSurgeAnnotationKey, val); err == nil {
+			return approve, true, decidedByAnnotation
+		}
+	}
+	if plan.Spec.AutoApproveAt != nil && !now.Before(plan.Spec.AutoApproveAt.Time) {
+		return true, true, decidedByTimeout
+	}
+	return false, false, ""
+}
+
+// surgePlanReason explains a surge for approvers.
+func surgePlanReason(displaced int32, pdb *policyv1.PodDisruptionBudget) string {
+	return fmt.Sprintf("PDB %s allows no disruptions and %d pods are on draining nodes", pdb.Name, displaced)
+}
@@ -0,0 +1,181 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/namespacefilter"
+)
+
+var _ = Describe("EvictionAutoScalerReconciler surge approval", func() {
+	var (
+		ctx context.Context
+		c   client.Client
+		r   *EvictionAutoScalerReconciler
+		key client.ObjectKey
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		Expect(policyv1.AddToScheme(scheme)).To(Succeed())
+		Expect(autoscalingv2.AddToScheme(scheme)).To(Succeed())
+		Expect(kedav1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(myappsv1.AddToScheme(scheme)).To(Succeed())
+
+		eas := &myappsv1.EvictionAutoScaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: myappsv1.EvictionAutoScalerSpec{TargetName: "web", TargetKind: myappsv1.TargetKindDeployment, SurgeMode: SurgeModeDirect,
+				LastEviction: myappsv1.Eviction{PodName: "web-1", EvictionTime: metav1.Now()}},
+			Status: myappsv1.EvictionAutoScalerStatus{MinReplicas: 2, TargetGeneration: 1},
+		}
+		key = client.ObjectKeyFromObject(eas)
+		c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}, Spec: corev1.NodeSpec{Unschedulable: true}},
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default", Labels: map[string]string{"app": "web"}},
+				Spec: corev1.PodSpec{NodeName: "node-1"}},
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Generation: 1},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: ptr.To(int32(2)),
+					Strategy: appsv1.DeploymentStrategy{RollingUpdate: &appsv1.RollingUpdateDeployment{MaxSurge: ptr.To(intstr.FromInt32(1))}},
+				},
+			},
+			&policyv1.PodDisruptionBudget{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+				Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+			},
+			eas,
+		).WithStatusSubresource(&myappsv1.EvictionAutoScaler{}, &myappsv1.SurgePlan{}).Build()
+		r = &EvictionAutoScalerReconciler{Client: c, Scheme: scheme, Filter: namespacefilter.New(nil, false), APIReader: c, SurgeApproval: true}
+	})
+
+	reconcile := func() ctrl.Result {
+		result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+		return result
+	}
+	replicas := func() int32 {
+		var dep appsv1.Deployment
+		Expect(c.Get(ctx, key, &dep)).To(Succeed())
+		return *dep.Spec.Replicas
+	}
+	plan := func() *myappsv1.SurgePlan {
+		var p myappsv1.SurgePlan
+		Expect(c.Get(ctx, key, &p)).To(Succeed())
+		return &p
+	}
+	evictionAutoScaler := func() *myappsv1.EvictionAutoScaler {
+		var eas myappsv1.EvictionAutoScaler
+		Expect(c.Get(ctx, key, &eas)).To(Succeed())
+		return &eas
+	}
+	annotate := func(value string) {
+		p := plan()
+		p.Annotations = map[string]string{ApproveSurgeAnnotationKey: value}
+		Expect(c.Update(ctx, p)).To(Succeed())
+	}
+
+	It("should publish a pending plan and leave the eviction unhandled", func() {
+		reconcile()
+		Expect(replicas()).To(Equal(int32(2)))
+
+		p := plan()
+		Expect(p.Status.Phase).To(Equal(myappsv1.SurgePlanPending))
+		Expect(p.Spec.CurrentReplicas).To(Equal(int32(2)))
+		Expect(p.Spec.SurgeReplicas).To(Equal(int32(3)))
+		Expect(p.Spec.AutoApproveAt).To(BeNil())
+		Expect(metav1.IsControlledBy(p, evictionAutoScaler())).To(BeTrue())
+
+		eas := evictionAutoScaler()
+		Expect(meta.FindStatusCondition(eas.Status.Conditions, ReadyCondition).Reason).To(Equal("SurgePlanPending"))
+		Expect(eas.Status.LastEviction).ToNot(Equal(eas.Spec.LastEviction))
+
+		// Still undecided on the next pass.
+		reconcile()
+		Expect(replicas()).To(Equal(int32(2)))
+	})
+
+	It("should surge once the plan is approved and mark it applied", func() {
+		reconcile()
+		annotate("true")
+		reconcile()
+		Expect(replicas()).To(Equal(int32(3)))
+
+		p := plan()
+		Expect(p.Status.Phase).To(Equal(myappsv1.SurgePlanApplied))
+		Expect(p.Status.DecidedBy).To(Equal(decidedByAnnotation))
+		Expect(evictionAutoScaler().Status.SurgeActive).To(BeTrue())
+	})
+
+	It("should record the eviction without surging once the plan is rejected", func() {
+		reconcile()
+		annotate("false")
+		reconcile()
+		Expect(replicas()).To(Equal(int32(2)))
+
+		Expect(plan().Status.Phase).To(Equal(myappsv1.SurgePlanRejected))
+		eas := evictionAutoScaler()
+		Expect(meta.FindStatusCondition(eas.Status.Conditions, ReadyCondition).Reason).To(Equal("SurgePlanRejected"))
+		Expect(eas.Status.LastEviction).To(Equal(eas.Spec.LastEviction))
+	})
+
+	It("should approve an undecided plan at its auto-approval time", func() {
+		r.SurgeAutoApproveAfter = time.Minute
+		result := reconcile()
+		Expect(result.RequeueAfter).To(Equal(time.Minute))
+		Expect(replicas()).To(Equal(int32(2)))
+
+		p := plan()
+		Expect(p.Spec.AutoApproveAt).ToNot(BeNil())
+		p.Spec.AutoApproveAt = &metav1.Time{Time: time.Now().Add(-time.Second)}
+		Expect(c.Update(ctx, p)).To(Succeed())
+
+		reconcile()
+		Expect(replicas()).To(Equal(int32(3)))
+		Expect(plan().Status.DecidedBy).To(Equal(decidedByTimeout))
+	})
+})
+
+var _ = Describe("surgePlanSuperseded", func() {
+	rejected := &myappsv1.SurgePlan{Status: myappsv1.SurgePlanStatus{Phase: myappsv1.SurgePlanRejected}}
+	evictedAfter := func(gap time.Duration) *myappsv1.EvictionAutoScaler {
+		turnedDown := time.Now()
+		eas := &myappsv1.EvictionAutoScaler{}
+		eas.Status.LastEviction.EvictionTime = metav1.NewTime(turnedDown)
+		eas.Spec.LastEviction.EvictionTime = metav1.NewTime(turnedDown.Add(gap))
+		return eas
+	}
+
+	It("should hold a rejected plan within the configured cooldown", func() {
+		Expect(surgePlanSuperseded(rejected, evictedAfter(2*time.Minute), 5*time.Minute)).To(BeFalse())
+	})
+
+	It("should lapse a rejected plan after the configured cooldown", func() {
+		Expect(surgePlanSuperseded(rejected, evictedAfter(6*time.Minute), 5*time.Minute)).To(BeTrue())
+	})
+
+	It("should fall back to the default cooldown when none is configured", func() {
+		Expect(surgePlanSuperseded(rejected, evictedAfter(2*time.Minute), 0)).To(BeTrue())
+	})
+})
@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/azure/eviction-autoscaler/internal/annotations"
+)
+
+// SurgePriorityClassAnnotationKey names the PriorityClass that new pods of a surged
+// deployment are given. The pod placement webhook applies it at admission.
+const SurgePriorityClassAnnotationKey = annotations.SurgePriorityClass
+
+// surgePriorityApplier wraps the surge applier of a deployment and records
+// spec.surgePriorityClassName on it while the surge is active. Like placement hints
+// it leaves the pod template alone, so only pods created during the surge are affected.
+type surgePriorityApplier struct {
+	SurgeApplier
+	writer        client.Client
+	target        Surger
+	priorityClass string
+}
+
+var _ SurgeApplier = &surgePriorityApplier{}
+
+// ApplySurge surges the target and records the PriorityClass. The surge itself is
+// what unblocks the drain, so failing to record the class is logged rather than returned.
+func (p *surgePriorityApplier) ApplySurge(ctx context.Context, surgeReplicas int32) error {
+	if err := p.SurgeApplier.ApplySurge(ctx, surgeReplicas); err != nil {
+		return err
+	}
+	if p.target.Obj().GetAnnotations()[SurgePriorityClassAnnotationKey] == p.priorityClass {
+		return nil
+	}
+	if err := patchAnnotation(ctx, p.writer, p.target, SurgePriorityClassAnnotationKey, &p.priorityClass); err != nil {
+		log.FromContext(ctx).Error(err, "failed to record surge priority class", "target", p.target.Obj().GetName())
+	}
+	return nil
+}
+
+// RevertSurge reverts the surge and drops the PriorityClass with it.
+func (p *surgePriorityApplier) RevertSurge(ctx context.Context, originalMinReplicas int32) error {
+	if err := p.SurgeApplier.RevertSurge(ctx, originalMinReplicas); err != nil {
+		return err
+	}
+	return clearSurgePriorityClass(ctx, p.writer, p.target)
+}
+
+// clearSurgePriorityClass removes the surge PriorityClass if the target carries one.
+func clearSurgePriorityClass(ctx context.Context, c client.Client, target Surger) error {
+	if _, ok := target.Obj().GetAnnotations()[SurgePriorityClassAnnotationKey]; !ok {
+		return nil
+	}
+	return patchAnnotation(ctx, c, target, SurgePriorityClassAnnotationKey, nil)
+}
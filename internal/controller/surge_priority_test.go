@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("surge priority class", func() {
+	It("should record the priority class on surge and drop it on revert", func() {
+		ctx := context.Background()
+		scheme := runtime.NewScheme()
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		dep := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+			Spec:       appsv1.DeploymentSpec{Replicas: ptr.To[int32](2)},
+		}
+		fc := fake.NewClientBuilder().WithScheme(scheme).WithObjects(dep).Build()
+		wrap := func() *surgePriorityApplier {
+			var current appsv1.Deployment
+			Expect(fc.Get(ctx, client.ObjectKeyFromObject(dep), &current)).To(Succeed())
+			target := &DeploymentWrapper{obj: &current}
+			return &surgePriorityApplier{
+				SurgeApplier:  &DeploymentSurgeApplier{client: fc, target: target},
+				writer:        fc,
+				target:        target,
+				priorityClass: "surge-critical",
+			}
+		}
+
+		Expect(wrap().ApplySurge(ctx, 3)).To(Succeed())
+		var updated appsv1.Deployment
+		Expect(fc.Get(ctx, client.ObjectKeyFromObject(dep), &updated)).To(Succeed())
+		Expect(*updated.Spec.Replicas).To(Equal(int32(3)))
+		Expect(updated.Annotations).To(HaveKeyWithValue(SurgePriorityClassAnnotationKey, "surge-critical"))
+		Expect(updated.Spec.Template.Spec.PriorityClassName).To(BeEmpty())
+
+		Expect(wrap().RevertSurge(ctx, 2)).To(Succeed())
+		Expect(fc.Get(ctx, client.ObjectKeyFromObject(dep), &updated)).To(Succeed())
+		Expect(*updated.Spec.Replicas).To(Equal(int32(2)))
+		Expect(updated.Annotations).ToNot(HaveKey(SurgePriorityClassAnnotationKey))
+	})
+})
@@ -0,0 +1,194 @@
+package controllers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/metrics"
+)
+
+// FailedSchedulingReason is the reason the scheduler gives events for pods it
+// could not place.
+const FailedSchedulingReason = "FailedScheduling"
+
+// eventTTL is how long the API server keeps an event after it last occurred, its
+// --event-ttl default.
+const eventTTL = time.Hour
+
+// SurgeSchedulingReconciler attributes FailedScheduling events to the surge that
+// created the pod, so operators can see when surge pods can't find room.
+type SurgeSchedulingReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	// Filter is only consulted for shard ownership.
+	Filter filter
+
+	// counted is the event count already added to the metric, per event. Events
+	// are updated in place as the scheduler retries, so only the increase counts.
+	// Entries go when their event is deleted or expires.
+	counted sync.Map
+	started time.Time
+}
+
+// +kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch
+
+func (r *SurgeSchedulingReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	var ev corev1.Event
+	if err := r.Get(ctx, req.NamespacedName, &ev); err != nil {
+		r.counted.Delete(req.NamespacedName)
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	expires := eventLastSeen(&ev).Add(eventTTL)
+	if !time.Now().Before(expires) {
+		// Left for the API server to delete; a retry would have moved its last time.
+		r.counted.Delete(req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+	count := eventCount(&ev)
+	previous, seen := r.counted.Load(req.NamespacedName)
+	r.counted.Store(req.NamespacedName, count)
+	// Look again once it expires, so the entry goes even if its deletion is missed.
+	next := ctrl.Result{RequeueAfter: time.Until(expires)}
+	delta := count
+	if seen {
+		delta -= previous.(int32)
+	} else if eventLastSeen(&ev).Before(r.started) {
+		// Already there when the controller started; its retries before now were
+		// either counted by a previous process or happened while none was running.
+		return next, nil
+	}
+	if delta <= 0 {
+		return next, nil
+	}
+
+	var pod corev1.Pod
+	if err := r.Get(ctx, types.NamespacedName{Namespace: ev.Namespace, Name: ev.InvolvedObject.Name}, &pod); err != nil {
+		if apierrors.IsNotFound(err) {
+			return next, nil
+		}
+		return ctrl.Result{}, err
+	}
+	eas, err := surgeForPod(ctx, r.Client, &pod)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if eas == nil {
+		return next, nil
+	}
+	logger.V(1).Info("Surge pod failed to schedule", "pod", pod.Name, "target", eas.Spec.TargetName, "message", ev.Message)
+	metrics.SurgeFailedSchedulingCounter.WithLabelValues(eas.Namespace, metrics.Name(eas.Spec.TargetName)).Add(float64(delta))
+	return next, nil
+}
+
+// surgeForPod returns the EvictionAutoScaler whose active surge created pod: one
+// named after a PDB selecting the pod, surging since before the pod was created.
+func surgeForPod(ctx context.Context, c client.Client, pod *corev1.Pod) (*myappsv1.EvictionAutoScaler, error) {
+	var pdbs policyv1.PodDisruptionBudgetList
+	if err := c.List(ctx, &pdbs, client.InNamespace(pod.Namespace)); err != nil {
+		return nil, err
+	}
+	for _, pdb := range pdbs.Items {
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil || pdb.Spec.Selector == nil || !selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		var eas myappsv1.EvictionAutoScaler
+		if err := c.Get(ctx, types.NamespacedName{Namespace: pdb.Namespace, Name: pdb.Name}, &eas); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				return nil, err
+			}
+			continue
+		}
+		if createdDuringSurge(pod, &eas.Status) {
+			return &eas, nil
+		}
+	}
+	return nil, nil
+}
+
+// createdDuringSurge reports whether pod was created while status records an
+// active surge.
+func createdDuringSurge(pod *corev1.Pod, status *myappsv1.EvictionAutoScalerStatus) bool {
+	return status.SurgeActive && status.SurgeStartTime != nil && !pod.CreationTimestamp.Before(status.SurgeStartTime)
+}
+
+// countPendingSurgePods counts the pods selected by pdb that were created during the
+// surge recorded on status and are still Pending, and returns when the oldest of
+// them was created.
+func countPendingSurgePods(ctx context.Context, c client.Client, pdb *policyv1.PodDisruptionBudget, status *myappsv1.EvictionAutoScalerStatus) (int, time.Time, error) {
+	selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	var pods corev1.PodList
+	if err := c.List(ctx, &pods, client.InNamespace(pdb.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return 0, time.Time{}, err
+	}
+	pending := 0
+	var oldest time.Time
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodPending && pod.DeletionTimestamp == nil && createdDuringSurge(&pod, status) {
+			pending++
+			if oldest.IsZero() || pod.CreationTimestamp.Time.Before(oldest) {
+				oldest = pod.CreationTimestamp.Time
+			}
+		}
+	}
+	return pending, oldest, nil
+}
+
+// eventCount is how many times the event has occurred, from either the legacy count
+// or the event series.
+func eventCount(ev *corev1.Event) int32 {
+	count := max(ev.Count, 1)
+	if ev.Series != nil {
+		count = max(count, ev.Series.Count)
+	}
+	return count
+}
+
+// eventLastSeen is when the event last occurred.
+func eventLastSeen(ev *corev1.Event) time.Time {
+	switch {
+	case ev.Series != nil && !ev.Series.LastObservedTime.IsZero():
+		return ev.Series.LastObservedTime.Time
+	case !ev.LastTimestamp.IsZero():
+		return ev.LastTimestamp.Time
+	case !ev.EventTime.IsZero():
+		return ev.EventTime.Time
+	}
+	return ev.CreationTimestamp.Time
+}
+
+// isPodFailedScheduling matches FailedScheduling events about pods.
+func isPodFailedScheduling(obj client.Object) bool {
+	ev, ok := obj.(*corev1.Event)
+	return ok && ev.Reason == FailedSchedulingReason && ev.InvolvedObject.Kind == "Pod"
+}
+
+func (r *SurgeSchedulingReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.started = time.Now()
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("surge-scheduling").
+		WithOptions(controllerOptions("surge-scheduling")).
+		For(&corev1.Event{}).
+		WithEventFilter(shardPredicate(r.Filter)).
+		WithEventFilter(predicate.NewPredicateFuncs(isPodFailedScheduling)).
+		Complete(traced("surge-scheduling", r))
+}
@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/metrics"
+)
+
+var _ = Describe("surge scheduling metrics", func() {
+	var (
+		ctx        context.Context
+		scheme     *runtime.Scheme
+		surgeStart time.Time
+		pdb        *policyv1.PodDisruptionBudget
+		eas        *myappsv1.EvictionAutoScaler
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(policyv1.AddToScheme(scheme)).To(Succeed())
+		Expect(myappsv1.AddToScheme(scheme)).To(Succeed())
+		surgeStart = time.Now().Add(-time.Minute).Truncate(time.Second)
+		pdb = &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "surge-scheduling"},
+			Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+		}
+		start := metav1.NewTime(surgeStart)
+		eas = &myappsv1.EvictionAutoScaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "surge-scheduling"},
+			Spec:       myappsv1.EvictionAutoScalerSpec{TargetName: "web", TargetKind: myappsv1.TargetKindDeployment},
+			Status:     myappsv1.EvictionAutoScalerStatus{SurgeActive: true, SurgeStartTime: &start},
+		}
+	})
+
+	pod := func(name string, created time.Time, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name, Namespace: "surge-scheduling", Labels: map[string]string{"app": "web"},
+				CreationTimestamp: metav1.NewTime(created),
+			},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+	build := func(objs ...client.Object) client.Client {
+		return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+	}
+
+	It("should only count Pending pods created during the surge", func() {
+		c := build(pdb,
+			pod("old", surgeStart.Add(-time.Hour), corev1.PodPending),
+			pod("surged-running", surgeStart.Add(time.Second), corev1.PodRunning),
+			pod("surged-pending", surgeStart.Add(time.Second), corev1.PodPending),
+			pod("surged-pending-later", surgeStart.Add(time.Minute), corev1.PodPending),
+		)
+		pending, oldest, err := countPendingSurgePods(ctx, c, pdb, &eas.Status)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pending).To(Equal(2))
+		Expect(oldest).To(BeTemporally("==", surgeStart.Add(time.Second)))
+
+		eas.Status.SurgeActive = false
+		pending, _, err = countPendingSurgePods(ctx, c, pdb, &eas.Status)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pending).To(BeZero())
+	})
+
+	It("should attribute a pod to the surge of the EvictionAutoScaler named after its PDB", func() {
+		surged := pod("surged", surgeStart.Add(time.Second), corev1.PodPending)
+		found, err := surgeForPod(ctx, build(pdb, eas, surged), surged)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(found).ToNot(BeNil())
+		Expect(found.Name).To(Equal("web"))
+
+		old := pod("old", surgeStart.Add(-time.Hour), corev1.PodPending)
+		found, err = surgeForPod(ctx, build(pdb, eas, old), old)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(found).To(BeNil())
+	})
+
+	It("should count only the increase of an event retried by the scheduler", func() {
+		surged := pod("surged", surgeStart.Add(time.Second), corev1.PodPending)
+		ev := &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "surged.1", Namespace: "surge-scheduling"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "surged", Namespace: "surge-scheduling"},
+			Reason:         FailedSchedulingReason,
+			Count:          1,
+			LastTimestamp:  metav1.Now(),
+		}
+		c := build(pdb, eas, surged, ev)
+		r := &SurgeSchedulingReconciler{Client: c, Scheme: scheme, started: time.Now().Add(-time.Hour)}
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: ev.Namespace, Name: ev.Name}}
+		counter := metrics.SurgeFailedSchedulingCounter.WithLabelValues("surge-scheduling", "web")
+		before := testutil.ToFloat64(counter)
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(testutil.ToFloat64(counter)).To(Equal(before + 1))
+
+		ev.Count = 4
+		Expect(c.Update(ctx, ev)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(testutil.ToFloat64(counter)).To(Equal(before + 4))
+
+		result, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(testutil.ToFloat64(counter)).To(Equal(before + 4))
+		Expect(result.RequeueAfter).To(BeNumerically("~", eventTTL, time.Minute))
+	})
+
+	It("should forget an event once it expires", func() {
+		ev := &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "expired.1", Namespace: "surge-scheduling"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "surged", Namespace: "surge-scheduling"},
+			Reason:         FailedSchedulingReason,
+			Count:          3,
+			LastTimestamp:  metav1.NewTime(time.Now().Add(-eventTTL - time.Minute)),
+		}
+		r := &SurgeSchedulingReconciler{Client: build(pdb, eas, ev), Scheme: scheme}
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: ev.Namespace, Name: ev.Name}}
+		r.counted.Store(req.NamespacedName, int32(3))
+
+		result, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeZero())
+		_, seen := r.counted.Load(req.NamespacedName)
+		Expect(seen).To(BeFalse())
+	})
+})
@@ -7,20 +7,29 @@ import (
 	"strconv"
 	"strings"
 
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/annotations"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 // SurgeApplier abstracts the mechanism for temporarily increasing minimum replicas.
-// Exactly one implementation is used per deployment, determined by detectSurgeApplier:
-//   - KEDASurgeApplier: when a KEDA ScaledObject targets the deployment
-//   - HPASurgeApplier: when a standalone HPA targets the deployment (no KEDA)
-//   - DeploymentSurgeApplier: when neither KEDA nor HPA is present
+// Exactly one implementation is used per EvictionAutoScaler. With the default
+// surgeMode it is determined by detectSurgeApplier:
+//   - KEDASurgeApplier: when a KEDA ScaledObject targets the deployment or rollout
+//   - HPASurgeApplier: when a standalone HPA targets the deployment or rollout (no KEDA)
+//   - ScaleSurgeApplier: when neither KEDA nor HPA is present, or for other kinds
+//
+// spec.surgeMode overrides detection; see surgeApplierFor. DeploymentSurgeApplier,
+// which writes the whole object, is only used when surgeMode is direct.
 //
 // KEDA + standalone HPA on the same target is unsupported and rejected by detectSurgeApplier.
 //
 // For autoscaler strategies (HPA, KEDA): the autoscaler floor is raised first, then
-// deployment replicas are set directly for immediate effect. On failure, the reconcile
+// deployment replicas are set through /scale for immediate effect. On failure, the reconcile
 // loop retries ApplySurge idempotently until the deployment write succeeds.
 type SurgeApplier interface {
 	// ApplySurge sets the minimum replica count to surgeReplicas.
@@ -40,63 +49,157 @@ type SurgeApplier interface {
 // by retrying — the reconciler should surface it on status and stop requeueing.
 var errUnsupportedAutoscalerConfig = errors.New("unsupported autoscaler configuration")
 
-// detectSurgeApplier determines which surge strategy to use based on the
-// autoscaler resources targeting this workload. The strategies are mutually
-// exclusive — exactly one applier is returned:
+// Surge modes selectable through EvictionAutoScaler spec.surgeMode.
+const (
+	SurgeModeAuto       = "auto"       // detect from the autoscalers targeting the workload (default)
+	SurgeModeDirect     = "direct"     // write spec.replicas on the target
+	SurgeModeScale      = "scale"      // write replicas through the target's /scale subresource
+	SurgeModeHPA        = "hpa"        // raise the minReplicas of the HPA targeting the workload
+	SurgeModeAnnotation = "annotation" // only annotate the target, for GitOps tooling to apply
+)
+
+// SurgeAnnotationKey set to "false" on a workload or its namespace keeps PDB
+// management but stops the controller from changing replicas. The workload's
+// value takes precedence over the namespace's.
+const SurgeAnnotationKey = annotations.Surge
+
+// surgeDisabled reports whether surging is turned off for target through
+// SurgeAnnotationKey on the target or its namespace.
+func surgeDisabled(ctx context.Context, c client.Client, target Surger) (bool, error) {
+	if val, ok := target.Obj().GetAnnotations()[SurgeAnnotationKey]; ok {
+		enabled, err := annotations.Bool(SurgeAnnotationKey, val)
+		return !enabled, err
+	}
+	namespace := &corev1.Namespace{}
+	if err := c.Get(ctx, types.NamespacedName{Name: target.Obj().GetNamespace()}, namespace); err != nil {
+		return false, fmt.Errorf("failed to get namespace %s: %w", target.Obj().GetNamespace(), err)
+	}
+	if val, ok := namespace.Annotations[SurgeAnnotationKey]; ok {
+		enabled, err := annotations.Bool(SurgeAnnotationKey, val)
+		return !enabled, err
+	}
+	return false, nil
+}
+
+// targetPaused reports whether target is a paused Deployment. Pausing only holds
+// back rollouts: the deployment controller still scales the existing ReplicaSets
+// when spec.replicas changes, so a surge works. Paused deployments are surged
+// through /scale, which changes replicas alone, rather than by rewriting an object
+// whose owner is in the middle of batching edits.
+func targetPaused(target Surger) bool {
+	deployment, ok := target.Obj().(*appsv1.Deployment)
+	return ok && deployment.Spec.Paused
+}
+
+// errInvalidSurgeMode is returned when spec.surgeMode is unknown or can't be used
+// with the target. Like errUnsupportedAutoscalerConfig it needs a user fix, not a retry.
+var errInvalidSurgeMode = errors.New("invalid surge mode")
+
+// surgeApplierFor returns the SurgeApplier selected by mode. An empty mode or
+// SurgeModeAuto falls back to detectSurgeApplier.
+func surgeApplierFor(ctx context.Context, c client.Client, mode, namespace, targetName, targetKind string, target Surger) (SurgeApplier, error) {
+	switch mode {
+	case "", SurgeModeAuto:
+		return detectSurgeApplier(ctx, c, namespace, targetName, targetKind, target)
+	case SurgeModeDirect:
+		if targetPaused(target) {
+			log.FromContext(ctx).Info("Target deployment is paused, surging through the scale subresource", "target", targetName)
+			return &ScaleSurgeApplier{client: c, target: target}, nil
+		}
+		return &DeploymentSurgeApplier{client: c, target: target}, nil
+	case SurgeModeScale:
+		return &ScaleSurgeApplier{client: c, target: target}, nil
+	case SurgeModeHPA:
+		hpa, err := findHPAForTarget(ctx, c, namespace, targetName, targetKind)
+		if errors.Is(err, errNotFound) {
+			return nil, fmt.Errorf("%w: surgeMode %q but no HPA targets %s %q", errInvalidSurgeMode, mode, targetKind, targetName)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("checking for HPA: %w", err)
+		}
+		return &HPASurgeApplier{client: c, hpa: hpa, target: target}, nil
+	case SurgeModeAnnotation:
+		return &AnnotationSurgeApplier{client: c, target: target}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", errInvalidSurgeMode, mode)
+	}
+}
+
+// detectSurgeApplier determines which surge strategy to use, first from the kind
+// of the target and then from the autoscaler resources targeting it. The strategies
+// are mutually exclusive — exactly one applier is returned:
 //
-//   - KEDA ScaledObject present → KEDASurgeApplier (raises minReplicaCount + sets deployment replicas)
-//   - Standalone HPA present (no KEDA) → HPASurgeApplier (raises minReplicas + sets deployment replicas)
-//   - Neither → DeploymentSurgeApplier (sets deployment replicas directly)
+//   - Deployment or Rollout → autoscalerSurgeApplier, falling back to ScaleSurgeApplier
+//   - Any other kind → ScaleSurgeApplier (sets replicas via the target's /scale subresource)
+func detectSurgeApplier(ctx context.Context, c client.Client, namespace, targetName, targetKind string, target Surger) (SurgeApplier, error) {
+	var applier SurgeApplier
+	var err error
+	switch myappsv1.TargetKind(targetKind).Normalized() {
+	case deploymentKind:
+		applier, err = autoscalerSurgeApplier(ctx, c, namespace, targetName, ResourceTypeDeployment, target)
+	case rolloutKind:
+		// Argo Rollouts are scaled by HPAs and KEDA ScaledObjects like Deployments,
+		// through scaleTargetRef kind Rollout.
+		applier, err = autoscalerSurgeApplier(ctx, c, namespace, targetName, rolloutGVK.Kind, target)
+	}
+	if err != nil || applier != nil {
+		return applier, err
+	}
+
+	// No autoscaler found — surge through the target's /scale subresource.
+	log.FromContext(ctx).V(1).Info("No KEDA or HPA found, using scale subresource surge strategy", "target", targetName)
+	return &ScaleSurgeApplier{client: c, target: target}, nil
+}
+
+// autoscalerSurgeApplier returns the applier for the autoscaler scaling the
+// workload of kind targetKind, or nil when none does:
+//
+//   - KEDA ScaledObject present → KEDASurgeApplier (raises minReplicaCount + sets the target's replicas)
+//   - Standalone HPA present (no KEDA) → HPASurgeApplier (raises minReplicas + sets the target's replicas)
 //
 // KEDA + standalone HPA on the same target is treated as unsupported. KEDA already
 // creates and owns its own HPA for the target, and validates against unmanaged HPAs
 // on the same scale target. If we detect both, we return an error — the eviction
 // autoscaler can't fix multiple-writer conflicts and shouldn't try. The reconciler
-// logs the error and skips the deployment. KEDA-managed HPAs (identified by
+// logs the error and skips the target. KEDA-managed HPAs (identified by
 // label/ownerRef) are always filtered out by findHPAForTarget and never reach this logic.
-func detectSurgeApplier(ctx context.Context, c client.Client, namespace, targetName, targetKind string, target Surger) (SurgeApplier, error) {
+func autoscalerSurgeApplier(ctx context.Context, c client.Client, namespace, targetName, targetKind string, target Surger) (SurgeApplier, error) {
 	logger := log.FromContext(ctx)
 
-	// HPA and KEDA only target Deployments; skip autoscaler detection for other kinds.
-	if strings.EqualFold(targetKind, ResourceTypeDeployment) {
-		// Check for KEDA ScaledObject targeting this workload
-		scaledObj, err := findScaledObjectForTarget(ctx, c, namespace, targetName, targetKind)
-		if err != nil && !errors.Is(err, errNotFound) {
-			return nil, fmt.Errorf("checking for KEDA ScaledObject: %w", err)
-		}
-		if scaledObj != nil {
-			// Reject if a standalone HPA also targets this deployment. This is an
-			// unsupported configuration — KEDA already owns an HPA for the target,
-			// and having an additional standalone HPA creates multiple-writer conflicts
-			// that the eviction autoscaler cannot resolve safely.
-			standaloneHPA, hpaErr := findHPAForTarget(ctx, c, namespace, targetName, targetKind)
-			if hpaErr == nil && standaloneHPA != nil {
-				return nil, fmt.Errorf("%w: both KEDA ScaledObject %q and "+
-					"standalone HPA %q target deployment %q in namespace %q — "+
-					"eviction autoscaler cannot safely surge with multiple autoscaler writers",
-					errUnsupportedAutoscalerConfig, scaledObj.GetName(), standaloneHPA.Name, targetName, namespace)
-			}
-
-			logger.Info("Found KEDA ScaledObject for target, using KEDA surge strategy",
-				"scaledObject", scaledObj.GetName(), "target", targetName)
-			return &KEDASurgeApplier{client: c, scaledObject: scaledObj, target: target}, nil
+	// Check for KEDA ScaledObject targeting this workload
+	scaledObj, err := findScaledObjectForTarget(ctx, c, namespace, targetName, targetKind)
+	if err != nil && !errors.Is(err, errNotFound) {
+		return nil, fmt.Errorf("checking for KEDA ScaledObject: %w", err)
+	}
+	if scaledObj != nil {
+		// Reject if a standalone HPA also targets this workload. This is an
+		// unsupported configuration — KEDA already owns an HPA for the target,
+		// and having an additional standalone HPA creates multiple-writer conflicts
+		// that the eviction autoscaler cannot resolve safely.
+		standaloneHPA, hpaErr := findHPAForTarget(ctx, c, namespace, targetName, targetKind)
+		if hpaErr == nil && standaloneHPA != nil {
+			return nil, fmt.Errorf("%w: both KEDA ScaledObject %q and "+
+				"standalone HPA %q target %s %q in namespace %q — "+
+				"eviction autoscaler cannot safely surge with multiple autoscaler writers",
+				errUnsupportedAutoscalerConfig, scaledObj.GetName(), standaloneHPA.Name, strings.ToLower(targetKind), targetName, namespace)
 		}
 
-		// No KEDA — check for standalone HPA
-		hpa, err := findHPAForTarget(ctx, c, namespace, targetName, targetKind)
-		if err != nil && !errors.Is(err, errNotFound) {
-			return nil, fmt.Errorf("checking for HPA: %w", err)
-		}
-		if hpa != nil {
-			logger.Info("Found standalone HPA for target, using HPA surge strategy",
-				"hpa", hpa.Name, "target", targetName)
-			return &HPASurgeApplier{client: c, hpa: hpa, target: target}, nil
-		}
+		logger.Info("Found KEDA ScaledObject for target, using KEDA surge strategy",
+			"scaledObject", scaledObj.GetName(), "target", targetName)
+		return &KEDASurgeApplier{client: c, scaledObject: scaledObj, target: target}, nil
 	}
 
-	// No autoscaler found — surge by modifying deployment replicas directly.
-	logger.V(1).Info("No KEDA or HPA found, using deployment surge strategy", "target", targetName)
-	return &DeploymentSurgeApplier{client: c, target: target}, nil
+	// No KEDA — check for standalone HPA
+	hpa, err := findHPAForTarget(ctx, c, namespace, targetName, targetKind)
+	if err != nil && !errors.Is(err, errNotFound) {
+		return nil, fmt.Errorf("checking for HPA: %w", err)
+	}
+	if hpa != nil {
+		logger.Info("Found standalone HPA for target, using HPA surge strategy",
+			"hpa", hpa.Name, "target", targetName)
+		return &HPASurgeApplier{client: c, hpa: hpa, target: target}, nil
+	}
+	return nil, nil
 }
 
 // hasTargetAnnotationWithValue checks if the target has the evictionSurgeReplicas annotation
@@ -120,27 +223,69 @@ func hasTargetAnnotation(target Surger) bool {
 	return exists
 }
 
+// withoutSurgeMarker stops applier from marking the target with the
+// evictionSurgeReplicas annotation, so surging the target only writes its replicas.
+// held, read from the EvictionAutoScaler's status, stands in for the marker. A marker
+// left from before is still honored and removed on revert. The HPA and KEDA appliers
+// keep their marker on the autoscaler, which they write anyway.
+func withoutSurgeMarker(applier SurgeApplier, held bool) {
+	switch a := applier.(type) {
+	case *ScaleSurgeApplier:
+		a.unmarked, a.held = true, held
+	case *DeploymentSurgeApplier:
+		a.unmarked, a.held = true, held
+	}
+}
+
+// withScaleDownFloor stops the HPA and KEDA appliers from reverting the
+// autoscaler's minimum below floor, the EvictionAutoScaler's spec.minReplicas. They
+// revert to the minimum they recorded on the autoscaler rather than the
+// originalMinReplicas RevertSurge is passed, so the floor would otherwise be lost.
+// The other appliers revert to originalMinReplicas, which already includes it.
+func withScaleDownFloor(applier SurgeApplier, floor *int32) {
+	switch a := applier.(type) {
+	case *HPASurgeApplier:
+		a.floor = floor
+	case *KEDASurgeApplier:
+		a.floor = floor
+	}
+}
+
 // --- DeploymentSurgeApplier ---
-// Surges by modifying the deployment/statefulset spec.replicas directly.
-// This is the default strategy when no KEDA or HPA is present.
+// Surges by modifying the deployment/statefulset spec.replicas with a full Update.
+// Only used with surgeMode: direct; ScaleSurgeApplier is the default when no KEDA
+// or HPA is present.
 
 type DeploymentSurgeApplier struct {
 	client client.Client
 	target Surger
+	// unmarked and held are set by withoutSurgeMarker.
+	unmarked bool
+	held     bool
 }
 
 var _ SurgeApplier = &DeploymentSurgeApplier{}
 
 func (d *DeploymentSurgeApplier) ApplySurge(ctx context.Context, surgeReplicas int32) error {
 	d.target.SetReplicas(surgeReplicas)
-	d.target.AddAnnotation(EvictionSurgeReplicasAnnotationKey, strconv.FormatInt(int64(surgeReplicas), 10))
-	return d.client.Update(ctx, d.target.Obj())
+	if !d.unmarked {
+		d.target.AddAnnotation(EvictionSurgeReplicasAnnotationKey, strconv.FormatInt(int64(surgeReplicas), 10))
+	}
+	if err := d.client.Update(ctx, d.target.Obj()); err != nil {
+		return err
+	}
+	d.held = true
+	return nil
 }
 
 func (d *DeploymentSurgeApplier) RevertSurge(ctx context.Context, originalMinReplicas int32) error {
 	d.target.SetReplicas(originalMinReplicas)
 	d.target.RemoveAnnotation(EvictionSurgeReplicasAnnotationKey)
-	return d.client.Update(ctx, d.target.Obj())
+	if err := d.client.Update(ctx, d.target.Obj()); err != nil {
+		return err
+	}
+	d.held = false
+	return nil
 }
 
 func (d *DeploymentSurgeApplier) Name() string {
@@ -148,5 +293,5 @@ func (d *DeploymentSurgeApplier) Name() string {
 }
 
 func (d *DeploymentSurgeApplier) IsSurgeActive() bool {
-	return hasTargetAnnotation(d.target)
+	return hasTargetAnnotation(d.target) || d.held
 }
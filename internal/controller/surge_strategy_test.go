@@ -2,14 +2,28 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
 
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/namespacefilter"
 )
 
 var _ = Describe("DeploymentSurgeApplier", func() {
@@ -74,6 +88,43 @@ var _ = Describe("DeploymentSurgeApplier", func() {
 	})
 })
 
+var _ = Describe("withoutSurgeMarker", func() {
+	It("should surge and revert without annotating the target", func() {
+		ctx := context.Background()
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{GenerateName: "test-unmarked-"}}
+		Expect(k8sClient.Create(ctx, ns)).To(Succeed())
+		maxUnavailable := intstr.FromInt(0)
+		dep := createDeployment("unmarked", ns.Name, "unmarked", 1, &maxUnavailable)
+		Expect(k8sClient.Create(ctx, dep)).To(Succeed())
+
+		applier := &DeploymentSurgeApplier{client: k8sClient, target: &DeploymentWrapper{obj: dep}}
+		withoutSurgeMarker(applier, false)
+		Expect(applier.IsSurgeActive()).To(BeFalse())
+
+		Expect(applier.ApplySurge(ctx, 3)).To(Succeed())
+		Expect(applier.IsSurgeActive()).To(BeTrue())
+		var updated appsv1.Deployment
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(dep), &updated)).To(Succeed())
+		Expect(*updated.Spec.Replicas).To(Equal(int32(3)))
+		Expect(updated.Annotations).ToNot(HaveKey(EvictionSurgeReplicasAnnotationKey))
+
+		Expect(applier.RevertSurge(ctx, 1)).To(Succeed())
+		Expect(applier.IsSurgeActive()).To(BeFalse())
+	})
+
+	It("should take a surge recorded in status for an active one", func() {
+		dep := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "test"}}
+		applier := &ScaleSurgeApplier{target: &DeploymentWrapper{obj: dep}}
+		withoutSurgeMarker(applier, true)
+		Expect(applier.IsSurgeActive()).To(BeTrue())
+
+		// A marker written before the switch still counts.
+		dep.Annotations = map[string]string{EvictionSurgeReplicasAnnotationKey: "3"}
+		withoutSurgeMarker(applier, false)
+		Expect(applier.IsSurgeActive()).To(BeTrue())
+	})
+})
+
 var _ = Describe("hasTargetAnnotation", func() {
 	It("should return false when annotations are nil", func() {
 		dep := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "test"}}
@@ -125,3 +176,145 @@ var _ = Describe("hasTargetAnnotationWithValue", func() {
 		Expect(hasTargetAnnotationWithValue(target, "3")).To(BeTrue())
 	})
 })
+
+var _ = Describe("surgeDisabled", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+	})
+
+	check := func(nsAnnotations, depAnnotations map[string]string) (bool, error) {
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default", Annotations: nsAnnotations}}
+		dep := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default", Annotations: depAnnotations}}
+		fc := fake.NewClientBuilder().WithScheme(scheme).WithObjects(ns).Build()
+		return surgeDisabled(ctx, fc, &DeploymentWrapper{obj: dep})
+	}
+
+	It("should allow surging without annotations", func() {
+		disabled, err := check(nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(disabled).To(BeFalse())
+	})
+
+	It("should honor the namespace annotation", func() {
+		disabled, err := check(map[string]string{SurgeAnnotationKey: "false"}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(disabled).To(BeTrue())
+	})
+
+	It("should let the deployment annotation override the namespace", func() {
+		disabled, err := check(map[string]string{SurgeAnnotationKey: "false"}, map[string]string{SurgeAnnotationKey: "true"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(disabled).To(BeFalse())
+	})
+
+	It("should reject an invalid value", func() {
+		_, err := check(nil, map[string]string{SurgeAnnotationKey: "nope"})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should honor the annotation on a statefulset", func() {
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+		sts := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default",
+			Annotations: map[string]string{SurgeAnnotationKey: "false"}}}
+		fc := fake.NewClientBuilder().WithScheme(scheme).WithObjects(ns).Build()
+		disabled, err := surgeDisabled(ctx, fc, &StatefulSetWrapper{obj: sts})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(disabled).To(BeTrue())
+	})
+})
+
+var _ = Describe("Paused deployments", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+		key    = client.ObjectKey{Namespace: "default", Name: "web"}
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		Expect(policyv1.AddToScheme(scheme)).To(Succeed())
+		Expect(autoscalingv1.AddToScheme(scheme)).To(Succeed())
+		Expect(autoscalingv2.AddToScheme(scheme)).To(Succeed())
+		Expect(kedav1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(myappsv1.AddToScheme(scheme)).To(Succeed())
+	})
+
+	deployment := func() *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Generation: 1},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: ptr.To(int32(2)),
+				Paused:   true,
+				Strategy: appsv1.DeploymentStrategy{RollingUpdate: &appsv1.RollingUpdateDeployment{MaxSurge: ptr.To(intstr.FromInt32(1))}},
+			},
+		}
+	}
+
+	// scalePatches applies /scale patches to the deployment, which the fake client
+	// can't do itself.
+	scalePatches := interceptor.Funcs{SubResourcePatch: func(ctx context.Context, c client.Client, subResource string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+		data, err := patch.Data(obj)
+		Expect(err).ToNot(HaveOccurred())
+		var scale autoscalingv1.Scale
+		Expect(json.Unmarshal(data, &scale)).To(Succeed())
+		var dep appsv1.Deployment
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(obj), &dep)).To(Succeed())
+		dep.Spec.Replicas = ptr.To(scale.Spec.Replicas)
+		return c.Update(ctx, &dep)
+	}}
+
+	It("should surge a paused deployment through the scale subresource in direct mode", func() {
+		fc := fake.NewClientBuilder().WithScheme(scheme).WithObjects(deployment()).WithInterceptorFuncs(scalePatches).Build()
+		var dep appsv1.Deployment
+		Expect(fc.Get(ctx, key, &dep)).To(Succeed())
+
+		applier, err := surgeApplierFor(ctx, fc, SurgeModeDirect, "default", "web", ResourceTypeDeployment, &DeploymentWrapper{obj: &dep})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(applier).To(BeAssignableToTypeOf(&ScaleSurgeApplier{}))
+
+		Expect(applier.ApplySurge(ctx, 3)).To(Succeed())
+		Expect(fc.Get(ctx, key, &dep)).To(Succeed())
+		Expect(*dep.Spec.Replicas).To(Equal(int32(3)))
+		Expect(dep.Spec.Paused).To(BeTrue())
+	})
+
+	It("should surge a paused deployment on eviction and record that it is paused", func() {
+		eas := &myappsv1.EvictionAutoScaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: myappsv1.EvictionAutoScalerSpec{TargetName: "web", TargetKind: myappsv1.TargetKindDeployment,
+				LastEviction: myappsv1.Eviction{PodName: "web-1", EvictionTime: metav1.Now()}},
+			Status: myappsv1.EvictionAutoScalerStatus{MinReplicas: 2, TargetGeneration: 1},
+		}
+		fc := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}, Spec: corev1.NodeSpec{Unschedulable: true}},
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default", Labels: map[string]string{"app": "web"}},
+				Spec: corev1.PodSpec{NodeName: "node-1"}},
+			deployment(),
+			&policyv1.PodDisruptionBudget{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+				Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+			},
+			eas,
+		).WithStatusSubresource(&myappsv1.EvictionAutoScaler{}).WithInterceptorFuncs(scalePatches).Build()
+		recorder := record.NewFakeRecorder(10)
+		r := &EvictionAutoScalerReconciler{Client: fc, Scheme: scheme, Filter: namespacefilter.New(nil, false), APIReader: fc, Recorder: recorder}
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+
+		var dep appsv1.Deployment
+		Expect(fc.Get(ctx, key, &dep)).To(Succeed())
+		Expect(*dep.Spec.Replicas).To(Equal(int32(3)))
+		Expect(recorder.Events).To(Receive(ContainSubstring("TargetPaused")))
+	})
+})
@@ -0,0 +1,224 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// topologyInfeasibleReason is the Ready reason of an EvictionAutoScaler whose surge
+// was skipped because the target's topology constraints leave no room for it.
+const topologyInfeasibleReason = "SurgeInfeasibleTopology"
+
+// surgeTopologyFit reports how many of extra more pods selected by pdb the
+// scheduler could place under their hard topology constraints, and, when that is
+// fewer than extra, which constraint holds them back. A surge pod the constraints
+// rule out stays Pending however much capacity the cluster has, e.g. when the
+// spread across zones can't take another pod while a zone's nodes are cordoned.
+//
+// Pods are placed like a running pod of the target. Only topology spread
+// constraints with whenUnsatisfiable DoNotSchedule and required pod anti-affinity
+// against pods in the same namespace are checked; domains are the nodes matching
+// the pod's node selector and required node affinity, and a domain takes new pods
+// only while one of its nodes is schedulable and its taints are tolerated.
+func surgeTopologyFit(ctx context.Context, c client.Reader, pdb *policyv1.PodDisruptionBudget, extra int32) (int32, string, error) {
+	if extra <= 0 {
+		return extra, "", nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid PDB selector: %w", err)
+	}
+	var selected corev1.PodList
+	if err := c.List(ctx, &selected, client.InNamespace(pdb.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return 0, "", fmt.Errorf("failed to list pods for PDB %s: %w", pdb.Name, err)
+	}
+	i := slices.IndexFunc(selected.Items, func(p corev1.Pod) bool { return p.DeletionTimestamp == nil })
+	if i < 0 {
+		return extra, "", nil
+	}
+	pod := &selected.Items[i]
+	spreads := slices.DeleteFunc(slices.Clone(pod.Spec.TopologySpreadConstraints), func(tsc corev1.TopologySpreadConstraint) bool {
+		return tsc.WhenUnsatisfiable != corev1.DoNotSchedule
+	})
+	antiAffinity := requiredAntiAffinity(pod)
+	if len(spreads) == 0 && len(antiAffinity) == 0 {
+		return extra, "", nil
+	}
+
+	var nodes corev1.NodeList
+	if err := c.List(ctx, &nodes); err != nil {
+		return 0, "", fmt.Errorf("failed to list nodes: %w", err)
+	}
+	var pods corev1.PodList
+	if err := c.List(ctx, &pods, client.InNamespace(pdb.Namespace)); err != nil {
+		return 0, "", fmt.Errorf("failed to list pods in %s: %w", pdb.Namespace, err)
+	}
+	eligible := map[string]*corev1.Node{}
+	for i := range nodes.Items {
+		if node := &nodes.Items[i]; nodeAffinityMatches(&pod.Spec, node) {
+			eligible[node.Name] = node
+		}
+	}
+
+	fits, reason := extra, ""
+	for _, tsc := range spreads {
+		s, err := metav1.LabelSelectorAsSelector(tsc.LabelSelector)
+		if err != nil {
+			continue
+		}
+		counts, open := topologyDomains(pod, eligible, pods.Items, tsc.TopologyKey, s)
+		globalMin := func() int32 {
+			if tsc.MinDomains != nil && int32(len(counts)) < *tsc.MinDomains {
+				return 0
+			}
+			return minCount(counts)
+		}
+		var placed int32
+		for ; placed < fits; placed++ {
+			domain, ok := minDomain(counts, open)
+			if !ok || counts[domain]+1-globalMin() > tsc.MaxSkew {
+				break
+			}
+			if s.Matches(labels.Set(pod.Labels)) {
+				counts[domain]++
+			}
+		}
+		if placed < fits {
+			fits = placed
+			reason = fmt.Sprintf("topology spread on %s (maxSkew %d) leaves room for %d of %d surge pods", tsc.TopologyKey, tsc.MaxSkew, fits, extra)
+		}
+	}
+	for _, term := range antiAffinity {
+		s, err := metav1.LabelSelectorAsSelector(term.LabelSelector)
+		if err != nil {
+			continue
+		}
+		counts, open := topologyDomains(pod, eligible, pods.Items, term.TopologyKey, s)
+		var free int32
+		for domain := range open {
+			if counts[domain] == 0 {
+				free++
+			}
+		}
+		// Surge pods only rule each other out when the term selects them too.
+		placed := fits
+		if s.Matches(labels.Set(pod.Labels)) || free == 0 {
+			placed = min(fits, free)
+		}
+		if placed < fits {
+			fits = placed
+			reason = fmt.Sprintf("required pod anti-affinity on %s leaves room for %d of %d surge pods", term.TopologyKey, fits, extra)
+		}
+	}
+	if reason != "" {
+		log.FromContext(ctx).V(1).Info("Topology constraints limit the surge", "pdb", pdb.Name, "extra", extra, "fits", fits, "reason", reason)
+	}
+	return fits, reason, nil
+}
+
+// requiredAntiAffinity returns pod's required anti-affinity terms that apply to
+// pods in its own namespace.
+func requiredAntiAffinity(pod *corev1.Pod) []corev1.PodAffinityTerm {
+	if pod.Spec.Affinity == nil || pod.Spec.Affinity.PodAntiAffinity == nil {
+		return nil
+	}
+	var terms []corev1.PodAffinityTerm
+	for _, term := range pod.Spec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+		if term.NamespaceSelector == nil && (len(term.Namespaces) == 0 || slices.Contains(term.Namespaces, pod.Namespace)) {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// topologyDomains counts the pods matching selector per value of key over the
+// eligible nodes, and returns the domains that can take a new pod.
+func topologyDomains(pod *corev1.Pod, eligible map[string]*corev1.Node, pods []corev1.Pod, key string, selector labels.Selector) (map[string]int32, map[string]bool) {
+	counts, open := map[string]int32{}, map[string]bool{}
+	for _, node := range eligible {
+		domain, ok := node.Labels[key]
+		if !ok {
+			continue
+		}
+		if _, seen := counts[domain]; !seen {
+			counts[domain] = 0
+		}
+		if !node.Spec.Unschedulable && toleratesNode(&pod.Spec, node) {
+			open[domain] = true
+		}
+	}
+	for i := range pods {
+		p := &pods[i]
+		node, ok := eligible[p.Spec.NodeName]
+		if !ok || p.DeletionTimestamp != nil || !selector.Matches(labels.Set(p.Labels)) {
+			continue
+		}
+		if domain, ok := node.Labels[key]; ok {
+			counts[domain]++
+		}
+	}
+	return counts, open
+}
+
+// minDomain returns the domain in open with the fewest pods, by name on a tie.
+func minDomain(counts map[string]int32, open map[string]bool) (string, bool) {
+	var best string
+	found := false
+	for domain := range open {
+		if !found || counts[domain] < counts[best] || (counts[domain] == counts[best] && domain < best) {
+			best, found = domain, true
+		}
+	}
+	return best, found
+}
+
+// minCount returns the fewest pods in any domain.
+func minCount(counts map[string]int32) int32 {
+	var lowest int32
+	first := true
+	for _, count := range counts {
+		if first || count < lowest {
+			lowest, first = count, false
+		}
+	}
+	return lowest
+}
+
+// nodeAffinityMatches reports whether node satisfies spec's node selector and
+// required node affinity.
+func nodeAffinityMatches(spec *corev1.PodSpec, node *corev1.Node) bool {
+	if !labels.SelectorFromSet(spec.NodeSelector).Matches(labels.Set(node.Labels)) {
+		return false
+	}
+	if spec.Affinity != nil && spec.Affinity.NodeAffinity != nil {
+		if required := spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution; required != nil &&
+			!slices.ContainsFunc(required.NodeSelectorTerms, func(term corev1.NodeSelectorTerm) bool {
+				return nodeSelectorTermMatches(term, node)
+			}) {
+			return false
+		}
+	}
+	return true
+}
+
+// toleratesNode reports whether spec tolerates every NoSchedule and NoExecute taint
+// of node.
+func toleratesNode(spec *corev1.PodSpec, node *corev1.Node) bool {
+	for _, taint := range node.Spec.Taints {
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		if !slices.ContainsFunc(spec.Tolerations, func(t corev1.Toleration) bool { return t.ToleratesTaint(log.Log, &taint, false) }) {
+			return false
+		}
+	}
+	return true
+}
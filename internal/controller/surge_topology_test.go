@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Surge topology feasibility", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(policyv1.AddToScheme(scheme)).To(Succeed())
+	})
+
+	webLabels := map[string]string{"app": "web"}
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: webLabels}},
+	}
+	node := func(name, zone string, cordoned bool) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{
+				corev1.LabelTopologyZone: zone, corev1.LabelHostname: name,
+			}},
+			Spec: corev1.NodeSpec{Unschedulable: cordoned},
+		}
+	}
+	pod := func(name, nodeName string, spec corev1.PodSpec) *corev1.Pod {
+		spec.NodeName = nodeName
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: webLabels}, Spec: spec}
+	}
+	build := func(objs ...client.Object) client.Client {
+		return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+	}
+
+	It("should surge everything when the pods have no hard topology constraints", func() {
+		c := build(node("a-1", "a", false), pod("web-1", "a-1", corev1.PodSpec{}))
+		fits, reason, err := surgeTopologyFit(ctx, c, pdb, 3)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fits).To(Equal(int32(3)))
+		Expect(reason).To(BeEmpty())
+	})
+
+	It("should stop at the skew a cordoned zone leaves", func() {
+		spread := corev1.PodSpec{TopologySpreadConstraints: []corev1.TopologySpreadConstraint{{
+			MaxSkew:           1,
+			TopologyKey:       corev1.LabelTopologyZone,
+			WhenUnsatisfiable: corev1.DoNotSchedule,
+			LabelSelector:     &metav1.LabelSelector{MatchLabels: webLabels},
+		}}}
+		c := build(node("a-1", "a", false), node("b-1", "b", false), node("c-1", "c", true),
+			pod("web-a", "a-1", spread), pod("web-b", "b-1", spread), pod("web-c", "c-1", spread))
+
+		fits, reason, err := surgeTopologyFit(ctx, c, pdb, 3)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fits).To(Equal(int32(2)))
+		Expect(reason).To(ContainSubstring("topology spread on " + corev1.LabelTopologyZone))
+	})
+
+	It("should count the free hosts under required anti-affinity", func() {
+		antiAffinity := corev1.PodSpec{Affinity: &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+				TopologyKey:   corev1.LabelHostname,
+				LabelSelector: &metav1.LabelSelector{MatchLabels: webLabels},
+			}},
+		}}}
+		c := build(node("n-1", "a", true), node("n-2", "a", false), node("n-3", "b", false),
+			pod("web-1", "n-1", antiAffinity), pod("web-2", "n-2", antiAffinity))
+
+		fits, reason, err := surgeTopologyFit(ctx, c, pdb, 2)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fits).To(Equal(int32(1)))
+		Expect(reason).To(ContainSubstring("anti-affinity"))
+	})
+})
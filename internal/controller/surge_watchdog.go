@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// surgeStuckPending reports whether the oldest of the pending pods of the active
+// surge, created at oldest, has been Pending longer than SurgePendingDeadline, and
+// keeps the SurgeStuckPending condition in step. Crossing the deadline emits a
+// warning event on the EvictionAutoScaler and on the target. The check only runs
+// while the EvictionAutoScaler is reconciled, which a held surge does at least
+// once per cooldown.
+func (r *EvictionAutoScalerReconciler) surgeStuckPending(ctx context.Context, eas *myappsv1.EvictionAutoScaler,
+	target Surger, pending int, oldest, now time.Time) bool {
+	if r.SurgePendingDeadline <= 0 {
+		return false
+	}
+	if pending == 0 || now.Sub(oldest) < r.SurgePendingDeadline {
+		setSurgeStuckPending(eas, "")
+		return false
+	}
+	msg := fmt.Sprintf("%d surge pods of %s pending for longer than %s", pending, eas.Spec.TargetName, r.SurgePendingDeadline)
+	if !meta.IsStatusConditionTrue(eas.Status.Conditions, SurgeStuckPendingCondition) {
+		log.FromContext(ctx).Info("Surge pods stuck pending", "target", eas.Spec.TargetName, "pending", pending,
+			"oldestCreated", oldest, "deadline", r.SurgePendingDeadline)
+		r.event(eas, corev1.EventTypeWarning, "SurgeStuckPending", msg)
+		r.event(target.Obj(), corev1.EventTypeWarning, "SurgeStuckPending", msg)
+	}
+	setSurgeStuckPending(eas, msg)
+	return true
+}
+
+// rollBackStuckSurge reverts a surge whose pods are stuck Pending, so they stop
+// adding FailedScheduling noise and autoscaler pressure on a cluster that has no
+// room for them. The eviction it was made for counts as handled. SurgeStuckPending
+// stays True until the next surge starts, which rolledBackRemaining holds off for
+// one SurgePendingDeadline.
+func (r *EvictionAutoScalerReconciler) rollBackStuckSurge(ctx context.Context, eas *myappsv1.EvictionAutoScaler,
+	target Surger, surgeApplier SurgeApplier) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	if err := r.revertSurge(ctx, eas, target, surgeApplier); err != nil {
+		return ctrl.Result{}, err
+	}
+	logger.Info("Rolled back surge stuck pending", "target", eas.Spec.TargetName, "minReplicas", scaleDownFloor(eas))
+	r.event(eas, corev1.EventTypeWarning, "SurgeRolledBack",
+		fmt.Sprintf("surge pods could not be scheduled, rolled back %s to %d replicas", eas.Spec.TargetName, scaleDownFloor(eas)))
+
+	eas.Status.LastEviction = eas.Spec.LastEviction
+	eas.Status.CooldownUntil = nil
+	ready(eas, "SurgeRolledBack", "surge pods stuck pending so rolled back")
+	return ctrl.Result{}, r.Status().Update(ctx, eas)
+}
+
+// rolledBackRemaining returns how long new surges are still held after a stuck
+// surge was rolled back: one SurgePendingDeadline from the rollback, so a drain
+// retrying its evictions doesn't surge straight back into the same shortage.
+func (r *EvictionAutoScalerReconciler) rolledBackRemaining(eas *myappsv1.EvictionAutoScaler, now time.Time) time.Duration {
+	if !r.RollbackStuckSurges || eas.Status.SurgeActive {
+		return 0
+	}
+	stuck := meta.FindStatusCondition(eas.Status.Conditions, SurgeStuckPendingCondition)
+	if stuck == nil || stuck.Status != metav1.ConditionTrue {
+		return 0
+	}
+	return max(stuck.LastTransitionTime.Add(r.SurgePendingDeadline).Sub(now), 0)
+}
@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+)
+
+var _ = Describe("Surge pending watchdog", func() {
+	var (
+		ctx        context.Context
+		now        time.Time
+		eas        *myappsv1.EvictionAutoScaler
+		deployment *appsv1.Deployment
+		recorder   *record.FakeRecorder
+		r          *EvictionAutoScalerReconciler
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		now = time.Now().Truncate(time.Second)
+		eviction := myappsv1.Eviction{PodName: "web-1", EvictionTime: metav1.NewTime(now.Add(-time.Hour))}
+		eas = &myappsv1.EvictionAutoScaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Finalizers: []string{SurgeFinalizer}},
+			Spec:       myappsv1.EvictionAutoScalerSpec{TargetName: "web", TargetKind: myappsv1.TargetKindDeployment, LastEviction: eviction},
+			Status:     myappsv1.EvictionAutoScalerStatus{MinReplicas: 2},
+		}
+		markSurge(&eas.Status, 3)
+		deployment = &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default",
+				Annotations: map[string]string{EvictionSurgeReplicasAnnotationKey: "3"}},
+			Spec: appsv1.DeploymentSpec{Replicas: ptr.To[int32](3)},
+		}
+		recorder = record.NewFakeRecorder(10)
+		r = &EvictionAutoScalerReconciler{Recorder: recorder, SurgePendingDeadline: 10 * time.Minute}
+	})
+
+	It("should do nothing without a deadline", func() {
+		r.SurgePendingDeadline = 0
+		Expect(r.surgeStuckPending(ctx, eas, &DeploymentWrapper{obj: deployment}, 1, now.Add(-time.Hour), now)).To(BeFalse())
+		Expect(meta.FindStatusCondition(eas.Status.Conditions, SurgeStuckPendingCondition)).To(BeNil())
+	})
+
+	It("should report pods pending past the deadline once, on the EvictionAutoScaler and the target", func() {
+		target := &DeploymentWrapper{obj: deployment}
+		Expect(r.surgeStuckPending(ctx, eas, target, 1, now.Add(-5*time.Minute), now)).To(BeFalse())
+		Expect(meta.IsStatusConditionFalse(eas.Status.Conditions, SurgeStuckPendingCondition)).To(BeTrue())
+		Expect(recorder.Events).To(BeEmpty())
+
+		Expect(r.surgeStuckPending(ctx, eas, target, 2, now.Add(-11*time.Minute), now)).To(BeTrue())
+		cond := meta.FindStatusCondition(eas.Status.Conditions, SurgeStuckPendingCondition)
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Message).To(ContainSubstring("2 surge pods"))
+		Expect(recorder.Events).To(HaveLen(2))
+		Expect(<-recorder.Events).To(ContainSubstring("Warning SurgeStuckPending"))
+
+		// Still stuck on the next reconcile: no new events.
+		<-recorder.Events
+		Expect(r.surgeStuckPending(ctx, eas, target, 2, now.Add(-11*time.Minute), now.Add(time.Minute))).To(BeTrue())
+		Expect(recorder.Events).To(BeEmpty())
+	})
+
+	It("should roll back a stuck surge and count its eviction as handled", func() {
+		scheme := runtime.NewScheme()
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		Expect(myappsv1.AddToScheme(scheme)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(eas, deployment).
+			WithStatusSubresource(&myappsv1.EvictionAutoScaler{}).Build()
+		r.Client = c
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(eas), eas)).To(Succeed())
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(deployment), deployment)).To(Succeed())
+		target := &DeploymentWrapper{obj: deployment}
+		Expect(r.surgeStuckPending(ctx, eas, target, 1, now.Add(-time.Hour), now)).To(BeTrue())
+
+		_, err := r.rollBackStuckSurge(ctx, eas, target, &DeploymentSurgeApplier{client: c, target: target})
+		Expect(err).ToNot(HaveOccurred())
+
+		var reverted appsv1.Deployment
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(deployment), &reverted)).To(Succeed())
+		Expect(*reverted.Spec.Replicas).To(Equal(int32(2)))
+		Expect(reverted.Annotations).ToNot(HaveKey(EvictionSurgeReplicasAnnotationKey))
+
+		var updated myappsv1.EvictionAutoScaler
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(eas), &updated)).To(Succeed())
+		Expect(updated.Finalizers).To(BeEmpty())
+		Expect(updated.Status.SurgeActive).To(BeFalse())
+		Expect(updated.Status.LastEviction).To(Equal(updated.Spec.LastEviction))
+		Expect(meta.FindStatusCondition(updated.Status.Conditions, ReadyCondition).Reason).To(Equal("SurgeRolledBack"))
+		Expect(meta.IsStatusConditionTrue(updated.Status.Conditions, SurgeStuckPendingCondition)).To(BeTrue())
+
+		// New surges are held for one deadline after the rollback.
+		Expect(r.rolledBackRemaining(&updated, time.Now())).To(BeZero())
+		r.RollbackStuckSurges = true
+		Expect(r.rolledBackRemaining(&updated, time.Now())).To(BeNumerically("~", r.SurgePendingDeadline, time.Minute))
+		Expect(r.rolledBackRemaining(&updated, time.Now().Add(r.SurgePendingDeadline))).To(BeZero())
+	})
+})
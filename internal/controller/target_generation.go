@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+)
+
+// targetSpecHash hashes the spec of target without its replicas, so a change that
+// only scaled the target hashes the same. Maps marshal with sorted keys, which keeps
+// the hash stable across reads.
+func targetSpecHash(target Surger) (string, error) {
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(target.Obj())
+	if err != nil {
+		return "", err
+	}
+	spec, _ := obj["spec"].(map[string]interface{})
+	delete(spec, "replicas")
+	return hashJSON(spec)
+}
+
+// podTemplateHash hashes the pod template and replica count of target, the parts of
+// its spec its owner changes on purpose. Unlike metadata.generation it doesn't move
+// when a rollout controller rewrites the rest of the spec, and it doesn't restart
+// when the target is recreated.
+func podTemplateHash(target Surger) (string, error) {
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(target.Obj())
+	if err != nil {
+		return "", err
+	}
+	spec, _ := obj["spec"].(map[string]interface{})
+	return hashJSON(map[string]interface{}{"template": spec["template"], "replicas": target.GetReplicas()})
+}
+
+func hashJSON(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	h := fnv.New64a()
+	h.Write(data)
+	return fmt.Sprintf("%016x", h.Sum64()), nil
+}
+
+// Values of spec.changeDetection.
+const (
+	ChangeDetectionGeneration  = "generation"
+	ChangeDetectionPodTemplate = "podTemplate"
+)
+
+// targetChanged reports whether target changed since the status of eas recorded it,
+// as selected by spec.changeDetection. A target never recorded counts as changed.
+func targetChanged(eas *myappsv1.EvictionAutoScaler, target Surger) bool {
+	if eas.Spec.ChangeDetection == ChangeDetectionPodTemplate {
+		hash, err := podTemplateHash(target)
+		return err != nil || eas.Status.PodTemplateHash == "" || hash != eas.Status.PodTemplateHash
+	}
+	return eas.Status.TargetGeneration == 0 || eas.Status.TargetGeneration != target.Obj().GetGeneration()
+}
+
+// recordTarget records the generation and hashes of target that the status of eas
+// now reflects. A hash that can't be computed is recorded empty and never matches, so
+// the target then counts as edited by its owner.
+func recordTarget(status *myappsv1.EvictionAutoScalerStatus, target Surger) {
+	status.TargetGeneration = target.Obj().GetGeneration()
+	status.TargetSpecHash, _ = targetSpecHash(target)
+	status.PodTemplateHash, _ = podTemplateHash(target)
+}
+
+// scaledBySurge reports whether the only change to target since status was recorded
+// is the replica count of a surge that status held, surgedTo. The surge's /scale
+// write bumps the generation after it is recorded, so when the surge marker is lost
+// before the revert, the change would otherwise be taken for the owner's and the surge
+// count become the new MinReplicas.
+func scaledBySurge(status *myappsv1.EvictionAutoScalerStatus, target Surger, surgedTo int32) bool {
+	if surgedTo == 0 || status.TargetSpecHash == "" || target.GetReplicas() != surgedTo {
+		return false
+	}
+	hash, err := targetSpecHash(target)
+	return err == nil && hash == status.TargetSpecHash
+}
@@ -0,0 +1,187 @@
+package controllers
+
+import (
+	"context"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/namespacefilter"
+)
+
+var _ = Describe("Target generation tracking", func() {
+	var (
+		ctx             context.Context
+		scheme          *runtime.Scheme
+		changeDetection string
+		key             = client.ObjectKey{Namespace: "default", Name: "web"}
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		changeDetection = ""
+		scheme = runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		Expect(policyv1.AddToScheme(scheme)).To(Succeed())
+		Expect(autoscalingv2.AddToScheme(scheme)).To(Succeed())
+		Expect(kedav1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(myappsv1.AddToScheme(scheme)).To(Succeed())
+	})
+
+	deployment := func(replicas int32, image string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Generation: 2},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: ptr.To(replicas),
+				Strategy: appsv1.DeploymentStrategy{RollingUpdate: &appsv1.RollingUpdateDeployment{MaxSurge: ptr.To(intstr.FromInt32(1))}},
+				Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "web", Image: image}}}},
+			},
+		}
+	}
+
+	It("should hash the spec without replicas", func() {
+		scaled, err := targetSpecHash(&DeploymentWrapper{obj: deployment(5, "web:v1")})
+		Expect(err).ToNot(HaveOccurred())
+		unscaled, err := targetSpecHash(&DeploymentWrapper{obj: deployment(2, "web:v1")})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(scaled).To(Equal(unscaled))
+
+		edited, err := targetSpecHash(&DeploymentWrapper{obj: deployment(2, "web:v2")})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(edited).ToNot(Equal(unscaled))
+	})
+
+	// surged is the status of an EvictionAutoScaler that surged web:v1 from 2 to 3
+	// replicas at generation 1 and has handled its eviction.
+	surged := func() myappsv1.EvictionAutoScalerStatus {
+		status := myappsv1.EvictionAutoScalerStatus{MinReplicas: 2, SurgeActive: true, SurgeReplicas: 3}
+		recordTarget(&status, &DeploymentWrapper{obj: deployment(2, "web:v1")})
+		status.TargetGeneration = 1
+		return status
+	}
+
+	// run reconciles an EvictionAutoScaler with status over dep and a PDB allowing
+	// disruptionsAllowed disruptions, and returns the EvictionAutoScaler and
+	// deployment afterwards.
+	run := func(dep *appsv1.Deployment, status myappsv1.EvictionAutoScalerStatus, disruptionsAllowed int32) (*myappsv1.EvictionAutoScaler, *appsv1.Deployment) {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+			dep,
+			&policyv1.PodDisruptionBudget{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+				Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+				Status:     policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: disruptionsAllowed},
+			},
+			&myappsv1.EvictionAutoScaler{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+				Spec:       myappsv1.EvictionAutoScalerSpec{TargetName: "web", TargetKind: myappsv1.TargetKindDeployment, SurgeMode: SurgeModeDirect, ChangeDetection: changeDetection},
+				Status:     status,
+			},
+		).WithStatusSubresource(&myappsv1.EvictionAutoScaler{}).Build()
+		r := &EvictionAutoScalerReconciler{Client: c, Scheme: scheme, Filter: namespacefilter.New(nil, false), APIReader: c}
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+
+		var eas myappsv1.EvictionAutoScaler
+		Expect(c.Get(ctx, key, &eas)).To(Succeed())
+		var got appsv1.Deployment
+		Expect(c.Get(ctx, key, &got)).To(Succeed())
+		return &eas, &got
+	}
+
+	// reconcile runs a surged EvictionAutoScaler over dep after its surge marker went
+	// missing, and returns its MinReplicas.
+	reconcile := func(dep *appsv1.Deployment) int32 {
+		eas, _ := run(dep, surged(), 0)
+		Expect(eas.Status.TargetGeneration).To(Equal(int64(2)))
+		return eas.Status.MinReplicas
+	}
+
+	It("should keep MinReplicas when only the surge changed the target", func() {
+		Expect(reconcile(deployment(3, "web:v1"))).To(Equal(int32(2)))
+	})
+
+	It("should reset MinReplicas when the owner also edited the spec", func() {
+		Expect(reconcile(deployment(3, "web:v2"))).To(Equal(int32(3)))
+	})
+
+	It("should reset MinReplicas when the owner scaled to another count", func() {
+		Expect(reconcile(deployment(4, "web:v1"))).To(Equal(int32(4)))
+	})
+
+	It("should scale down a surge recovered from status after its marker went missing", func() {
+		eas, dep := run(deployment(3, "web:v1"), surged(), 1)
+		Expect(*dep.Spec.Replicas).To(Equal(int32(2)))
+		Expect(eas.Status.SurgeActive).To(BeFalse())
+		Expect(eas.Status.MinReplicas).To(Equal(int32(2)))
+	})
+
+	It("should scale down a surge that outlived its eviction", func() {
+		dep := deployment(3, "web:v1")
+		dep.Annotations = map[string]string{EvictionSurgeReplicasAnnotationKey: "3"}
+		eas, dep := run(dep, surged(), 1)
+		Expect(*dep.Spec.Replicas).To(Equal(int32(2)))
+		Expect(dep.Annotations).ToNot(HaveKey(EvictionSurgeReplicasAnnotationKey))
+		Expect(eas.Status.SurgeActive).To(BeFalse())
+	})
+
+	It("should hold a recovered surge while the PDB allows no disruptions", func() {
+		eas, dep := run(deployment(3, "web:v1"), surged(), 0)
+		Expect(*dep.Spec.Replicas).To(Equal(int32(3)))
+		Expect(eas.Status.SurgeActive).To(BeTrue())
+	})
+
+	It("should hash the pod template and replicas only", func() {
+		base, err := podTemplateHash(&DeploymentWrapper{obj: deployment(2, "web:v1")})
+		Expect(err).ToNot(HaveOccurred())
+		rewritten := deployment(2, "web:v1")
+		rewritten.Spec.Strategy.RollingUpdate.MaxSurge = ptr.To(intstr.FromInt32(2))
+		Expect(podTemplateHash(&DeploymentWrapper{obj: rewritten})).To(Equal(base))
+		Expect(podTemplateHash(&DeploymentWrapper{obj: deployment(3, "web:v1")})).ToNot(Equal(base))
+		Expect(podTemplateHash(&DeploymentWrapper{obj: deployment(2, "web:v2")})).ToNot(Equal(base))
+	})
+
+	// recorded is the status of an EvictionAutoScaler that recorded web:v1 at 2
+	// replicas and generation 1.
+	recorded := func() myappsv1.EvictionAutoScalerStatus {
+		status := myappsv1.EvictionAutoScalerStatus{MinReplicas: 2}
+		recordTarget(&status, &DeploymentWrapper{obj: deployment(2, "web:v1")})
+		status.TargetGeneration = 1
+		return status
+	}
+
+	It("should ignore a rewrite outside the pod template with podTemplate change detection", func() {
+		dep := deployment(2, "web:v1")
+		dep.Spec.Strategy.RollingUpdate.MaxSurge = ptr.To(intstr.FromInt32(2))
+		eas, _ := run(dep, recorded(), 1)
+		Expect(meta.FindStatusCondition(eas.Status.Conditions, ReadyCondition).Reason).To(Equal("TargetSpecChange"))
+
+		changeDetection = ChangeDetectionPodTemplate
+		eas, _ = run(dep, recorded(), 1)
+		Expect(meta.FindStatusCondition(eas.Status.Conditions, ReadyCondition).Reason).ToNot(Equal("TargetSpecChange"))
+	})
+
+	It("should reset MinReplicas on a pod template change the generation missed", func() {
+		changeDetection = ChangeDetectionPodTemplate
+		status := recorded()
+		// Recreated by its owner: a new spec at the generation recorded for the old one.
+		status.TargetGeneration = 2
+		eas, _ := run(deployment(3, "web:v2"), status, 1)
+		Expect(eas.Status.MinReplicas).To(Equal(int32(3)))
+	})
+})
@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/tracing"
+)
+
+// traced wraps r so each of its reconciles runs in a span named after the controller,
+// the parent of the spans the reconcile starts itself, e.g. FetchTarget and Scale.
+// Without an OTLP endpoint configured the spans are no-ops. Reconciles refused
+// because the controller is paused are requeued without an error.
+func traced(name string, r reconcile.Reconciler) reconcile.Reconciler {
+	return reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+		ctx, span := tracing.Start(ctx, name+".Reconcile",
+			attribute.String("k8s.namespace.name", req.Namespace),
+			attribute.String("k8s.object.name", req.Name),
+			attribute.String("reconcile.id", reconcileID(ctx)),
+		)
+		result, err := r.Reconcile(ctx, req)
+		if result.RequeueAfter > 0 {
+			span.SetAttributes(attribute.String("reconcile.requeue_after", result.RequeueAfter.String()))
+		}
+		tracing.End(span, err)
+		return requeueWhilePaused(ctx, result, err)
+	})
+}
+
+// targetAttributes describe the target of eas on the spans of its reconcile.
+func targetAttributes(eas *myappsv1.EvictionAutoScaler) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("target.kind", string(eas.Spec.TargetKind)),
+		attribute.String("target.name", eas.Spec.TargetName),
+	}
+}
@@ -3,7 +3,11 @@ package controllers
 import (
 	"fmt"
 
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/annotations"
 	v1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -19,12 +23,17 @@ type Surger interface {
 	RemoveAnnotation(string)
 }
 
-// Todo change casing to match k8s?
+// Canonical targetKind values; see myappsv1.NormalizeTargetKind for the accepted aliases.
 const (
-	deploymentKind  = "deployment"
-	statefulSetKind = "statefulset"
+	deploymentKind  = myappsv1.TargetKindDeployment
+	statefulSetKind = myappsv1.TargetKindStatefulSet
+	rolloutKind     = myappsv1.TargetKindRollout
 )
 
+// rolloutGVK identifies Argo Rollouts. Rollouts are read as unstructured objects
+// so this package does not take a dependency on the Argo API module.
+var rolloutGVK = schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Rollout"}
+
 type DeploymentWrapper struct {
 	obj *v1.Deployment
 }
@@ -92,19 +101,31 @@ func (s *StatefulSetWrapper) SetReplicas(replicas int32) {
 	s.obj.Spec.Replicas = &replicas
 }
 
+// GetMaxSurge returns the statefulset's max-surge annotation, or a single pod
+// without one. StatefulSets have no maxSurge of their own, and every surge pod is
+// a new ordinal with its own PVCs.
 func (s *StatefulSetWrapper) GetMaxSurge() intstr.IntOrString {
-	return intstr.FromString("10%") //there is no max surge for stateful sets.
+	if val, ok := s.obj.Annotations[annotations.MaxSurge]; ok {
+		return intstr.Parse(val)
+	}
+	return intstr.FromInt32(1)
 }
 
-func GetSurger(kind string) (Surger, error) {
-	if kind == deploymentKind {
+// GetSurger returns an empty Surger for the canonical targetKind kind, to be
+// filled by a Get.
+func GetSurger(kind myappsv1.TargetKind) (Surger, error) {
+	switch kind {
+	case deploymentKind:
 		return &DeploymentWrapper{obj: &v1.Deployment{}}, nil
-	} else if kind == statefulSetKind {
+	case statefulSetKind:
 		return &StatefulSetWrapper{obj: &v1.StatefulSet{}}, nil
-	} else {
-		return nil, fmt.Errorf("unknown target kind %s", kind) //be good to enforce this with admission policy
+	case rolloutKind:
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(rolloutGVK)
+		return &RolloutWrapper{obj: obj}, nil
+	default:
+		return nil, fmt.Errorf("unknown target kind %s", kind)
 	}
-
 }
 
 // AddAnnotation will reset and add new annotation map every time this func is called
@@ -122,3 +143,63 @@ func (s *StatefulSetWrapper) RemoveAnnotation(status string) {
 		delete(s.obj.Annotations, status)
 	}
 }
+
+// RolloutWrapper wraps an Argo Rollout held as an unstructured object.
+type RolloutWrapper struct {
+	obj *unstructured.Unstructured
+}
+
+var _ Surger = &RolloutWrapper{}
+
+func (r *RolloutWrapper) Obj() client.Object {
+	return r.obj
+}
+
+func (r *RolloutWrapper) GetReplicas() int32 {
+	replicas, found, err := unstructured.NestedInt64(r.obj.Object, "spec", "replicas")
+	if err != nil || !found {
+		return 1 // Default value in Argo Rollouts if not set
+	}
+	return int32(replicas)
+}
+
+func (r *RolloutWrapper) SetReplicas(replicas int32) {
+	r.obj = r.obj.DeepCopy() //don't mutate the cache
+	_ = unstructured.SetNestedField(r.obj.Object, int64(replicas), "spec", "replicas")
+}
+
+// GetMaxSurge returns the canary strategy's maxSurge. Argo defaults it to 25%,
+// and blue-green rollouts have no maxSurge so they get the same default.
+func (r *RolloutWrapper) GetMaxSurge() intstr.IntOrString {
+	val, found, err := unstructured.NestedFieldNoCopy(r.obj.Object, "spec", "strategy", "canary", "maxSurge")
+	if err == nil && found {
+		switch v := val.(type) {
+		case int64:
+			return intstr.FromInt32(int32(v))
+		case float64:
+			return intstr.FromInt32(int32(v))
+		case string:
+			return intstr.FromString(v)
+		}
+	}
+	return intstr.FromString("25%")
+}
+
+// AddAnnotation add new status annotation
+func (r *RolloutWrapper) AddAnnotation(status, newReplicas string) {
+	annotations := r.obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[status] = newReplicas
+	r.obj.SetAnnotations(annotations)
+}
+
+// RemoveAnnotation will delete specific status annotation
+func (r *RolloutWrapper) RemoveAnnotation(status string) {
+	annotations := r.obj.GetAnnotations()
+	if annotations != nil {
+		delete(annotations, status)
+		r.obj.SetAnnotations(annotations)
+	}
+}
@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	myappsv1 "github.com/azure/eviction-autoscaler/api/v1"
+	"github.com/azure/eviction-autoscaler/internal/annotations"
+)
+
+var _ = Describe("GetSurger", func() {
+	It("should return a Surger for every canonical target kind", func() {
+		for _, kind := range myappsv1.TargetKinds {
+			surger, err := GetSurger(kind)
+			Expect(err).ToNot(HaveOccurred(), "kind %s", kind)
+			Expect(surger.Obj()).ToNot(BeNil())
+		}
+	})
+
+	It("should reject kinds that were not normalized", func() {
+		_, err := GetSurger("Deployment")
+		Expect(err).To(MatchError(ContainSubstring("unknown target kind")))
+	})
+})
+
+var _ = Describe("StatefulSetWrapper", func() {
+	It("should take maxSurge from the max-surge annotation", func() {
+		sts := &v1.StatefulSet{}
+		Expect((&StatefulSetWrapper{obj: sts}).GetMaxSurge()).To(Equal(intstr.FromInt32(1)))
+
+		sts.Annotations = map[string]string{annotations.MaxSurge: "25%"}
+		Expect((&StatefulSetWrapper{obj: sts}).GetMaxSurge()).To(Equal(intstr.FromString("25%")))
+
+		sts.Annotations[annotations.MaxSurge] = "0"
+		_, err := calculateSurge(context.Background(), &StatefulSetWrapper{obj: sts}, 3)
+		Expect(err).To(MatchError(errMaxSurgeZero))
+	})
+})
@@ -0,0 +1,68 @@
+package decisions
+
+import (
+	"net/http"
+	"strings"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// +kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+var authlog = logf.Log.WithName("decisions-auth")
+
+// Authorized serves next only to callers that present a bearer token the API server
+// accepts, and whose user may get the request path as a non-resource URL, e.g. with
+// a ClusterRole granting get on nonResourceURLs ["/debug/decisions"]. Traces name
+// workloads and their replica counts, so they are not served to anyone who can reach
+// the port.
+func Authorized(c client.Client, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		review := &authenticationv1.TokenReview{Spec: authenticationv1.TokenReviewSpec{Token: token}}
+		if err := c.Create(r.Context(), review); err != nil {
+			authlog.Error(err, "failed to review token")
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		if !review.Status.Authenticated {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		user := review.Status.User
+		access := &authorizationv1.SubjectAccessReview{Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   user.Username,
+			UID:    user.UID,
+			Groups: user.Groups,
+			NonResourceAttributes: &authorizationv1.NonResourceAttributes{
+				Path: r.URL.Path,
+				Verb: strings.ToLower(r.Method),
+			},
+		}}
+		if len(user.Extra) > 0 {
+			access.Spec.Extra = make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+			for key, value := range user.Extra {
+				access.Spec.Extra[key] = authorizationv1.ExtraValue(value)
+			}
+		}
+		if err := c.Create(r.Context(), access); err != nil {
+			authlog.Error(err, "failed to review access", "user", user.Username)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		if !access.Status.Allowed {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,129 @@
+// Package decisions keeps the last few reconcile decisions made for each
+// EvictionAutoScaler in memory, and serves them so a support escalation can see why
+// the controller did what it did without turning up log verbosity.
+package decisions
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Decision is one reconcile of an EvictionAutoScaler: what it observed, which branch
+// it took and what it did.
+type Decision struct {
+	Time time.Time `json:"time"`
+	// ReconcileID is the ID controller-runtime gave the reconcile, which its log
+	// lines carry too.
+	ReconcileID string `json:"reconcileID,omitempty"`
+	// Inputs are the values the branch was chosen from, e.g. the PDB's allowed
+	// disruptions and the target's replicas.
+	Inputs map[string]string `json:"inputs,omitempty"`
+	// Branch names the path taken, usually the reason of the Ready condition.
+	Branch string `json:"branch"`
+	// Action describes what was done.
+	Action       string `json:"action,omitempty"`
+	RequeueAfter string `json:"requeueAfter,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// Log holds the last Size decisions of each EvictionAutoScaler. A nil Log records
+// nothing.
+type Log struct {
+	size   int
+	mu     sync.Mutex
+	traces map[types.NamespacedName]*ring
+}
+
+// NewLog returns a Log keeping size decisions per EvictionAutoScaler, or nil if size
+// is not positive.
+func NewLog(size int) *Log {
+	if size <= 0 {
+		return nil
+	}
+	return &Log{size: size, traces: map[types.NamespacedName]*ring{}}
+}
+
+// Record adds d to the trace of key, dropping its oldest decision once full.
+func (l *Log) Record(key types.NamespacedName, d Decision) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	trace, ok := l.traces[key]
+	if !ok {
+		trace = &ring{items: make([]Decision, 0, l.size)}
+		l.traces[key] = trace
+	}
+	trace.add(d)
+}
+
+// Forget drops the trace of key, e.g. once its EvictionAutoScaler is deleted.
+func (l *Log) Forget(key types.NamespacedName) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.traces, key)
+}
+
+// Decisions returns the trace of key, oldest first.
+func (l *Log) Decisions(key types.NamespacedName) []Decision {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if trace, ok := l.traces[key]; ok {
+		return trace.list()
+	}
+	return nil
+}
+
+// Handler serves the traces as JSON, keyed by namespace/name. The namespace and name
+// query parameters narrow it down to one namespace or one EvictionAutoScaler.
+func (l *Log) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		namespace, name := r.URL.Query().Get("namespace"), r.URL.Query().Get("name")
+		out := map[string][]Decision{}
+		if l != nil {
+			l.mu.Lock()
+			for key, trace := range l.traces {
+				if (namespace == "" || key.Namespace == namespace) && (name == "" || key.Name == name) {
+					out[key.String()] = trace.list()
+				}
+			}
+			l.mu.Unlock()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(out); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// ring is a fixed-capacity buffer overwriting its oldest item once full.
+type ring struct {
+	items []Decision
+	next  int
+}
+
+func (r *ring) add(d Decision) {
+	if len(r.items) < cap(r.items) {
+		r.items = append(r.items, d)
+		return
+	}
+	r.items[r.next] = d
+	r.next = (r.next + 1) % len(r.items)
+}
+
+func (r *ring) list() []Decision {
+	out := make([]Decision, 0, len(r.items))
+	out = append(out, r.items[r.next:]...)
+	return append(out, r.items[:r.next]...)
+}
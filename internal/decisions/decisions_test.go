@@ -0,0 +1,96 @@
+package decisions
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestLogKeepsTheLastDecisions(t *testing.T) {
+	log := NewLog(3)
+	key := types.NamespacedName{Namespace: "default", Name: "web"}
+	for i := range 5 {
+		log.Record(key, Decision{Branch: strconv.Itoa(i)})
+	}
+	got := log.Decisions(key)
+	if len(got) != 3 || got[0].Branch != "2" || got[2].Branch != "4" {
+		t.Errorf("expected decisions 2 to 4 oldest first, got %+v", got)
+	}
+
+	log.Forget(key)
+	if got := log.Decisions(key); got != nil {
+		t.Errorf("expected no decisions after Forget, got %+v", got)
+	}
+}
+
+func TestNilLog(t *testing.T) {
+	log := NewLog(0)
+	if log != nil {
+		t.Fatal("expected a nil log for size 0")
+	}
+	log.Record(types.NamespacedName{Name: "web"}, Decision{})
+	if got := log.Decisions(types.NamespacedName{Name: "web"}); got != nil {
+		t.Errorf("expected a nil log to record nothing, got %+v", got)
+	}
+}
+
+func TestHandlerFilters(t *testing.T) {
+	log := NewLog(2)
+	log.Record(types.NamespacedName{Namespace: "default", Name: "web"}, Decision{Time: time.Now(), Branch: "Reconciled"})
+	log.Record(types.NamespacedName{Namespace: "default", Name: "db"}, Decision{Time: time.Now(), Branch: "Reconciled"})
+	log.Record(types.NamespacedName{Namespace: "other", Name: "web"}, Decision{Time: time.Now(), Branch: "Reconciled"})
+
+	for query, want := range map[string]int{"": 3, "?namespace=default": 2, "?namespace=default&name=web": 1} {
+		rec := httptest.NewRecorder()
+		log.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/decisions"+query, nil))
+		var got map[string][]Decision
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Fatalf("%q: invalid JSON: %v", query, err)
+		}
+		if len(got) != want {
+			t.Errorf("%q: expected %d traces, got %d", query, want, len(got))
+		}
+	}
+}
+
+func TestAuthorized(t *testing.T) {
+	c := fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+		Create: func(_ context.Context, _ client.WithWatch, obj client.Object, _ ...client.CreateOption) error {
+			switch review := obj.(type) {
+			case *authenticationv1.TokenReview:
+				review.Status.Authenticated = review.Spec.Token != "bad"
+				review.Status.User.Username = review.Spec.Token
+			case *authorizationv1.SubjectAccessReview:
+				review.Status.Allowed = review.Spec.User == "reader" &&
+					review.Spec.NonResourceAttributes.Path == "/debug/decisions" && review.Spec.NonResourceAttributes.Verb == "get"
+			}
+			return nil
+		},
+	}).Build()
+	handler := Authorized(c, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for token, want := range map[string]int{"": http.StatusUnauthorized, "bad": http.StatusUnauthorized, "someone": http.StatusForbidden, "reader": http.StatusOK} {
+		req := httptest.NewRequest(http.MethodGet, "/debug/decisions", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("token %q: expected status %d, got %d", token, want, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// Package diagnostics captures runtime profiles of a running manager, for looking
+// into slow reconciles or memory growth during mass drains without restarting it.
+package diagnostics
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"syscall"
+	"time"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("diagnostics")
+
+// profiles are written by Snapshot, with the pprof debug level of each: goroutine
+// stacks as text, the heap in the format go tool pprof reads.
+var profiles = []struct {
+	name  string
+	debug int
+}{
+	{"goroutine", 2},
+	{"heap", 0},
+}
+
+// Snapshot writes the goroutine and heap profiles of the process to dir, named after
+// now, and returns the files written. A garbage collection runs first so the heap
+// profile is current.
+func Snapshot(dir string, now time.Time) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	runtime.GC()
+	stamp := now.UTC().Format("20060102T150405Z")
+	var files []string
+	for _, p := range profiles {
+		path := filepath.Join(dir, fmt.Sprintf("%s-%s.pprof", p.name, stamp))
+		if err := writeProfile(path, p.name, p.debug); err != nil {
+			return files, err
+		}
+		files = append(files, path)
+	}
+	return files, nil
+}
+
+func writeProfile(path, name string, debug int) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := pprof.Lookup(name).WriteTo(f, debug); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// SnapshotOnSignal is a manager Runnable that takes a Snapshot into Dir each time the
+// process receives SIGUSR1, e.g. from kubectl debug, when the pprof port can't be
+// reached.
+type SnapshotOnSignal struct {
+	Dir string
+}
+
+// Start waits for SIGUSR1 until ctx is done.
+func (s *SnapshotOnSignal) Start(ctx context.Context) error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1)
+	defer signal.Stop(signals)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-signals:
+			files, err := Snapshot(s.Dir, time.Now())
+			if err != nil {
+				log.Error(err, "failed to write diagnostics snapshot", "dir", s.Dir)
+				continue
+			}
+			log.Info("Wrote diagnostics snapshot", "files", files)
+		}
+	}
+}
+
+// NeedLeaderElection is false: every replica, standby or not, can be profiled.
+func (s *SnapshotOnSignal) NeedLeaderElection() bool {
+	return false
+}
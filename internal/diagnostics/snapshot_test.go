@@ -0,0 +1,43 @@
+package diagnostics
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSnapshotWritesProfiles(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "snapshots")
+	now := time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC)
+
+	files, err := Snapshot(dir, now)
+	if err != nil {
+		t.Fatalf("Snapshot returned error: %v", err)
+	}
+	want := []string{
+		filepath.Join(dir, "goroutine-20260301T123000Z.pprof"),
+		filepath.Join(dir, "heap-20260301T123000Z.pprof"),
+	}
+	if len(files) != len(want) {
+		t.Fatalf("got files %v, want %v", files, want)
+	}
+	for i, path := range want {
+		if files[i] != path {
+			t.Errorf("file %d = %s, want %s", i, files[i], path)
+		}
+		info, err := os.Stat(path)
+		if err != nil || info.Size() == 0 {
+			t.Errorf("expected %s to be written, stat: %v", path, err)
+		}
+	}
+
+	stacks, err := os.ReadFile(want[0])
+	if err != nil {
+		t.Fatalf("reading goroutine profile: %v", err)
+	}
+	if !strings.Contains(string(stacks), "TestSnapshotWritesProfiles") {
+		t.Error("goroutine profile should include the stack of the calling test")
+	}
+}
@@ -0,0 +1,191 @@
+// Package features holds the controller's runtime feature flags. A flag narrows a
+// behavior its command-line flag turned on to the namespaces a ConfigMap names, so
+// it can be rolled out across a fleet namespace by namespace and rolled back at
+// once, without a new image or a restart. The ConfigMap is polled like the pause
+// ConfigMap.
+package features
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("features")
+
+// Flag names a runtime feature flag. It is also the ConfigMap data key that
+// targets it.
+type Flag string
+
+const (
+	// SurgeBatches gates recording surge waves on deployments and gating their pods
+	// until the wave is released (--surge-batch-window).
+	SurgeBatches Flag = "surgeBatches"
+	// CapacityPrecheck gates the checks made before a surge: cluster headroom
+	// (--headroom-source), ResourceQuotas and topology constraints.
+	CapacityPrecheck Flag = "capacityPrecheck"
+	// WebhookGating gates the pod placement webhook applying surge hints to new pods.
+	WebhookGating Flag = "webhookGating"
+)
+
+// Flags are the runtime feature flags, in the order they are logged.
+var Flags = []Flag{SurgeBatches, CapacityPrecheck, WebhookGating}
+
+// defaults are the flags until the ConfigMap is first read. Safety checks are on,
+// so a restart or an unreadable ConfigMap never skips them; behaviors are off, so
+// one rolled back before a restart stays off while the controller starts.
+var defaults = map[Flag]bool{CapacityPrecheck: true}
+
+// targeting is where a flag is on: everywhere, or only in the listed namespaces.
+type targeting struct {
+	all        bool
+	namespaces map[string]bool
+}
+
+func (t targeting) String() string {
+	if t.all {
+		return "true"
+	}
+	if len(t.namespaces) == 0 {
+		return "false"
+	}
+	return strings.Join(slices.Sorted(maps.Keys(t.namespaces)), ",")
+}
+
+// Set holds the runtime feature flags, as last read from their ConfigMap. Until
+// the first read every flag holds its default.
+type Set struct {
+	configMap types.NamespacedName
+	interval  time.Duration
+
+	mu     sync.RWMutex
+	loaded bool
+	flags  map[Flag]targeting
+}
+
+// New returns a Set reading the named ConfigMap every interval, or nil if name is
+// empty. Every flag of a nil Set is on.
+func New(namespace, name string, interval time.Duration) *Set {
+	if name == "" {
+		return nil
+	}
+	return &Set{configMap: types.NamespacedName{Namespace: namespace, Name: name}, interval: interval}
+}
+
+// Enabled reports whether flag is on in namespace. A flag the ConfigMap doesn't
+// set, or a missing ConfigMap, leaves it on.
+func (s *Set) Enabled(flag Flag, namespace string) bool {
+	if s == nil {
+		return true
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if !s.loaded {
+		return defaults[flag]
+	}
+	t, ok := s.flags[flag]
+	return !ok || t.all || t.namespaces[namespace]
+}
+
+// set records the flags read and returns the ones whose targeting changed.
+func (s *Set) set(flags map[Flag]targeting) []Flag {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var changed []Flag
+	for _, flag := range Flags {
+		old, had := s.flags[flag]
+		t, has := flags[flag]
+		if !s.loaded || had != has || old.String() != t.String() {
+			changed = append(changed, flag)
+		}
+	}
+	s.loaded, s.flags = true, flags
+	return changed
+}
+
+// Watcher returns the runnable that polls the ConfigMap with reader, which should
+// read from the API server rather than the manager's cache.
+func (s *Set) Watcher(reader client.Reader) *Watcher {
+	return &Watcher{s: s, reader: reader}
+}
+
+// Watcher polls the ConfigMap of a Set.
+type Watcher struct {
+	s      *Set
+	reader client.Reader
+}
+
+// Start polls until ctx is done.
+func (w *Watcher) Start(ctx context.Context) error {
+	ticker := time.NewTicker(w.s.interval)
+	defer ticker.Stop()
+	for {
+		w.poll(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// NeedLeaderElection is false: webhook replicas and standbys evaluate flags too.
+func (w *Watcher) NeedLeaderElection() bool {
+	return false
+}
+
+func (w *Watcher) poll(ctx context.Context) {
+	var cm corev1.ConfigMap
+	err := w.reader.Get(ctx, w.s.configMap, &cm)
+	if err != nil && !apierrors.IsNotFound(err) {
+		// Keep the last flags read; an unreadable ConfigMap must not turn them on.
+		log.Error(err, "unable to read feature flag ConfigMap", "configMap", w.s.configMap)
+		return
+	}
+	flags := map[Flag]targeting{}
+	for key, value := range cm.Data {
+		flag := Flag(key)
+		if !slices.Contains(Flags, flag) {
+			log.V(1).Info("Ignoring unknown feature flag", "configMap", w.s.configMap, "flag", key)
+			continue
+		}
+		flags[flag] = parse(value)
+	}
+	for _, flag := range w.s.set(flags) {
+		value := "true"
+		if t, ok := flags[flag]; ok {
+			value = t.String()
+		}
+		log.Info("Feature flag updated", "configMap", w.s.configMap, "flag", flag, "enabled", value)
+	}
+}
+
+// parse reads a flag's targeting: "true" turns it on everywhere, "false" or an
+// empty value turns it off, and anything else is a comma-separated list of the
+// namespaces to turn it on in.
+func parse(value string) targeting {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return targeting{}
+	}
+	if on, err := strconv.ParseBool(value); err == nil {
+		return targeting{all: on}
+	}
+	namespaces := map[string]bool{}
+	for _, ns := range strings.Split(value, ",") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			namespaces[ns] = true
+		}
+	}
+	return targeting{namespaces: namespaces}
+}
@@ -0,0 +1,120 @@
+package features
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSetFollowsConfigMap(t *testing.T) {
+	ctx := context.Background()
+	flagsCM := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "flags", Namespace: "eviction-autoscaler"}}
+	reader := fake.NewClientBuilder().Build()
+	s := New("eviction-autoscaler", "flags", time.Second)
+	w := s.Watcher(reader)
+
+	if s.Enabled(SurgeBatches, "team-a") || s.Enabled(WebhookGating, "team-a") {
+		t.Fatal("expected behaviors to be off before the first read")
+	}
+	if !s.Enabled(CapacityPrecheck, "team-a") {
+		t.Fatal("expected the capacity pre-check to be on before the first read")
+	}
+
+	w.poll(ctx)
+	if !s.Enabled(SurgeBatches, "team-a") {
+		t.Fatal("expected a missing ConfigMap to leave flags on")
+	}
+
+	flagsCM.Data = map[string]string{
+		string(SurgeBatches):     "team-a, team-b",
+		string(CapacityPrecheck): "false",
+		"unknown":                "true",
+	}
+	if err := reader.Create(ctx, flagsCM); err != nil {
+		t.Fatal(err)
+	}
+	w.poll(ctx)
+	for _, tc := range []struct {
+		flag      Flag
+		namespace string
+		want      bool
+	}{
+		{SurgeBatches, "team-a", true},
+		{SurgeBatches, "team-b", true},
+		{SurgeBatches, "team-c", false},
+		{CapacityPrecheck, "team-a", false},
+		{WebhookGating, "team-c", true},
+	} {
+		if got := s.Enabled(tc.flag, tc.namespace); got != tc.want {
+			t.Errorf("%s in %s: expected %v, got %v", tc.flag, tc.namespace, tc.want, got)
+		}
+	}
+
+	// An unreadable ConfigMap keeps the last flags read.
+	w.reader = fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build()
+	w.poll(ctx)
+	if s.Enabled(CapacityPrecheck, "team-a") {
+		t.Error("expected a failed read to keep the flag off")
+	}
+	w.reader = reader
+
+	cm := flagsCM.DeepCopy()
+	if err := reader.Get(ctx, client.ObjectKeyFromObject(cm), cm); err != nil {
+		t.Fatal(err)
+	}
+	cm.Data = map[string]string{string(CapacityPrecheck): "true"}
+	if err := reader.Update(ctx, cm); err != nil {
+		t.Fatal(err)
+	}
+	w.poll(ctx)
+	if !s.Enabled(CapacityPrecheck, "team-a") || !s.Enabled(SurgeBatches, "team-c") {
+		t.Error("expected flags to follow the updated ConfigMap")
+	}
+}
+
+func TestUnreadableConfigMapKeepsDefaults(t *testing.T) {
+	s := New("eviction-autoscaler", "flags", time.Second)
+	w := s.Watcher(fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build())
+	w.poll(context.Background())
+	if !s.Enabled(CapacityPrecheck, "team-a") {
+		t.Error("expected a failed first read to keep the capacity pre-check on")
+	}
+	if s.Enabled(SurgeBatches, "team-a") {
+		t.Error("expected a failed first read to keep surge batches off")
+	}
+}
+
+func TestParse(t *testing.T) {
+	for value, want := range map[string]string{
+		"":          "false",
+		"false":     "false",
+		"0":         "false",
+		"true":      "true",
+		"1":         "true",
+		"b,a":       "a,b",
+		" a , ,b ":  "a,b",
+		"team-prod": "team-prod",
+	} {
+		if got := parse(value).String(); got != want {
+			t.Errorf("parse(%q): expected %q, got %q", value, want, got)
+		}
+	}
+}
+
+func TestNilSet(t *testing.T) {
+	s := New("eviction-autoscaler", "", time.Second)
+	if s != nil {
+		t.Fatal("expected a nil set without a ConfigMap name")
+	}
+	for _, flag := range Flags {
+		if !s.Enabled(flag, "default") {
+			t.Errorf("expected %s to be on in a nil set", flag)
+		}
+	}
+}
@@ -17,7 +17,12 @@ limitations under the License.
 package metrics
 
 import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
 	policyv1 "k8s.io/api/policy/v1"
 	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 )
@@ -53,6 +58,17 @@ var (
 		[]string{"namespace"},
 	)
 
+	// StaleEvictionCounter tracks evictions ignored because they were older than the
+	// freshness window when first seen
+	// Labels: namespace
+	StaleEvictionCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "eviction_autoscaler_stale_evictions_ignored_total",
+			Help: "Total number of evictions ignored for being older than the freshness window",
+		},
+		[]string{"namespace"},
+	)
+
 	// BlockedEvictionCounter tracks how often evictions are blocked by PDBs
 	// Labels: namespace, pdb_name
 	BlockedEvictionCounter = prometheus.NewCounterVec(
@@ -63,6 +79,17 @@ var (
 		[]string{"namespace", "pdb_name"},
 	)
 
+	// PDBBlockedSecondsCounter tracks how long a PDB allowed no disruptions while
+	// an eviction of its pods was outstanding
+	// Labels: namespace, pdb
+	PDBBlockedSecondsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "eviction_autoscaler_pdb_blocked_seconds_total",
+			Help: "Total seconds a PDB allowed no disruptions while an eviction of its pods was outstanding",
+		},
+		[]string{"namespace", "pdb"},
+	)
+
 	// ScalingOpportunityCounter tracks how often the controller thinks it could have scaled a deployment
 	// Labels: namespace, deployment_name, action (scale_up/scale_down), signal
 	ScalingOpportunityCounter = prometheus.NewCounterVec(
@@ -111,6 +138,28 @@ var (
 		},
 	)
 
+	// NodeDrainPodsRemainingGauge tracks the pods a drain still has to evict from a
+	// cordoned node
+	// Labels: node
+	NodeDrainPodsRemainingGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "eviction_autoscaler_node_drain_pods_remaining",
+			Help: "Number of pods a drain still has to evict from a cordoned node",
+		},
+		[]string{"node"},
+	)
+
+	// NodeDrainBlockedPodsGauge tracks the pods on a cordoned node covered by a PDB
+	// that allows no disruptions
+	// Labels: node
+	NodeDrainBlockedPodsGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "eviction_autoscaler_node_drain_blocked_pods",
+			Help: "Number of pods on a cordoned node covered by a PDB that allows no disruptions",
+		},
+		[]string{"node"},
+	)
+
 	// PDBInfoGauge tracks various PDB-related metrics
 	// Labels: namespace, pdb_name, target_name, metric_type
 	// todo:chnage with PDBGauge instead of separate gauges per PDB
@@ -123,6 +172,178 @@ var (
 		[]string{"namespace", "pdb_name", "target_name", "metric_type"},
 	)
 
+	// SurgeDurationHistogram tracks how long a surge held a target above its floor,
+	// from surge start until scale-down completed
+	// Labels: namespace, target
+	SurgeDurationHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: "eviction_autoscaler_surge_duration_seconds",
+			Help: "Time from surge start to scale-down completion",
+			// 30s up to a little over 4h
+			Buckets: prometheus.ExponentialBuckets(30, 2, 10),
+		},
+		[]string{"namespace", "target"},
+	)
+
+	// SurgePodSecondsCounter tracks the extra replicas surges held above their
+	// target's floor, integrated over time
+	// Labels: namespace, target
+	SurgePodSecondsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "eviction_autoscaler_surge_pod_seconds_total",
+			Help: "Total pod-seconds of extra replicas held by surges above the target's floor",
+		},
+		[]string{"namespace", "target"},
+	)
+
+	// SurgePodsPendingGauge tracks pods created during an active surge that are still
+	// Pending
+	// Labels: namespace, target
+	SurgePodsPendingGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "eviction_autoscaler_surge_pods_pending",
+			Help: "Number of pods created during an active surge that are still Pending",
+		},
+		[]string{"namespace", "target"},
+	)
+
+	// SurgeFailedSchedulingCounter tracks FailedScheduling events reported for pods
+	// created during an active surge
+	// Labels: namespace, target
+	SurgeFailedSchedulingCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "eviction_autoscaler_surge_failed_scheduling_events_total",
+			Help: "Total number of FailedScheduling events for pods created during an active surge",
+		},
+		[]string{"namespace", "target"},
+	)
+
+	// OrphanedSurgeRepairedCounter tracks surge annotations found on deployments
+	// without an active surge and repaired by the orphan sweeper
+	// Labels: namespace
+	OrphanedSurgeRepairedCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "eviction_autoscaler_orphaned_surges_repaired_total",
+			Help: "Total number of leaked surge annotations repaired on deployments without an active surge",
+		},
+		[]string{"namespace"},
+	)
+
+	// SurgeBatchReleasedCounter tracks waves of surge pods released to the scheduler
+	// together by lifting their scheduling gate
+	// Labels: namespace, reason
+	SurgeBatchReleasedCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "eviction_autoscaler_surge_batches_released_total",
+			Help: "Total number of surge pod waves released to the scheduler together, by whether the wave was complete or its window ran out",
+		},
+		[]string{"namespace", "reason"},
+	)
+
+	// SurgeUnlikelyToHelpCounter tracks evictions that got no surge because the
+	// workload's newest pods are the ones failing
+	// Labels: namespace, target
+	SurgeUnlikelyToHelpCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "eviction_autoscaler_surge_unlikely_to_help_total",
+			Help: "Total number of evictions not surged because the workload's newest pods are failing, so surge pods would fail too",
+		},
+		[]string{"namespace", "target"},
+	)
+
+	// NamespaceEnrollmentTransitionsCounter tracks namespaces enrolled into or opted
+	// out of eviction-autoscaler
+	// Labels: namespace, transition
+	NamespaceEnrollmentTransitionsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "eviction_autoscaler_namespace_enrollment_transitions_total",
+			Help: "Total number of times a namespace was enrolled into or opted out of eviction-autoscaler",
+		},
+		[]string{"namespace", "transition"},
+	)
+
+	// NamespaceEnrolledGauge is 1 while a namespace is enrolled and 0 once it opts out.
+	// Namespaces that were never enrolled have no series.
+	// Labels: namespace
+	NamespaceEnrolledGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "eviction_autoscaler_namespace_enrolled",
+			Help: "Whether a namespace that has been enrolled at some point is currently enrolled (1) or opted out (0)",
+		},
+		[]string{"namespace"},
+	)
+
+	// CircuitOpenGauge is 1 while the write circuit breaker is open and writes to the
+	// API server are paused, and 0 otherwise
+	CircuitOpenGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "eviction_autoscaler_circuit_open",
+			Help: "Whether writes to the API server are paused by the circuit breaker after consecutive API errors (1) or not (0)",
+		},
+	)
+
+	// PausedGauge is 1 while the controller is paused by its pause ConfigMap and
+	// refuses all writes, and 0 otherwise
+	PausedGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "eviction_autoscaler_paused",
+			Help: "Whether the controller is paused by its pause ConfigMap and refuses all writes (1) or not (0)",
+		},
+	)
+
+	// ClusterHeadroomGauge tracks the last cluster headroom reading, the fraction of
+	// capacity that is free, when --headroom-source is set
+	ClusterHeadroomGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "eviction_autoscaler_cluster_headroom_ratio",
+			Help: "Fraction of cluster capacity that is free, as last read from the configured headroom source",
+		},
+	)
+
+	// SurgeDeferredCounter tracks surges deferred because cluster headroom fell
+	// below the threshold, counted once per deferral rather than per retry
+	// Labels: namespace, target
+	SurgeDeferredCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "eviction_autoscaler_surge_deferred_total",
+			Help: "Total number of surges deferred because cluster headroom was below the threshold",
+		},
+		[]string{"namespace", "target"},
+	)
+
+	// SurgeQuotaExceededCounter tracks surges skipped because they would exceed a
+	// ResourceQuota in the namespace
+	// Labels: namespace, target
+	SurgeQuotaExceededCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "eviction_autoscaler_surge_quota_exceeded_total",
+			Help: "Total number of surges skipped because they would exceed a ResourceQuota",
+		},
+		[]string{"namespace", "target"},
+	)
+
+	// SurgeInfeasibleTopologyCounter tracks surges limited or skipped because the
+	// target's topology constraints leave no room for the surge pods
+	// Labels: namespace, target
+	SurgeInfeasibleTopologyCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "eviction_autoscaler_surge_infeasible_topology_total",
+			Help: "Total number of surges limited or skipped because topology constraints leave no room for the surge pods",
+		},
+		[]string{"namespace", "target"},
+	)
+
+	// SurgePlanCounter tracks surge plans proposed, applied and rejected with
+	// --surge-approval
+	// Labels: namespace, outcome
+	SurgePlanCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "eviction_autoscaler_surge_plans_total",
+			Help: "Total number of surge plans proposed, applied after approval, or rejected",
+		},
+		[]string{"namespace", "outcome"},
+	)
+
 	// PDBCounter tracks the number of PDBs with an increment interface
 	// Labels: namespace, created_by_us (true/false)
 	PDBCounter = prometheus.NewCounterVec(
@@ -160,16 +381,122 @@ const (
 	ScaleDownAction = "scale_down"
 )
 
+// Constants for why a surge batch was released
+const (
+	BatchCompleteReason = "complete"
+	BatchWindowReason   = "window"
+)
+
+// Constants for namespace enrollment transitions
+const (
+	EnrolledTransition   = "enrolled"
+	UnenrolledTransition = "unenrolled"
+)
+
+// Constants for surge plan outcomes
+const (
+	ProposedSurgePlan = "proposed"
+	AppliedSurgePlan  = "applied"
+	RejectedSurgePlan = "rejected"
+)
+
+// Constants for which of a workload's pods are failing, see FailurePattern
+const (
+	NoFailurePattern     = "none"
+	OldestFailurePattern = "oldest"
+	NewestFailurePattern = "newest"
+	RandomFailurePattern = "random"
+)
+
+// failingWaitReasons are container waiting reasons that don't clear up on their own.
+var failingWaitReasons = map[string]bool{
+	"CrashLoopBackOff":           true,
+	"ImagePullBackOff":           true,
+	"ErrImagePull":               true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+	"RunContainerError":          true,
+}
+
 // Constants for scaling opportunity signals
 const (
 	PDBBlockedSignal                = "pdb_blocked"
 	MinAvailableEqualsDesiredSignal = "min_available_equals_desired_healthy"
 	CooldownElapsedSignal           = "cooldown_elapsed"
+	// HealthyPodsBlockedSignal marks a surge for a PDB that always allows evicting
+	// unhealthy pods, so only its Ready pods were held up.
+	HealthyPodsBlockedSignal = "healthy_pods_blocked"
 	// todo: Implement these when additional scaling logic is added
 	// OldNotReadyPodsSignal           = "old_not_ready_pods"
 	// WouldExceedMinAvailableSignal   = "would_exceed_min_available"
 )
 
+// Metric modes, selected with --metrics-mode. NamespaceMode is LowCardinalityMode
+// under the name of what it keeps.
+const (
+	FullMode           = "full"
+	LowCardinalityMode = "low-cardinality"
+	NamespaceMode      = "namespace"
+)
+
+var lowCardinality atomic.Bool
+
+// SetMode selects how object-name labels (deployment_name, pdb_name, target, ...)
+// are recorded. In low-cardinality mode they are left empty so every series is
+// aggregated per namespace, which keeps large fleets within their series budget.
+func SetMode(mode string) error {
+	switch mode {
+	case FullMode:
+		lowCardinality.Store(false)
+	case LowCardinalityMode, NamespaceMode:
+		lowCardinality.Store(true)
+	default:
+		return fmt.Errorf("unknown metrics mode %q, expected %s, %s or %s", mode, FullMode, NamespaceMode, LowCardinalityMode)
+	}
+	return nil
+}
+
+// ForgetTarget drops the per-object series of a workload target that is no longer
+// tracked, so gauges of deleted objects don't linger at their last value.
+func ForgetTarget(namespace, target string) {
+	SurgePodsPendingGauge.DeletePartialMatch(prometheus.Labels{"namespace": namespace, "target": target})
+	SurgePodSecondsCounter.DeletePartialMatch(prometheus.Labels{"namespace": namespace, "target": target})
+	PDBInfoGauge.DeletePartialMatch(prometheus.Labels{"namespace": namespace, "target_name": target})
+}
+
+// ForgetPDB drops the per-object series of a PDB that is no longer tracked.
+func ForgetPDB(namespace, pdb string) {
+	PDBInfoGauge.DeletePartialMatch(prometheus.Labels{"namespace": namespace, "pdb_name": pdb})
+	PDBBlockedSecondsCounter.DeletePartialMatch(prometheus.Labels{"namespace": namespace, "pdb": pdb})
+}
+
+// ForgetNamespace drops every gauge series of a deleted namespace.
+func ForgetNamespace(namespace string) {
+	match := prometheus.Labels{"namespace": namespace}
+	for _, gauge := range []*prometheus.GaugeVec{DeploymentGauge, PDBGauge, PDBInfoGauge, SurgePodsPendingGauge, NamespaceEnrolledGauge} {
+		gauge.DeletePartialMatch(match)
+	}
+	PDBBlockedSecondsCounter.DeletePartialMatch(match)
+	SurgePodSecondsCounter.DeletePartialMatch(match)
+}
+
+// Name returns the label value for an object name: the name itself, or "" in
+// low-cardinality mode. Prometheus treats an empty label value like a missing
+// label, so the series aggregate per namespace without changing metric names.
+func Name(name string) string {
+	if lowCardinality.Load() {
+		return ""
+	}
+	return name
+}
+
+// PerObject reports whether per-object gauges are recorded. A gauge set per object
+// can't be aggregated by overwriting one series, so low-cardinality mode skips them.
+func PerObject() bool {
+	return !lowCardinality.Load()
+}
+
 // GetPDBCreatedByUsLabel returns the appropriate label value based on PDB annotations
 func GetPDBCreatedByUsLabel(annotations map[string]string) string {
 	if ann, ok := annotations["createdBy"]; ok && ann == "DeploymentToPDBController" {
@@ -184,22 +511,99 @@ func GetScalingSignal(pdb *policyv1.PodDisruptionBudget) string {
 	// if pdb.Spec.MinAvailable != nil && int64(pdb.Spec.MinAvailable.IntValue()) == int64(pdb.Status.DesiredHealthy) {
 	//     return MinAvailableEqualsDesiredSignal
 	// }
+	if policy := pdb.Spec.UnhealthyPodEvictionPolicy; policy != nil && *policy == policyv1.AlwaysAllow {
+		return HealthyPodsBlockedSignal
+	}
 	return PDBBlockedSignal
 }
 
+// FailurePattern classifies which of a workload's pods are failing by age. Failing
+// pods that are all newer than every healthy pod, or all of the pods, are "newest":
+// typically a bad rollout, where more pods from the same template fail the same way.
+// Failing pods all older than every healthy pod are "oldest", anything else "random".
+// Terminating pods are ignored.
+func FailurePattern(pods []corev1.Pod) string {
+	var newestHealthy, oldestFailing, newestFailing, oldestHealthy time.Time
+	failing, healthy := 0, 0
+	for i := range pods {
+		pod := &pods[i]
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		created := pod.CreationTimestamp.Time
+		if podFailing(pod) {
+			if failing == 0 || created.Before(oldestFailing) {
+				oldestFailing = created
+			}
+			if failing == 0 || created.After(newestFailing) {
+				newestFailing = created
+			}
+			failing++
+			continue
+		}
+		if healthy == 0 || created.After(newestHealthy) {
+			newestHealthy = created
+		}
+		if healthy == 0 || created.Before(oldestHealthy) {
+			oldestHealthy = created
+		}
+		healthy++
+	}
+	switch {
+	case failing == 0:
+		return NoFailurePattern
+	case healthy == 0 || !oldestFailing.Before(newestHealthy):
+		return NewestFailurePattern
+	case !newestFailing.After(oldestHealthy):
+		return OldestFailurePattern
+	default:
+		return RandomFailurePattern
+	}
+}
+
+// podFailing reports whether any container of pod is stuck in a failing state.
+func podFailing(pod *corev1.Pod) bool {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Waiting != nil && failingWaitReasons[status.State.Waiting.Reason] {
+			return true
+		}
+	}
+	return false
+}
+
 func init() {
 	// Register metrics with controller-runtime's registry
 	ctrlmetrics.Registry.MustRegister(
 		DeploymentGauge,
 		PDBGauge,
 		EvictionCounter,
+		StaleEvictionCounter,
 		BlockedEvictionCounter,
+		PDBBlockedSecondsCounter,
 		ScalingOpportunityCounter,
 		ActualScalingCounter,
 		PDBCreationCounter,
 		EvictionAutoScalerCreationCounter,
 		NodeCordoningCounter,
+		NodeDrainPodsRemainingGauge,
+		NodeDrainBlockedPodsGauge,
 		PDBInfoGauge,
 		PDBCounter,
+		SurgeDurationHistogram,
+		SurgePodSecondsCounter,
+		SurgePodsPendingGauge,
+		SurgeFailedSchedulingCounter,
+		OrphanedSurgeRepairedCounter,
+		SurgeBatchReleasedCounter,
+		SurgeUnlikelyToHelpCounter,
+		NamespaceEnrollmentTransitionsCounter,
+		NamespaceEnrolledGauge,
+		ClusterHeadroomGauge,
+		CircuitOpenGauge,
+		PausedGauge,
+		SurgeDeferredCounter,
+		SurgePlanCounter,
+		SurgeQuotaExceededCounter,
+		SurgeInfeasibleTopologyCounter,
 	)
 }
@@ -0,0 +1,139 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetModeLowCardinalityDropsNames(t *testing.T) {
+	t.Cleanup(func() { _ = SetMode(FullMode) })
+
+	if got := Name("web"); got != "web" {
+		t.Fatalf("full mode: Name() = %q, want %q", got, "web")
+	}
+	if !PerObject() {
+		t.Fatal("full mode should record per-object gauges")
+	}
+
+	if err := SetMode(LowCardinalityMode); err != nil {
+		t.Fatalf("SetMode(%q) returned error: %v", LowCardinalityMode, err)
+	}
+	if got := Name("web"); got != "" {
+		t.Fatalf("low-cardinality mode: Name() = %q, want empty", got)
+	}
+	if PerObject() {
+		t.Fatal("low-cardinality mode should skip per-object gauges")
+	}
+}
+
+func TestSetModeRejectsUnknownMode(t *testing.T) {
+	if err := SetMode("minimal"); err == nil {
+		t.Fatal("expected an error for an unknown mode")
+	}
+}
+
+func TestGetScalingSignal(t *testing.T) {
+	pdb := &policyv1.PodDisruptionBudget{}
+	if got := GetScalingSignal(pdb); got != PDBBlockedSignal {
+		t.Errorf("default policy: got %q, want %q", got, PDBBlockedSignal)
+	}
+	alwaysAllow := policyv1.AlwaysAllow
+	pdb.Spec.UnhealthyPodEvictionPolicy = &alwaysAllow
+	if got := GetScalingSignal(pdb); got != HealthyPodsBlockedSignal {
+		t.Errorf("AlwaysAllow: got %q, want %q", got, HealthyPodsBlockedSignal)
+	}
+}
+
+func TestFailurePattern(t *testing.T) {
+	base := time.Now()
+	pod := func(age time.Duration, reason string) corev1.Pod {
+		p := corev1.Pod{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(base.Add(-age))}}
+		if reason != "" {
+			p.Status.ContainerStatuses = []corev1.ContainerStatus{{State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason}}}}
+		}
+		return p
+	}
+	tests := []struct {
+		name string
+		pods []corev1.Pod
+		want string
+	}{
+		{"healthy", []corev1.Pod{pod(time.Hour, ""), pod(time.Minute, "")}, NoFailurePattern},
+		{"not yet started", []corev1.Pod{pod(time.Hour, ""), pod(time.Minute, "ContainerCreating")}, NoFailurePattern},
+		{"bad rollout", []corev1.Pod{pod(time.Hour, ""), pod(2*time.Minute, "CrashLoopBackOff"), pod(time.Minute, "ImagePullBackOff")}, NewestFailurePattern},
+		{"all failing", []corev1.Pod{pod(time.Hour, "CrashLoopBackOff"), pod(time.Minute, "CrashLoopBackOff")}, NewestFailurePattern},
+		{"aging pods", []corev1.Pod{pod(time.Hour, "CrashLoopBackOff"), pod(time.Minute, "")}, OldestFailurePattern},
+		{"mixed", []corev1.Pod{pod(time.Hour, ""), pod(time.Minute*30, "CrashLoopBackOff"), pod(time.Minute, "")}, RandomFailurePattern},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FailurePattern(tt.pods); got != tt.want {
+				t.Fatalf("FailurePattern() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetModeNamespace(t *testing.T) {
+	t.Cleanup(func() { _ = SetMode(FullMode) })
+
+	if err := SetMode(NamespaceMode); err != nil {
+		t.Fatalf("SetMode(%q) returned error: %v", NamespaceMode, err)
+	}
+	if got := Name("web"); got != "" {
+		t.Fatalf("namespace mode: Name() = %q, want empty", got)
+	}
+	if err := SetMode(FullMode); err != nil {
+		t.Fatalf("SetMode(%q) returned error: %v", FullMode, err)
+	}
+	if got := Name("web"); got != "web" {
+		t.Fatalf("full mode: Name() = %q, want %q", got, "web")
+	}
+	if err := SetMode("cluster"); err == nil {
+		t.Fatal("expected an error for an unknown metrics mode")
+	}
+}
+
+func TestForgetNamespaceDropsGauges(t *testing.T) {
+	SurgePodsPendingGauge.WithLabelValues("gone", "web").Set(2)
+	SurgePodsPendingGauge.WithLabelValues("kept", "web").Set(1)
+	PDBBlockedSecondsCounter.WithLabelValues("gone", "web").Add(5)
+	t.Cleanup(func() { ForgetNamespace("kept") })
+
+	ForgetNamespace("gone")
+	if SurgePodsPendingGauge.DeleteLabelValues("gone", "web") {
+		t.Fatal("expected the deleted namespace's surge_pods_pending series to be dropped")
+	}
+	if PDBBlockedSecondsCounter.DeleteLabelValues("gone", "web") {
+		t.Fatal("expected the deleted namespace's pdb_blocked_seconds series to be dropped")
+	}
+	if !SurgePodsPendingGauge.DeleteLabelValues("kept", "web") {
+		t.Fatal("expected other namespaces' series to be kept")
+	}
+}
+
+func TestForgetTargetAndPDB(t *testing.T) {
+	SurgePodsPendingGauge.WithLabelValues("default", "web").Set(2)
+	PDBInfoGauge.WithLabelValues("default", "web", "web", MaxUnavailableMetric).Set(1)
+	PDBBlockedSecondsCounter.WithLabelValues("default", "web").Add(5)
+	SurgePodSecondsCounter.WithLabelValues("default", "web").Add(5)
+
+	ForgetTarget("default", "web")
+	if SurgePodsPendingGauge.DeleteLabelValues("default", "web") {
+		t.Fatal("expected the target's surge_pods_pending series to be dropped")
+	}
+	if SurgePodSecondsCounter.DeleteLabelValues("default", "web") {
+		t.Fatal("expected the target's surge_pod_seconds series to be dropped")
+	}
+	ForgetPDB("default", "web")
+	if PDBInfoGauge.DeleteLabelValues("default", "web", "web", MaxUnavailableMetric) {
+		t.Fatal("expected the PDB's pdb_info series to be dropped")
+	}
+	if PDBBlockedSecondsCounter.DeleteLabelValues("default", "web") {
+		t.Fatal("expected the PDB's pdb_blocked_seconds series to be dropped")
+	}
+}
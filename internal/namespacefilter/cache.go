@@ -0,0 +1,127 @@
+package namespacefilter
+
+import (
+	"context"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// decision is a cached Decision and the namespace revision it was made from.
+type decision struct {
+	Decision
+	resourceVersion string
+}
+
+// decisionCache holds Filter's decisions by namespace. It only serves and stores
+// decisions while a namespace informer invalidates them.
+type decisionCache struct {
+	mu        sync.RWMutex
+	store     toolscache.Store
+	decisions map[string]decision
+}
+
+func newDecisionCache() *decisionCache {
+	return &decisionCache{decisions: map[string]decision{}}
+}
+
+// get returns the decision for ns if it was made from the namespace revision the
+// informer holds. Invalidation handlers run after the informer's store is updated
+// and in no set order with other handlers, so a controller woken by a namespace
+// change could otherwise be served the decision from before it.
+func (d *decisionCache) get(ns string) (Decision, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.store == nil {
+		return Decision{}, false
+	}
+	cached, ok := d.decisions[ns]
+	if !ok {
+		return Decision{}, false
+	}
+	obj, exists, err := d.store.GetByKey(ns)
+	if err != nil || !exists {
+		return Decision{}, false
+	}
+	namespace, ok := obj.(*corev1.Namespace)
+	if !ok || namespace.ResourceVersion != cached.resourceVersion {
+		return Decision{}, false
+	}
+	return cached.Decision, true
+}
+
+func (d *decisionCache) put(ns string, cached decision) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.store != nil {
+		d.decisions[ns] = cached
+	}
+}
+
+// invalidate drops the decision for the namespace obj, or every decision when obj
+// is a tombstone whose namespace is unknown.
+func (d *decisionCache) invalidate(obj any) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if o, ok := obj.(client.Object); ok {
+		delete(d.decisions, o.GetName())
+		return
+	}
+	clear(d.decisions)
+}
+
+// setStore starts caching against store, or stops and forgets every decision when
+// store is nil.
+func (d *decisionCache) setStore(store toolscache.Store) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.store = store
+	clear(d.decisions)
+}
+
+// decisionInvalidator is the Runnable returned by CacheDecisions.
+type decisionInvalidator struct {
+	decisions *decisionCache
+	informers cache.Informers
+}
+
+// CacheDecisions returns a Runnable that, while the manager runs, caches Filter's
+// decisions and drops a namespace's decision whenever the namespace changes.
+// informers should back the Reader passed to Filter, which already watches
+// namespaces through them.
+func (n *nsfilter) CacheDecisions(informers cache.Informers) manager.Runnable {
+	return &decisionInvalidator{decisions: n.decisions, informers: informers}
+}
+
+func (d *decisionInvalidator) Start(ctx context.Context) error {
+	informer, err := d.informers.GetInformer(ctx, &corev1.Namespace{})
+	if err != nil {
+		return err
+	}
+	// The revision check in get reads the informer's store without copying.
+	withStore, ok := informer.(interface{ GetStore() toolscache.Store })
+	if !ok || withStore.GetStore() == nil {
+		return nil
+	}
+	registration, err := informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc:    d.decisions.invalidate,
+		UpdateFunc: func(_, obj any) { d.decisions.invalidate(obj) },
+		DeleteFunc: d.decisions.invalidate,
+	})
+	if err != nil {
+		return err
+	}
+	d.decisions.setStore(withStore.GetStore())
+	<-ctx.Done()
+	d.decisions.setStore(nil)
+	return informer.RemoveEventHandler(registration)
+}
+
+// NeedLeaderElection is false: webhooks and every shard filter namespaces.
+func (d *decisionInvalidator) NeedLeaderElection() bool {
+	return false
+}
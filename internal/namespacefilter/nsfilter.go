@@ -3,16 +3,18 @@ package namespacefilter
 import (
 	"context"
 	"fmt"
+	"hash/fnv"
 	"slices"
-	"strconv"
 
+	"github.com/azure/eviction-autoscaler/internal/annotations"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-const EnableEvictionAutoscalerAnnotationKey = "eviction-autoscaler.azure.com/enable"
+const EnableEvictionAutoscalerAnnotationKey = annotations.Enable
 
 // aksOwnedNamespaces mirrors ProtectedNamespaces in aks-rp
 // (toolkit/constvalues/automatic/subjects.go). It is intentionally unexported so its
@@ -50,60 +52,191 @@ func IsAKSOwnedNamespace(ns string) bool {
 	return slices.Contains(aksOwnedNamespaces, ns)
 }
 
+// AKSOwnedNamespaces returns a copy of the AKS-owned namespaces, the default
+// always-enabled namespaces.
+func AKSOwnedNamespaces() []string {
+	return slices.Clone(aksOwnedNamespaces)
+}
+
 type nsfilter struct {
 	disabledByDefault bool
-	hardcoded         []string
+	hardcoded         []pattern
+	// alwaysEnabled namespaces are managed regardless of the configuration and the
+	// enable annotation. Defaults to the AKS-owned namespaces.
+	alwaysEnabled []string
+	// selector enables namespaces by label like hardcoded does by name; nil matches none.
+	selector labels.Selector
+	// shardIndex/shardCount partition namespaces across controller replicas.
+	// shardCount <= 1 means sharding is off and every namespace is in shard.
+	shardIndex uint32
+	shardCount uint32
+	// exempt deployments are managed even in a namespace the filter disables.
+	exempt []types.NamespacedName
+	// decisions caches Filter's results while CacheDecisions runs.
+	decisions *decisionCache
 }
 
+// New returns a filter that, when disabledByDefault, enables the namespaces matching
+// hardcoded: names, globs or /regexes/, see ValidatePatterns.
 func New(hardcoded []string, disabledByDefault bool) *nsfilter {
 	return &nsfilter{
-		hardcoded:         hardcoded,
+		hardcoded:         compilePatterns(hardcoded),
 		disabledByDefault: disabledByDefault,
+		alwaysEnabled:     AKSOwnedNamespaces(),
+		decisions:         newDecisionCache(),
+	}
+}
+
+// WithAlwaysEnabled replaces the namespaces that are managed regardless of the
+// configuration and the enable annotation. With none, every namespace follows them.
+func (n *nsfilter) WithAlwaysEnabled(namespaces []string) *nsfilter {
+	n.alwaysEnabled = slices.Clone(namespaces)
+	return n
+}
+
+// AlwaysEnabled reports whether ns is managed regardless of the configuration and
+// the enable annotation.
+func (n *nsfilter) AlwaysEnabled(ns string) bool {
+	return slices.Contains(n.alwaysEnabled, ns)
+}
+
+// WithSelector enables the namespaces whose labels match selector, as if they were
+// listed in hardcoded. The enable annotation still takes precedence.
+func (n *nsfilter) WithSelector(selector labels.Selector) *nsfilter {
+	if selector != nil && selector.Empty() {
+		selector = nil
 	}
+	n.selector = selector
+	return n
+}
+
+// WithShard restricts the filter to the namespaces hashing to index out of count shards.
+func (n *nsfilter) WithShard(index, count uint32) *nsfilter {
+	n.shardIndex = index
+	n.shardCount = count
+	return n
+}
+
+// WithExempt manages deployments, and the PDBs and EvictionAutoScalers targeting
+// them, even in a namespace the filter disables, leaving the rest of it disabled.
+func (n *nsfilter) WithExempt(deployments ...types.NamespacedName) *nsfilter {
+	n.exempt = slices.Clone(deployments)
+	return n
+}
+
+// Exempt reports whether deployment is managed regardless of its namespace.
+func (n *nsfilter) Exempt(deployment types.NamespacedName) bool {
+	return slices.Contains(n.exempt, deployment)
+}
+
+// InShard reports whether ns belongs to this replica's shard. Unlike Filter, a
+// namespace outside the shard is not disabled: another replica owns it, so callers
+// must skip it rather than clean up.
+func (n *nsfilter) InShard(ns string) bool {
+	if n.shardCount <= 1 {
+		return true
+	}
+	return ShardFor(ns, n.shardCount) == n.shardIndex
+}
+
+// ShardFor returns the shard a namespace hashes to out of count shards.
+func ShardFor(ns string, count uint32) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(ns))
+	return h.Sum32() % count
 }
 
 type Reader interface {
 	Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error
 }
 
+// Interface reports whether eviction-autoscaler manages a namespace. Every
+// controller, watch predicate and webhook is handed the same one, so they can't
+// disagree about a namespace.
+type Interface interface {
+	Filter(ctx context.Context, c Reader, ns string) (bool, error)
+}
+
+// Sources of a Decision, in the order they are consulted.
+const (
+	SourceAlwaysEnabled = "always-enabled"
+	SourceAnnotation    = "annotation"
+	SourceSelector      = "selector"
+	SourceActioned      = "actioned-namespaces"
+	SourceDefault       = "default"
+)
+
+// Decision is whether eviction-autoscaler manages a namespace and what decided it.
+type Decision struct {
+	Enabled bool
+	Source  string
+}
+
+// Filter reports whether eviction-autoscaler manages namespace ns. While
+// CacheDecisions runs, decisions are served from memory until the namespace changes.
 func (n *nsfilter) Filter(ctx context.Context, c Reader, ns string) (bool, error) {
+	d, err := n.Decide(ctx, c, ns)
+	return d.Enabled, err
+}
+
+// Decide is Filter, also naming the source of the decision.
+func (n *nsfilter) Decide(ctx context.Context, c Reader, ns string) (Decision, error) {
+	if d, ok := n.decisions.get(ns); ok {
+		return d, nil
+	}
+	d, resourceVersion, err := n.decide(ctx, c, ns)
+	if err == nil && resourceVersion != "" {
+		n.decisions.put(ns, decision{Decision: d, resourceVersion: resourceVersion})
+	}
+	return d, err
+}
+
+// decide computes Filter's decision and returns the revision of the namespace it
+// read, or "" if it read none.
+func (n *nsfilter) decide(ctx context.Context, c Reader, ns string) (Decision, string, error) {
 	logger := ctrl.LoggerFrom(ctx)
 
-	// AKS-owned namespaces are always managed, ignoring config and the enable annotation.
-	if IsAKSOwnedNamespace(ns) {
-		logger.Info("namespace filtering decision", "namespace", ns, "source", "aks-owned", "filtering", true)
-		return true, nil
+	// Always-enabled namespaces, by default the AKS-owned ones, ignore config and the
+	// enable annotation.
+	if n.AlwaysEnabled(ns) {
+		logger.Info("namespace filtering decision", "namespace", ns, "source", SourceAlwaysEnabled, "filtering", true)
+		return Decision{Enabled: true, Source: SourceAlwaysEnabled}, "", nil
 	}
 
 	// Fetch the namespace to check for the annotation
 	namespace := &corev1.Namespace{}
 	err := c.Get(ctx, types.NamespacedName{Name: ns}, namespace)
 	if err != nil {
-		return false, fmt.Errorf("failed to get namespace %s: %w", ns, err)
+		return Decision{}, "", fmt.Errorf("failed to get namespace %s: %w", ns, err)
 	}
 
 	//annotation takes precedence
 	val, ok := namespace.Annotations[EnableEvictionAutoscalerAnnotationKey]
 	if ok {
-		value, err := strconv.ParseBool(val)
+		value, err := annotations.Bool(EnableEvictionAutoscalerAnnotationKey, val)
 		if err != nil {
-			return false, fmt.Errorf("failed to parse annotation value %s: %w", val, err)
+			return Decision{}, "", err
 		}
-		logger.Info("namespace filtering decision", "namespace", ns, "annotation", EnableEvictionAutoscalerAnnotationKey, "value", value, "filtering", value)
-		return value, nil
+		logger.Info("namespace filtering decision", "namespace", ns, "source", SourceAnnotation, "value", value, "filtering", value)
+		return Decision{Enabled: value, Source: SourceAnnotation}, namespace.ResourceVersion, nil
 	}
 
+	// namespaces matching the label selector are enabled; with disabledByDefault=false they already are
+	if n.selector != nil && n.selector.Matches(labels.Set(namespace.Labels)) {
+		logger.Info("namespace filtering decision", "namespace", ns, "source", SourceSelector, "selector", n.selector.String(), "filtering", true)
+		return Decision{Enabled: true, Source: SourceSelector}, namespace.ResourceVersion, nil
+	}
 	// if namespaces are disabled by default (disabledByDefault=true) and namespace is in hardcoded list, enable it
 	// if namespaces are enabled by default (disabledByDefault=false), hardcoded list is ignored
-	if n.disabledByDefault && slices.Contains(n.hardcoded, ns) {
-		logger.Info("namespace filtering decision", "namespace", ns, "source", "hardcoded", "disabledByDefault", true, "filtering", true)
-		return true, nil
+	if i := slices.IndexFunc(n.hardcoded, func(p pattern) bool { return p.match(ns) }); n.disabledByDefault && i >= 0 {
+		logger.Info("namespace filtering decision", "namespace", ns, "source", SourceActioned, "pattern", n.hardcoded[i].entry, "disabledByDefault", true, "filtering", true)
+		return Decision{Enabled: true, Source: SourceActioned}, namespace.ResourceVersion, nil
 	}
 
 	// If the namespace is not in the hardcoded list, return the default value
 	// disabledByDefault=true (ENABLED_BY_DEFAULT=false): return false (disabled by default)
 	// disabledByDefault=false (ENABLED_BY_DEFAULT=true): return true (enabled by default)
 	defaultValue := !n.disabledByDefault
-	logger.Info("namespace filtering decision", "namespace", ns, "source", "default", "disabledByDefault", n.disabledByDefault, "filtering", defaultValue)
-	return defaultValue, nil
+	logger.Info("namespace filtering decision", "namespace", ns, "source", SourceDefault, "disabledByDefault", n.disabledByDefault, "filtering", defaultValue)
+	return Decision{Enabled: defaultValue, Source: SourceDefault}, namespace.ResourceVersion, nil
 }
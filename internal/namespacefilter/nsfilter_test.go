@@ -2,12 +2,21 @@ package namespacefilter
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllertest"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 )
@@ -473,3 +482,337 @@ func TestFilter_RealWorldScenario_OptOut(t *testing.T) {
 		t.Errorf("expected disabled to be disabled via annotation, got %v", result)
 	}
 }
+
+// Patterns: ACTIONED_NAMESPACES entries may be globs or /regexes/
+
+func TestFilter_OptIn_Patterns(t *testing.T) {
+	filter := New([]string{"team-*", "/^prod-[a-z]+$/", "staging"}, true)
+
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	names := []string{"team-a", "prod-payments", "prod-1", "staging", "staging-2", "myteam-a"}
+	var namespaces []client.Object
+	for _, name := range names {
+		namespaces = append(namespaces, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}})
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(namespaces...).Build()
+	ctx := context.Background()
+
+	tests := map[string]bool{
+		"team-a":        true,  // glob
+		"prod-payments": true,  // regex
+		"prod-1":        false, // regex requires letters
+		"staging":       true,  // literal
+		"staging-2":     false, // literals match exactly
+		"myteam-a":      false, // globs match the whole name
+	}
+	for ns, expected := range tests {
+		result, err := filter.Filter(ctx, fakeClient, ns)
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %v", ns, err)
+		}
+		if result != expected {
+			t.Errorf("expected %s to be %v, got %v", ns, expected, result)
+		}
+	}
+}
+
+func TestValidatePatterns(t *testing.T) {
+	if err := ValidatePatterns([]string{"team-*", "/^prod-.*$/", "kube-system", "ns-?"}); err != nil {
+		t.Errorf("unexpected error for valid patterns: %v", err)
+	}
+	for _, entry := range []string{"/prod-(/", "team-[a"} {
+		if err := ValidatePatterns([]string{"default", entry}); err == nil {
+			t.Errorf("expected %q to be rejected", entry)
+		}
+	}
+}
+
+// Label selector: matching namespaces are enabled like those in the hardcoded list
+
+func TestFilter_OptIn_Selector(t *testing.T) {
+	filter := New([]string{}, true).WithSelector(labels.SelectorFromSet(labels.Set{"tier": "critical"}))
+
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	namespaces := []client.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "payments", Labels: map[string]string{"tier": "critical"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "batch", Labels: map[string]string{"tier": "best-effort"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:        "ledger",
+			Labels:      map[string]string{"tier": "critical"},
+			Annotations: map[string]string{EnableEvictionAutoscalerAnnotationKey: "false"},
+		}},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(namespaces...).Build()
+	ctx := context.Background()
+
+	tests := map[string]bool{
+		"payments": true,  // matches the selector
+		"batch":    false, // doesn't match and opt-in mode disables it
+		"ledger":   false, // annotation takes precedence over the selector
+	}
+	for ns, expected := range tests {
+		result, err := filter.Filter(ctx, fakeClient, ns)
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %v", ns, err)
+		}
+		if result != expected {
+			t.Errorf("expected %s to be %v, got %v", ns, expected, result)
+		}
+	}
+}
+
+func TestFilter_EmptySelectorMatchesNothing(t *testing.T) {
+	filter := New([]string{}, true).WithSelector(labels.Everything())
+
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-namespace"}}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(ns).Build()
+
+	result, err := filter.Filter(context.Background(), fakeClient, "test-namespace")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != false {
+		t.Errorf("expected an empty --namespace-selector to enable nothing, got %v", result)
+	}
+}
+
+func TestFilter_AlwaysEnabled(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	optedOut := map[string]string{EnableEvictionAutoscalerAnnotationKey: "false"}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system", Annotations: optedOut}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "monitoring", Annotations: optedOut}},
+	).Build()
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		filter   *nsfilter
+		expected map[string]bool
+	}{
+		// AKS-owned namespaces ignore the annotation by default
+		{"default", New(nil, true), map[string]bool{"kube-system": true, "monitoring": false}},
+		{"replaced", New(nil, true).WithAlwaysEnabled([]string{"monitoring"}), map[string]bool{"kube-system": false, "monitoring": true}},
+		{"none", New(nil, true).WithAlwaysEnabled(nil), map[string]bool{"kube-system": false, "monitoring": false}},
+	}
+	for _, tt := range tests {
+		for ns, expected := range tt.expected {
+			result, err := tt.filter.Filter(ctx, fakeClient, ns)
+			if err != nil {
+				t.Fatalf("%s: unexpected error for %s: %v", tt.name, ns, err)
+			}
+			if result != expected {
+				t.Errorf("%s: expected %s to be %v, got %v", tt.name, ns, expected, result)
+			}
+		}
+	}
+}
+
+// TestDecide_MatchesFilter walks every source of a decision, in precedence order,
+// and checks that Filter, which controllers, watch predicates and webhooks call, and
+// Decide, which also names the source, agree.
+func TestDecide_MatchesFilter(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	namespace := func(name string, labels, annotations map[string]string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels, Annotations: annotations}}
+	}
+	critical := map[string]string{"tier": "critical"}
+	optIn := map[string]string{EnableEvictionAutoscalerAnnotationKey: "true"}
+	optOut := map[string]string{EnableEvictionAutoscalerAnnotationKey: "false"}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		namespace("kube-system", nil, optOut),
+		namespace("payments", critical, nil),
+		namespace("ledger", critical, optOut),
+		namespace("team-a", nil, nil),
+		namespace("team-b", nil, optOut),
+		namespace("batch", nil, nil),
+		namespace("staging", nil, optIn),
+	).Build()
+	optInMode := func() *nsfilter {
+		return New([]string{"team-*"}, true).WithSelector(labels.SelectorFromSet(critical))
+	}
+
+	tests := []struct {
+		filter  *nsfilter
+		ns      string
+		enabled bool
+		source  string
+	}{
+		{optInMode(), "kube-system", true, SourceAlwaysEnabled},
+		{optInMode().WithAlwaysEnabled(nil), "kube-system", false, SourceAnnotation},
+		{optInMode(), "ledger", false, SourceAnnotation},
+		{optInMode(), "team-b", false, SourceAnnotation},
+		{optInMode(), "staging", true, SourceAnnotation},
+		{optInMode(), "payments", true, SourceSelector},
+		{optInMode(), "team-a", true, SourceActioned},
+		{optInMode(), "batch", false, SourceDefault},
+		{New([]string{"team-*"}, false), "team-a", true, SourceDefault},
+		{New(nil, false), "batch", true, SourceDefault},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		d, err := tt.filter.Decide(ctx, fakeClient, tt.ns)
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %v", tt.ns, err)
+		}
+		if d.Enabled != tt.enabled || d.Source != tt.source {
+			t.Errorf("expected %s to be %v by %s, got %v by %s", tt.ns, tt.enabled, tt.source, d.Enabled, d.Source)
+		}
+		enabled, err := tt.filter.Filter(ctx, fakeClient, tt.ns)
+		if err != nil || enabled != d.Enabled {
+			t.Errorf("Filter disagrees with Decide for %s: %v, %v", tt.ns, enabled, err)
+		}
+	}
+}
+
+// Sharding: namespaces are partitioned across replicas by hash
+
+func TestInShard_NoSharding(t *testing.T) {
+	filter := New([]string{}, true)
+
+	for _, ns := range []string{"default", "kube-system", "production"} {
+		if !filter.InShard(ns) {
+			t.Errorf("expected %s to be in shard when sharding is off", ns)
+		}
+	}
+}
+
+func TestInShard_ExactlyOneShardOwnsEachNamespace(t *testing.T) {
+	const count = 4
+	filters := make([]*nsfilter, count)
+	for i := range filters {
+		filters[i] = New([]string{}, true).WithShard(uint32(i), count)
+	}
+
+	for _, ns := range []string{"default", "kube-system", "production", "staging", "team-a", "team-b"} {
+		owners := 0
+		for i, f := range filters {
+			if f.InShard(ns) {
+				owners++
+				if uint32(i) != ShardFor(ns, count) {
+					t.Errorf("namespace %s owned by shard %d, expected %d", ns, i, ShardFor(ns, count))
+				}
+			}
+		}
+		if owners != 1 {
+			t.Errorf("expected exactly one shard to own %s, got %d", ns, owners)
+		}
+	}
+}
+
+// Exemptions: single deployments managed in a disabled namespace
+
+func TestExempt_LeavesNamespaceDisabled(t *testing.T) {
+	own := types.NamespacedName{Namespace: "eviction-autoscaler", Name: "eviction-autoscaler"}
+	filter := New([]string{}, true).WithExempt(own)
+	c := fake.NewClientBuilder().WithObjects(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: own.Namespace}}).Build()
+
+	if !filter.Exempt(own) {
+		t.Errorf("expected %s to be exempt", own)
+	}
+	if filter.Exempt(types.NamespacedName{Namespace: own.Namespace, Name: "other"}) {
+		t.Error("expected other deployments in the namespace not to be exempt")
+	}
+	enabled, err := filter.Filter(context.Background(), c, own.Namespace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if enabled {
+		t.Error("expected an exemption to leave the namespace disabled")
+	}
+}
+
+// Decision cache: repeated decisions are served from memory while CacheDecisions runs
+
+// storeInformer is a fake namespace informer backed by a real store.
+type storeInformer struct {
+	*controllertest.FakeInformer
+	store toolscache.Store
+}
+
+func (s *storeInformer) GetStore() toolscache.Store { return s.store }
+
+// storeReader reads namespaces from the informer's store, counting reads.
+type storeReader struct {
+	store toolscache.Store
+	gets  int
+}
+
+func (r *storeReader) Get(_ context.Context, key client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+	r.gets++
+	stored, exists, err := r.store.GetByKey(key.Name)
+	if err != nil || !exists {
+		return fmt.Errorf("namespace %s not found", key.Name)
+	}
+	*obj.(*corev1.Namespace) = *stored.(*corev1.Namespace).DeepCopy()
+	return nil
+}
+
+func TestFilter_CachesDecisionsUntilNamespaceChanges(t *testing.T) {
+	filter := New([]string{}, true)
+	informer := &storeInformer{FakeInformer: &controllertest.FakeInformer{}, store: toolscache.NewStore(toolscache.MetaNamespaceKeyFunc)}
+	informers := &informertest.FakeInformers{InformersByGVK: map[schema.GroupVersionKind]toolscache.SharedIndexInformer{
+		corev1.SchemeGroupVersion.WithKind("Namespace"): informer,
+	}}
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	informers.Scheme = scheme
+
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", ResourceVersion: "1"}}
+	_ = informer.store.Add(ns)
+	reader := &storeReader{store: informer.store}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- filter.CacheDecisions(informers).Start(ctx) }()
+	for !cachingStarted(filter) {
+		time.Sleep(time.Millisecond)
+	}
+
+	filterOnce := func(want bool, wantGets int) {
+		t.Helper()
+		result, err := filter.Filter(ctx, reader, "team-a")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result != want || reader.gets != wantGets {
+			t.Errorf("expected %v after %d reads, got %v after %d reads", want, wantGets, result, reader.gets)
+		}
+	}
+	filterOnce(false, 1)
+	filterOnce(false, 1)
+
+	if allocs := testing.AllocsPerRun(100, func() { _, _ = filter.Filter(ctx, reader, "team-a") }); allocs != 0 {
+		t.Errorf("expected a cached decision to allocate nothing, got %v allocations", allocs)
+	}
+
+	// The store has the new revision before the invalidation handler runs.
+	enabled := ns.DeepCopy()
+	enabled.ResourceVersion = "2"
+	enabled.Annotations = map[string]string{EnableEvictionAutoscalerAnnotationKey: "true"}
+	_ = informer.store.Update(enabled)
+	filterOnce(true, 2)
+	filterOnce(true, 2)
+
+	informer.Update(ns, enabled)
+	filterOnce(true, 3)
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	filterOnce(true, 4)
+	filterOnce(true, 5)
+}
+
+func cachingStarted(n *nsfilter) bool {
+	n.decisions.mu.RLock()
+	defer n.decisions.mu.RUnlock()
+	return n.decisions.store != nil
+}
@@ -0,0 +1,63 @@
+package namespacefilter
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// pattern matches namespace names for an ACTIONED_NAMESPACES entry: a literal name,
+// a glob such as team-*, or a regular expression between slashes such as
+// /^prod-.*$/.
+type pattern struct {
+	entry string
+	match func(ns string) bool
+}
+
+// parsePattern compiles one ACTIONED_NAMESPACES entry. Globs use path.Match syntax;
+// regular expressions match anywhere in the name unless anchored.
+func parsePattern(entry string) (pattern, error) {
+	switch {
+	case len(entry) >= 2 && strings.HasPrefix(entry, "/") && strings.HasSuffix(entry, "/"):
+		re, err := regexp.Compile(entry[1 : len(entry)-1])
+		if err != nil {
+			return pattern{}, fmt.Errorf("invalid namespace regex %s: %w", entry, err)
+		}
+		return pattern{entry: entry, match: re.MatchString}, nil
+	case strings.ContainsAny(entry, "*?["):
+		if _, err := path.Match(entry, ""); err != nil {
+			return pattern{}, fmt.Errorf("invalid namespace glob %s: %w", entry, err)
+		}
+		return pattern{entry: entry, match: func(ns string) bool {
+			ok, _ := path.Match(entry, ns)
+			return ok
+		}}, nil
+	default:
+		return pattern{entry: entry, match: func(ns string) bool { return ns == entry }}, nil
+	}
+}
+
+// ValidatePatterns reports every ACTIONED_NAMESPACES entry that doesn't compile.
+// New drops such entries, so callers check them first to fail at startup instead.
+func ValidatePatterns(entries []string) error {
+	var errs []error
+	for _, entry := range entries {
+		if _, err := parsePattern(entry); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// compilePatterns compiles entries, dropping those ValidatePatterns rejects.
+func compilePatterns(entries []string) []pattern {
+	var patterns []pattern
+	for _, entry := range entries {
+		if p, err := parsePattern(entry); err == nil {
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns
+}
@@ -0,0 +1,238 @@
+// Package pause is the controller's emergency brake. While the pause ConfigMap sets
+// its paused key, every write the controller's client makes is refused, so the
+// reconcilers keep reading, logging, recording events and exporting metrics but
+// change nothing. The ConfigMap is polled, so pausing and resuming take effect
+// without restarting the manager.
+package pause
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/azure/eviction-autoscaler/internal/metrics"
+)
+
+var log = logf.Log.WithName("pause")
+
+// Key is the ConfigMap data key that pauses the controller. Any value other than
+// one strconv.ParseBool reads as false pauses it, so a mistyped "yes" during an
+// incident still stops the writes.
+const Key = "paused"
+
+// ErrPaused is returned for writes refused while the controller is paused.
+var ErrPaused = errors.New("controller paused")
+
+const (
+	unknown int32 = iota
+	running
+	paused
+)
+
+// Switch holds whether the controller is paused, as last read from its ConfigMap.
+// Until the first successful read it refuses writes, so reconcilers starting next
+// to it can't write during an incident before it has looked.
+type Switch struct {
+	configMap types.NamespacedName
+	interval  time.Duration
+	state     atomic.Int32
+}
+
+// New returns a Switch reading the named ConfigMap every interval, or nil if name is
+// empty. A nil Switch is never paused.
+func New(namespace, name string, interval time.Duration) *Switch {
+	if name == "" {
+		return nil
+	}
+	return &Switch{configMap: types.NamespacedName{Namespace: namespace, Name: name}, interval: interval}
+}
+
+// Paused reports whether writes are refused.
+func (s *Switch) Paused() bool {
+	return s != nil && s.state.Load() != running
+}
+
+// Do runs write unless the controller is paused.
+func (s *Switch) Do(write func() error) error {
+	if s.Paused() {
+		return ErrPaused
+	}
+	return write()
+}
+
+// set records whether the controller is paused and reports whether that changed.
+func (s *Switch) set(p bool) bool {
+	state := running
+	if p {
+		state = paused
+	}
+	old := s.state.Swap(state)
+	if old == state {
+		return false
+	}
+	if p {
+		metrics.PausedGauge.Set(1)
+	} else {
+		metrics.PausedGauge.Set(0)
+	}
+	// Finding the controller running on the first read is not news.
+	return old != unknown || p
+}
+
+// Watcher returns the runnable that polls the ConfigMap with reader, which should
+// read from the API server: the manager's cache would list and watch every
+// ConfigMap in the cluster. Pausing and resuming are recorded as events on the
+// ConfigMap.
+func (s *Switch) Watcher(reader client.Reader, recorder record.EventRecorder) *Watcher {
+	return &Watcher{s: s, reader: reader, recorder: recorder}
+}
+
+// Watcher polls the pause ConfigMap of a Switch.
+type Watcher struct {
+	s        *Switch
+	reader   client.Reader
+	recorder record.EventRecorder
+}
+
+// Start polls until ctx is done.
+func (w *Watcher) Start(ctx context.Context) error {
+	ticker := time.NewTicker(w.s.interval)
+	defer ticker.Stop()
+	for {
+		w.poll(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// NeedLeaderElection is true: only the leader's reconcilers write.
+func (w *Watcher) NeedLeaderElection() bool {
+	return true
+}
+
+func (w *Watcher) poll(ctx context.Context) {
+	var cm corev1.ConfigMap
+	err := w.reader.Get(ctx, w.s.configMap, &cm)
+	if apierrors.IsNotFound(err) {
+		if w.s.set(false) {
+			log.Info("Pause ConfigMap deleted, writes resumed", "configMap", w.s.configMap)
+		}
+		return
+	}
+	if err != nil {
+		// Keep the last known state; an unreadable ConfigMap must not resume writes.
+		log.Error(err, "unable to read pause ConfigMap", "configMap", w.s.configMap)
+		return
+	}
+	value, p := pausedBy(cm.Data)
+	if !w.s.set(p) {
+		return
+	}
+	if p {
+		log.Info("Controller paused, refusing all writes", "configMap", w.s.configMap, "value", value)
+		w.event(&cm, "Paused", "eviction-autoscaler paused: all writes are refused until "+Key+" is removed or set to false")
+	} else {
+		log.Info("Controller resumed, writes allowed", "configMap", w.s.configMap)
+		w.event(&cm, "Resumed", "eviction-autoscaler resumed")
+	}
+}
+
+func (w *Watcher) event(obj runtime.Object, reason, msg string) {
+	if w.recorder != nil {
+		w.recorder.Event(obj, corev1.EventTypeNormal, reason, msg)
+	}
+}
+
+// pausedBy reports whether data pauses the controller, and the value that decided it.
+func pausedBy(data map[string]string) (string, bool) {
+	value, ok := data[Key]
+	if !ok || value == "" {
+		return value, false
+	}
+	if p, err := strconv.ParseBool(value); err == nil {
+		return value, p
+	}
+	return value, true
+}
+
+// Client wraps c so every write, including status and other subresource writes, is
+// refused while s is paused. Reads are left alone.
+func (s *Switch) Client(c client.Client) client.Client {
+	if s == nil {
+		return c
+	}
+	return &pausedClient{Client: c, s: s}
+}
+
+type pausedClient struct {
+	client.Client
+	s *Switch
+}
+
+func (c *pausedClient) Apply(ctx context.Context, obj runtime.ApplyConfiguration, opts ...client.ApplyOption) error {
+	return c.s.Do(func() error { return c.Client.Apply(ctx, obj, opts...) })
+}
+
+func (c *pausedClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	return c.s.Do(func() error { return c.Client.Create(ctx, obj, opts...) })
+}
+
+func (c *pausedClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	return c.s.Do(func() error { return c.Client.Delete(ctx, obj, opts...) })
+}
+
+func (c *pausedClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	return c.s.Do(func() error { return c.Client.Update(ctx, obj, opts...) })
+}
+
+func (c *pausedClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return c.s.Do(func() error { return c.Client.Patch(ctx, obj, patch, opts...) })
+}
+
+func (c *pausedClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	return c.s.Do(func() error { return c.Client.DeleteAllOf(ctx, obj, opts...) })
+}
+
+func (c *pausedClient) Status() client.SubResourceWriter {
+	return &subResourceWriter{SubResourceWriter: c.Client.Status(), s: c.s}
+}
+
+func (c *pausedClient) SubResource(subResource string) client.SubResourceClient {
+	sub := c.Client.SubResource(subResource)
+	return &subResourceClient{SubResourceReader: sub, subResourceWriter: subResourceWriter{SubResourceWriter: sub, s: c.s}}
+}
+
+type subResourceWriter struct {
+	client.SubResourceWriter
+	s *Switch
+}
+
+func (w *subResourceWriter) Create(ctx context.Context, obj client.Object, subResource client.Object, opts ...client.SubResourceCreateOption) error {
+	return w.s.Do(func() error { return w.SubResourceWriter.Create(ctx, obj, subResource, opts...) })
+}
+
+func (w *subResourceWriter) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	return w.s.Do(func() error { return w.SubResourceWriter.Update(ctx, obj, opts...) })
+}
+
+func (w *subResourceWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+	return w.s.Do(func() error { return w.SubResourceWriter.Patch(ctx, obj, patch, opts...) })
+}
+
+type subResourceClient struct {
+	client.SubResourceReader
+	subResourceWriter
+}
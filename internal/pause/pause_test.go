@@ -0,0 +1,86 @@
+package pause
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSwitchFollowsConfigMap(t *testing.T) {
+	ctx := context.Background()
+	pauseCM := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "pause", Namespace: "eviction-autoscaler"}}
+	other := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"}}
+	reader := fake.NewClientBuilder().WithObjects(pauseCM.DeepCopy()).Build()
+	recorder := record.NewFakeRecorder(10)
+	s := New("eviction-autoscaler", "pause", time.Second)
+	w := s.Watcher(reader, recorder)
+	c := s.Client(fake.NewClientBuilder().WithObjects(other).Build())
+
+	if err := c.Update(ctx, other); !errors.Is(err, ErrPaused) {
+		t.Fatalf("expected writes to be refused before the first read, got %v", err)
+	}
+
+	w.poll(ctx)
+	if s.Paused() {
+		t.Fatal("expected a ConfigMap without the key not to pause")
+	}
+	if err := c.Update(ctx, other); err != nil {
+		t.Fatalf("expected the write to go through, got %v", err)
+	}
+	if len(recorder.Events) != 0 {
+		t.Errorf("expected no event for starting unpaused, got %d", len(recorder.Events))
+	}
+
+	for value, want := range map[string]bool{"true": true, "false": false, "yes": true, "0": false} {
+		cm := pauseCM.DeepCopy()
+		if err := reader.Get(ctx, client.ObjectKeyFromObject(cm), cm); err != nil {
+			t.Fatal(err)
+		}
+		cm.Data = map[string]string{Key: value}
+		if err := reader.Update(ctx, cm); err != nil {
+			t.Fatal(err)
+		}
+		w.poll(ctx)
+		if s.Paused() != want {
+			t.Errorf("%s=%q: expected paused %v", Key, value, want)
+		}
+	}
+
+	// Paused again, then resumed by deleting the ConfigMap.
+	cm := pauseCM.DeepCopy()
+	_ = reader.Get(ctx, client.ObjectKeyFromObject(cm), cm)
+	cm.Data = map[string]string{Key: "true"}
+	_ = reader.Update(ctx, cm)
+	w.poll(ctx)
+	if err := c.Status().Update(ctx, other); !errors.Is(err, ErrPaused) {
+		t.Fatalf("expected status writes to be refused while paused, got %v", err)
+	}
+	if err := reader.Delete(ctx, cm); err != nil {
+		t.Fatal(err)
+	}
+	w.poll(ctx)
+	if s.Paused() {
+		t.Error("expected deleting the ConfigMap to resume")
+	}
+}
+
+func TestNilSwitch(t *testing.T) {
+	s := New("eviction-autoscaler", "", time.Second)
+	if s != nil {
+		t.Fatal("expected a nil switch without a ConfigMap name")
+	}
+	if s.Paused() {
+		t.Error("expected a nil switch never to pause")
+	}
+	c := fake.NewClientBuilder().Build()
+	if s.Client(c) != c {
+		t.Error("expected a nil switch to return the client unwrapped")
+	}
+}
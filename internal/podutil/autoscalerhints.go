@@ -0,0 +1,59 @@
+package podutil
+
+import (
+	"slices"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/azure/eviction-autoscaler/internal/annotations"
+)
+
+// pinAnnotations keep node autoscalers from removing the node a pod runs on: the
+// Cluster Autoscaler won't scale it down and Karpenter won't consolidate it.
+var pinAnnotations = map[string]string{
+	annotations.SafeToEvict:  "false",
+	annotations.DoNotDisrupt: "true",
+}
+
+// PinNode annotates pod so node autoscalers leave its node alone, and records the
+// annotations it added under annotations.AutoscalerHints. Annotations the pod already
+// carries are kept as they are. It reports whether the pod changed.
+func PinNode(pod *v1.Pod) bool {
+	if _, pinned := pod.Annotations[annotations.AutoscalerHints]; pinned {
+		return false
+	}
+	var added []string
+	for key, value := range pinAnnotations {
+		if _, ok := pod.Annotations[key]; ok {
+			continue
+		}
+		if pod.Annotations == nil {
+			pod.Annotations = map[string]string{}
+		}
+		pod.Annotations[key] = value
+		added = append(added, key)
+	}
+	if len(added) == 0 {
+		return false
+	}
+	slices.Sort(added)
+	pod.Annotations[annotations.AutoscalerHints] = strings.Join(added, ",")
+	return true
+}
+
+// UnpinNode removes the annotations PinNode added to pod. It reports whether the pod
+// changed.
+func UnpinNode(pod *v1.Pod) bool {
+	added, pinned := pod.Annotations[annotations.AutoscalerHints]
+	if !pinned {
+		return false
+	}
+	for _, key := range strings.Split(added, ",") {
+		if _, ours := pinAnnotations[key]; ours {
+			delete(pod.Annotations, key)
+		}
+	}
+	delete(pod.Annotations, annotations.AutoscalerHints)
+	return true
+}
@@ -51,3 +51,9 @@ func getPodConditionFromList(conditions []v1.PodCondition, conditionType v1.PodC
 	}
 	return -1, nil
 }
+
+// IsPodReady returns true if the pod's Ready condition is true.
+func IsPodReady(pod *v1.Pod) bool {
+	_, condition := getPodCondition(&pod.Status, v1.PodReady)
+	return condition != nil && condition.Status == v1.ConditionTrue
+}
@@ -0,0 +1,97 @@
+// Package tracing exports OpenTelemetry spans of reconciles over OTLP, so the time a
+// drain spends in each controller shows up in a tracing backend. It is configured
+// with the standard OTEL_* environment variables.
+package tracing
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ServiceName is the service.name of the spans, unless OTEL_SERVICE_NAME or
+// OTEL_RESOURCE_ATTRIBUTES set another.
+const ServiceName = "eviction-autoscaler"
+
+// shutdownTimeout bounds flushing the spans still buffered when the manager stops.
+const shutdownTimeout = 5 * time.Second
+
+// tracer is the global provider's, so spans started before NewProvider installs the
+// OTLP one, or without it, cost nothing.
+var tracer = otel.Tracer("github.com/azure/eviction-autoscaler")
+
+// Enabled reports whether the environment names an OTLP endpoint for traces, and the
+// SDK isn't disabled with OTEL_SDK_DISABLED.
+func Enabled() bool {
+	if strings.EqualFold(os.Getenv("OTEL_SDK_DISABLED"), "true") {
+		return false
+	}
+	return os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != ""
+}
+
+// Provider is a manager Runnable holding the OTLP tracer provider. It flushes the
+// spans still buffered when the manager stops.
+type Provider struct {
+	tp *sdktrace.TracerProvider
+}
+
+// NewProvider installs a tracer provider exporting over OTLP/gRPC as the global one.
+// The endpoint, headers, TLS, sampler and batching come from the OTEL_* environment
+// variables the OpenTelemetry SDK reads, e.g. OTEL_EXPORTER_OTLP_ENDPOINT and
+// OTEL_TRACES_SAMPLER.
+func NewProvider(ctx context.Context) (*Provider, error) {
+	exporter, err := otlptracegrpc.New(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", ServiceName)),
+		resource.WithTelemetrySDK(),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return &Provider{tp: tp}, nil
+}
+
+// Start waits until ctx is done, then flushes and shuts down the provider.
+func (p *Provider) Start(ctx context.Context) error {
+	<-ctx.Done()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	return p.tp.Shutdown(shutdownCtx)
+}
+
+// NeedLeaderElection is false: standby replicas reconcile nothing, but the webhook
+// and the runnables that don't need leader election still trace.
+func (p *Provider) NeedLeaderElection() bool {
+	return false
+}
+
+// Start starts a span named name as a child of the span in ctx, if any.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End ends span, marking it failed with err if set.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,64 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestEnabled(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want bool
+	}{
+		{name: "no endpoint", want: false},
+		{name: "endpoint", env: map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://collector:4317"}, want: true},
+		{name: "traces endpoint", env: map[string]string{"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT": "http://collector:4317"}, want: true},
+		{name: "sdk disabled", env: map[string]string{
+			"OTEL_EXPORTER_OTLP_ENDPOINT": "http://collector:4317",
+			"OTEL_SDK_DISABLED":           "TRUE",
+		}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"OTEL_EXPORTER_OTLP_ENDPOINT", "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "OTEL_SDK_DISABLED"} {
+				t.Setenv(key, tt.env[key])
+			}
+			if got := Enabled(); got != tt.want {
+				t.Errorf("Enabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpansNestAndRecordErrors(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(tp)
+
+	ctx, parent := Start(context.Background(), "evictionautoscaler.Reconcile")
+	_, child := Start(ctx, "Scale")
+	End(child, errors.New("conflict"))
+	End(parent, nil)
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	scale, reconcile := spans[0], spans[1]
+	if scale.Parent().SpanID() != reconcile.SpanContext().SpanID() {
+		t.Error("Scale span should be a child of the reconcile span")
+	}
+	if scale.Status().Code != codes.Error || scale.Status().Description != "conflict" {
+		t.Errorf("Scale span status = %+v, want error conflict", scale.Status())
+	}
+	if reconcile.Status().Code != codes.Unset {
+		t.Errorf("reconcile span status = %+v, want unset", reconcile.Status())
+	}
+}
@@ -0,0 +1,232 @@
+// Package v1 holds the admission webhooks served by eviction-autoscaler.
+package v1
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	eav1 "github.com/azure/eviction-autoscaler/api/v1"
+)
+
+var evictionautoscalerlog = logf.Log.WithName("evictionautoscaler-resource")
+
+// SetupEvictionAutoScalerWebhookWithManager registers the defaulting and validating
+// webhooks for EvictionAutoScaler with the manager's webhook server.
+func SetupEvictionAutoScalerWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&eav1.EvictionAutoScaler{}).
+		WithDefaulter(&EvictionAutoScalerCustomDefaulter{Client: mgr.GetClient()}).
+		WithValidator(&EvictionAutoScalerCustomValidator{Client: mgr.GetClient()}).
+		Complete()
+}
+
+// Both webhooks fail open after a short timeout: the reconciler still normalizes
+// targetKind and reports an InvalidTarget condition, so an unavailable or wedged
+// webhook never blocks PDB-created EvictionAutoScalers.
+// +kubebuilder:webhook:path=/mutate-eviction-autoscaler-azure-com-v1-evictionautoscaler,mutating=true,failurePolicy=ignore,sideEffects=None,groups=eviction-autoscaler.azure.com,resources=evictionautoscalers,verbs=create;update,versions=v1,name=mevictionautoscaler-v1.eviction-autoscaler.azure.com,admissionReviewVersions=v1,timeoutSeconds=5
+
+// EvictionAutoScalerCustomDefaulter rewrites spec.targetKind to its canonical form,
+// or fills it in from the workload owning the pods of the PDB of the same name.
+type EvictionAutoScalerCustomDefaulter struct {
+	Client client.Reader
+}
+
+var _ webhook.CustomDefaulter = &EvictionAutoScalerCustomDefaulter{}
+
+// Default normalizes casing and aliases of spec.targetKind, and drops the
+// EvictionAutoScaler's own namespace from a targetName written as namespace/name.
+// Unknown kinds and other namespaces are left as-is for the validator to reject. An empty targetKind, and an empty targetName
+// with it, is taken from the owner of the pods selected by the PDB the
+// EvictionAutoScaler is named after; if no owner is found it stays empty and the
+// validator rejects it.
+func (d *EvictionAutoScalerCustomDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	eas, ok := obj.(*eav1.EvictionAutoScaler)
+	if !ok {
+		return fmt.Errorf("expected an EvictionAutoScaler object but got %T", obj)
+	}
+	if namespace, name, ok := eav1.SplitTargetName(eas.Spec.TargetName); ok && namespace == requestNamespace(ctx, eas) {
+		evictionautoscalerlog.V(1).Info("Dropping the EvictionAutoScaler's own namespace from targetName", "name", eas.Name, "from", eas.Spec.TargetName, "to", name)
+		eas.Spec.TargetName = name
+	}
+	if eas.Spec.TargetKind == "" {
+		namespace := requestNamespace(ctx, eas)
+		kind, name, err := d.discoverTarget(ctx, namespace, eas.Name)
+		if err != nil {
+			evictionautoscalerlog.Error(err, "failed to discover the target from the PDB", "namespace", namespace, "name", eas.Name)
+		} else if kind != "" && (eas.Spec.TargetName == "" || eas.Spec.TargetName == name) {
+			evictionautoscalerlog.V(1).Info("Defaulting target from the PDB's pods", "namespace", namespace, "name", eas.Name, "kind", kind, "target", name)
+			eas.Spec.TargetKind = kind
+			eas.Spec.TargetName = name
+		}
+		return nil
+	}
+	if kind, err := eav1.NormalizeTargetKind(eas.Spec.TargetKind); err == nil && kind != eas.Spec.TargetKind {
+		evictionautoscalerlog.V(1).Info("Normalizing targetKind", "name", eas.Name, "from", eas.Spec.TargetKind, "to", kind)
+		eas.Spec.TargetKind = kind
+	}
+	return nil
+}
+
+// discoverTarget returns the kind and name of the workload controlling the pods the
+// named PDB selects: a deployment or Argo Rollout through its ReplicaSet, or a
+// statefulset. A missing PDB or one with no owned pods yields an empty kind.
+func (d *EvictionAutoScalerCustomDefaulter) discoverTarget(ctx context.Context, namespace, name string) (eav1.TargetKind, string, error) {
+	var pdb policyv1.PodDisruptionBudget
+	if err := d.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &pdb); err != nil {
+		return "", "", client.IgnoreNotFound(err)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+	if err != nil || selector.Empty() {
+		return "", "", err
+	}
+	var pods corev1.PodList
+	if err := d.Client.List(ctx, &pods, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return "", "", err
+	}
+	for i := range pods.Items {
+		owner := metav1.GetControllerOf(&pods.Items[i])
+		if owner == nil {
+			continue
+		}
+		switch owner.Kind {
+		case "StatefulSet":
+			return eav1.TargetKindStatefulSet, owner.Name, nil
+		case "ReplicaSet":
+			var rs appsv1.ReplicaSet
+			if err := d.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: owner.Name}, &rs); err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+				return "", "", err
+			}
+			owner = metav1.GetControllerOf(&rs)
+			switch {
+			case owner == nil:
+			case owner.Kind == "Deployment":
+				return eav1.TargetKindDeployment, owner.Name, nil
+			case owner.Kind == "Rollout" && strings.HasPrefix(owner.APIVersion, "argoproj.io/"):
+				return eav1.TargetKindRollout, owner.Name, nil
+			}
+		}
+	}
+	return "", "", nil
+}
+
+// requestNamespace returns the namespace of obj, falling back to the admission
+// request's for objects created from a generateName.
+func requestNamespace(ctx context.Context, obj client.Object) string {
+	if obj.GetNamespace() != "" {
+		return obj.GetNamespace()
+	}
+	if req, err := admission.RequestFromContext(ctx); err == nil {
+		return req.Namespace
+	}
+	return ""
+}
+
+// +kubebuilder:webhook:path=/validate-eviction-autoscaler-azure-com-v1-evictionautoscaler,mutating=false,failurePolicy=ignore,sideEffects=None,groups=eviction-autoscaler.azure.com,resources=evictionautoscalers,verbs=create;update,versions=v1,name=vevictionautoscaler-v1.eviction-autoscaler.azure.com,admissionReviewVersions=v1,timeoutSeconds=5
+
+// EvictionAutoScalerCustomValidator rejects EvictionAutoScalers whose target can
+// never be resolved, or that target a workload another EvictionAutoScaler in the
+// namespace already surges.
+type EvictionAutoScalerCustomValidator struct {
+	Client client.Reader
+}
+
+var _ webhook.CustomValidator = &EvictionAutoScalerCustomValidator{}
+
+// ValidateCreate validates the target of a new EvictionAutoScaler.
+func (v *EvictionAutoScalerCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	eas, ok := obj.(*eav1.EvictionAutoScaler)
+	if !ok {
+		return nil, fmt.Errorf("expected an EvictionAutoScaler object but got %T", obj)
+	}
+	return nil, v.validate(ctx, eas)
+}
+
+// ValidateUpdate only validates the target when it changes, so objects admitted
+// before the webhook existed can still have their eviction and status recorded.
+func (v *EvictionAutoScalerCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldEAS, ok := oldObj.(*eav1.EvictionAutoScaler)
+	if !ok {
+		return nil, fmt.Errorf("expected an EvictionAutoScaler object but got %T", oldObj)
+	}
+	eas, ok := newObj.(*eav1.EvictionAutoScaler)
+	if !ok {
+		return nil, fmt.Errorf("expected an EvictionAutoScaler object but got %T", newObj)
+	}
+	if oldEAS.Spec.TargetKind == eas.Spec.TargetKind && oldEAS.Spec.TargetName == eas.Spec.TargetName {
+		return nil, nil
+	}
+	return nil, v.validate(ctx, eas)
+}
+
+// ValidateDelete allows every delete.
+func (v *EvictionAutoScalerCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *EvictionAutoScalerCustomValidator) validate(ctx context.Context, eas *eav1.EvictionAutoScaler) error {
+	if err := validateTarget(eas); err != nil {
+		return err
+	}
+	return v.validateUniqueTarget(ctx, eas)
+}
+
+// validateUniqueTarget rejects eas if another EvictionAutoScaler in its namespace
+// targets the same workload: both would surge it and revert each other's surges.
+// A failed lookup admits eas, like the webhook's failure policy.
+func (v *EvictionAutoScalerCustomValidator) validateUniqueTarget(ctx context.Context, eas *eav1.EvictionAutoScaler) error {
+	namespace := requestNamespace(ctx, eas)
+	var list eav1.EvictionAutoScalerList
+	if err := v.Client.List(ctx, &list, client.InNamespace(namespace)); err != nil {
+		evictionautoscalerlog.Error(err, "failed to list EvictionAutoScalers, skipping the duplicate target check", "namespace", namespace)
+		return nil
+	}
+	kind, _ := eav1.NormalizeTargetKind(eas.Spec.TargetKind)
+	for _, other := range list.Items {
+		if other.Name == eas.Name || other.Spec.TargetName != eas.Spec.TargetName {
+			continue
+		}
+		if otherKind, err := eav1.NormalizeTargetKind(other.Spec.TargetKind); err != nil || otherKind != kind {
+			continue
+		}
+		errs := field.ErrorList{field.Duplicate(field.NewPath("spec", "targetName"),
+			fmt.Sprintf("%s %s is already targeted by EvictionAutoScaler %s", kind, eas.Spec.TargetName, other.Name))}
+		return apierrors.NewInvalid(eav1.GroupVersion.WithKind("EvictionAutoScaler").GroupKind(), eas.Name, errs)
+	}
+	return nil
+}
+
+func validateTarget(eas *eav1.EvictionAutoScaler) error {
+	var errs field.ErrorList
+	specPath := field.NewPath("spec")
+	if eas.Spec.TargetName == "" {
+		errs = append(errs, field.Required(specPath.Child("targetName"), "name of the workload to surge"))
+	} else if namespace, _, ok := eav1.SplitTargetName(eas.Spec.TargetName); ok {
+		errs = append(errs, field.Invalid(specPath.Child("targetName"), eas.Spec.TargetName,
+			fmt.Sprintf("cross-namespace targets are not supported: create the EvictionAutoScaler in namespace %s instead", namespace)))
+	}
+	if _, err := eav1.NormalizeTargetKind(eas.Spec.TargetKind); err != nil {
+		errs = append(errs, field.NotSupported(specPath.Child("targetKind"), eas.Spec.TargetKind,
+			eav1.TargetKinds))
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(eav1.GroupVersion.WithKind("EvictionAutoScaler").GroupKind(), eas.Name, errs)
+}
@@ -0,0 +1,181 @@
+package v1
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	eav1 "github.com/azure/eviction-autoscaler/api/v1"
+)
+
+func easFor(name string, kind eav1.TargetKind) *eav1.EvictionAutoScaler {
+	return &eav1.EvictionAutoScaler{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       eav1.EvictionAutoScalerSpec{TargetName: name, TargetKind: kind},
+	}
+}
+
+func fakeReader(t *testing.T, objs ...client.Object) client.Reader {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	for _, add := range []func(*runtime.Scheme) error{appsv1.AddToScheme, corev1.AddToScheme, policyv1.AddToScheme, eav1.AddToScheme} {
+		if err := add(scheme); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+}
+
+func TestDefaultNormalizesTargetKind(t *testing.T) {
+	tests := []struct {
+		kind, want eav1.TargetKind
+	}{
+		{"deployment", "deployment"},
+		{"Deployment", "deployment"},
+		{"deployments", "deployment"},
+		{"deploy", "deployment"},
+		{"StatefulSet", "statefulset"},
+		{"sts", "statefulset"},
+		{"Rollouts", "rollout"},
+		{"cronjob", "cronjob"},
+	}
+	for _, tt := range tests {
+		eas := easFor("app", tt.kind)
+		if err := (&EvictionAutoScalerCustomDefaulter{}).Default(context.Background(), eas); err != nil {
+			t.Fatalf("Default(%q) returned %v", tt.kind, err)
+		}
+		if eas.Spec.TargetKind != tt.want {
+			t.Errorf("Default(%q) = %q, want %q", tt.kind, eas.Spec.TargetKind, tt.want)
+		}
+	}
+}
+
+func TestDefaultDropsOwnNamespaceFromTargetName(t *testing.T) {
+	for targetName, want := range map[string]string{"default/app": "app", "other/app": "other/app", "app": "app"} {
+		eas := easFor("app", "deployment")
+		eas.Spec.TargetName = targetName
+		if err := (&EvictionAutoScalerCustomDefaulter{}).Default(context.Background(), eas); err != nil {
+			t.Fatalf("Default(%q) returned %v", targetName, err)
+		}
+		if eas.Spec.TargetName != want {
+			t.Errorf("Default(%q) = %q, want %q", targetName, eas.Spec.TargetName, want)
+		}
+	}
+}
+
+func TestValidateCreate(t *testing.T) {
+	tests := []struct {
+		name    string
+		eas     *eav1.EvictionAutoScaler
+		wantErr bool
+	}{
+		{"canonical kind", easFor("app", "deployment"), false},
+		{"alias", easFor("app", "Deployment"), false},
+		{"unknown kind", easFor("app", "cronjob"), true},
+		{"missing name", easFor("", "deployment"), true},
+		{"cross-namespace target", &eav1.EvictionAutoScaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+			Spec:       eav1.EvictionAutoScalerSpec{TargetName: "other/app", TargetKind: "deployment"},
+		}, true},
+	}
+	for _, tt := range tests {
+		_, err := (&EvictionAutoScalerCustomValidator{Client: fakeReader(t)}).ValidateCreate(context.Background(), tt.eas)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: ValidateCreate err = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if err != nil && !apierrors.IsInvalid(err) {
+			t.Errorf("%s: expected an Invalid status error, got %v", tt.name, err)
+		}
+	}
+}
+
+func TestValidateUpdateOnlyChecksChangedTarget(t *testing.T) {
+	v := &EvictionAutoScalerCustomValidator{Client: fakeReader(t)}
+	legacy := easFor("app", "cronjob")
+	recorded := legacy.DeepCopy()
+	recorded.Spec.LastEviction.PodName = "app-1"
+	if _, err := v.ValidateUpdate(context.Background(), legacy, recorded); err != nil {
+		t.Errorf("recording an eviction on an existing object was rejected: %v", err)
+	}
+
+	retargeted := easFor("app", "job")
+	if _, err := v.ValidateUpdate(context.Background(), easFor("app", "deployment"), retargeted); err == nil {
+		t.Error("changing targetKind to an unsupported kind was allowed")
+	}
+}
+
+func TestDefaultDiscoversTargetFromPDB(t *testing.T) {
+	controller := func(apiVersion, kind, name string) []metav1.OwnerReference {
+		return []metav1.OwnerReference{{APIVersion: apiVersion, Kind: kind, Name: name, Controller: ptr.To(true)}}
+	}
+	pod := func(name, app string, owners []metav1.OwnerReference) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": app}, OwnerReferences: owners}}
+	}
+	pdb := func(app string) *policyv1.PodDisruptionBudget {
+		return &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: app, Namespace: "default"},
+			Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": app}}},
+		}
+	}
+	d := &EvictionAutoScalerCustomDefaulter{Client: fakeReader(t,
+		pdb("web"), pod("web-abc-1", "web", controller("apps/v1", "ReplicaSet", "web-abc")),
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "web-abc", Namespace: "default", OwnerReferences: controller("apps/v1", "Deployment", "web-deploy")}},
+		pdb("db"), pod("db-0", "db", controller("apps/v1", "StatefulSet", "db")),
+		pdb("canary"), pod("canary-xyz-1", "canary", controller("apps/v1", "ReplicaSet", "canary-xyz")),
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "canary-xyz", Namespace: "default", OwnerReferences: controller("argoproj.io/v1alpha1", "Rollout", "canary")}},
+		pdb("orphan"), pod("orphan", "orphan", nil),
+	)}
+
+	tests := []struct {
+		name, targetName string
+		wantKind         eav1.TargetKind
+		wantName         string
+	}{
+		{"web", "", "deployment", "web-deploy"},
+		{"db", "", "statefulset", "db"},
+		{"canary", "canary", "rollout", "canary"},
+		{"web", "other", "", "other"},
+		{"orphan", "", "", ""},
+		{"missing", "", "", ""},
+	}
+	for _, tt := range tests {
+		eas := easFor(tt.name, "")
+		eas.Spec.TargetName = tt.targetName
+		if err := d.Default(context.Background(), eas); err != nil {
+			t.Fatalf("%s: Default returned %v", tt.name, err)
+		}
+		if eas.Spec.TargetKind != tt.wantKind || eas.Spec.TargetName != tt.wantName {
+			t.Errorf("%s: target = %s/%s, want %s/%s", tt.name, eas.Spec.TargetKind, eas.Spec.TargetName, tt.wantKind, tt.wantName)
+		}
+	}
+}
+
+func TestValidateRejectsDuplicateTarget(t *testing.T) {
+	existing := easFor("web", "deployment")
+	v := &EvictionAutoScalerCustomValidator{Client: fakeReader(t, existing)}
+
+	duplicate := easFor("web-copy", "Deployment")
+	duplicate.Spec.TargetName = "web"
+	if _, err := v.ValidateCreate(context.Background(), duplicate); !apierrors.IsInvalid(err) {
+		t.Errorf("a second EvictionAutoScaler for deployment web was admitted: %v", err)
+	}
+
+	statefulSet := duplicate.DeepCopy()
+	statefulSet.Spec.TargetKind = "statefulset"
+	if _, err := v.ValidateCreate(context.Background(), statefulSet); err != nil {
+		t.Errorf("a statefulset sharing the deployment's name was rejected: %v", err)
+	}
+
+	if _, err := v.ValidateUpdate(context.Background(), easFor("web", "statefulset"), existing); err != nil {
+		t.Errorf("updating the existing EvictionAutoScaler counted itself as a duplicate: %v", err)
+	}
+}
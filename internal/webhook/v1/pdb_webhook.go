@@ -0,0 +1,218 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/azure/eviction-autoscaler/internal/annotations"
+	"github.com/azure/eviction-autoscaler/internal/namespacefilter"
+)
+
+var pdblog = logf.Log.WithName("pdb-validation")
+
+// PDB validation modes accepted by --pdb-validation.
+const (
+	PDBValidationWarn = "warn" // admit the PDB with a warning
+	PDBValidationDeny = "deny" // reject the PDB
+)
+
+// SetupPDBWebhookWithManager registers the webhook that flags PDBs blocking every
+// eviction of their workload in namespaces eviction-autoscaler won't surge. mode is
+// PDBValidationWarn or PDBValidationDeny.
+func SetupPDBWebhookWithManager(mgr ctrl.Manager, filter namespacefilter.Interface, mode string) error {
+	if mode != PDBValidationWarn && mode != PDBValidationDeny {
+		return fmt.Errorf("unknown PDB validation mode %q, want %s or %s", mode, PDBValidationWarn, PDBValidationDeny)
+	}
+	return ctrl.NewWebhookManagedBy(mgr).For(&policyv1.PodDisruptionBudget{}).
+		WithValidator(&PDBCustomValidator{Client: mgr.GetClient(), Filter: filter, Deny: mode == PDBValidationDeny}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-policy-v1-poddisruptionbudget,mutating=false,failurePolicy=ignore,sideEffects=None,groups=policy,resources=poddisruptionbudgets,verbs=create;update,versions=v1,name=vpoddisruptionbudget.eviction-autoscaler.azure.com,admissionReviewVersions=v1,timeoutSeconds=5
+
+// PDBCustomValidator warns about, or with Deny rejects, PDBs whose minAvailable
+// covers every replica of the workloads they select, or whose maxUnavailable is 0,
+// when eviction-autoscaler won't surge those workloads. Such a PDB blocks every
+// drain of their nodes.
+type PDBCustomValidator struct {
+	Client client.Reader
+	Filter namespacefilter.Interface
+	Deny   bool
+}
+
+var _ webhook.CustomValidator = &PDBCustomValidator{}
+
+// ValidateCreate checks a new PDB.
+func (v *PDBCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	pdb, ok := obj.(*policyv1.PodDisruptionBudget)
+	if !ok {
+		return nil, fmt.Errorf("expected a PodDisruptionBudget object but got %T", obj)
+	}
+	return v.validate(ctx, pdb)
+}
+
+// ValidateUpdate only checks a PDB whose spec changes, so existing PDBs can still
+// have their metadata and status updated.
+func (v *PDBCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldPDB, ok := oldObj.(*policyv1.PodDisruptionBudget)
+	if !ok {
+		return nil, fmt.Errorf("expected a PodDisruptionBudget object but got %T", oldObj)
+	}
+	pdb, ok := newObj.(*policyv1.PodDisruptionBudget)
+	if !ok {
+		return nil, fmt.Errorf("expected a PodDisruptionBudget object but got %T", newObj)
+	}
+	if equality.Semantic.DeepEqual(oldPDB.Spec, pdb.Spec) {
+		return nil, nil
+	}
+	return v.validate(ctx, pdb)
+}
+
+// ValidateDelete allows every delete.
+func (v *PDBCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validate admits pdb unless it blocks every eviction and no surge can unblock it.
+// A failed lookup admits it, like the webhook's failure policy. PDBs the controller
+// created are always admitted: it sizes them itself and must not be refused its own
+// writes.
+func (v *PDBCustomValidator) validate(ctx context.Context, pdb *policyv1.PodDisruptionBudget) (admission.Warnings, error) {
+	if pdb.Annotations[annotations.OwnedBy] == annotations.OwnedByController {
+		return nil, nil
+	}
+	namespace := requestNamespace(ctx, pdb)
+	reason, err := v.unsatisfiable(ctx, namespace, pdb)
+	if err != nil {
+		pdblog.Error(err, "failed to check the PDB's workloads, admitting it", "namespace", namespace, "name", pdb.Name)
+		return nil, nil
+	}
+	if reason == "" {
+		return nil, nil
+	}
+	if !v.Deny {
+		return admission.Warnings{reason}, nil
+	}
+	return nil, apierrors.NewInvalid(policyv1.SchemeGroupVersion.WithKind("PodDisruptionBudget").GroupKind(), pdb.Name,
+		field.ErrorList{field.Forbidden(field.NewPath("spec"), reason)})
+}
+
+// unsatisfiable returns why pdb blocks every eviction of the workloads it selects
+// while eviction-autoscaler won't surge them, or "" if it doesn't.
+func (v *PDBCustomValidator) unsatisfiable(ctx context.Context, namespace string, pdb *policyv1.PodDisruptionBudget) (string, error) {
+	if pdb.Spec.MinAvailable == nil && pdb.Spec.MaxUnavailable == nil {
+		return "", nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+	if err != nil || selector.Empty() {
+		return "", nil
+	}
+	replicas, workloads, err := v.matchedReplicas(ctx, namespace, selector)
+	if err != nil || replicas == 0 {
+		return "", err
+	}
+
+	var blocking string
+	switch {
+	case pdb.Spec.MinAvailable != nil:
+		minAvailable, err := intstr.GetScaledValueFromIntOrPercent(pdb.Spec.MinAvailable, int(replicas), true)
+		if err != nil || minAvailable < int(replicas) {
+			return "", nil
+		}
+		blocking = fmt.Sprintf("minAvailable %s is at least the %d replicas it selects", pdb.Spec.MinAvailable.String(), replicas)
+	default:
+		maxUnavailable, err := intstr.GetScaledValueFromIntOrPercent(pdb.Spec.MaxUnavailable, int(replicas), true)
+		if err != nil || maxUnavailable > 0 {
+			return "", nil
+		}
+		blocking = fmt.Sprintf("maxUnavailable %s allows none of the %d replicas it selects to be evicted", pdb.Spec.MaxUnavailable.String(), replicas)
+	}
+
+	surged, err := v.surged(ctx, namespace, workloads)
+	if err != nil || surged {
+		return "", err
+	}
+	return fmt.Sprintf("%s, and eviction-autoscaler is not enrolled to surge them in namespace %s, so this PDB blocks every drain of their nodes", blocking, namespace), nil
+}
+
+// matchedReplicas sums the replicas of the deployments and statefulsets in namespace
+// whose pod template selector matches, and returns their annotations.
+func (v *PDBCustomValidator) matchedReplicas(ctx context.Context, namespace string, selector labels.Selector) (int32, []map[string]string, error) {
+	var replicas int32
+	var workloads []map[string]string
+	var deployments appsv1.DeploymentList
+	if err := v.Client.List(ctx, &deployments, client.InNamespace(namespace)); err != nil {
+		return 0, nil, err
+	}
+	for i := range deployments.Items {
+		deployment := &deployments.Items[i]
+		if selector.Matches(labels.Set(deployment.Spec.Template.Labels)) {
+			replicas += replicasOrDefault(deployment.Spec.Replicas)
+			workloads = append(workloads, deployment.Annotations)
+		}
+	}
+	var statefulSets appsv1.StatefulSetList
+	if err := v.Client.List(ctx, &statefulSets, client.InNamespace(namespace)); err != nil {
+		return 0, nil, err
+	}
+	for i := range statefulSets.Items {
+		statefulSet := &statefulSets.Items[i]
+		if selector.Matches(labels.Set(statefulSet.Spec.Template.Labels)) {
+			replicas += replicasOrDefault(statefulSet.Spec.Replicas)
+			workloads = append(workloads, statefulSet.Annotations)
+		}
+	}
+	return replicas, workloads, nil
+}
+
+// surged reports whether eviction-autoscaler manages namespace and would surge every
+// workload: the surge annotation on a workload, then on its namespace, must not be
+// false.
+func (v *PDBCustomValidator) surged(ctx context.Context, namespace string, workloads []map[string]string) (bool, error) {
+	enrolled, err := v.Filter.Filter(ctx, v.Client, namespace)
+	if err != nil || !enrolled {
+		return false, err
+	}
+	var ns corev1.Namespace
+	if err := v.Client.Get(ctx, types.NamespacedName{Name: namespace}, &ns); err != nil {
+		return false, err
+	}
+	for _, workload := range workloads {
+		val, ok := workload[annotations.Surge]
+		if !ok {
+			val, ok = ns.Annotations[annotations.Surge]
+		}
+		if !ok {
+			continue
+		}
+		if enabled, err := annotations.Bool(annotations.Surge, val); err == nil && !enabled {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// replicasOrDefault returns the replica count of a workload, which defaults to 1.
+func replicasOrDefault(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
@@ -0,0 +1,108 @@
+package v1
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/azure/eviction-autoscaler/internal/annotations"
+	"github.com/azure/eviction-autoscaler/internal/namespacefilter"
+)
+
+// enrolledNamespaces is a namespacefilter.Interface managing a fixed set of namespaces.
+type enrolledNamespaces map[string]bool
+
+func (e enrolledNamespaces) Filter(_ context.Context, _ namespacefilter.Reader, ns string) (bool, error) {
+	return e[ns], nil
+}
+
+func TestPDBValidation(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := appsv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	deployment := func(namespace string, replicas int32, annotations map[string]string) *appsv1.Deployment {
+		d := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: namespace, Annotations: annotations}}
+		d.Spec.Replicas = ptr.To(replicas)
+		d.Spec.Template.Labels = map[string]string{"app": "web"}
+		return d
+	}
+	objs := []runtime.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "plain"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "enrolled"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "no-surge", Annotations: map[string]string{annotations.Surge: "false"}}},
+		deployment("plain", 3, nil),
+		deployment("enrolled", 3, nil),
+		deployment("no-surge", 3, nil),
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objs...).Build()
+	filter := enrolledNamespaces{"enrolled": true, "no-surge": true}
+
+	pdb := func(namespace string, minAvailable, maxUnavailable *intstr.IntOrString) *policyv1.PodDisruptionBudget {
+		return &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: namespace},
+			Spec: policyv1.PodDisruptionBudgetSpec{
+				MinAvailable:   minAvailable,
+				MaxUnavailable: maxUnavailable,
+				Selector:       &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			},
+		}
+	}
+	three, two, all, zero := intstr.FromInt32(3), intstr.FromInt32(2), intstr.FromString("100%"), intstr.FromInt32(0)
+	owned := pdb("plain", &three, nil)
+	owned.Annotations = map[string]string{annotations.OwnedBy: annotations.OwnedByController}
+
+	for _, tc := range []struct {
+		name  string
+		pdb   *policyv1.PodDisruptionBudget
+		flags bool
+	}{
+		{"minAvailable equals replicas", pdb("plain", &three, nil), true},
+		{"minAvailable of 100%", pdb("plain", &all, nil), true},
+		{"maxUnavailable of 0", pdb("plain", nil, &zero), true},
+		{"surge disabled on the namespace", pdb("no-surge", &three, nil), true},
+		{"minAvailable below replicas", pdb("plain", &two, nil), false},
+		{"enrolled namespace", pdb("enrolled", &three, nil), false},
+		{"no matching workload", pdb("empty", &three, nil), false},
+		{"created by the controller", owned, false},
+	} {
+		warn := &PDBCustomValidator{Client: c, Filter: filter}
+		warnings, err := warn.ValidateCreate(context.Background(), tc.pdb)
+		if err != nil {
+			t.Fatalf("%s: warn mode returned %v", tc.name, err)
+		}
+		if got := len(warnings) > 0; got != tc.flags {
+			t.Errorf("%s: warned = %v, want %v (%v)", tc.name, got, tc.flags, warnings)
+		}
+
+		deny := &PDBCustomValidator{Client: c, Filter: filter, Deny: true}
+		_, err = deny.ValidateCreate(context.Background(), tc.pdb)
+		if got := apierrors.IsInvalid(err); got != tc.flags {
+			t.Errorf("%s: denied = %v, want %v (%v)", tc.name, got, tc.flags, err)
+		}
+	}
+}
+
+func TestPDBValidationSkipsUnchangedSpec(t *testing.T) {
+	v := &PDBCustomValidator{Client: fake.NewClientBuilder().Build(), Filter: enrolledNamespaces{}, Deny: true}
+	three := intstr.FromInt32(3)
+	old := &policyv1.PodDisruptionBudget{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "plain"},
+		Spec: policyv1.PodDisruptionBudgetSpec{MinAvailable: &three}}
+	updated := old.DeepCopy()
+	updated.Labels = map[string]string{"team": "a"}
+	if _, err := v.ValidateUpdate(context.Background(), old, updated); err != nil {
+		t.Errorf("metadata-only update should be admitted, got %v", err)
+	}
+}
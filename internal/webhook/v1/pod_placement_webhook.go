@@ -0,0 +1,193 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/azure/eviction-autoscaler/internal/annotations"
+	"github.com/azure/eviction-autoscaler/internal/features"
+	"github.com/azure/eviction-autoscaler/internal/podutil"
+)
+
+var podlog = logf.Log.WithName("pod-placement")
+
+// SetupPodPlacementWebhookWithManager registers the webhook that applies the surge
+// hints the controller recorded on a deployment to its new pods: the draining nodes
+// to keep off, the PriorityClass to run at, the batch to be released with and
+// whether node autoscalers must leave their nodes alone. flags, when set, narrows
+// that to the namespaces its runtime feature flags turn it on in.
+func SetupPodPlacementWebhookWithManager(mgr ctrl.Manager, flags *features.Set) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&corev1.Pod{}).
+		WithDefaulter(&PodPlacementCustomDefaulter{Client: mgr.GetClient(), Features: flags}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate--v1-pod,mutating=true,failurePolicy=ignore,sideEffects=None,groups="",resources=pods,verbs=create,versions=v1,name=mpod-placement.eviction-autoscaler.azure.com,admissionReviewVersions=v1,timeoutSeconds=5
+
+// +kubebuilder:rbac:groups=scheduling.k8s.io,resources=priorityclasses,verbs=get;list;watch
+
+// PodPlacementCustomDefaulter adds a required node anti-affinity against the
+// deployment's avoid-nodes annotation, the PriorityClass from its
+// surge-priority-class annotation, the scheduling gate for its surge-batch
+// annotation, and the node autoscaler annotations for its autoscaler-hints
+// annotation, to pods created while a surge is active.
+type PodPlacementCustomDefaulter struct {
+	Client client.Reader
+	// Features, when set, leaves pods alone in namespaces where webhook gating is
+	// off, and ungated in namespaces where surge batching is off.
+	Features *features.Set
+}
+
+var _ webhook.CustomDefaulter = &PodPlacementCustomDefaulter{}
+
+// Default leaves pods alone unless they belong to a deployment carrying a hint. A
+// returned error would deny the pod rather than fail open, so lookup errors are
+// logged and the pod is admitted unchanged.
+func (d *PodPlacementCustomDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return fmt.Errorf("expected a Pod object but got %T", obj)
+	}
+	if pod.Spec.NodeName != "" {
+		return nil
+	}
+	namespace := pod.Namespace
+	if namespace == "" {
+		// Pods created from a generateName may not carry their namespace yet.
+		if req, err := admission.RequestFromContext(ctx); err == nil {
+			namespace = req.Namespace
+		}
+	}
+	if !d.Features.Enabled(features.WebhookGating, namespace) {
+		return nil
+	}
+
+	deployment, err := d.owningDeployment(ctx, namespace, pod)
+	if err != nil {
+		podlog.Error(err, "failed to look up the pod's deployment, admitting it unchanged", "namespace", namespace)
+		return nil
+	}
+	if deployment == nil {
+		return nil
+	}
+	if value := deployment.Annotations[annotations.AvoidNodes]; value != "" {
+		nodes := strings.Split(value, ",")
+		podlog.V(1).Info("Keeping surge pod off draining nodes", "namespace", namespace, "deployment", deployment.Name, "nodes", nodes)
+		avoidNodes(pod, nodes)
+	}
+	if name := deployment.Annotations[annotations.SurgePriorityClass]; name != "" {
+		if err := d.raisePriority(ctx, pod, name); err != nil {
+			podlog.Error(err, "failed to raise surge pod priority", "namespace", namespace, "priorityClass", name)
+		}
+	}
+	if deployment.Annotations[annotations.AutoscalerHints] == "true" && podutil.PinNode(pod) {
+		podlog.V(1).Info("Keeping node autoscalers off surge pod's node", "namespace", namespace, "deployment", deployment.Name)
+	}
+	if batch := deployment.Annotations[annotations.SurgeBatch]; batch != "" && d.Features.Enabled(features.SurgeBatches, namespace) {
+		podlog.V(1).Info("Gating surge pod until its batch is released", "namespace", namespace, "deployment", deployment.Name, "batch", batch)
+		gateBatch(pod, batch)
+	}
+	return nil
+}
+
+// gateBatch tags the pod with its surge batch and holds it from scheduling until
+// the controller lifts the gate for the whole batch. Node provisioners ignore gated
+// pods, so the batch reaches them as one wave.
+func gateBatch(pod *corev1.Pod, batch string) {
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	pod.Annotations[annotations.SurgeBatch] = batch
+	for _, gate := range pod.Spec.SchedulingGates {
+		if gate.Name == annotations.SurgeBatch {
+			return
+		}
+	}
+	pod.Spec.SchedulingGates = append(pod.Spec.SchedulingGates, corev1.PodSchedulingGate{Name: annotations.SurgeBatch})
+}
+
+// raisePriority moves the pod to the named PriorityClass unless it already runs at
+// a higher priority. The Priority admission plugin resolved the pod's original class
+// before this webhook ran, so the integer priority and preemption policy are set to
+// match the new class as well. A missing class leaves the pod alone.
+func (d *PodPlacementCustomDefaulter) raisePriority(ctx context.Context, pod *corev1.Pod, name string) error {
+	var class schedulingv1.PriorityClass
+	if err := d.Client.Get(ctx, types.NamespacedName{Name: name}, &class); err != nil {
+		if apierrors.IsNotFound(err) {
+			podlog.Info("Surge priority class not found, leaving pod priority alone", "priorityClass", name)
+			return nil
+		}
+		return err
+	}
+	if pod.Spec.Priority != nil && *pod.Spec.Priority >= class.Value {
+		return nil
+	}
+	podlog.V(1).Info("Raising surge pod priority", "namespace", pod.Namespace, "priorityClass", name, "priority", class.Value)
+	pod.Spec.PriorityClassName = class.Name
+	pod.Spec.Priority = &class.Value
+	pod.Spec.PreemptionPolicy = class.PreemptionPolicy
+	return nil
+}
+
+// owningDeployment follows the pod's controlling ReplicaSet to its Deployment. It
+// returns nil for pods not owned that way or whose owners are already gone.
+func (d *PodPlacementCustomDefaulter) owningDeployment(ctx context.Context, namespace string, pod *corev1.Pod) (*appsv1.Deployment, error) {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil || owner.Kind != "ReplicaSet" {
+		return nil, nil
+	}
+	var rs appsv1.ReplicaSet
+	if err := d.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: owner.Name}, &rs); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	owner = metav1.GetControllerOf(&rs)
+	if owner == nil || owner.Kind != "Deployment" {
+		return nil, nil
+	}
+	var deployment appsv1.Deployment
+	if err := d.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: owner.Name}, &deployment); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	return &deployment, nil
+}
+
+// avoidNodes requires the pod to schedule outside nodes. Node selector terms are
+// ORed, so the requirement is added to every existing term.
+func avoidNodes(pod *corev1.Pod, nodes []string) {
+	requirement := corev1.NodeSelectorRequirement{
+		Key:      "metadata.name",
+		Operator: corev1.NodeSelectorOpNotIn,
+		Values:   nodes,
+	}
+	if pod.Spec.Affinity == nil {
+		pod.Spec.Affinity = &corev1.Affinity{}
+	}
+	if pod.Spec.Affinity.NodeAffinity == nil {
+		pod.Spec.Affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	nodeAffinity := pod.Spec.Affinity.NodeAffinity
+	if nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = &corev1.NodeSelector{}
+	}
+	selector := nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	if len(selector.NodeSelectorTerms) == 0 {
+		selector.NodeSelectorTerms = []corev1.NodeSelectorTerm{{}}
+	}
+	for i := range selector.NodeSelectorTerms {
+		selector.NodeSelectorTerms[i].MatchFields = append(selector.NodeSelectorTerms[i].MatchFields, requirement)
+	}
+}
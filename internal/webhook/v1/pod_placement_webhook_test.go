@@ -0,0 +1,226 @@
+package v1
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/azure/eviction-autoscaler/internal/annotations"
+	"github.com/azure/eviction-autoscaler/internal/features"
+)
+
+func controlledBy(kind, name string) []metav1.OwnerReference {
+	return []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: kind, Name: name, Controller: ptr.To(true)}}
+}
+
+func TestPodPlacementDefault(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := appsv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	hinted := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "hinted", Namespace: "default",
+		Annotations: map[string]string{annotations.AvoidNodes: "node-a,node-b"}}}
+	plain := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "plain", Namespace: "default"}}
+	hintedRS := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "hinted-1", Namespace: "default",
+		OwnerReferences: controlledBy("Deployment", "hinted")}}
+	plainRS := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "plain-1", Namespace: "default",
+		OwnerReferences: controlledBy("Deployment", "plain")}}
+	d := &PodPlacementCustomDefaulter{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(hinted, plain, hintedRS, plainRS).Build(),
+	}
+
+	podOf := func(rs string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", OwnerReferences: controlledBy("ReplicaSet", rs)}}
+	}
+
+	pod := podOf("hinted-1")
+	if err := d.Default(context.Background(), pod); err != nil {
+		t.Fatal(err)
+	}
+	terms := pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	if len(terms) != 1 || len(terms[0].MatchFields) != 1 || terms[0].MatchFields[0].Operator != corev1.NodeSelectorOpNotIn {
+		t.Fatalf("expected a single NotIn term, got %+v", terms)
+	}
+	if got := terms[0].MatchFields[0].Values; len(got) != 2 || got[0] != "node-a" || got[1] != "node-b" {
+		t.Errorf("avoided nodes = %v, want [node-a node-b]", got)
+	}
+
+	for _, pod := range []*corev1.Pod{podOf("plain-1"), podOf("gone"), {ObjectMeta: metav1.ObjectMeta{Namespace: "default"}}} {
+		if err := d.Default(context.Background(), pod); err != nil {
+			t.Fatal(err)
+		}
+		if pod.Spec.Affinity != nil {
+			t.Errorf("pod owned by %v should not be changed", pod.OwnerReferences)
+		}
+	}
+}
+
+func TestAvoidNodesKeepsExistingTerms(t *testing.T) {
+	zone := corev1.NodeSelectorRequirement{Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"1"}}
+	pod := &corev1.Pod{Spec: corev1.PodSpec{Affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{
+			{MatchExpressions: []corev1.NodeSelectorRequirement{zone}},
+			{MatchExpressions: []corev1.NodeSelectorRequirement{zone}},
+		}},
+	}}}}
+	avoidNodes(pod, []string{"node-a"})
+	for i, term := range pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		if len(term.MatchExpressions) != 1 || len(term.MatchFields) != 1 {
+			t.Errorf("term %d = %+v, want the zone expression and the node exclusion", i, term)
+		}
+	}
+}
+
+func TestPodPlacementRaisesPriority(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := appsv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := schedulingv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	never := corev1.PreemptNever
+	surge := &schedulingv1.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: "surge"}, Value: 1000, PreemptionPolicy: &never}
+	prioritized := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default",
+		Annotations: map[string]string{annotations.SurgePriorityClass: "surge"}}}
+	missing := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "missing", Namespace: "default",
+		Annotations: map[string]string{annotations.SurgePriorityClass: "gone"}}}
+	d := &PodPlacementCustomDefaulter{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(surge, prioritized, missing,
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "app-1", Namespace: "default", OwnerReferences: controlledBy("Deployment", "app")}},
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "missing-1", Namespace: "default", OwnerReferences: controlledBy("Deployment", "missing")}},
+	).Build()}
+
+	podAt := func(rs string, priority int32) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", OwnerReferences: controlledBy("ReplicaSet", rs)},
+			Spec:       corev1.PodSpec{PriorityClassName: "default", Priority: ptr.To(priority)},
+		}
+	}
+
+	low := podAt("app-1", 0)
+	if err := d.Default(context.Background(), low); err != nil {
+		t.Fatal(err)
+	}
+	if low.Spec.PriorityClassName != "surge" || *low.Spec.Priority != 1000 || *low.Spec.PreemptionPolicy != corev1.PreemptNever {
+		t.Errorf("low priority pod = %q/%d/%v, want surge/1000/Never", low.Spec.PriorityClassName, *low.Spec.Priority, low.Spec.PreemptionPolicy)
+	}
+
+	for _, pod := range []*corev1.Pod{podAt("app-1", 2000), podAt("missing-1", 0)} {
+		if err := d.Default(context.Background(), pod); err != nil {
+			t.Fatal(err)
+		}
+		if pod.Spec.PriorityClassName != "default" {
+			t.Errorf("pod at priority %d moved to %q", *pod.Spec.Priority, pod.Spec.PriorityClassName)
+		}
+	}
+}
+
+func TestPodPlacementGatesSurgeBatch(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := appsv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	batched := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default",
+		Annotations: map[string]string{annotations.SurgeBatch: "app-5"}}}
+	d := &PodPlacementCustomDefaulter{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(batched,
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "app-1", Namespace: "default", OwnerReferences: controlledBy("Deployment", "app")}},
+	).Build()}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", OwnerReferences: controlledBy("ReplicaSet", "app-1")},
+		Spec:       corev1.PodSpec{SchedulingGates: []corev1.PodSchedulingGate{{Name: "example.com/other"}}},
+	}
+	for range 2 { // a reinvoked webhook must not gate twice
+		if err := d.Default(context.Background(), pod); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := pod.Annotations[annotations.SurgeBatch]; got != "app-5" {
+		t.Errorf("batch annotation = %q, want app-5", got)
+	}
+	gates := pod.Spec.SchedulingGates
+	if len(gates) != 2 || gates[0].Name != "example.com/other" || gates[1].Name != annotations.SurgeBatch {
+		t.Errorf("scheduling gates = %v, want the existing gate and %s", gates, annotations.SurgeBatch)
+	}
+}
+
+func TestPodPlacementFollowsFeatureFlags(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := appsv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	hinted := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default",
+		Annotations: map[string]string{annotations.AvoidNodes: "node-a", annotations.SurgeBatch: "app-5"}}}
+	flagsCM := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "flags", Namespace: "eviction-autoscaler"},
+		Data: map[string]string{string(features.SurgeBatches): "false", string(features.WebhookGating): "default"}}
+	otherHinted := hinted.DeepCopy()
+	otherHinted.Namespace = "other"
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(hinted, otherHinted, flagsCM,
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "app-1", Namespace: "default", OwnerReferences: controlledBy("Deployment", "app")}},
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "app-1", Namespace: "other", OwnerReferences: controlledBy("Deployment", "app")}},
+	).Build()
+	flags := features.New("eviction-autoscaler", "flags", time.Second)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // read the flags once
+	if err := flags.Watcher(c).Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	d := &PodPlacementCustomDefaulter{Client: c, Features: flags}
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", OwnerReferences: controlledBy("ReplicaSet", "app-1")}}
+	if err := d.Default(context.Background(), pod); err != nil {
+		t.Fatal(err)
+	}
+	if pod.Spec.Affinity == nil {
+		t.Error("expected webhook gating to apply the placement hint in a targeted namespace")
+	}
+	if len(pod.Spec.SchedulingGates) != 0 {
+		t.Errorf("scheduling gates = %v, want none with surge batching off", pod.Spec.SchedulingGates)
+	}
+
+	other := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "other", OwnerReferences: controlledBy("ReplicaSet", "app-1")}}
+	if err := d.Default(context.Background(), other); err != nil {
+		t.Fatal(err)
+	}
+	if other.Spec.Affinity != nil {
+		t.Error("expected a namespace without webhook gating to be left alone")
+	}
+}
+
+func TestPodPlacementPinsSurgePodNode(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := appsv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	hinted := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default",
+		Annotations: map[string]string{annotations.AutoscalerHints: "true"}}}
+	d := &PodPlacementCustomDefaulter{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(hinted,
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "app-1", Namespace: "default", OwnerReferences: controlledBy("Deployment", "app")}},
+	).Build()}
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", OwnerReferences: controlledBy("ReplicaSet", "app-1"),
+		Annotations: map[string]string{annotations.SafeToEvict: "true"}}}
+	if err := d.Default(context.Background(), pod); err != nil {
+		t.Fatal(err)
+	}
+	if got := pod.Annotations[annotations.SafeToEvict]; got != "true" {
+		t.Errorf("safe-to-evict = %q, the pod's own value should be kept", got)
+	}
+	if got := pod.Annotations[annotations.DoNotDisrupt]; got != "true" {
+		t.Errorf("do-not-disrupt = %q, want true", got)
+	}
+	if got := pod.Annotations[annotations.AutoscalerHints]; got != annotations.DoNotDisrupt {
+		t.Errorf("autoscaler hints = %q, want only the annotation added", got)
+	}
+}
@@ -28,7 +28,6 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	policy "k8s.io/api/policy/v1"
-	"k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/util/homedir"
@@ -307,33 +306,9 @@ var _ = Describe("controller", Ordered, func() {
 			EventuallyWithOffset(1, verifyAnnotationExists, time.Minute, time.Second).Should(Succeed())
 
 			By("By Draining " + nodeName)
-			drain := func() error {
-				var podsmeta = []v1.ObjectMeta{}
-				var namespaces = &corev1.NamespaceList{}
-				err := clientset.List(ctx, namespaces)
-				ExpectWithOffset(1, err).NotTo(HaveOccurred())
-				for _, ns := range namespaces.Items {
-					var pods = &corev1.PodList{}
-					err := clientset.List(ctx, pods, client.InNamespace(ns.Name), client.MatchingFields{"spec.nodeName": nodeName})
-					ExpectWithOffset(1, err).NotTo(HaveOccurred())
-					for _, p := range pods.Items {
-						podsmeta = append(podsmeta, p.ObjectMeta)
-					}
-				}
-				//todo parallize so
-				for _, meta := range podsmeta {
-					err = evictionClient.PolicyV1().Evictions(meta.Namespace).Evict(ctx, &policy.Eviction{
-						ObjectMeta: meta,
-					})
-					if errors.IsTooManyRequests(err) {
-						return fmt.Errorf("failed to evict %s/%s: %v", meta.Namespace, meta.Name, err)
-					}
-					ExpectWithOffset(1, err).NotTo(HaveOccurred())
-					fmt.Printf("evicted %s/%s\n", meta.Namespace, meta.Name)
-				}
-				return nil
-			}
-			EventuallyWithOffset(1, drain, time.Minute, time.Second).Should(Succeed())
+			drainCtx, cancelDrain := context.WithTimeout(ctx, time.Minute)
+			defer cancelDrain()
+			Expect(utils.DrainNode(drainCtx, evictionClient, nodeName, utils.DrainOptions{})).To(Succeed())
 			//verify there is always one running pod? other might be terminating/creating so need different
 			//check that there are two pods temporarily or does that not matter as long as we successfully evicted?
 			By("Verifying we scale back down")
@@ -1137,24 +1112,9 @@ var _ = Describe("controller", Ordered, func() {
 			}, 2*time.Minute, time.Second).Should(Succeed())
 
 			By("draining the node to trigger evictions")
-			drain := func() error {
-				var podList corev1.PodList
-				err := clientset.List(ctx, &podList, client.InNamespace(testNs),
-					client.MatchingFields{"spec.nodeName": nodeName})
-				if err != nil {
-					return err
-				}
-				for _, p := range podList.Items {
-					err = evictionClient.PolicyV1().Evictions(p.Namespace).Evict(ctx, &policy.Eviction{
-						ObjectMeta: p.ObjectMeta,
-					})
-					if err != nil {
-						return fmt.Errorf("failed to evict %s: %w", p.Name, err)
-					}
-				}
-				return nil
-			}
-			EventuallyWithOffset(1, drain, time.Minute, time.Second).Should(Succeed())
+			drainCtx, cancelDrain := context.WithTimeout(ctx, time.Minute)
+			defer cancelDrain()
+			Expect(utils.DrainNode(drainCtx, evictionClient, nodeName, utils.DrainOptions{Namespace: testNs})).To(Succeed())
 
 			By("verifying HPA minReplicas reverts to 1 after cooldown")
 			EventuallyWithOffset(1, func() error {
@@ -1271,6 +1231,108 @@ var _ = Describe("controller", Ordered, func() {
 			_, _ = utils.Run(cmd)
 		})
 
+		// Test 3c: ownership transfer during an active surge - the EvictionAutoScaler keeps and reverts the surge
+		It("should revert an active surge after the user takes ownership of the PDB", func() {
+			ctx := context.Background()
+			testNs := "test-ownership-surge"
+			name := "nginx-ownership-surge"
+
+			By("creating test namespace with enable annotation")
+			cmd := exec.Command("kubectl", "create", "namespace", testNs)
+			_, err := utils.Run(cmd)
+			Expect(err).NotTo(HaveOccurred())
+
+			cmd = exec.Command("kubectl", "annotate", "namespace", testNs,
+				"eviction-autoscaler.azure.com/enable=true")
+			_, err = utils.Run(cmd)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("creating a deployment with 3 replicas and maxUnavailable=0")
+			err = createDeployment(deploymentConfig{
+				Name:           name,
+				Namespace:      testNs,
+				Replicas:       3,
+				MaxUnavailable: 0,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			err = waitForDeployment(name, testNs)
+			Expect(err).NotTo(HaveOccurred())
+
+			config, err := clientcmd.BuildConfigFromFlags("", filepath.Join(homedir.HomeDir(), ".kube", "config"))
+			Expect(err).NotTo(HaveOccurred())
+			clientset, err := client.New(config, client.Options{Scheme: scheme})
+			Expect(err).NotTo(HaveOccurred())
+
+			EventuallyWithOffset(1, func() error {
+				return verifyPdbMinAvailable(ctx, clientset, testNs, name, 3)
+			}, time.Minute, time.Second).Should(Succeed())
+			EventuallyWithOffset(1, func() error {
+				return verifyEvictionAutoScalerCreated(ctx, clientset, testNs, name)
+			}, time.Minute, time.Second).Should(Succeed())
+
+			By("recording a blocked eviction to start a surge")
+			EventuallyWithOffset(1, func() error {
+				var pdb policy.PodDisruptionBudget
+				if err := clientset.Get(ctx, client.ObjectKey{Namespace: testNs, Name: name}, &pdb); err != nil {
+					return err
+				}
+				if pdb.Status.DisruptionsAllowed != 0 {
+					return fmt.Errorf("expected 0 disruptions allowed, got %d", pdb.Status.DisruptionsAllowed)
+				}
+				var eas types.EvictionAutoScaler
+				if err := clientset.Get(ctx, client.ObjectKey{Namespace: testNs, Name: name}, &eas); err != nil {
+					return err
+				}
+				eas.Spec.LastEviction = types.Eviction{PodName: name + "-evicted", EvictionTime: v1.Now()}
+				return clientset.Update(ctx, &eas)
+			}, time.Minute, time.Second).Should(Succeed())
+
+			surged := func() error {
+				var dep appsv1.Deployment
+				if err := clientset.Get(ctx, client.ObjectKey{Namespace: testNs, Name: name}, &dep); err != nil {
+					return err
+				}
+				if _, ok := dep.Annotations["evictionSurgeReplicas"]; !ok || *dep.Spec.Replicas != 4 {
+					return fmt.Errorf("deployment not surged: replicas=%d annotations=%v", *dep.Spec.Replicas, dep.Annotations)
+				}
+				return nil
+			}
+			EventuallyWithOffset(1, surged, time.Minute, time.Second).Should(Succeed())
+
+			By("removing ownedBy annotation from PDB while the surge is active")
+			cmd = exec.Command("kubectl", "annotate", "pdb/"+name, "--namespace", testNs, "ownedBy-", "--overwrite")
+			_, err = utils.Run(cmd)
+			Expect(err).NotTo(HaveOccurred())
+			EventuallyWithOffset(1, func() error {
+				return verifyNoOwnerReference(ctx, clientset, testNs, name)
+			}, time.Minute, time.Second).Should(Succeed())
+
+			By("verifying the surge and the EvictionAutoScaler survive the transfer")
+			Expect(surged()).To(Succeed())
+			Expect(verifyEvictionAutoScalerCreated(ctx, clientset, testNs, name)).To(Succeed())
+
+			By("verifying the EvictionAutoScaler reverts the surge and clears the annotation after cooldown")
+			EventuallyWithOffset(1, func() error {
+				var dep appsv1.Deployment
+				if err := clientset.Get(ctx, client.ObjectKey{Namespace: testNs, Name: name}, &dep); err != nil {
+					return err
+				}
+				if _, ok := dep.Annotations["evictionSurgeReplicas"]; ok || *dep.Spec.Replicas != 3 {
+					return fmt.Errorf("surge not reverted: replicas=%d annotations=%v", *dep.Spec.Replicas, dep.Annotations)
+				}
+				return nil
+			}, 3*time.Minute, time.Second).Should(Succeed())
+
+			By("verifying the user-owned PDB keeps its minAvailable")
+			Expect(verifyPdbMinAvailable(ctx, clientset, testNs, name, 3)).To(Succeed())
+
+			By("cleaning up ownership surge test resources")
+			deleteDeployment(name, testNs)
+			deletePDB(name, testNs)
+			cmd = exec.Command("kubectl", "delete", "namespace", testNs)
+			_, _ = utils.Run(cmd)
+		})
+
 		// Test 4: KEDA surge strategy - when a ScaledObject exists, surge by updating minReplicaCount
 		It("should surge via KEDA ScaledObject minReplicaCount when a ScaledObject targets the deployment", func() {
 			ctx := context.Background()
@@ -1482,24 +1544,9 @@ var _ = Describe("controller", Ordered, func() {
 			}, 2*time.Minute, time.Second).Should(Succeed())
 
 			By("draining the node to trigger evictions")
-			drain := func() error {
-				var podList corev1.PodList
-				err := clientset.List(ctx, &podList, client.InNamespace(testNs),
-					client.MatchingFields{"spec.nodeName": nodeName})
-				if err != nil {
-					return err
-				}
-				for _, p := range podList.Items {
-					err = evictionClient.PolicyV1().Evictions(p.Namespace).Evict(ctx, &policy.Eviction{
-						ObjectMeta: p.ObjectMeta,
-					})
-					if err != nil {
-						return fmt.Errorf("failed to evict %s: %w", p.Name, err)
-					}
-				}
-				return nil
-			}
-			EventuallyWithOffset(1, drain, time.Minute, time.Second).Should(Succeed())
+			drainCtx, cancelDrain := context.WithTimeout(ctx, time.Minute)
+			defer cancelDrain()
+			Expect(utils.DrainNode(drainCtx, evictionClient, nodeName, utils.DrainOptions{Namespace: testNs})).To(Succeed())
 
 			By("verifying ScaledObject minReplicaCount reverts to 1 after cooldown")
 			EventuallyWithOffset(1, func() error {
@@ -1619,25 +1666,10 @@ var _ = Describe("controller", Ordered, func() {
 			}, 2*time.Minute, time.Second).Should(Succeed())
 
 			By("draining the node to complete the eviction")
-			drain := func() error {
-				var podList corev1.PodList
-				if err := clientset.List(ctx, &podList, client.InNamespace(aksNs),
-					client.MatchingFields{"spec.nodeName": nodeName}); err != nil {
-					return err
-				}
-				for _, pod := range podList.Items {
-					if pod.Labels["app"] != depName {
-						continue
-					}
-					if err := evictionClient.PolicyV1().Evictions(pod.Namespace).Evict(ctx, &policy.Eviction{
-						ObjectMeta: pod.ObjectMeta,
-					}); err != nil {
-						return fmt.Errorf("failed to evict %s: %w", pod.Name, err)
-					}
-				}
-				return nil
-			}
-			EventuallyWithOffset(1, drain, time.Minute, time.Second).Should(Succeed())
+			drainCtx, cancelDrain := context.WithTimeout(ctx, time.Minute)
+			defer cancelDrain()
+			Expect(utils.DrainNode(drainCtx, evictionClient, nodeName,
+				utils.DrainOptions{Namespace: aksNs, LabelSelector: "app=" + depName})).To(Succeed())
 
 			By("verifying the deployment scales back down to 1 and clears the surge annotation")
 			EventuallyWithOffset(1, func() error {
@@ -0,0 +1,117 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	defaultDrainConcurrency = 10
+	defaultRetryInterval    = time.Second
+)
+
+// DrainOptions narrows and tunes a drain. The zero value evicts every pod on the
+// node, ten at a time, retrying blocked evictions every second.
+type DrainOptions struct {
+	// Namespace limits the drain to one namespace; empty means all namespaces.
+	Namespace string
+	// LabelSelector limits the drain to matching pods.
+	LabelSelector string
+	// Concurrency caps the evictions in flight.
+	Concurrency int
+	// RetryInterval is the wait before retrying an eviction a PDB blocked.
+	RetryInterval time.Duration
+}
+
+// DrainNode evicts the pods on node concurrently through the Eviction API, like
+// kubectl drain without the cordon. It returns once every pod is evicted, or with
+// the errors of those that weren't when ctx is done; callers bound it with a
+// deadline.
+func DrainNode(ctx context.Context, clientset kubernetes.Interface, node string, opts DrainOptions) error {
+	pods, err := clientset.CoreV1().Pods(opts.Namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", node).String(),
+		LabelSelector: opts.LabelSelector,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list pods on node %s: %w", node, err)
+	}
+	return EvictPods(ctx, clientset, pods.Items, opts)
+}
+
+// EvictPods evicts pods with at most opts.Concurrency evictions in flight. Each
+// eviction is retried while a PDB blocks it; see EvictPod.
+func EvictPods(ctx context.Context, clientset kubernetes.Interface, pods []corev1.Pod, opts DrainOptions) error {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultDrainConcurrency
+	}
+	slots := make(chan struct{}, concurrency)
+	errs := make([]error, len(pods))
+	var wg sync.WaitGroup
+	for i := range pods {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				errs[i] = fmt.Errorf("failed to evict %s/%s: %w", pods[i].Namespace, pods[i].Name, ctx.Err())
+				return
+			}
+			defer func() { <-slots }()
+			errs[i] = EvictPod(ctx, clientset, pods[i].Namespace, pods[i].Name, opts.RetryInterval)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// EvictPod evicts one pod, retrying every retryInterval while the API server
+// answers 429 Too Many Requests, which is how it reports a PDB that allows no
+// disruptions. A pod that is already gone counts as evicted.
+func EvictPod(ctx context.Context, clientset kubernetes.Interface, namespace, name string, retryInterval time.Duration) error {
+	if retryInterval <= 0 {
+		retryInterval = defaultRetryInterval
+	}
+	eviction := &policy.Eviction{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+	for {
+		err := clientset.PolicyV1().Evictions(namespace).Evict(ctx, eviction)
+		if err == nil || apierrors.IsNotFound(err) {
+			return nil
+		}
+		if !apierrors.IsTooManyRequests(err) {
+			return fmt.Errorf("failed to evict %s/%s: %w", namespace, name, err)
+		}
+		select {
+		case <-time.After(retryInterval):
+		case <-ctx.Done():
+			return fmt.Errorf("failed to evict %s/%s: %w", namespace, name, errors.Join(err, ctx.Err()))
+		}
+	}
+}
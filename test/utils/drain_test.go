@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func pod(name, node string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       corev1.PodSpec{NodeName: node},
+	}
+}
+
+func TestDrainNodeRetriesBlockedEvictions(t *testing.T) {
+	// The fake clientset ignores field selectors, so only pods on the drained node exist.
+	clientset := fake.NewClientset(pod("a", "node-1"), pod("b", "node-1"))
+	var calls, blocked atomic.Int32
+	clientset.PrependReactor("create", "pods/eviction", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		calls.Add(1)
+		// The PDB blocks the first two evictions, whichever pods they are for.
+		if blocked.Add(1) <= 2 {
+			return true, nil, apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0)
+		}
+		return true, nil, nil
+	})
+
+	err := DrainNode(context.Background(), clientset, "node-1", DrainOptions{RetryInterval: time.Millisecond})
+	if err != nil {
+		t.Fatalf("DrainNode() error = %v", err)
+	}
+	if got := calls.Load(); got != 4 {
+		t.Errorf("eviction calls = %d, want 4", got)
+	}
+}
+
+func TestEvictPodGivesUpWhenContextEnds(t *testing.T) {
+	clientset := fake.NewClientset(pod("a", "node-1"))
+	clientset.PrependReactor("create", "pods/eviction", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewTooManyRequests("blocked", 0)
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := EvictPod(ctx, clientset, "default", "a", time.Millisecond)
+	if !apierrors.IsTooManyRequests(err) {
+		t.Errorf("EvictPod() error = %v, want the last 429", err)
+	}
+}
+
+func TestEvictPodTreatsMissingPodAsEvicted(t *testing.T) {
+	clientset := fake.NewClientset()
+	clientset.PrependReactor("create", "pods/eviction", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewNotFound(corev1.Resource("pods"), "a")
+	})
+	if err := EvictPod(context.Background(), clientset, "default", "a", time.Millisecond); err != nil {
+		t.Errorf("EvictPod() error = %v", err)
+	}
+}